@@ -11,6 +11,9 @@ import (
 	"github.com/LINSITrack/backend/src/routes"
 	"github.com/LINSITrack/backend/src/seed"
 	"github.com/LINSITrack/backend/src/services"
+	"github.com/LINSITrack/backend/utils/mailer"
+	"github.com/LINSITrack/backend/utils/storage"
+	"github.com/LINSITrack/backend/utils/upload"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -26,6 +29,14 @@ func initAuth() {
 	middleware.SetSecretKey(secretKey)
 }
 
+// URL del frontend para los enlaces que se envían por email
+func frontendURL() string {
+	if url := os.Getenv("FRONTEND_URL"); url != "" {
+		return url
+	}
+	return "http://localhost:3000"
+}
+
 func main() {
 
 	// Database connection
@@ -40,11 +51,17 @@ func main() {
 	// Setup de router
 	router := gin.Default()
 
+	// Proxies de confianza: solo de ellos se acepta X-Forwarded-For como IP del cliente
+	if err := middleware.SetTrustedProxies(router, middleware.TrustedProxiesFromEnv()); err != nil {
+		log.Fatalf("Error configuring TRUSTED_PROXIES: %v\n", err)
+	}
+
 	// Configuración CORS
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:3000", "http://localhost:8080"},
-		AllowMethods:     []string{"GET", "POST", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "Range", "If-None-Match", "If-Range"},
+		ExposeHeaders:    []string{"Content-Disposition", "Content-Range", "Accept-Ranges", "ETag"},
 		AllowCredentials: true,
 	}))
 
@@ -67,9 +84,6 @@ func main() {
 		c.String(200, "Hello from Gin! Server is up and running. (Protected Route)")
 	})
 
-	// Serve uploads directory so frontend can access uploaded files
-	router.Static("/uploads", "./uploads")
-
 	// // (¡Peligro: Borra la base de datos al descomentar! Excepto las instancias creadas con la seed al iniciar el servidor)
 	//  	db.Migrator().DropTable(
 	// 	&models.Profesor{},
@@ -83,18 +97,19 @@ func main() {
 	// 	&models.TpModel{},
 	// 	&models.Competencia{},
 	// 	&models.Entrega{},
-	// 	&models.Archivo{},
+	// 	&models.ArchivoMetadata{},
 	// 	&models.EvaluacionModel{},
 	// 	&models.Anexo{},
-	// 	&models.AnexoArchivo{},
 	// ) 
 
 	// Automigraciones
 	if err := db.AutoMigrate(
+		&models.Usuario{},
 		&models.Profesor{},
 		&models.Admin{},
 		&models.Alumno{},
 		&models.Materia{},
+		&models.PeriodoAcademico{},
 		&models.Comision{},
 		&models.Cursada{},
 		&models.Notificacion{},
@@ -104,11 +119,31 @@ func main() {
 		&models.Competencia{},
 		&models.MateriaCompetencia{},
 		&models.Entrega{},
-		&models.Archivo{},
+		&models.ArchivoMetadata{},
 		&models.EvaluacionModel{},
 		&models.EntregaEvaluacion{},
 		&models.Anexo{},
-		&models.AnexoArchivo{},
+		&models.Session{},
+		&models.RevokedToken{},
+		&models.AccountToken{},
+		&models.LoginAttempt{},
+		&models.LoginLock{},
+		&models.ApiKey{},
+		&models.ProrrogaTP{},
+		&models.IntentoEntregaTP{},
+		&models.TransicionEntregaTP{},
+		&models.GrupoTP{},
+		&models.IntegranteGrupoTP{},
+		&models.EsquemaCalificacion{},
+		&models.PesoItemEsquema{},
+		&models.AjusteNotaFinal{},
+		&models.ReglasCondicion{},
+		&models.Clase{},
+		&models.Asistencia{},
+		&models.HorarioComision{},
+		&models.CalendarioToken{},
+		&models.Correlatividad{},
+		&models.ExcepcionCorrelatividad{},
 	); err != nil {
 		log.Fatalf("Error during auto migration: %v\n", err)
 	}
@@ -126,6 +161,14 @@ func main() {
 		}
 	}
 
+	// Storage de archivos subidos (disco local o S3/MinIO según STORAGE_BACKEND)
+	archivoService := services.NewArchivoService(db, storage.NewFromEnv(), upload.MaxSizeFromEnv())
+
+	// Pasar al storage los archivos subidos antes de la capa de storage
+	if err := services.MigrateArchivos(db, archivoService); err != nil {
+		log.Fatalf("Error migrating archivos: %v\n", err)
+	}
+
 	// Ejecutar seed inicial de la DB
 	log.Println("=== Iniciando proceso de seeding ===")
 	seed.AdminSeed(db)
@@ -138,13 +181,42 @@ func main() {
 	seed.ProfesorXComisionSeed(db)
 	seed.TpSeed(db)
 	seed.CompetenciaSeed(db)
-	seed.EntregaSeed(db)
+	seed.EntregaSeed(db, archivoService)
 	seed.EvaluacionSeed(db)
-	seed.AnexoSeed(db)
+	seed.AnexoSeed(db, archivoService)
 	log.Println("=== Seeding completado ===")
 
+	// Vincular perfiles sin identidad (filas previas a la tabla usuarios o creadas por los seeds)
+	if err := services.MigrateUsuarios(db); err != nil {
+		log.Fatalf("Error migrating usuarios: %v\n", err)
+	}
+
+	// Crear el intento 1 de las entregas previas al historial de versiones
+	if err := services.MigrateIntentos(db); err != nil {
+		log.Fatalf("Error migrating intentos: %v\n", err)
+	}
+
+	// Llevar los estados de texto libre de las entregas previas a los de la máquina de estados
+	if err := services.MigrateEstadosEntrega(db); err != nil {
+		log.Fatalf("Error migrating estados de entregas: %v\n", err)
+	}
+
+	// Marcar como cargadas las notas conceptuales previas a nota_conceptual_cargada
+	if err := services.MigrateNotasConceptuales(db); err != nil {
+		log.Fatalf("Error migrating notas conceptuales: %v\n", err)
+	}
+
+	// Crear las franjas horarias de las comisiones que solo tienen el texto libre
+	if err := services.MigrateHorarios(db); err != nil {
+		log.Fatalf("Error migrating horarios: %v\n", err)
+	}
+
 	// Setup de services
 	authService := services.NewAuthService(db)
+	middleware.SetRevocationChecker(authService.IsTokenRevoked)
+	accountService := services.NewAccountService(db, mailer.NewFromEnv(), authService, frontendURL())
+	apiKeyService := services.NewApiKeyService(db, authService)
+	middleware.SetAPIKeyResolver(apiKeyService.Authenticate)
 	profesorService := services.NewProfesorService(db)
 	adminService := services.NewAdminService(db)
 	alumnoService := services.NewAlumnoService(db)
@@ -156,27 +228,51 @@ func main() {
 	tpService := services.NewTpService(db)
 	competenciaService := services.NewCompetenciaService(db)
 	materiaCompetenciaService := services.NewMateriaCompetenciaService(db)
-	// entregaService := services.NewEntregaService(db) // Commented out - using EntregaTP instead
+	// entregaService := services.NewEntregaService(db, archivoService) // Commented out - using EntregaTP instead
 	evaluacionService := services.NewEvaluacionService(db)
-	anexoService := services.NewAnexoService(db)
+	anexoService := services.NewAnexoService(db, archivoService)
+	policyService := services.NewPolicyService(db)
+	plazoService := services.NewPlazoService(db)
+	entregaEstadoService := services.NewEntregaEstadoService(db)
+	grupoService := services.NewGrupoService(db, entregaEstadoService)
+	notasImportService := services.NewNotasImportService(db, entregaEstadoService, grupoService)
+	libretaService := services.NewLibretaService(db)
+	notaFinalService := services.NewNotaFinalService(db)
+	condicionService := services.NewCondicionService(db)
+	asistenciaService := services.NewAsistenciaService(db)
+	horarioService := services.NewHorarioService(db)
+	calendarioService := services.NewCalendarioService(db)
+	periodoService := services.NewPeriodoService(db)
+	correlatividadService := services.NewCorrelatividadService(db)
 
 	// Setup de rutas
-	routes.SetupAuthRoutes(router, authService)
-	routes.SetupProfesoresRoutes(router, profesorService)
-	routes.SetupAdminsRoutes(router, adminService)
-	routes.SetupAlumnosRoutes(router, alumnoService)
+	routes.SetupAuthRoutes(router, authService, accountService)
+	routes.SetupApiKeyRoutes(router, apiKeyService)
+	routes.SetupProfesoresRoutes(router, profesorService, accountService)
+	routes.SetupAdminsRoutes(router, adminService, accountService)
+	routes.SetupAlumnosRoutes(router, alumnoService, accountService)
 	routes.SetupMateriasRoutes(router, materiaService)
 	routes.SetupComisionRoutes(router, comisionService)
-	routes.SetupCursadasRoutes(router, cursadaService)
-	routes.SetupNotificacionRoutes(router, notificacionService)
+	routes.SetupCursadasRoutes(router, cursadaService, policyService)
+	routes.SetupNotificacionRoutes(router, notificacionService, policyService)
 	routes.SetupProfesorXComisionRoutes(router, profesorXComisionService)
-	routes.SetupTpRoutes(router, tpService)
-	routes.SetupEntregaTPRoutes(router, db)
-	routes.SetupCompetenciaRoutes(router, competenciaService)
+	routes.SetupTpRoutes(router, tpService, policyService, plazoService, grupoService)
+	routes.SetupEntregaTPRoutes(router, db, policyService, archivoService)
+	routes.SetupCompetenciaRoutes(router, competenciaService, policyService)
 	routes.SetupMateriaCompetenciaRoutes(router, materiaCompetenciaService)
-	// routes.SetupEntregaRoutes(router, entregaService) // Commented out - using EntregaTP instead
-	routes.SetupEvaluacionRoutes(router, evaluacionService)
-	routes.SetupAnexoRoutes(router, anexoService)
+	// routes.SetupEntregaRoutes(router, entregaService, policyService, archivoService) // Commented out - using EntregaTP instead
+	routes.SetupEvaluacionRoutes(router, evaluacionService, policyService)
+	routes.SetupNotasImportRoutes(router, notasImportService, policyService)
+	routes.SetupLibretaRoutes(router, libretaService, policyService)
+	routes.SetupNotaFinalRoutes(router, notaFinalService, policyService)
+	routes.SetupCondicionRoutes(router, condicionService)
+	routes.SetupAsistenciaRoutes(router, asistenciaService, policyService)
+	routes.SetupHorarioRoutes(router, horarioService, policyService)
+	routes.SetupCalendarioRoutes(router, calendarioService)
+	routes.SetupPeriodoRoutes(router, periodoService)
+	routes.SetupCorrelatividadRoutes(router, correlatividadService)
+	routes.SetupAnexoRoutes(router, anexoService, policyService, archivoService)
+	routes.SetupArchivoRoutes(router, archivoService, policyService)
 
 	// Run
 	router.Run()
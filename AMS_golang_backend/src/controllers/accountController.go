@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/LINSITrack/backend/src/models"
+	"github.com/LINSITrack/backend/src/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type AccountController struct {
+	service *services.AccountService
+}
+
+func NewAccountController(service *services.AccountService) *AccountController {
+	return &AccountController{service: service}
+}
+
+func (c *AccountController) ForgotPassword(ctx *gin.Context) {
+	var req models.ForgotPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.service.RequestPasswordReset(req.Email); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Misma respuesta exista o no la cuenta
+	ctx.JSON(http.StatusOK, gin.H{"message": "Si el email está registrado, recibirás un enlace para restablecer la contraseña"})
+}
+
+func (c *AccountController) ResetPassword(ctx *gin.Context) {
+	var req models.RedeemTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.service.ResetPassword(req.Token, req.Password); err != nil {
+		c.respondRedeemError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Contraseña actualizada"})
+}
+
+func (c *AccountController) ActivateAccount(ctx *gin.Context) {
+	var req models.RedeemTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.service.ActivateAccount(req.Token, req.Password); err != nil {
+		c.respondRedeemError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Cuenta activada"})
+}
+
+// SendInvitation reenvía la invitación de una cuenta pendiente (solo admins)
+func (c *AccountController) SendInvitation(ctx *gin.Context) {
+	usuarioID, err := strconv.Atoi(ctx.Param("usuarioId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	if err := c.service.SendInvitation(usuarioID); err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "usuario no encontrado"})
+		case errors.Is(err, services.ErrUsuarioNoPendiente):
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Invitación enviada"})
+}
+
+func (c *AccountController) respondRedeemError(ctx *gin.Context, err error) {
+	if errors.Is(err, services.ErrInvalidAccountToken) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
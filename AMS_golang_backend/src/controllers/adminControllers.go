@@ -12,11 +12,12 @@ import (
 )
 
 type AdminController struct {
-	service *services.AdminService
+	service        *services.AdminService
+	accountService *services.AccountService
 }
 
-func NewAdminController(service *services.AdminService) *AdminController {
-	return &AdminController{service: service}
+func NewAdminController(service *services.AdminService, accountService *services.AccountService) *AdminController {
+	return &AdminController{service: service, accountService: accountService}
 }
 
 func (c *AdminController) GetAllAdmins(ctx *gin.Context) {
@@ -58,6 +59,8 @@ func (c *AdminController) CreateAdmin(ctx *gin.Context) {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	// Si se creó sin contraseña, se envía la invitación para activar la cuenta
+	c.accountService.SendInvitationIfPending(createdAdmin.UsuarioID)
 	ctx.JSON(http.StatusCreated, createdAdmin)
 }
 
@@ -12,27 +12,17 @@ import (
 )
 
 type AlumnoController struct {
-	service *services.AlumnoService
+	service        *services.AlumnoService
+	accountService *services.AccountService
 }
 
-func NewAlumnoController(service *services.AlumnoService) *AlumnoController {
-	return &AlumnoController{service: service}
+func NewAlumnoController(service *services.AlumnoService, accountService *services.AccountService) *AlumnoController {
+	return &AlumnoController{service: service, accountService: accountService}
 }
 
 func (c *AlumnoController) UpdateAlumno(ctx *gin.Context) {
+	// La ruta ya verifica que un alumno solo modifique su propia cuenta
 	id := ctx.Param("id")
-	userID, _ := ctx.Get("userID")
-	userRole, _ := ctx.Get("userRole")
-
-	// Si es alumno, verificar que solo modifique su propia cuenta
-	if userRole.(string) == string(models.RoleAlumno) {
-		userIDStr := strconv.Itoa(int(userID.(float64)))
-		if userIDStr != id {
-			ctx.JSON(http.StatusForbidden, gin.H{"error": "solo puedes modificar tu propia cuenta"})
-			return
-		}
-	}
-	// Si es admin, puede modificar cualquier cuenta
 
 	var updateReq models.AlumnoUpdateRequest
 
@@ -99,6 +89,8 @@ func (c *AlumnoController) CreateAlumno(ctx *gin.Context) {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	// Si se creó sin contraseña, se envía la invitación para activar la cuenta
+	c.accountService.SendInvitationIfPending(createdAlumno.UsuarioID)
 	ctx.JSON(http.StatusCreated, createdAlumno)
 }
 
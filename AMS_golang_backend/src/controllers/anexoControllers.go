@@ -2,20 +2,21 @@ package controllers
 
 import (
 	"net/http"
-	"os"
 	"strconv"
 
+	"github.com/LINSITrack/backend/src/middleware"
 	"github.com/LINSITrack/backend/src/models"
 	"github.com/LINSITrack/backend/src/services"
 	"github.com/gin-gonic/gin"
 )
 
 type AnexoController struct {
-	anexoService *services.AnexoService
+	anexoService   *services.AnexoService
+	archivoService *services.ArchivoService
 }
 
-func NewAnexoController(anexoService *services.AnexoService) *AnexoController {
-	return &AnexoController{anexoService: anexoService}
+func NewAnexoController(anexoService *services.AnexoService, archivoService *services.ArchivoService) *AnexoController {
+	return &AnexoController{anexoService: anexoService, archivoService: archivoService}
 }
 
 func (c *AnexoController) GetAllAnexos(ctx *gin.Context) {
@@ -122,22 +123,16 @@ func (c *AnexoController) UploadAnexoArchivo(ctx *gin.Context) {
 		return
 	}
 
-	// Validaciones básicas del archivo
-	if file.Size == 0 {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "El archivo está vacío"})
-		return
-	}
-
-	// Límite de tamaño (ejemplo: 100MB para anexos)
-	maxSize := int64(100 * 1024 * 1024) // 100MB
-	if file.Size > maxSize {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "El archivo excede el tamaño máximo permitido (100MB)"})
-		return
-	}
+	userID, _ := middleware.CurrentUserID(ctx)
+	userRole, _ := ctx.Get("userRole")
+	role, _ := userRole.(string)
 
-	// Guardar archivo
-	anexoArchivo, err := c.anexoService.SaveFile(file, anexoID)
+	// Guardar archivo (el servicio valida tamaño, extensión y tipo real)
+	anexoArchivo, err := c.anexoService.SaveFile(file, anexoID, userID, models.Role(role))
 	if err != nil {
+		if respondUploadError(ctx, err) {
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Error al guardar el archivo",
 			"details": err.Error(),
@@ -214,21 +209,7 @@ func (c *AnexoController) DownloadAnexoArchivo(ctx *gin.Context) {
 		return
 	}
 
-	// Verificar que el archivo físico existe
-	if _, err := os.Stat(anexoArchivo.FilePath); os.IsNotExist(err) {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "El archivo físico no existe"})
-		return
-	}
-
-	// Establecer headers para la descarga
-	ctx.Header("Content-Description", "File Transfer")
-	ctx.Header("Content-Transfer-Encoding", "binary")
-	ctx.Header("Content-Disposition", "attachment; filename="+anexoArchivo.OriginalName)
-	ctx.Header("Content-Type", anexoArchivo.ContentType)
-	ctx.Header("Content-Length", strconv.FormatInt(anexoArchivo.Size, 10))
-
-	// Enviar archivo
-	ctx.File(anexoArchivo.FilePath)
+	serveArchivo(ctx, c.archivoService, anexoArchivo)
 }
 
 // GetAnexosByTpID - Obtiene todos los anexos de un TP específico
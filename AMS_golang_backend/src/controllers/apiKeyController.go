@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/LINSITrack/backend/src/middleware"
+	"github.com/LINSITrack/backend/src/models"
+	"github.com/LINSITrack/backend/src/services"
+	"github.com/gin-gonic/gin"
+)
+
+type ApiKeyController struct {
+	service *services.ApiKeyService
+}
+
+func NewApiKeyController(service *services.ApiKeyService) *ApiKeyController {
+	return &ApiKeyController{service: service}
+}
+
+func (c *ApiKeyController) CreateApiKey(ctx *gin.Context) {
+	var req models.ApiKeyCreateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	adminID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "ID de usuario no encontrado"})
+		return
+	}
+
+	created, err := c.service.CreateApiKey(&req, adminID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusCreated, created)
+}
+
+func (c *ApiKeyController) GetApiKeys(ctx *gin.Context) {
+	userID := 0
+	if value := ctx.Query("user_id"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "user_id inválido"})
+			return
+		}
+		userID = id
+	}
+
+	keys, err := c.service.GetApiKeys(userID, models.Role(ctx.Query("role")))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, keys)
+}
+
+func (c *ApiKeyController) RevokeApiKey(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	if err := c.service.RevokeApiKey(id); err != nil {
+		if errors.Is(err, services.ErrApiKeyNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "API key revocada"})
+}
@@ -0,0 +1,264 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/LINSITrack/backend/src/middleware"
+	"github.com/LINSITrack/backend/src/models"
+	"github.com/LINSITrack/backend/src/services"
+	"github.com/LINSITrack/backend/utils/upload"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ArchivoController struct {
+	archivoService *services.ArchivoService
+	policy         *services.PolicyService
+}
+
+func NewArchivoController(archivoService *services.ArchivoService, policy *services.PolicyService) *ArchivoController {
+	return &ArchivoController{archivoService: archivoService, policy: policy}
+}
+
+func (c *ArchivoController) GetArchivo(ctx *gin.Context) {
+	archivo, ok := c.archivoAutorizado(ctx)
+	if !ok {
+		return
+	}
+	ctx.JSON(http.StatusOK, archivo)
+}
+
+// GetURLFirmada devuelve una URL de descarga temporal que no requiere sesión
+func (c *ArchivoController) GetURLFirmada(ctx *gin.Context) {
+	archivo, ok := c.archivoAutorizado(ctx)
+	if !ok {
+		return
+	}
+	url, expira := c.archivoService.URLFirmada(archivo)
+	ctx.JSON(http.StatusOK, gin.H{"url": url, "expires_at": expira})
+}
+
+func (c *ArchivoController) DownloadArchivo(ctx *gin.Context) {
+	archivo, ok := c.archivoAutorizado(ctx)
+	if !ok {
+		return
+	}
+	serveArchivo(ctx, c.archivoService, archivo)
+}
+
+// VerificarFirma valida una URL de descarga firmada (lo usa middleware.AuthOrSignedURL)
+func (c *ArchivoController) VerificarFirma(ctx *gin.Context) bool {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return false
+	}
+	return c.archivoService.VerificarFirma(id, ctx.Query("expires"), ctx.Query("signature"))
+}
+
+// archivoAutorizado busca el archivo de la ruta y verifica que el usuario pueda verlo.
+// Las URLs firmadas ya fueron validadas por el middleware.
+func (c *ArchivoController) archivoAutorizado(ctx *gin.Context) (*models.ArchivoMetadata, bool) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return nil, false
+	}
+
+	archivo, err := c.archivoService.GetArchivo(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Archivo no encontrado"})
+			return nil, false
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if ctx.GetString("authMethod") == "signed_url" {
+		return archivo, true
+	}
+
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return nil, false
+	}
+	role, _ := ctx.Get("userRole")
+	roleStr, _ := role.(string)
+	allowed, err := c.policy.PuedeVerArchivo(archivo, userID, models.Role(roleStr))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if !allowed {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "no tienes acceso a este archivo"})
+		return nil, false
+	}
+	return archivo, true
+}
+
+// serveArchivo envía el contenido de un archivo como descarga. Como el contenido
+// está direccionado por SHA-256, el hash sirve de ETag fuerte. Soporta
+// If-None-Match, If-Range y un único rango de bytes (Range: bytes=a-b).
+func serveArchivo(ctx *gin.Context, archivos *services.ArchivoService, archivo *models.ArchivoMetadata) {
+	etag := `"` + archivo.SHA256 + `"`
+	header := ctx.Writer.Header()
+	header.Set("ETag", etag)
+	header.Set("Accept-Ranges", "bytes")
+	header.Set("Cache-Control", "private, no-cache")
+	header.Set("X-Content-Type-Options", "nosniff")
+
+	if etagMatch(ctx.GetHeader("If-None-Match"), etag) {
+		ctx.AbortWithStatus(http.StatusNotModified)
+		return
+	}
+
+	contentType := archivo.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	extraHeaders := map[string]string{"Content-Disposition": contentDisposition(archivo.OriginalName)}
+
+	rangeHeader := ctx.GetHeader("Range")
+	if ifRange := ctx.GetHeader("If-Range"); ifRange != "" && ifRange != etag {
+		// El cliente tiene otra versión: se envía el archivo completo
+		rangeHeader = ""
+	}
+	if rangeHeader != "" {
+		offset, length, ok := parseRange(rangeHeader, archivo.Size)
+		if !ok {
+			header.Set("Content-Range", fmt.Sprintf("bytes */%d", archivo.Size))
+			ctx.JSON(http.StatusRequestedRangeNotSatisfiable, gin.H{"error": "Rango inválido"})
+			return
+		}
+		if length > 0 {
+			content, err := archivos.AbrirRango(archivo, offset, length)
+			if err != nil {
+				ctx.JSON(http.StatusNotFound, gin.H{"error": "El archivo físico no existe"})
+				return
+			}
+			defer content.Close()
+
+			extraHeaders["Content-Range"] = fmt.Sprintf("bytes %d-%d/%d", offset, offset+length-1, archivo.Size)
+			ctx.DataFromReader(http.StatusPartialContent, length, contentType, content, extraHeaders)
+			return
+		}
+	}
+
+	content, err := archivos.Abrir(archivo)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "El archivo físico no existe"})
+		return
+	}
+	defer content.Close()
+
+	ctx.DataFromReader(http.StatusOK, archivo.Size, contentType, content, extraHeaders)
+}
+
+// parseRange interpreta un header Range de un solo rango ("bytes=0-99", "bytes=100-",
+// "bytes=-50"). Con varios rangos o un formato desconocido devuelve length 0 para
+// que se envíe el archivo completo; ok es false si el rango no es satisfacible.
+func parseRange(value string, size int64) (offset, length int64, ok bool) {
+	spec, found := strings.CutPrefix(value, "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return 0, 0, true
+	}
+	startStr, endStr, found := strings.Cut(strings.TrimSpace(spec), "-")
+	if !found {
+		return 0, 0, true
+	}
+
+	if startStr == "" {
+		// Sufijo: los últimos N bytes
+		n, err := strconv.ParseInt(endStr, 10, 64)
+		if err != nil || n <= 0 || size == 0 {
+			return 0, 0, false
+		}
+		if n > size {
+			n = size
+		}
+		return size - n, n, true
+	}
+
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil || start < 0 || start >= size {
+		return 0, 0, false
+	}
+	end := size - 1
+	if endStr != "" {
+		end, err = strconv.ParseInt(endStr, 10, 64)
+		if err != nil || end < start {
+			return 0, 0, false
+		}
+		if end >= size {
+			end = size - 1
+		}
+	}
+	return start, end - start + 1, true
+}
+
+// etagMatch indica si el header If-None-Match incluye el ETag (o es "*")
+func etagMatch(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// contentDisposition arma el header de descarga con el nombre original: una versión
+// ASCII en filename para clientes viejos y el nombre completo en filename* (RFC 6266)
+func contentDisposition(nombre string) string {
+	if nombre == "" {
+		return "attachment"
+	}
+	var ascii, encoded strings.Builder
+	for _, r := range nombre {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			ascii.WriteByte('_')
+		} else {
+			ascii.WriteRune(r)
+		}
+	}
+	for _, b := range []byte(nombre) {
+		if isAttrChar(b) {
+			encoded.WriteByte(b)
+		} else {
+			fmt.Fprintf(&encoded, "%%%02X", b)
+		}
+	}
+	return fmt.Sprintf(`attachment; filename="%s"; filename*=UTF-8''%s`, ascii.String(), encoded.String())
+}
+
+// isAttrChar indica los caracteres que no necesitan codificarse en filename* (RFC 5987)
+func isAttrChar(b byte) bool {
+	switch {
+	case 'a' <= b && b <= 'z', 'A' <= b && b <= 'Z', '0' <= b && b <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$&+-.^_`|~", b) >= 0
+}
+
+// respondUploadError responde los rechazos de validación de un archivo con su código.
+// Devuelve false si err no es un rechazo de validación.
+func respondUploadError(ctx *gin.Context, err error) bool {
+	var uploadErr *upload.Error
+	if !errors.As(err, &uploadErr) {
+		return false
+	}
+
+	status := http.StatusUnsupportedMediaType
+	switch uploadErr.Code {
+	case upload.CodeArchivoVacio, upload.CodeArchivoNoDisponible:
+		status = http.StatusBadRequest
+	case upload.CodeArchivoMuyGrande:
+		status = http.StatusRequestEntityTooLarge
+	}
+	ctx.JSON(status, gin.H{"error": uploadErr.Message, "code": uploadErr.Code})
+	return true
+}
@@ -0,0 +1,86 @@
+package controllers
+
+import "testing"
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		value      string
+		size       int64
+		wantOffset int64
+		wantLength int64
+		wantOk     bool
+	}{
+		{value: "bytes=0-99", size: 1000, wantOffset: 0, wantLength: 100, wantOk: true},
+		{value: "bytes=100-", size: 1000, wantOffset: 100, wantLength: 900, wantOk: true},
+		{value: "bytes=-50", size: 1000, wantOffset: 950, wantLength: 50, wantOk: true},
+		{value: "bytes=-5000", size: 1000, wantOffset: 0, wantLength: 1000, wantOk: true},
+		{value: "bytes=900-5000", size: 1000, wantOffset: 900, wantLength: 100, wantOk: true},
+		{value: "bytes=999-999", size: 1000, wantOffset: 999, wantLength: 1, wantOk: true},
+		// Varios rangos o formato desconocido: archivo completo
+		{value: "bytes=0-9,20-29", size: 1000, wantOk: true},
+		{value: "items=0-9", size: 1000, wantOk: true},
+		{value: "bytes=10", size: 1000, wantOk: true},
+		// No satisfacibles
+		{value: "bytes=1000-", size: 1000},
+		{value: "bytes=50-10", size: 1000},
+		{value: "bytes=-0", size: 1000},
+		{value: "bytes=-10", size: 0},
+		{value: "bytes=abc-", size: 1000},
+		{value: "bytes=-1-5", size: 1000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			offset, length, ok := parseRange(tt.value, tt.size)
+			if offset != tt.wantOffset || length != tt.wantLength || ok != tt.wantOk {
+				t.Errorf("parseRange(%q, %d) = %d, %d, %v; want %d, %d, %v",
+					tt.value, tt.size, offset, length, ok, tt.wantOffset, tt.wantLength, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestEtagMatch(t *testing.T) {
+	const etag = `"abc123"`
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{header: `"abc123"`, want: true},
+		{header: `W/"abc123"`, want: true},
+		{header: `"otro", "abc123"`, want: true},
+		{header: `*`, want: true},
+		{header: `"otro"`, want: false},
+		{header: `abc123`, want: false},
+		{header: ``, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			if got := etagMatch(tt.header, etag); got != tt.want {
+				t.Errorf("etagMatch(%q) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContentDisposition(t *testing.T) {
+	tests := []struct {
+		nombre string
+		want   string
+	}{
+		{nombre: "", want: "attachment"},
+		{nombre: "tp1.pdf", want: `attachment; filename="tp1.pdf"; filename*=UTF-8''tp1.pdf`},
+		{nombre: "Informe final ñ.pdf", want: `attachment; filename="Informe final _.pdf"; filename*=UTF-8''Informe%20final%20%C3%B1.pdf`},
+		{nombre: `a"b\c.txt`, want: `attachment; filename="a_b_c.txt"; filename*=UTF-8''a%22b%5Cc.txt`},
+		{nombre: "linea\r\nX-Inyectado: 1", want: `attachment; filename="linea__X-Inyectado: 1"; filename*=UTF-8''linea%0D%0AX-Inyectado%3A%201`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.nombre, func(t *testing.T) {
+			if got := contentDisposition(tt.nombre); got != tt.want {
+				t.Errorf("contentDisposition(%q) =\n%s\nwant\n%s", tt.nombre, got, tt.want)
+			}
+		})
+	}
+}
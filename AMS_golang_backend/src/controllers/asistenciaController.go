@@ -0,0 +1,172 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/LINSITrack/backend/src/middleware"
+	"github.com/LINSITrack/backend/src/models"
+	"github.com/LINSITrack/backend/src/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type AsistenciaController struct {
+	service *services.AsistenciaService
+}
+
+func NewAsistenciaController(service *services.AsistenciaService) *AsistenciaController {
+	return &AsistenciaController{service: service}
+}
+
+// GenerarClases crea las clases de la comisión según sus horarios entre dos fechas
+func (c *AsistenciaController) GenerarClases(ctx *gin.Context) {
+	id, ok := paramID(ctx, "ID de comisión inválido")
+	if !ok {
+		return
+	}
+	var req models.GenerarClasesRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	clases, err := c.service.GenerarClases(id, &req)
+	if err != nil {
+		respondAsistenciaError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, clases)
+}
+
+// GetClases lista las clases de la comisión (?ano_lectivo=2025)
+func (c *AsistenciaController) GetClases(ctx *gin.Context) {
+	id, ok := paramID(ctx, "ID de comisión inválido")
+	if !ok {
+		return
+	}
+	var anoLectivo *int
+	if value := ctx.Query("ano_lectivo"); value != "" {
+		ano, err := strconv.Atoi(value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Año lectivo inválido"})
+			return
+		}
+		anoLectivo = &ano
+	}
+	clases, err := c.service.GetClases(id, anoLectivo)
+	if err != nil {
+		respondAsistenciaError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, clases)
+}
+
+func (c *AsistenciaController) CrearClase(ctx *gin.Context) {
+	id, ok := paramID(ctx, "ID de comisión inválido")
+	if !ok {
+		return
+	}
+	var req models.ClaseRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	clase, err := c.service.CrearClase(id, &req)
+	if err != nil {
+		respondAsistenciaError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, clase)
+}
+
+func (c *AsistenciaController) UpdateClase(ctx *gin.Context) {
+	id, ok := paramID(ctx, "ID de clase inválido")
+	if !ok {
+		return
+	}
+	var req models.ClaseUpdateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	clase, err := c.service.UpdateClase(id, &req)
+	if err != nil {
+		respondAsistenciaError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, clase)
+}
+
+func (c *AsistenciaController) DeleteClase(ctx *gin.Context) {
+	id, ok := paramID(ctx, "ID de clase inválido")
+	if !ok {
+		return
+	}
+	if err := c.service.DeleteClase(id); err != nil {
+		respondAsistenciaError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Clase eliminada"})
+}
+
+// GetPlanilla devuelve la asistencia de la clase para todos los alumnos de la comisión
+func (c *AsistenciaController) GetPlanilla(ctx *gin.Context) {
+	id, ok := paramID(ctx, "ID de clase inválido")
+	if !ok {
+		return
+	}
+	planilla, err := c.service.GetPlanilla(id)
+	if err != nil {
+		respondAsistenciaError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, planilla)
+}
+
+func (c *AsistenciaController) TomarAsistencia(ctx *gin.Context) {
+	id, ok := paramID(ctx, "ID de clase inválido")
+	if !ok {
+		return
+	}
+	var req models.TomarAsistenciaRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID, _ := middleware.CurrentUserID(ctx)
+	userRole, _ := ctx.Get("userRole")
+	role, _ := userRole.(string)
+
+	planilla, err := c.service.TomarAsistencia(id, &req, userID, models.Role(role))
+	if err != nil {
+		respondAsistenciaError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, planilla)
+}
+
+// GetAsistenciaCursada devuelve el resumen y el detalle de asistencia de la cursada
+func (c *AsistenciaController) GetAsistenciaCursada(ctx *gin.Context) {
+	id, ok := paramID(ctx, "ID de cursada inválido")
+	if !ok {
+		return
+	}
+	asistencia, err := c.service.GetAsistenciaCursada(id)
+	if err != nil {
+		respondAsistenciaError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, asistencia)
+}
+
+func respondAsistenciaError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "recurso no encontrado"})
+	case errors.Is(err, services.ErrClaseDuplicada):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
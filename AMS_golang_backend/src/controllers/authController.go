@@ -1,8 +1,13 @@
 package controllers
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/LINSITrack/backend/src/middleware"
 	"github.com/LINSITrack/backend/src/models"
 	"github.com/LINSITrack/backend/src/services"
 	"github.com/gin-gonic/gin"
@@ -24,13 +29,50 @@ func (c *AuthController) Login(ctx *gin.Context) {
 		return
 	}
 
-	token, err := c.service.Login(loginReq.Email, loginReq.Password)
+	tokens, err := c.service.Login(loginReq.Email, loginReq.Password, loginReq.Role, ctx.Request.UserAgent(), middleware.ClientIP(ctx))
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "credenciales inválidas"})
+		var roleErr *services.RoleSelectionError
+		var lockedErr *services.LoginLockedError
+		switch {
+		case errors.As(err, &lockedErr):
+			ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(lockedErr.RetryAfter.Seconds()))))
+			ctx.JSON(http.StatusTooManyRequests, gin.H{"error": lockedErr.Error()})
+		case errors.As(err, &roleErr):
+			ctx.JSON(http.StatusConflict, models.RoleSelectionResponse{Error: roleErr.Error(), Roles: roleErr.Roles})
+		case errors.Is(err, services.ErrRoleNotAssigned):
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrInvalidCredentials):
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "credenciales inválidas"})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
-	c.setCookie(ctx, token)
+	c.setCookies(ctx, tokens)
+	ctx.JSON(http.StatusOK, gin.H{"message": "Login successful"})
+}
+
+func (c *AuthController) Refresh(ctx *gin.Context) {
+	refreshToken, err := ctx.Cookie("refresh_token")
+	if err != nil || refreshToken == "" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "No se encontró el refresh token"})
+		return
+	}
+
+	tokens, err := c.service.Refresh(refreshToken, ctx.Request.UserAgent(), middleware.ClientIP(ctx))
+	if err != nil {
+		c.clearCookies(ctx)
+		if errors.Is(err, services.ErrInvalidRefreshToken) {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.setCookies(ctx, tokens)
+	ctx.JSON(http.StatusOK, gin.H{"message": "Token refreshed"})
 }
 
 func (c *AuthController) WhoAmI(ctx *gin.Context) {
@@ -38,6 +80,7 @@ func (c *AuthController) WhoAmI(ctx *gin.Context) {
 	userID, _ := ctx.Get("userID")
 	userEmail, _ := ctx.Get("userEmail")
 	userRole, _ := ctx.Get("userRole")
+	usuarioID, _ := ctx.Get("usuarioID")
 
 	// Obtener otros claims si existen
 	userName, _ := ctx.Get("userName")
@@ -46,6 +89,7 @@ func (c *AuthController) WhoAmI(ctx *gin.Context) {
 
 	// Usar el servicio para generar la respuesta
 	user := c.service.GetCurrentUser(userID, userEmail.(string), userRole.(string), userName, userSurname, userLegajo)
+	user.UsuarioID = usuarioID
 
 	ctx.JSON(http.StatusOK, gin.H{
 		"message": "Usuario autenticado",
@@ -54,23 +98,217 @@ func (c *AuthController) WhoAmI(ctx *gin.Context) {
 }
 
 func (c *AuthController) Logout(ctx *gin.Context) {
-	ctx.SetSameSite(http.SameSiteLaxMode)
-	ctx.SetCookie("jwt", "", -1, "/", "", false, true)
+	if sessionID, exists := ctx.Get("sessionID"); exists {
+		if err := c.service.Logout(sessionID.(int)); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	c.clearCookies(ctx)
 	ctx.JSON(http.StatusOK, gin.H{"message": "Logout successful"})
 }
 
-func (c *AuthController) setCookie(ctx *gin.Context, token string) {
+func (c *AuthController) GetSessions(ctx *gin.Context) {
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "ID de usuario no encontrado"})
+		return
+	}
+	userRole, _ := ctx.Get("userRole")
+	sessionID, _ := ctx.Get("sessionID")
+	currentSessionID, _ := sessionID.(int)
+
+	sessions, err := c.service.GetActiveSessions(userID, models.Role(userRole.(string)), currentSessionID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, sessions)
+}
+
+func (c *AuthController) RevokeSession(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "ID de usuario no encontrado"})
+		return
+	}
+	userRole, _ := ctx.Get("userRole")
+
+	if err := c.service.RevokeSession(id, userID, models.Role(userRole.(string))); err != nil {
+		if errors.Is(err, services.ErrSessionNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Sesión revocada"})
+}
+
+// Endpoints de administración de sesiones
+
+func (c *AuthController) GetUserSessions(ctx *gin.Context) {
+	userID, err := strconv.Atoi(ctx.Query("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "user_id inválido"})
+		return
+	}
+	role := models.Role(ctx.Query("role"))
+	if role != models.RoleAdmin && role != models.RoleProfesor && role != models.RoleAlumno {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "rol inválido"})
+		return
+	}
+
+	sessions, err := c.service.GetActiveSessions(userID, role, 0)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, sessions)
+}
+
+func (c *AuthController) AdminRevokeSession(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	if err := c.service.RevokeSessionByID(id); err != nil {
+		if errors.Is(err, services.ErrSessionNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Sesión revocada"})
+}
+
+func (c *AuthController) ForceLogout(ctx *gin.Context) {
+	var req models.ForceLogoutRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	revoked, err := c.service.RevokeAllSessions(req.UserID, req.Role)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Sesiones revocadas", "revoked": revoked})
+}
+
+// Endpoints de auditoría de login
+
+func (c *AuthController) GetLoginAttempts(ctx *gin.Context) {
+	filter := models.LoginAuditFilter{
+		Email: ctx.Query("email"),
+		IP:    ctx.Query("ip"),
+	}
+	if success := ctx.Query("success"); success != "" {
+		value, err := strconv.ParseBool(success)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "success inválido"})
+			return
+		}
+		filter.Success = &value
+	}
+	if desde := ctx.Query("desde"); desde != "" {
+		t, err := time.Parse(time.RFC3339, desde)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "desde inválido (formato RFC3339)"})
+			return
+		}
+		filter.Desde = &t
+	}
+	if hasta := ctx.Query("hasta"); hasta != "" {
+		t, err := time.Parse(time.RFC3339, hasta)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "hasta inválido (formato RFC3339)"})
+			return
+		}
+		filter.Hasta = &t
+	}
+	if limit := ctx.Query("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "limit inválido"})
+			return
+		}
+		filter.Limit = value
+	}
+
+	attempts, err := c.service.GetLoginAttempts(filter)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, attempts)
+}
+
+func (c *AuthController) GetLoginLocks(ctx *gin.Context) {
+	locks, err := c.service.GetLoginLocks()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, locks)
+}
+
+func (c *AuthController) UnlockLogin(ctx *gin.Context) {
+	var req models.UnlockLoginRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Email == "" && req.IP == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "se debe indicar un email o una IP"})
+		return
+	}
+
+	unlocked, err := c.service.UnlockLogin(req.Email, req.IP)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Login desbloqueado", "unlocked": unlocked})
+}
+
+func (c *AuthController) setCookies(ctx *gin.Context, tokens *models.TokenPair) {
 	ctx.SetSameSite(http.SameSiteLaxMode)
 
 	ctx.SetCookie(
 		"jwt",
-		token,
-		3600*24, // 1 día
+		tokens.AccessToken,
+		int(services.AccessTokenTTL.Seconds()),
 		"/",
 		"",
 		false,
 		true,
 	)
 
-	ctx.JSON(http.StatusOK, gin.H{"message": "Login successful"})
+	// El refresh token solo viaja a los endpoints de /auth
+	ctx.SetCookie(
+		"refresh_token",
+		tokens.RefreshToken,
+		int(services.RefreshTokenTTL.Seconds()),
+		"/auth",
+		"",
+		false,
+		true,
+	)
+}
+
+func (c *AuthController) clearCookies(ctx *gin.Context) {
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie("jwt", "", -1, "/", "", false, true)
+	ctx.SetCookie("refresh_token", "", -1, "/auth", "", false, true)
 }
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/LINSITrack/backend/src/middleware"
+	"github.com/LINSITrack/backend/src/models"
+	"github.com/LINSITrack/backend/src/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type CalendarioController struct {
+	service *services.CalendarioService
+}
+
+func NewCalendarioController(service *services.CalendarioService) *CalendarioController {
+	return &CalendarioController{service: service}
+}
+
+// GetSuscripcion indica si el usuario tiene un feed activo (sin revelar el token)
+func (c *CalendarioController) GetSuscripcion(ctx *gin.Context) {
+	userID, role := usuarioCalendario(ctx)
+	suscripcion, err := c.service.GetSuscripcion(userID, role)
+	if err != nil {
+		respondCalendarioError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, suscripcion)
+}
+
+// GenerarSuscripcion crea (o reemplaza) el token del feed y devuelve la URL para suscribirse
+func (c *CalendarioController) GenerarSuscripcion(ctx *gin.Context) {
+	userID, role := usuarioCalendario(ctx)
+	suscripcion, err := c.service.GenerarSuscripcion(userID, role)
+	if err != nil {
+		respondCalendarioError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, suscripcion)
+}
+
+func (c *CalendarioController) RevocarSuscripcion(ctx *gin.Context) {
+	userID, role := usuarioCalendario(ctx)
+	if err := c.service.RevocarSuscripcion(userID, role); err != nil {
+		respondCalendarioError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Suscripción al calendario revocada"})
+}
+
+// GetFeed devuelve el calendario .ics; el token de la URL es la única autenticación
+func (c *CalendarioController) GetFeed(ctx *gin.Context) {
+	token := strings.TrimSuffix(ctx.Param("token"), ".ics")
+	var buf bytes.Buffer
+	if err := c.service.EscribirFeed(&buf, token); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Calendario no encontrado"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.Header("Cache-Control", "private, max-age=300")
+	ctx.Header("Content-Disposition", `inline; filename="linsitrack.ics"`)
+	ctx.Data(http.StatusOK, "text/calendar; charset=utf-8", buf.Bytes())
+}
+
+func usuarioCalendario(ctx *gin.Context) (int, models.Role) {
+	userID, _ := middleware.CurrentUserID(ctx)
+	userRole, _ := ctx.Get("userRole")
+	role, _ := userRole.(string)
+	return userID, models.Role(role)
+}
+
+func respondCalendarioError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "No hay una suscripción al calendario activa"})
+	case errors.Is(err, services.ErrCalendarioRol):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
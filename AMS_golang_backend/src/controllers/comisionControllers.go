@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -17,8 +18,13 @@ func NewComisionController(comisionService *services.ComisionService) *ComisionC
 	return &ComisionController{comisionService: comisionService}
 }
 
+// GetAllComisiones lista las comisiones (?periodo_id=3)
 func (c *ComisionController) GetAllComisiones(ctx *gin.Context) {
-	comisiones, err := c.comisionService.GetAllComisiones()
+	periodoID, ok := queryPeriodoID(ctx)
+	if !ok {
+		return
+	}
+	comisiones, err := c.comisionService.GetAllComisiones(periodoID)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -48,7 +54,11 @@ func (c *ComisionController) GetComisionesByMateriaID(ctx *gin.Context) {
 		return
 	}
 
-	comisiones, err := c.comisionService.GetComisionesByMateriaID(materiaID)
+	periodoID, ok := queryPeriodoID(ctx)
+	if !ok {
+		return
+	}
+	comisiones, err := c.comisionService.GetComisionesByMateriaID(materiaID, periodoID)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -70,15 +80,20 @@ func (c *ComisionController) CreateComision(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "el nombre de la comisión es obligatorio"})
 		return
 	}
-	if comision.Horarios == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "los horarios de la comisión son obligatorios"})
-		return
-	}
 	if comision.MateriaId == 0 {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "la materia de la comisión es obligatoria"})
 		return
 	}
 	if err := c.comisionService.CreateComision(&comision); err != nil {
+		if errors.Is(err, services.ErrPeriodoInexistente) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		var choques *services.ChoquesHorarioError
+		if errors.As(err, &choques) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error(), "choques": choques.Choques})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -110,6 +125,19 @@ func (c *ComisionController) UpdateComision(ctx *gin.Context) {
 		return
 	}
 	comision, err := c.comisionService.UpdateComision(id, &updateRequest)
+	if errors.Is(err, services.ErrPeriodoInexistente) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, services.ErrHorariosEstructurados) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	var choques *services.ChoquesHorarioError
+	if errors.As(err, &choques) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error(), "choques": choques.Choques})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/LINSITrack/backend/src/models"
+	"github.com/LINSITrack/backend/src/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type CondicionController struct {
+	service *services.CondicionService
+}
+
+func NewCondicionController(service *services.CondicionService) *CondicionController {
+	return &CondicionController{service: service}
+}
+
+func (c *CondicionController) GetReglas(ctx *gin.Context) {
+	id, ok := paramID(ctx, "ID de materia inválido")
+	if !ok {
+		return
+	}
+	reglas, err := c.service.GetReglas(id)
+	if err != nil {
+		respondCondicionError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, reglas)
+}
+
+func (c *CondicionController) GuardarReglas(ctx *gin.Context) {
+	id, ok := paramID(ctx, "ID de materia inválido")
+	if !ok {
+		return
+	}
+	var req models.ReglasCondicionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	reglas, err := c.service.GuardarReglas(id, &req)
+	if err != nil {
+		respondCondicionError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, reglas)
+}
+
+func (c *CondicionController) BorrarReglas(ctx *gin.Context) {
+	id, ok := paramID(ctx, "ID de materia inválido")
+	if !ok {
+		return
+	}
+	if err := c.service.BorrarReglas(id); err != nil {
+		respondCondicionError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Reglas de condición eliminadas"})
+}
+
+func respondCondicionError(ctx *gin.Context, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "materia o reglas no encontradas"})
+		return
+	}
+	ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/LINSITrack/backend/src/models"
+	"github.com/LINSITrack/backend/src/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type CorrelatividadController struct {
+	service *services.CorrelatividadService
+}
+
+func NewCorrelatividadController(service *services.CorrelatividadService) *CorrelatividadController {
+	return &CorrelatividadController{service: service}
+}
+
+func (c *CorrelatividadController) GetCorrelatividades(ctx *gin.Context) {
+	id, ok := paramID(ctx, "ID de materia inválido")
+	if !ok {
+		return
+	}
+	correlatividades, err := c.service.GetCorrelatividades(id)
+	if err != nil {
+		respondCorrelatividadError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, correlatividades)
+}
+
+func (c *CorrelatividadController) GuardarCorrelatividades(ctx *gin.Context) {
+	id, ok := paramID(ctx, "ID de materia inválido")
+	if !ok {
+		return
+	}
+	var req models.CorrelatividadesRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	correlatividades, err := c.service.GuardarCorrelatividades(id, &req)
+	if err != nil {
+		respondCorrelatividadError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, correlatividades)
+}
+
+// GetEstado indica si el alumno cumple las correlatividades para inscribirse en la materia
+func (c *CorrelatividadController) GetEstado(ctx *gin.Context) {
+	id, ok := paramID(ctx, "ID de materia inválido")
+	if !ok {
+		return
+	}
+	alumnoID, err := strconv.Atoi(ctx.Param("alumnoId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de alumno inválido"})
+		return
+	}
+	estado, err := c.service.GetEstado(id, alumnoID)
+	if err != nil {
+		respondCorrelatividadError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, estado)
+}
+
+// GetExcepciones lista las inscripciones a la materia hechas sin cumplir las correlatividades
+func (c *CorrelatividadController) GetExcepciones(ctx *gin.Context) {
+	id, ok := paramID(ctx, "ID de materia inválido")
+	if !ok {
+		return
+	}
+	excepciones, err := c.service.GetExcepciones(id)
+	if err != nil {
+		respondCorrelatividadError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, excepciones)
+}
+
+func respondCorrelatividadError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "recurso no encontrado"})
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
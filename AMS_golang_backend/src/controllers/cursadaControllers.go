@@ -1,9 +1,11 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
+	"github.com/LINSITrack/backend/src/middleware"
 	"github.com/LINSITrack/backend/src/models"
 	"github.com/LINSITrack/backend/src/services"
 	"github.com/gin-gonic/gin"
@@ -17,8 +19,13 @@ func NewCursadaController(cursadaService *services.CursadaService) *CursadaContr
 	return &CursadaController{cursadaService: cursadaService}
 }
 
+// GetAllCursadas lista las cursadas (?periodo_id=3)
 func (c *CursadaController) GetAllCursadas(ctx *gin.Context) {
-	cursadas, err := c.cursadaService.GetAllCursadas()
+	periodoID, ok := queryPeriodoID(ctx)
+	if !ok {
+		return
+	}
+	cursadas, err := c.cursadaService.GetAllCursadas(periodoID)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -48,19 +55,11 @@ func (c *CursadaController) GetCursadaByAlumnoID(ctx *gin.Context) {
 		return
 	}
 
-	userID, _ := ctx.Get("userID")
-	userRole, _ := ctx.Get("userRole")
-
-	// Si es alumno, verificar que solo acceda a sus propias cursadas
-	if userRole.(string) == string(models.RoleAlumno) {
-		userAlumnoID := int(userID.(float64))
-		if alumnoID != userAlumnoID {
-			ctx.JSON(http.StatusForbidden, gin.H{"error": "solo puedes acceder a tus propias cursadas"})
-			return
-		}
+	periodoID, ok := queryPeriodoID(ctx)
+	if !ok {
+		return
 	}
-
-	cursadas, err := c.cursadaService.GetCursadaByAlumnoID(alumnoID)
+	cursadas, err := c.cursadaService.GetCursadaByAlumnoID(alumnoID, periodoID)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -68,15 +67,17 @@ func (c *CursadaController) GetCursadaByAlumnoID(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, cursadas)
 }
 
+// CreateCursada inscribe al alumno; un admin puede saltear las correlatividades con motivo_excepcion
 func (c *CursadaController) CreateCursada(ctx *gin.Context) {
-	var cursada models.Cursada
-	if err := ctx.ShouldBindJSON(&cursada); err != nil {
+	var req models.CursadaCreateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"error":  "datos invalidos",
 			"detail": err.Error(),
 		})
 		return
 	}
+	cursada := req.Cursada
 	if cursada.AnoLectivo <= 0 {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "El año lectivo debe ser un número positivo"})
 		return
@@ -97,7 +98,16 @@ func (c *CursadaController) CreateCursada(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "La comision asociada a la cursada es obligatoria"})
 		return
 	}
-	if err := c.cursadaService.CreateCursada(&cursada); err != nil {
+	userID, _ := middleware.CurrentUserID(ctx)
+	userRole, _ := ctx.Get("userRole")
+	role, _ := userRole.(string)
+
+	if err := c.cursadaService.CreateCursada(&cursada, req.MotivoExcepcion, userID, models.Role(role)); err != nil {
+		var correlatividades *services.CorrelatividadesError
+		if errors.As(err, &correlatividades) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error(), "faltantes": correlatividades.Faltantes})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -111,30 +121,11 @@ func (c *CursadaController) UpdateCursada(ctx *gin.Context) {
 		return
 	}
 
-	userID, _ := ctx.Get("userID")
 	userRole, _ := ctx.Get("userRole")
 
-	// Si es alumno, verificar permisos especiales
+	// Si es alumno (la ruta ya verificó que la cursada es suya), solo puede modificar el feedback
 	if userRole.(string) == string(models.RoleAlumno) {
-		// Obtener la cursada para verificar que pertenece al alumno
-		cursada, err := c.cursadaService.GetCursadaByID(id)
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-
-		// Verificar que la cursada pertenece al alumno que hace la request
-		alumnoID := int(userID.(float64))
-		if cursada.AlumnoID != alumnoID {
-			ctx.JSON(http.StatusForbidden, gin.H{"error": "solo puedes modificar tus propias cursadas"})
-			return
-		}
-
-		// Para alumnos, solo permitir modificación del feedback
-		var alumnoUpdateRequest struct {
-			Feedback *string `json:"feedback,omitempty"`
-		}
-
+		var alumnoUpdateRequest models.CursadaUpdateRequest
 		if err := ctx.ShouldBindJSON(&alumnoUpdateRequest); err != nil {
 			ctx.JSON(http.StatusBadRequest, gin.H{
 				"error":   "Datos inválidos",
@@ -143,12 +134,19 @@ func (c *CursadaController) UpdateCursada(ctx *gin.Context) {
 			return
 		}
 
+		if alumnoUpdateRequest.AnoLectivo != nil || alumnoUpdateRequest.NotaFinal != nil || alumnoUpdateRequest.NotaConceptual != nil ||
+			alumnoUpdateRequest.AlumnoID != nil || alumnoUpdateRequest.ComisionID != nil {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "los alumnos solo pueden modificar el feedback de la cursada"})
+			return
+		}
+
 		// Crear el request de actualización solo con feedback
 		updateRequest := models.CursadaUpdateRequest{
 			Feedback: alumnoUpdateRequest.Feedback,
 		}
 
-		cursadaUpdated, err := c.cursadaService.UpdateCursada(id, &updateRequest)
+		userID, _ := middleware.CurrentUserID(ctx)
+		cursadaUpdated, err := c.cursadaService.UpdateCursada(id, &updateRequest, userID, models.RoleAlumno)
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
@@ -171,8 +169,8 @@ func (c *CursadaController) UpdateCursada(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "El año lectivo debe ser un número positivo"})
 		return
 	}
-	if updateRequest.NotaFinal != nil && (*updateRequest.NotaFinal < 0 || *updateRequest.NotaFinal > 10) {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "La nota final debe estar entre 0 y 10"})
+	if updateRequest.NotaFinal != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": services.ErrNotaFinalDirecta.Error()})
 		return
 	}
 	if updateRequest.NotaConceptual != nil && (*updateRequest.NotaConceptual < 0 || *updateRequest.NotaConceptual > 10) {
@@ -188,8 +186,16 @@ func (c *CursadaController) UpdateCursada(ctx *gin.Context) {
 		return
 	}
 
-	cursada, err := c.cursadaService.UpdateCursada(id, &updateRequest)
+	userID, _ := middleware.CurrentUserID(ctx)
+	role, _ := userRole.(string)
+
+	cursada, err := c.cursadaService.UpdateCursada(id, &updateRequest, userID, models.Role(role))
 	if err != nil {
+		var correlatividades *services.CorrelatividadesError
+		if errors.As(err, &correlatividades) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error(), "faltantes": correlatividades.Faltantes})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -218,8 +224,12 @@ func (c *CursadaController) GetCursadasByProfesor(ctx *gin.Context) {
 	}
 
 	profesorID := int(userID.(float64))
+	periodoID, ok := queryPeriodoID(ctx)
+	if !ok {
+		return
+	}
 
-	cursadas, err := c.cursadaService.GetCursadasByProfesorID(profesorID)
+	cursadas, err := c.cursadaService.GetCursadasByProfesorID(profesorID, periodoID)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/LINSITrack/backend/src/middleware"
 	"github.com/LINSITrack/backend/src/models"
 	"github.com/LINSITrack/backend/src/services"
 	"github.com/gin-gonic/gin"
@@ -12,10 +13,11 @@ import (
 
 type EntregaController struct {
 	entregaService *services.EntregaService
+	archivoService *services.ArchivoService
 }
 
-func NewEntregaController(entregaService *services.EntregaService) *EntregaController {
-	return &EntregaController{entregaService: entregaService}
+func NewEntregaController(entregaService *services.EntregaService, archivoService *services.ArchivoService) *EntregaController {
+	return &EntregaController{entregaService: entregaService, archivoService: archivoService}
 }
 
 func (c *EntregaController) GetAllEntregas(ctx *gin.Context) {
@@ -140,22 +142,16 @@ func (c *EntregaController) UploadArchivo(ctx *gin.Context) {
 		return
 	}
 
-	// Validaciones básicas del archivo
-	if file.Size == 0 {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "El archivo está vacío"})
-		return
-	}
+	userID, _ := middleware.CurrentUserID(ctx)
+	userRole, _ := ctx.Get("userRole")
+	role, _ := userRole.(string)
 
-	// Límite de tamaño (ejemplo: 50MB)
-	maxSize := int64(50 * 1024 * 1024) // 50MB
-	if file.Size > maxSize {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "El archivo es demasiado grande (máximo 50MB)"})
-		return
-	}
-
-	// Guardar archivo
-	archivo, err := c.entregaService.SaveFile(file, entregaID)
+	// Guardar archivo (el servicio valida tamaño, extensión y tipo real)
+	archivo, err := c.entregaService.SaveFile(file, entregaID, userID, models.Role(role))
 	if err != nil {
+		if respondUploadError(ctx, err) {
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Error al guardar el archivo",
 			"details": err.Error(),
@@ -232,12 +228,7 @@ func (c *EntregaController) DownloadArchivo(ctx *gin.Context) {
 		return
 	}
 
-	// Verificar que el archivo físico existe
-	ctx.Header("Content-Description", "File Transfer")
-	ctx.Header("Content-Transfer-Encoding", "binary")
-	ctx.Header("Content-Disposition", "attachment; filename="+archivo.OriginalName)
-	ctx.Header("Content-Type", archivo.ContentType)
-	ctx.File(archivo.FilePath)
+	serveArchivo(ctx, c.archivoService, archivo)
 }
 
 // GetEntregasByAlumno - Obtiene todas las entregas del alumno logueado
@@ -365,10 +356,5 @@ func (c *EntregaController) DownloadArchivoForAlumno(ctx *gin.Context) {
 		return
 	}
 
-	// Verificar que el archivo físico existe y enviarlo
-	ctx.Header("Content-Description", "File Transfer")
-	ctx.Header("Content-Transfer-Encoding", "binary")
-	ctx.Header("Content-Disposition", "attachment; filename="+archivo.OriginalName)
-	ctx.Header("Content-Type", archivo.ContentType)
-	ctx.File(archivo.FilePath)
+	serveArchivo(ctx, c.archivoService, archivo)
 }
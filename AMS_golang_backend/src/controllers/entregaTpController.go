@@ -1,31 +1,51 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strconv"
-	"time"
 
+	"github.com/LINSITrack/backend/src/middleware"
 	"github.com/LINSITrack/backend/src/models"
+	"github.com/LINSITrack/backend/src/services"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
 type EntregaTPController struct {
-	DB *gorm.DB
+	DB       *gorm.DB
+	intentos *services.IntentoService
+	estados  *services.EntregaEstadoService
+	grupos   *services.GrupoService
+	archivos *services.ArchivoService
+	zip      *services.EntregaZipService
 }
 
-func NewEntregaTPController(db *gorm.DB) *EntregaTPController {
-	return &EntregaTPController{DB: db}
+func NewEntregaTPController(db *gorm.DB, archivos *services.ArchivoService) *EntregaTPController {
+	estados := services.NewEntregaEstadoService(db)
+	grupos := services.NewGrupoService(db, estados)
+	return &EntregaTPController{
+		DB:       db,
+		intentos: services.NewIntentoService(db, services.NewPlazoService(db), estados, grupos, archivos),
+		estados:  estados,
+		grupos:   grupos,
+		archivos: archivos,
+		zip:      services.NewEntregaZipService(db, archivos),
+	}
 }
 
-// GetAllEntregas - Get all TP submissions (for teachers)
+// GetAllEntregas - Get all TP submissions (admins see every comisión, teachers only theirs)
 func (ctrl *EntregaTPController) GetAllEntregas(c *gin.Context) {
 	var entregas []models.EntregaTP
 
-	result := ctrl.DB.Preload("Tp").Preload("Tp.Comision").Preload("Alumno").Preload("Cursada").Find(&entregas)
+	query := ctrl.DB.Preload("Tp").Preload("Tp.Comision").Preload("Alumno").Preload("Cursada")
+	if c.GetString("userRole") != string(models.RoleAdmin) {
+		profesorID, _ := middleware.CurrentUserID(c)
+		query = query.Where("tp_id IN (SELECT tps.id FROM tp_models tps JOIN profesor_x_comisions pxc ON pxc.comision_id = tps.comision_id WHERE pxc.profesor_id = ?)", profesorID)
+	}
+	result := query.Find(&entregas)
 	if result.Error != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching entregas"})
 		return
@@ -48,6 +68,34 @@ func (ctrl *EntregaTPController) GetEntregasByTP(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"entregas": entregas})
 }
 
+// DownloadEntregasZip - Streams a ZIP with every submission of a TP and a CSV manifest.
+// Filters: ?sin_corregir=true (only ungraded) and ?solo_ultimo_intento=true
+func (ctrl *EntregaTPController) DownloadEntregasZip(c *gin.Context) {
+	tpID, err := strconv.Atoi(c.Param("tp_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid TP ID"})
+		return
+	}
+	filtro := models.EntregasZipFiltro{
+		SinCorregir:       c.Query("sin_corregir") == "true",
+		SoloUltimoIntento: c.Query("solo_ultimo_intento") == "true",
+	}
+
+	entregas, err := ctrl.zip.GetEntregas(tpID, filtro)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching entregas for TP"})
+		return
+	}
+
+	// Once streaming starts the status can no longer change: errors are only logged
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="tp-%d-entregas.zip"`, tpID))
+	c.Status(http.StatusOK)
+	if err := ctrl.zip.EscribirZip(c.Writer, entregas, filtro); err != nil {
+		log.Printf("Error writing ZIP for TP %d: %v", tpID, err)
+	}
+}
+
 // GetEntregasByAlumno - Get all submissions by a specific student
 func (ctrl *EntregaTPController) GetEntregasByAlumno(c *gin.Context) {
 	// Get alumno_id from authenticated user
@@ -94,7 +142,7 @@ func (ctrl *EntregaTPController) GetEntregaByID(c *gin.Context) {
 	userRole, roleExists := c.Get("userRole")
 	userIdInterface, idExists := c.Get("userID")
 
-	if roleExists && idExists && userRole == models.RoleAlumno {
+	if roleExists && idExists && userRole == string(models.RoleAlumno) {
 		userIdFloat, ok := userIdInterface.(float64)
 		if ok {
 			userId := int(userIdFloat)
@@ -138,32 +186,31 @@ func (ctrl *EntregaTPController) CreateEntrega(c *gin.Context) {
 		return
 	}
 
-	// Check if student already submitted for this TP
-	var existingEntrega models.EntregaTP
-	if err := ctrl.DB.Where("tp_id = ? AND alumno_id = ?", req.TpId, alumnoId).First(&existingEntrega).Error; err == nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "You have already submitted this TP"})
-		return
-	}
-
-	// Create submission
+	// A previous submission for this TP (own or by a group member) gets a new attempt instead of a new entrega
 	entrega := models.EntregaTP{
-		TpId:         req.TpId,
-		AlumnoId:     alumnoId,
-		CursadaId:    req.CursadaId,
-		ArchivoURL:   req.ArchivoURL,
-		FechaEntrega: time.Now(),
-		Estado:       "pendiente",
+		TpId:      req.TpId,
+		AlumnoId:  alumnoId,
+		CursadaId: req.CursadaId,
+	}
+	status := http.StatusCreated
+	err := ctrl.DB.Where("tp_id = ? AND alumno_id = ?", req.TpId, alumnoId).First(&entrega).Error
+	if err == nil {
+		status = http.StatusOK
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching entrega"})
+		return
 	}
 
-	if result := ctrl.DB.Create(&entrega); result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating entrega"})
+	// Checks deadline policy and max attempts, then stores the attempt
+	if err := ctrl.intentos.RegistrarIntento(&entrega, &tp, req.ArchivoURL); err != nil {
+		respondPlazoError(c, err)
 		return
 	}
 
 	// Load relationships
 	ctrl.DB.Preload("Tp").Preload("Alumno").Preload("Cursada").First(&entrega, entrega.ID)
 
-	c.JSON(http.StatusCreated, entrega)
+	c.JSON(status, entrega)
 }
 
 // UpdateEntrega - Update submission (for re-submission or teacher grading)
@@ -201,7 +248,7 @@ func (ctrl *EntregaTPController) UpdateEntrega(c *gin.Context) {
 	}
 
 	// Check permissions based on role
-	if userRole == models.RoleAlumno {
+	if userRole == string(models.RoleAlumno) {
 		// Students can only update their own submissions
 		userIdFloat, ok := userIdInterface.(float64)
 		if !ok {
@@ -222,29 +269,93 @@ func (ctrl *EntregaTPController) UpdateEntrega(c *gin.Context) {
 		}
 	}
 
-	// Track if this is a grading update
-	isGrading := req.Nota != nil && entrega.Nota == nil
+	// A resubmission by the student is stored as a new attempt
+	if req.ArchivoURL != nil && userRole == string(models.RoleAlumno) {
+		var tp models.TpModel
+		if err := ctrl.DB.First(&tp, entrega.TpId).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching TP"})
+			return
+		}
+		if err := ctrl.intentos.RegistrarIntento(&entrega, &tp, *req.ArchivoURL); err != nil {
+			respondPlazoError(c, err)
+			return
+		}
+		ctrl.DB.Preload("Tp").Preload("Alumno").Preload("Cursada").First(&entrega, entrega.ID)
+		c.JSON(http.StatusOK, entrega)
+		return
+	}
 
 	// Update fields if provided
 	if req.ArchivoURL != nil {
 		entrega.ArchivoURL = *req.ArchivoURL
 	}
 	if req.Nota != nil {
-		entrega.Nota = req.Nota
+		// Late penalty is discounted from the grade; the raw grade is kept
+		services.AsignarNota(&entrega, *req.Nota)
 	}
 	if req.Devolucion != nil {
 		entrega.Devolucion = *req.Devolucion
 	}
-	if req.Estado != nil {
-		entrega.Estado = *req.Estado
-	}
 
-	// If nota is being set, update estado to calificado
-	if req.Nota != nil && entrega.Estado == "pendiente" {
-		entrega.Estado = "calificado"
+	// Estado changes go through the state machine; a grade on an uncorrected
+	// submission moves it to calificado. Sending the current estado again (as when
+	// correcting a grade) is not a transition.
+	var nuevoEstado models.EstadoEntrega
+	if req.Estado != nil {
+		if *req.Estado != entrega.Estado {
+			nuevoEstado = *req.Estado
+		}
+	} else if req.Nota != nil && (entrega.Estado == models.EstadoPendiente || entrega.Estado == models.EstadoEnCorreccion) {
+		nuevoEstado = models.EstadoCalificado
+	}
+	role := models.Role(userRole.(string))
+	if nuevoEstado != "" && !models.PuedeTransicionar(role, entrega.Estado, nuevoEstado) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":      fmt.Sprintf("Invalid estado transition from %s to %s", entrega.Estado, nuevoEstado),
+			"permitidos": models.TransicionesPermitidas(role, entrega.Estado),
+		})
+		return
 	}
+	actorID, _ := userIdInterface.(float64)
 
-	if result := ctrl.DB.Save(&entrega); result.Error != nil {
+	err = ctrl.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Estado", "Intentos", "Tp", "Alumno", "Cursada").Save(&entrega).Error; err != nil {
+			return err
+		}
+		// Grades belong to the current attempt
+		if req.Nota != nil || req.Devolucion != nil {
+			if err := ctrl.intentos.CalificarIntentoActual(tx, &entrega); err != nil {
+				return err
+			}
+		}
+		comentario := ""
+		if req.Devolucion != nil {
+			comentario = *req.Devolucion
+		}
+		if nuevoEstado != "" {
+			if err := ctrl.estados.Transicionar(tx, &entrega, nuevoEstado, int(actorID), role, comentario); err != nil {
+				return err
+			}
+		}
+		// Group submissions: the correction applies to every member
+		if err := ctrl.grupos.PropagarCorreccion(tx, &entrega, nuevoEstado, int(actorID), role, comentario); err != nil {
+			return err
+		}
+		if req.Nota == nil {
+			return nil
+		}
+		// The new grade can change the final grade and condición of every member
+		var tp models.TpModel
+		if err := tx.Select("id", "comision_id").First(&tp, entrega.TpId).Error; err != nil {
+			return err
+		}
+		alumnos, err := ctrl.grupos.IntegrantesDe(tp.ID, entrega.AlumnoId)
+		if err != nil {
+			return err
+		}
+		return services.RecalcularCursadas(tx, tp.ComisionId, alumnos)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating entrega"})
 		return
 	}
@@ -252,25 +363,163 @@ func (ctrl *EntregaTPController) UpdateEntrega(c *gin.Context) {
 	// Reload with relationships
 	ctrl.DB.Preload("Tp").Preload("Tp.Comision").Preload("Tp.Comision.Materia").Preload("Alumno").Preload("Cursada").First(&entrega, entrega.ID)
 
-	// Create notification if TP was graded
-	if isGrading && entrega.Nota != nil {
-		materiaNombre := "TP"
-		if entrega.Tp.Comision.Materia.Nombre != "" {
-			materiaNombre = entrega.Tp.Comision.Materia.Nombre
-		}
+	c.JSON(http.StatusOK, entrega)
+}
+
+// CambiarEstado - Move a submission to another state (teachers/admin)
+func (ctrl *EntregaTPController) CambiarEstado(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
 
-		notificacion := models.Notificacion{
-			Mensaje:   "Tu TP de " + materiaNombre + " ha sido calificado con nota: " + strconv.FormatFloat(*entrega.Nota, 'f', 1, 64),
-			FechaHora: time.Now(),
-			Leida:     false,
-			AlumnoID:  entrega.AlumnoId,
+	var req models.CambioEstadoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userRole, _ := c.Get("userRole")
+	userIdInterface, _ := c.Get("userID")
+	actorID, _ := userIdInterface.(float64)
+	role, _ := userRole.(string)
+
+	entrega, err := ctrl.estados.CambiarEstado(id, &req, int(actorID), models.Role(role))
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Entrega not found"})
+		case errors.Is(err, services.ErrTransicionInvalida):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating estado"})
 		}
-		ctrl.DB.Create(&notificacion)
+		return
 	}
 
 	c.JSON(http.StatusOK, entrega)
 }
 
+// GetTransiciones - State history of a submission
+func (ctrl *EntregaTPController) GetTransiciones(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	transiciones, err := ctrl.estados.GetTransiciones(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching estado history"})
+		return
+	}
+	c.JSON(http.StatusOK, transiciones)
+}
+
+// GetIntentos - List all attempts of a submission
+func (ctrl *EntregaTPController) GetIntentos(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	intentos, err := ctrl.intentos.GetIntentos(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching attempts"})
+		return
+	}
+	c.JSON(http.StatusOK, intentos)
+}
+
+// GetIntento - Get a specific attempt of a submission
+func (ctrl *EntregaTPController) GetIntento(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+	numero, err := strconv.Atoi(c.Param("numero"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attempt number"})
+		return
+	}
+
+	intento, err := ctrl.intentos.GetIntento(id, numero)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Attempt not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching attempt"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, intento)
+}
+
+// DiffIntentos - Compare two attempts (?desde=1&hasta=2, defaults to the last two)
+func (ctrl *EntregaTPController) DiffIntentos(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var entrega models.EntregaTP
+	if err := ctrl.DB.Select("id", "intento_actual").First(&entrega, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Entrega not found"})
+		return
+	}
+
+	hasta := entrega.IntentoActual
+	if value := c.Query("hasta"); value != "" {
+		if hasta, err = strconv.Atoi(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid hasta"})
+			return
+		}
+	}
+	desde := hasta - 1
+	if value := c.Query("desde"); value != "" {
+		if desde, err = strconv.Atoi(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid desde"})
+			return
+		}
+	}
+
+	diff, err := ctrl.intentos.DiffIntentos(id, desde, hasta)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Attempt not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error comparing attempts"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, diff)
+}
+
+// respondPlazoError maps deadline policy and file restriction errors to HTTP responses
+func respondPlazoError(c *gin.Context, err error) {
+	if respondUploadError(c, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, services.ErrArchivoAjeno):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrTpNoVigente):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrEntregaFueraDePlazo):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrMaxIntentos):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrReentregaNoPermitida):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
 // DeleteEntrega - Delete a submission
 func (ctrl *EntregaTPController) DeleteEntrega(c *gin.Context) {
 	id := c.Param("id")
@@ -296,7 +545,8 @@ func (ctrl *EntregaTPController) DeleteEntrega(c *gin.Context) {
 // UploadArchivoForAlumno - Allows a student to upload a file for a TP and returns a URL
 func (ctrl *EntregaTPController) UploadArchivoForAlumno(c *gin.Context) {
 	// Require authenticated user (middleware should set userID)
-	if _, exists := c.Get("userID"); !exists {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
 		return
 	}
@@ -307,28 +557,26 @@ func (ctrl *EntregaTPController) UploadArchivoForAlumno(c *gin.Context) {
 		return
 	}
 
-	if file.Size == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "El archivo está vacío"})
-		return
-	}
-
-	uploadDir := "uploads/entregas_tp"
-	if err := os.MkdirAll(uploadDir, 0755); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "No se pudo crear el directorio de uploads", "details": err.Error()})
-		return
+	// If the TP is known, its size and extension restrictions are applied right away
+	reglas := ctrl.archivos.ReglasGlobales()
+	if tpID := c.PostForm("tp_id"); tpID != "" {
+		var tp models.TpModel
+		if err := ctrl.DB.First(&tp, tpID).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "TP not found"})
+			return
+		}
+		reglas = ctrl.archivos.ReglasTp(&tp)
 	}
 
-	timestamp := time.Now().Unix()
-	safeName := filepath.Base(file.Filename)
-	filename := fmt.Sprintf("%d_%s", timestamp, safeName)
-	destPath := filepath.Join(uploadDir, filename)
-
-	if err := c.SaveUploadedFile(file, destPath); err != nil {
+	// The file is linked to the entrega once the student submits it
+	archivo, err := ctrl.archivos.GuardarUpload(file, reglas, models.RecursoEntregaTP, 0, userID, models.RoleAlumno)
+	if err != nil {
+		if respondUploadError(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error guardando el archivo", "details": err.Error()})
 		return
 	}
 
-	// Return a public URL path under /uploads so frontend can use it
-	archivoURL := "/uploads/entregas_tp/" + filename
-	c.JSON(http.StatusCreated, gin.H{"archivo_url": archivoURL})
+	c.JSON(http.StatusCreated, gin.H{"archivo_url": archivo.URL, "archivo": archivo})
 }
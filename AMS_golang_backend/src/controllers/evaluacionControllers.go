@@ -1,12 +1,15 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
+	"github.com/LINSITrack/backend/src/middleware"
 	"github.com/LINSITrack/backend/src/models"
 	"github.com/LINSITrack/backend/src/services"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type EvaluacionController struct {
@@ -17,8 +20,24 @@ func NewEvaluacionController(evaluacionService *services.EvaluacionService) *Eva
 	return &EvaluacionController{evaluacionService: evaluacionService}
 }
 
+// GetAllEvaluaciones lista las evaluaciones (?periodo_id=3). Un admin ve todas;
+// un profesor, las de sus comisiones y un alumno, las de las comisiones que cursa.
 func (c *EvaluacionController) GetAllEvaluaciones(ctx *gin.Context) {
-	evaluaciones, err := c.evaluacionService.GetAllEvaluaciones()
+	periodoID, ok := queryPeriodoID(ctx)
+	if !ok {
+		return
+	}
+	userID, _ := middleware.CurrentUserID(ctx)
+	var evaluaciones []models.EvaluacionModel
+	var err error
+	switch ctx.GetString("userRole") {
+	case string(models.RoleAdmin):
+		evaluaciones, err = c.evaluacionService.GetAllEvaluaciones(periodoID)
+	case string(models.RoleProfesor):
+		evaluaciones, err = c.evaluacionService.GetEvaluacionesByProfesorID(userID, periodoID)
+	default:
+		evaluaciones, err = c.evaluacionService.GetEvaluacionesByAlumnoID(userID, periodoID)
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -35,7 +54,11 @@ func (c *EvaluacionController) GetMyEvaluaciones(ctx *gin.Context) {
 	}
 
 	profesorID := int(userID.(float64))
-	evaluaciones, err := c.evaluacionService.GetEvaluacionesByProfesorID(profesorID)
+	periodoID, ok := queryPeriodoID(ctx)
+	if !ok {
+		return
+	}
+	evaluaciones, err := c.evaluacionService.GetEvaluacionesByProfesorID(profesorID, periodoID)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -120,7 +143,7 @@ func (c *EvaluacionController) CreateEvaluacion(ctx *gin.Context) {
 	}
 
 	if err := c.evaluacionService.CreateEvaluacion(&evaluacion); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondEvaluacionError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusCreated, evaluacion)
@@ -158,7 +181,7 @@ func (c *EvaluacionController) UpdateEvaluacion(ctx *gin.Context) {
 
 	evaluacion, err := c.evaluacionService.UpdateEvaluacion(id, &updateRequest)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondEvaluacionError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, evaluacion)
@@ -171,12 +194,27 @@ func (c *EvaluacionController) DeleteEvaluacion(ctx *gin.Context) {
 		return
 	}
 	if err := c.evaluacionService.DeleteEvaluacion(id); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondEvaluacionError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusNoContent, nil)
 }
 
+// InscribirRecuperatorio recalcula los alumnos que tienen que rendir un recuperatorio
+func (c *EvaluacionController) InscribirRecuperatorio(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+	inscripcion, err := c.evaluacionService.InscribirRecuperatorio(id)
+	if err != nil {
+		respondEvaluacionError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, inscripcion)
+}
+
 // SyncEntregasEvaluaciones creates missing EntregaEvaluacion entries (for migration purposes)
 func (c *EvaluacionController) SyncEntregasEvaluaciones(ctx *gin.Context) {
 	if err := c.evaluacionService.SyncEntregasEvaluaciones(); err != nil {
@@ -258,3 +296,16 @@ func (c *EvaluacionController) UpdateEntregaEvaluacion(ctx *gin.Context) {
 	}
 	ctx.JSON(http.StatusOK, entrega)
 }
+
+func respondEvaluacionError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "evaluación no encontrada"})
+	case errors.Is(err, services.ErrRecuperatorioInvalido):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrTieneRecuperatorio):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/LINSITrack/backend/src/middleware"
+	"github.com/LINSITrack/backend/src/models"
+	"github.com/LINSITrack/backend/src/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type GrupoController struct {
+	service *services.GrupoService
+}
+
+func NewGrupoController(service *services.GrupoService) *GrupoController {
+	return &GrupoController{service: service}
+}
+
+func (c *GrupoController) GetGruposByTp(ctx *gin.Context) {
+	tpID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	grupos, err := c.service.GetGruposByTp(tpID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, grupos)
+}
+
+// GetMiGrupo devuelve el grupo del alumno autenticado para el TP
+func (c *GrupoController) GetMiGrupo(ctx *gin.Context) {
+	tpID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+	alumnoID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "ID de usuario no encontrado"})
+		return
+	}
+
+	grupo, err := c.service.GetGrupoDeAlumno(tpID, alumnoID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "no tenés grupo para este TP"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, grupo)
+}
+
+func (c *GrupoController) CrearGrupo(ctx *gin.Context) {
+	tpID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var req models.GrupoCreateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "ID de usuario no encontrado"})
+		return
+	}
+	userRole, _ := ctx.Get("userRole")
+	role, _ := userRole.(string)
+
+	grupo, err := c.service.CrearGrupo(tpID, &req, userID, models.Role(role))
+	if err != nil {
+		respondGrupoError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, grupo)
+}
+
+func (c *GrupoController) ActualizarGrupo(ctx *gin.Context) {
+	tpID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+	grupoID, err := strconv.Atoi(ctx.Param("grupoId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de grupo inválido"})
+		return
+	}
+
+	var req models.GrupoUpdateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	grupo, err := c.service.ActualizarGrupo(tpID, grupoID, &req)
+	if err != nil {
+		respondGrupoError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, grupo)
+}
+
+func (c *GrupoController) EliminarGrupo(ctx *gin.Context) {
+	tpID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+	grupoID, err := strconv.Atoi(ctx.Param("grupoId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de grupo inválido"})
+		return
+	}
+
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "ID de usuario no encontrado"})
+		return
+	}
+	userRole, _ := ctx.Get("userRole")
+	role, _ := userRole.(string)
+
+	if err := c.service.EliminarGrupo(tpID, grupoID, userID, models.Role(role)); err != nil {
+		respondGrupoError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "grupo eliminado"})
+}
+
+func respondGrupoError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "grupo o TP no encontrado"})
+	case errors.Is(err, services.ErrGrupoNoPermitido), errors.Is(err, services.ErrNoIntegranteGrupo):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrGrupoConEntregas):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
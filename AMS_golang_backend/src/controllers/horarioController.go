@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/LINSITrack/backend/src/models"
+	"github.com/LINSITrack/backend/src/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type HorarioController struct {
+	service *services.HorarioService
+}
+
+func NewHorarioController(service *services.HorarioService) *HorarioController {
+	return &HorarioController{service: service}
+}
+
+// GetHorarios lista las franjas semanales de la comisión
+func (c *HorarioController) GetHorarios(ctx *gin.Context) {
+	id, ok := paramID(ctx, "ID de comisión inválido")
+	if !ok {
+		return
+	}
+	horarios, err := c.service.GetHorarios(id)
+	if err != nil {
+		respondHorarioError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, horarios)
+}
+
+func (c *HorarioController) CrearHorario(ctx *gin.Context) {
+	id, ok := paramID(ctx, "ID de comisión inválido")
+	if !ok {
+		return
+	}
+	var req models.HorarioComisionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	franja, err := c.service.CrearHorario(id, &req)
+	if err != nil {
+		respondHorarioError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, franja)
+}
+
+func (c *HorarioController) UpdateHorario(ctx *gin.Context) {
+	id, ok := paramID(ctx, "ID de horario inválido")
+	if !ok {
+		return
+	}
+	var req models.HorarioComisionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	franja, err := c.service.UpdateHorario(id, &req)
+	if err != nil {
+		respondHorarioError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, franja)
+}
+
+func (c *HorarioController) DeleteHorario(ctx *gin.Context) {
+	id, ok := paramID(ctx, "ID de horario inválido")
+	if !ok {
+		return
+	}
+	if err := c.service.DeleteHorario(id); err != nil {
+		respondHorarioError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Horario eliminado"})
+}
+
+func respondHorarioError(ctx *gin.Context, err error) {
+	var choques *services.ChoquesHorarioError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "recurso no encontrado"})
+	case errors.As(err, &choques):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error(), "choques": choques.Choques})
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
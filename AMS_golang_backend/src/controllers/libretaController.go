@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/LINSITrack/backend/src/models"
+	"github.com/LINSITrack/backend/src/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var libretaContentTypes = map[string]string{
+	models.FormatoLibretaCSV:  "text/csv; charset=utf-8",
+	models.FormatoLibretaXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	models.FormatoLibretaPDF:  "application/pdf",
+}
+
+type LibretaController struct {
+	service *services.LibretaService
+}
+
+func NewLibretaController(service *services.LibretaService) *LibretaController {
+	return &LibretaController{service: service}
+}
+
+// GetLibreta devuelve la libreta de calificaciones de la comisión.
+// Query: ?formato=json|csv|xlsx|pdf (json por defecto) y ?ano_lectivo=2025
+func (c *LibretaController) GetLibreta(ctx *gin.Context) {
+	comisionID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de comisión inválido"})
+		return
+	}
+
+	var anoLectivo *int
+	if value := ctx.Query("ano_lectivo"); value != "" {
+		ano, err := strconv.Atoi(value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Año lectivo inválido"})
+			return
+		}
+		anoLectivo = &ano
+	}
+
+	formato := ctx.DefaultQuery("formato", models.FormatoLibretaJSON)
+	contentType, ok := libretaContentTypes[formato]
+	if !ok && formato != models.FormatoLibretaJSON {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": services.ErrFormatoLibreta.Error()})
+		return
+	}
+
+	libreta, err := c.service.GetLibreta(comisionID, anoLectivo)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Comisión no encontrada"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if formato == models.FormatoLibretaJSON {
+		ctx.JSON(http.StatusOK, libreta)
+		return
+	}
+
+	// Se arma en memoria para poder responder un error si falla la generación
+	var buf bytes.Buffer
+	if err := c.service.Escribir(&buf, libreta, formato); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.Header("Content-Disposition", contentDisposition(c.service.NombreArchivo(libreta, formato)))
+	ctx.Data(http.StatusOK, contentType, buf.Bytes())
+}
@@ -0,0 +1,227 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/LINSITrack/backend/src/middleware"
+	"github.com/LINSITrack/backend/src/models"
+	"github.com/LINSITrack/backend/src/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type NotaFinalController struct {
+	service *services.NotaFinalService
+}
+
+func NewNotaFinalController(service *services.NotaFinalService) *NotaFinalController {
+	return &NotaFinalController{service: service}
+}
+
+// GetEsquemaComision devuelve el esquema que se aplica a la comisión (el propio o el de la materia)
+func (c *NotaFinalController) GetEsquemaComision(ctx *gin.Context) {
+	id, ok := paramID(ctx, "ID de comisión inválido")
+	if !ok {
+		return
+	}
+	esquema, err := c.service.GetEsquemaComision(id)
+	if err != nil {
+		respondNotaFinalError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, esquema)
+}
+
+func (c *NotaFinalController) GuardarEsquemaComision(ctx *gin.Context) {
+	id, ok := paramID(ctx, "ID de comisión inválido")
+	if !ok {
+		return
+	}
+	var req models.EsquemaCalificacionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	esquema, err := c.service.GuardarEsquemaComision(id, &req)
+	if err != nil {
+		respondNotaFinalError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, esquema)
+}
+
+func (c *NotaFinalController) BorrarEsquemaComision(ctx *gin.Context) {
+	id, ok := paramID(ctx, "ID de comisión inválido")
+	if !ok {
+		return
+	}
+	if err := c.service.BorrarEsquemaComision(id); err != nil {
+		respondNotaFinalError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Esquema de calificación eliminado"})
+}
+
+func (c *NotaFinalController) GetEsquemaMateria(ctx *gin.Context) {
+	id, ok := paramID(ctx, "ID de materia inválido")
+	if !ok {
+		return
+	}
+	esquema, err := c.service.GetEsquemaMateria(id)
+	if err != nil {
+		respondNotaFinalError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, esquema)
+}
+
+func (c *NotaFinalController) GuardarEsquemaMateria(ctx *gin.Context) {
+	id, ok := paramID(ctx, "ID de materia inválido")
+	if !ok {
+		return
+	}
+	var req models.EsquemaCalificacionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	esquema, err := c.service.GuardarEsquemaMateria(id, &req)
+	if err != nil {
+		respondNotaFinalError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, esquema)
+}
+
+func (c *NotaFinalController) BorrarEsquemaMateria(ctx *gin.Context) {
+	id, ok := paramID(ctx, "ID de materia inválido")
+	if !ok {
+		return
+	}
+	if err := c.service.BorrarEsquemaMateria(id); err != nil {
+		respondNotaFinalError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Esquema de calificación eliminado"})
+}
+
+// RecalcularComision calcula y guarda la nota final de las cursadas de la comisión (?ano_lectivo=2025)
+func (c *NotaFinalController) RecalcularComision(ctx *gin.Context) {
+	id, ok := paramID(ctx, "ID de comisión inválido")
+	if !ok {
+		return
+	}
+	var anoLectivo *int
+	if value := ctx.Query("ano_lectivo"); value != "" {
+		ano, err := strconv.Atoi(value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Año lectivo inválido"})
+			return
+		}
+		anoLectivo = &ano
+	}
+	resultados, err := c.service.RecalcularComision(id, anoLectivo)
+	if err != nil {
+		respondNotaFinalError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, resultados)
+}
+
+// GetNotaFinal devuelve el cálculo actual de la nota final de la cursada con su detalle
+func (c *NotaFinalController) GetNotaFinal(ctx *gin.Context) {
+	id, ok := paramID(ctx, "ID de cursada inválido")
+	if !ok {
+		return
+	}
+	resultado, err := c.service.CalcularCursada(id)
+	if err != nil {
+		respondNotaFinalError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, resultado)
+}
+
+func (c *NotaFinalController) AjustarNotaFinal(ctx *gin.Context) {
+	id, ok := paramID(ctx, "ID de cursada inválido")
+	if !ok {
+		return
+	}
+	var req models.AjusteNotaFinalRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID, _ := middleware.CurrentUserID(ctx)
+	userRole, _ := ctx.Get("userRole")
+	role, _ := userRole.(string)
+
+	cursada, err := c.service.AjustarNotaFinal(id, &req, userID, models.Role(role))
+	if err != nil {
+		respondNotaFinalError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, cursada)
+}
+
+// QuitarAjuste vuelve a la nota calculada; el body con el motivo es opcional
+func (c *NotaFinalController) QuitarAjuste(ctx *gin.Context) {
+	id, ok := paramID(ctx, "ID de cursada inválido")
+	if !ok {
+		return
+	}
+	var req models.QuitarAjusteRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	userID, _ := middleware.CurrentUserID(ctx)
+	userRole, _ := ctx.Get("userRole")
+	role, _ := userRole.(string)
+
+	cursada, err := c.service.QuitarAjuste(id, req.Motivo, userID, models.Role(role))
+	if err != nil {
+		respondNotaFinalError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, cursada)
+}
+
+func (c *NotaFinalController) GetAjustes(ctx *gin.Context) {
+	id, ok := paramID(ctx, "ID de cursada inválido")
+	if !ok {
+		return
+	}
+	ajustes, err := c.service.GetAjustes(id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, ajustes)
+}
+
+func paramID(ctx *gin.Context, mensaje string) (int, bool) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": mensaje})
+		return 0, false
+	}
+	return id, true
+}
+
+func respondNotaFinalError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "recurso no encontrado"})
+	case errors.Is(err, services.ErrSinEsquema):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrNotaNoAjustada):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/LINSITrack/backend/src/middleware"
+	"github.com/LINSITrack/backend/src/models"
+	"github.com/LINSITrack/backend/src/services"
+	"github.com/LINSITrack/backend/utils/upload"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Restricciones de la planilla de notas
+var reglasPlanillaNotas = upload.Reglas{MaxSize: 5 << 20, Extensiones: []string{".csv", ".xlsx"}}
+
+type NotasImportController struct {
+	service *services.NotasImportService
+}
+
+func NewNotasImportController(service *services.NotasImportService) *NotasImportController {
+	return &NotasImportController{service: service}
+}
+
+// ImportarNotasTp recibe la planilla en el campo "file". Con ?dry_run=true solo devuelve el diff.
+func (c *NotasImportController) ImportarNotasTp(ctx *gin.Context) {
+	tpID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de TP inválido"})
+		return
+	}
+	userID, _ := middleware.CurrentUserID(ctx)
+	userRole, _ := ctx.Get("userRole")
+	role, _ := userRole.(string)
+
+	c.importar(ctx, func(nombre string, planilla io.Reader, dryRun bool) (*models.NotasImportResultado, error) {
+		return c.service.ImportarNotasTp(tpID, nombre, planilla, dryRun, userID, models.Role(role))
+	})
+}
+
+// ImportarNotasEvaluacion recibe la planilla en el campo "file". Con ?dry_run=true solo devuelve el diff.
+func (c *NotasImportController) ImportarNotasEvaluacion(ctx *gin.Context) {
+	evaluacionID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de evaluación inválido"})
+		return
+	}
+
+	c.importar(ctx, func(nombre string, planilla io.Reader, dryRun bool) (*models.NotasImportResultado, error) {
+		return c.service.ImportarNotasEvaluacion(evaluacionID, nombre, planilla, dryRun)
+	})
+}
+
+// importar valida la planilla subida, ejecuta la importación y responde el diff:
+// 200 si se aplicó (o es un dry-run) y 422 si alguna fila tiene errores
+func (c *NotasImportController) importar(ctx *gin.Context, run func(nombre string, planilla io.Reader, dryRun bool) (*models.NotasImportResultado, error)) {
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "No se pudo obtener el archivo", "details": err.Error()})
+		return
+	}
+	if err := upload.ValidarTamanoYExtension(file.Filename, file.Size, reglasPlanillaNotas); err != nil {
+		respondUploadError(ctx, err)
+		return
+	}
+	planilla, err := file.Open()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer planilla.Close()
+
+	resultado, err := run(file.Filename, planilla, ctx.Query("dry_run") == "true")
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "recurso no encontrado"})
+		case errors.Is(err, services.ErrPlanillaInvalida):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	if resultado.Errores > 0 {
+		ctx.JSON(http.StatusUnprocessableEntity, resultado)
+		return
+	}
+	ctx.JSON(http.StatusOK, resultado)
+}
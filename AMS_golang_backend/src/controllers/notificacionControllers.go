@@ -48,18 +48,6 @@ func (c *NotificacionController) GetNotificacionesByAlumnoID(ctx *gin.Context) {
 		return
 	}
 
-	userID, _ := ctx.Get("userID")
-	userRole, _ := ctx.Get("userRole")
-
-	// Si es alumno, verificar que solo acceda a sus propias notificaciones
-	if userRole.(string) == string(models.RoleAlumno) {
-		userAlumnoID := int(userID.(float64))
-		if alumnoID != userAlumnoID {
-			ctx.JSON(http.StatusForbidden, gin.H{"error": "solo puedes acceder a tus propias notificaciones"})
-			return
-		}
-	}
-
 	notificaciones, err := c.notificacionService.GetNotificacionesByAlumnoID(alumnoID)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -75,18 +63,6 @@ func (c *NotificacionController) GetUnreadNotificacionesByAlumnoID(ctx *gin.Cont
 		return
 	}
 
-	userID, _ := ctx.Get("userID")
-	userRole, _ := ctx.Get("userRole")
-
-	// Si es alumno, verificar que solo acceda a sus propias notificaciones
-	if userRole.(string) == string(models.RoleAlumno) {
-		userAlumnoID := int(userID.(float64))
-		if alumnoID != userAlumnoID {
-			ctx.JSON(http.StatusForbidden, gin.H{"error": "solo puedes acceder a tus propias notificaciones"})
-			return
-		}
-	}
-
 	notificaciones, err := c.notificacionService.GetUnreadNotificacionesByAlumnoID(alumnoID)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -102,18 +78,6 @@ func (c *NotificacionController) GetReadNotificacionesByAlumnoID(ctx *gin.Contex
 		return
 	}
 
-	userID, _ := ctx.Get("userID")
-	userRole, _ := ctx.Get("userRole")
-
-	// Si es alumno, verificar que solo acceda a sus propias notificaciones
-	if userRole.(string) == string(models.RoleAlumno) {
-		userAlumnoID := int(userID.(float64))
-		if alumnoID != userAlumnoID {
-			ctx.JSON(http.StatusForbidden, gin.H{"error": "solo puedes acceder a tus propias notificaciones"})
-			return
-		}
-	}
-
 	notificaciones, err := c.notificacionService.GetReadNotificacionesByAlumnoID(alumnoID)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -142,18 +106,6 @@ func (c *NotificacionController) MarkAllNotificacionAsReadByAlumnoID(ctx *gin.Co
 		return
 	}
 
-	userID, _ := ctx.Get("userID")
-	userRole, _ := ctx.Get("userRole")
-
-	// Si es alumno, verificar que solo acceda a sus propias notificaciones
-	if userRole.(string) == string(models.RoleAlumno) {
-		userAlumnoID := int(userID.(float64))
-		if alumnoID != userAlumnoID {
-			ctx.JSON(http.StatusForbidden, gin.H{"error": "solo puedes modificar tus propias notificaciones"})
-			return
-		}
-	}
-
 	if err := c.notificacionService.MarkAllNotificacionAsReadByAlumnoID(alumnoID); err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/LINSITrack/backend/src/models"
+	"github.com/LINSITrack/backend/src/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type PeriodoController struct {
+	service *services.PeriodoService
+}
+
+func NewPeriodoController(service *services.PeriodoService) *PeriodoController {
+	return &PeriodoController{service: service}
+}
+
+// queryPeriodoID lee el filtro opcional ?periodo_id; si es inválido responde 400
+func queryPeriodoID(ctx *gin.Context) (*int, bool) {
+	value := ctx.Query("periodo_id")
+	if value == "" {
+		return nil, true
+	}
+	id, err := strconv.Atoi(value)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de período inválido"})
+		return nil, false
+	}
+	return &id, true
+}
+
+func (c *PeriodoController) GetPeriodos(ctx *gin.Context) {
+	periodos, err := c.service.GetPeriodos()
+	if err != nil {
+		respondPeriodoError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, periodos)
+}
+
+func (c *PeriodoController) GetPeriodo(ctx *gin.Context) {
+	id, ok := paramID(ctx, "ID de período inválido")
+	if !ok {
+		return
+	}
+	periodo, err := c.service.GetPeriodo(id)
+	if err != nil {
+		respondPeriodoError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, periodo)
+}
+
+func (c *PeriodoController) CrearPeriodo(ctx *gin.Context) {
+	var req models.PeriodoRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	periodo, err := c.service.CrearPeriodo(&req)
+	if err != nil {
+		respondPeriodoError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, periodo)
+}
+
+func (c *PeriodoController) UpdatePeriodo(ctx *gin.Context) {
+	id, ok := paramID(ctx, "ID de período inválido")
+	if !ok {
+		return
+	}
+	var req models.PeriodoRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	periodo, err := c.service.UpdatePeriodo(id, &req)
+	if err != nil {
+		respondPeriodoError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, periodo)
+}
+
+func (c *PeriodoController) DeletePeriodo(ctx *gin.Context) {
+	id, ok := paramID(ctx, "ID de período inválido")
+	if !ok {
+		return
+	}
+	if err := c.service.DeletePeriodo(id); err != nil {
+		respondPeriodoError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Período eliminado"})
+}
+
+// Rollover copia la estructura de la comisión (TPs, competencias, anexos y
+// profesores) a una nueva comisión del período siguiente o del indicado
+func (c *PeriodoController) Rollover(ctx *gin.Context) {
+	id, ok := paramID(ctx, "ID de comisión inválido")
+	if !ok {
+		return
+	}
+	var req models.RolloverRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	resultado, err := c.service.Rollover(id, &req)
+	if err != nil {
+		respondPeriodoError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, resultado)
+}
+
+func respondPeriodoError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "recurso no encontrado"})
+	case errors.Is(err, services.ErrPeriodoEnUso), errors.Is(err, services.ErrRolloverDuplicado):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
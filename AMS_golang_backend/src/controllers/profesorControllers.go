@@ -12,11 +12,12 @@ import (
 )
 
 type ProfesorController struct {
-	service *services.ProfesorService
+	service        *services.ProfesorService
+	accountService *services.AccountService
 }
 
-func NewProfesorController(service *services.ProfesorService) *ProfesorController {
-	return &ProfesorController{service: service}
+func NewProfesorController(service *services.ProfesorService, accountService *services.AccountService) *ProfesorController {
+	return &ProfesorController{service: service, accountService: accountService}
 }
 
 func (c *ProfesorController) UpdateProfesor(ctx *gin.Context) {
@@ -99,6 +100,8 @@ func (c *ProfesorController) CreateProfesor(ctx *gin.Context) {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	// Si se creó sin contraseña, se envía la invitación para activar la cuenta
+	c.accountService.SendInvitationIfPending(createdProfesor.UsuarioID)
 	ctx.JSON(http.StatusCreated, createdProfesor)
 }
 
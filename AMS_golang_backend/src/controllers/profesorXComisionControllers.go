@@ -35,8 +35,13 @@ func (c *ProfesorXComisionController) CreateProfesorXComision(ctx *gin.Context)
 	ctx.JSON(http.StatusCreated, response)
 }
 
+// GetAllProfesorXComision lista las asignaciones (?periodo_id=3)
 func (c *ProfesorXComisionController) GetAllProfesorXComision(ctx *gin.Context) {
-	responses, err := c.service.GetAllProfesorXComision()
+	periodoID, ok := queryPeriodoID(ctx)
+	if !ok {
+		return
+	}
+	responses, err := c.service.GetAllProfesorXComision(periodoID)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -71,7 +76,11 @@ func (c *ProfesorXComisionController) GetComisionesByProfesorID(ctx *gin.Context
 		return
 	}
 
-	responses, err := c.service.GetComisionesByProfesorID(profesorID)
+	periodoID, ok := queryPeriodoID(ctx)
+	if !ok {
+		return
+	}
+	responses, err := c.service.GetComisionesByProfesorID(profesorID, periodoID)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -104,7 +113,11 @@ func (c *ProfesorXComisionController) GetMisComisiones(ctx *gin.Context) {
 	}
 
 	profesorID := int(userID.(float64))
-	responses, err := c.service.GetComisionesByProfesorID(profesorID)
+	periodoID, ok := queryPeriodoID(ctx)
+	if !ok {
+		return
+	}
+	responses, err := c.service.GetComisionesByProfesorID(profesorID, periodoID)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/LINSITrack/backend/src/middleware"
+	"github.com/LINSITrack/backend/src/models"
+	"github.com/LINSITrack/backend/src/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ProrrogaController struct {
+	plazoService *services.PlazoService
+	tpService    *services.TpService
+}
+
+func NewProrrogaController(plazoService *services.PlazoService, tpService *services.TpService) *ProrrogaController {
+	return &ProrrogaController{plazoService: plazoService, tpService: tpService}
+}
+
+func (c *ProrrogaController) GetProrrogasByTp(ctx *gin.Context) {
+	tpID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	prorrogas, err := c.plazoService.GetProrrogasByTp(tpID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, prorrogas)
+}
+
+func (c *ProrrogaController) OtorgarProrroga(ctx *gin.Context) {
+	tpID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var req models.ProrrogaCreateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "ID de usuario no encontrado"})
+		return
+	}
+
+	prorroga, err := c.plazoService.OtorgarProrroga(tpID, &req, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "TP no encontrado"})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusCreated, prorroga)
+}
+
+func (c *ProrrogaController) RevocarProrroga(ctx *gin.Context) {
+	tpID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+	alumnoID, err := strconv.Atoi(ctx.Param("alumnoId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de alumno inválido"})
+		return
+	}
+
+	if err := c.plazoService.RevocarProrroga(tpID, alumnoID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "prórroga no encontrada"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "prórroga revocada"})
+}
+
+// GetMiPlazo devuelve al alumno su fecha límite efectiva para el TP
+func (c *ProrrogaController) GetMiPlazo(ctx *gin.Context) {
+	tpID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+	alumnoID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "ID de usuario no encontrado"})
+		return
+	}
+
+	tp, err := c.tpService.GetTpByID(tpID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "TP no encontrado"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	plazo, err := c.plazoService.GetPlazo(tp, alumnoID, time.Now())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, plazo)
+}
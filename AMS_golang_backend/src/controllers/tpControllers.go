@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/LINSITrack/backend/src/middleware"
 	"github.com/LINSITrack/backend/src/models"
 	"github.com/LINSITrack/backend/src/services"
 	"github.com/gin-gonic/gin"
@@ -18,8 +19,24 @@ func NewTpController(tpService *services.TpService) *TpController {
 	return &TpController{tpService: tpService}
 }
 
+// GetAllTps lista los TPs (?periodo_id=3). Un admin ve todos; un profesor, los
+// de sus comisiones y un alumno, los de las comisiones que cursa.
 func (c *TpController) GetAllTps(ctx *gin.Context) {
-	tps, err := c.tpService.GetAllTps()
+	periodoID, ok := queryPeriodoID(ctx)
+	if !ok {
+		return
+	}
+	userID, _ := middleware.CurrentUserID(ctx)
+	var tps []models.TpModel
+	var err error
+	switch ctx.GetString("userRole") {
+	case string(models.RoleAdmin):
+		tps, err = c.tpService.GetAllTps(periodoID)
+	case string(models.RoleProfesor):
+		tps, err = c.tpService.GetTpsByProfesorID(userID, periodoID)
+	default:
+		tps, err = c.tpService.GetTpsByAlumnoID(userID, periodoID)
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -36,7 +53,11 @@ func (c *TpController) GetMyTps(ctx *gin.Context) {
 	}
 
 	profesorID := int(userID.(float64))
-	tps, err := c.tpService.GetTpsByProfesorID(profesorID)
+	periodoID, ok := queryPeriodoID(ctx)
+	if !ok {
+		return
+	}
+	tps, err := c.tpService.GetTpsByProfesorID(profesorID, periodoID)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -53,7 +74,11 @@ func (c *TpController) GetMyTpsAsAlumno(ctx *gin.Context) {
 	}
 
 	alumnoID := int(userID.(float64))
-	tps, err := c.tpService.GetTpsByAlumnoID(alumnoID)
+	periodoID, ok := queryPeriodoID(ctx)
+	if !ok {
+		return
+	}
+	tps, err := c.tpService.GetTpsByAlumnoID(alumnoID, periodoID)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
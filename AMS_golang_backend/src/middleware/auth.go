@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/LINSITrack/backend/src/models"
@@ -21,13 +22,31 @@ func GetSecretKey() string {
 	return secretKey
 }
 
+// isTokenRevoked consulta la lista de access tokens revocados (por jti).
+// Se establece en main con AuthService.IsTokenRevoked.
+var isTokenRevoked func(jti string) bool
+
+func SetRevocationChecker(checker func(jti string) bool) {
+	isTokenRevoked = checker
+}
+
 func AuthMiddleware() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		// Obtener el token de la cookie
-		tokenString, err := ctx.Cookie("jwt")
-		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "No se encontró la cookie de autenticación"})
-			ctx.Abort()
+		// Obtener el token del header Authorization (scripts, CLI) o de la cookie (frontend)
+		tokenString, fromHeader := bearerToken(ctx)
+		if !fromHeader {
+			var err error
+			tokenString, err = ctx.Cookie("jwt")
+			if err != nil {
+				ctx.JSON(http.StatusUnauthorized, gin.H{"error": "No se encontró la cookie de autenticación"})
+				ctx.Abort()
+				return
+			}
+		}
+
+		// Las API keys se validan contra la base y se limitan a sus scopes
+		if fromHeader && strings.HasPrefix(tokenString, models.APIKeyPrefix) {
+			authenticateAPIKey(ctx, tokenString)
 			return
 		}
 
@@ -53,10 +72,28 @@ func AuthMiddleware() gin.HandlerFunc {
 			}
 		}
 
+		// Verificar que el token no haya sido revocado (logout, force-logout, rotación)
+		jti, _ := claims["jti"].(string)
+		if jti != "" && isTokenRevoked != nil && isTokenRevoked(jti) {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "token revocado"})
+			ctx.Abort()
+			return
+		}
+
 		// Establecer claims en el contexto de Gin
 		ctx.Set("userID", claims["id"])
+		ctx.Set("usuarioID", claims["uid"])
 		ctx.Set("userEmail", claims["email"])
 		ctx.Set("userRole", claims["role"])
+		ctx.Set("tokenID", jti)
+		if fromHeader {
+			ctx.Set("authMethod", "bearer")
+		} else {
+			ctx.Set("authMethod", "cookie")
+		}
+		if sid, ok := claims["sid"].(float64); ok {
+			ctx.Set("sessionID", int(sid))
+		}
 
         // Claims opcionales
         if nombre, exists := claims["nombre"]; exists {
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// trustedProxies son los proxies (IPs o CIDRs) de los que se aceptan los
+// encabezados X-Forwarded-For / X-Real-IP. Se establece en main.
+var trustedProxies []string
+
+// TrustedProxiesFromEnv lee TRUSTED_PROXIES (separados por coma); vacío = ninguno
+func TrustedProxiesFromEnv() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+
+// SetTrustedProxies configura el router para aceptar los encabezados de IP solo de los proxies dados
+func SetTrustedProxies(router *gin.Engine, proxies []string) error {
+	if err := router.SetTrustedProxies(proxies); err != nil {
+		return err
+	}
+	trustedProxies = proxies
+	return nil
+}
+
+// ClientIP devuelve la IP del cliente para el límite de intentos y la auditoría.
+// Sin proxies de confianza es la IP de la conexión: los encabezados los puede
+// falsificar cualquier cliente.
+func ClientIP(ctx *gin.Context) string {
+	if len(trustedProxies) == 0 {
+		return ctx.RemoteIP()
+	}
+	return ctx.ClientIP()
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/LINSITrack/backend/src/models"
+	"github.com/gin-gonic/gin"
+)
+
+// SelfParam toma el parámetro de la ruta como ID del alumno (ej: /cursadas/alumno/:alumnoId)
+func SelfParam(param string) IDResolver {
+	return FromParam(param, func(id int) (int, error) {
+		return id, nil
+	})
+}
+
+// RequireAlumnoSelf restringe a los alumnos a sus propios datos: el alumno
+// resuelto a partir del request debe coincidir con el claim userID.
+// Los demás roles pasan; su acceso lo controlan RequireRole y RequireComisionAccess.
+func RequireAlumnoSelf(resolve IDResolver) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userRole, _ := ctx.Get("userRole")
+		if role, _ := userRole.(string); models.Role(role) != models.RoleAlumno {
+			ctx.Next()
+			return
+		}
+
+		alumnoID, ok := CurrentUserID(ctx)
+		if !ok {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "ID de usuario no encontrado"})
+			ctx.Abort()
+			return
+		}
+
+		ownerID, err := resolve(ctx)
+		if err != nil {
+			abortResolveError(ctx, err)
+			return
+		}
+		if ownerID != alumnoID {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "solo puedes acceder a tus propios datos"})
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// AlumnoPolicy decide si un alumno cursa en una comisión.
+// La implementa services.PolicyService.
+type AlumnoPolicy interface {
+	AlumnoEnComision(alumnoID, comisionID int) (bool, error)
+}
+
+// RequireAlumnoInscripto restringe a los alumnos a los recursos de las
+// comisiones en las que tienen una cursada. Los demás roles pasan.
+func RequireAlumnoInscripto(policy AlumnoPolicy, resolve IDResolver) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userRole, _ := ctx.Get("userRole")
+		if role, _ := userRole.(string); models.Role(role) != models.RoleAlumno {
+			ctx.Next()
+			return
+		}
+
+		alumnoID, ok := CurrentUserID(ctx)
+		if !ok {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "ID de usuario no encontrado"})
+			ctx.Abort()
+			return
+		}
+
+		comisionID, err := resolve(ctx)
+		if err != nil {
+			abortResolveError(ctx, err)
+			return
+		}
+
+		allowed, err := policy.AlumnoEnComision(alumnoID, comisionID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			ctx.Abort()
+			return
+		}
+		if !allowed {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "no estás inscripto en la comisión de este recurso"})
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/LINSITrack/backend/src/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ComisionPolicy decide si un profesor está asignado a una comisión.
+// La implementa services.PolicyService.
+type ComisionPolicy interface {
+	ProfesorEnComision(profesorID, comisionID int, cargos ...models.ProfesorCargo) (bool, error)
+}
+
+// IDResolver obtiene, a partir del request, el ID del dueño del recurso
+// (la comisión o el alumno). Devuelve 0 cuando el request no lo referencia.
+type IDResolver func(ctx *gin.Context) (int, error)
+
+var errParametroInvalido = errors.New("parámetro inválido")
+
+// FromParam resuelve el dueño a partir de un parámetro de la ruta (ej: /tps/:id)
+func FromParam(param string, lookup func(id int) (int, error)) IDResolver {
+	return func(ctx *gin.Context) (int, error) {
+		id, err := strconv.Atoi(ctx.Param(param))
+		if err != nil {
+			return 0, errParametroInvalido
+		}
+		return lookup(id)
+	}
+}
+
+// FromBody resuelve el dueño a partir de un campo numérico del body JSON.
+// El body se restaura para que el controller pueda volver a leerlo.
+func FromBody(field string, lookup func(id int) (int, error)) IDResolver {
+	return func(ctx *gin.Context) (int, error) {
+		if ctx.Request.Body == nil {
+			return 0, nil
+		}
+		body, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			return 0, err
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		var payload map[string]interface{}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			// El controller se encarga de responder por el JSON inválido
+			return 0, nil
+		}
+		value, ok := payload[field].(float64)
+		if !ok || value == 0 {
+			return 0, nil
+		}
+		return lookup(int(value))
+	}
+}
+
+// RequireComisionAccess restringe a los profesores a los recursos de las
+// comisiones en las que están asignados (opcionalmente con alguno de los cargos dados).
+// Los admins pasan siempre; el resto de los roles queda a cargo de RequireRole.
+func RequireComisionAccess(policy ComisionPolicy, resolve IDResolver, cargos ...models.ProfesorCargo) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userRole, _ := ctx.Get("userRole")
+		if role, _ := userRole.(string); models.Role(role) != models.RoleProfesor {
+			ctx.Next()
+			return
+		}
+
+		profesorID, ok := CurrentUserID(ctx)
+		if !ok {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "ID de usuario no encontrado"})
+			ctx.Abort()
+			return
+		}
+
+		comisionID, err := resolve(ctx)
+		if err != nil {
+			abortResolveError(ctx, err)
+			return
+		}
+		if comisionID == 0 {
+			ctx.Next()
+			return
+		}
+
+		allowed, err := policy.ProfesorEnComision(profesorID, comisionID, cargos...)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			ctx.Abort()
+			return
+		}
+		if !allowed {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "no estás asignado a la comisión de este recurso"})
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// abortResolveError responde según el error devuelto por un IDResolver
+func abortResolveError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errParametroInvalido):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "recurso no encontrado"})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+	ctx.Abort()
+}
+
+// CurrentUserID devuelve el ID del usuario autenticado (el claim "id" llega como float64)
+func CurrentUserID(ctx *gin.Context) (int, bool) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		return 0, false
+	}
+	id, ok := userID.(float64)
+	if !ok {
+		return 0, false
+	}
+	return int(id), true
+}
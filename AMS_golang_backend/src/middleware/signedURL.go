@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuthOrSignedURL deja pasar sin sesión los requests que traen una firma válida
+// (URLs de descarga firmadas, ?expires=...&signature=...) y exige autenticación
+// al resto. La validación de la firma la hace verify.
+func AuthOrSignedURL(verify func(ctx *gin.Context) bool) gin.HandlerFunc {
+	auth := AuthMiddleware()
+	return func(ctx *gin.Context) {
+		if ctx.Query("signature") == "" {
+			auth(ctx)
+			return
+		}
+		if !verify(ctx) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "URL de descarga inválida o vencida"})
+			ctx.Abort()
+			return
+		}
+		ctx.Set("authMethod", "signed_url")
+		ctx.Next()
+	}
+}
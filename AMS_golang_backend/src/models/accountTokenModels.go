@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+type AccountTokenPurpose string
+
+const (
+	TokenPasswordReset AccountTokenPurpose = "password_reset"
+	TokenInvitation    AccountTokenPurpose = "invitation"
+)
+
+// AccountToken es un token de un solo uso enviado por email para recuperar la
+// contraseña o activar una cuenta creada sin contraseña. Se guarda hasheado.
+type AccountToken struct {
+	ID        int                 `json:"id" gorm:"primaryKey;autoIncrement"`
+	UsuarioID int                 `json:"usuario_id" gorm:"column:usuario_id;not null;index"`
+	Purpose   AccountTokenPurpose `json:"purpose" gorm:"column:purpose;type:varchar(20);not null"`
+	TokenHash string              `json:"-" gorm:"column:token_hash;type:varchar(64);uniqueIndex;not null"`
+	ExpiresAt time.Time           `json:"expires_at" gorm:"column:expires_at;not null"`
+	UsedAt    *time.Time          `json:"used_at,omitempty" gorm:"column:used_at;default:null"`
+	CreatedAt time.Time           `json:"created_at" gorm:"column:created_at"`
+
+	Usuario Usuario `json:"-" gorm:"foreignKey:UsuarioID;constraint:OnDelete:CASCADE"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// RedeemTokenRequest sirve tanto para el reset de contraseña como para la activación
+type RedeemTokenRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=8"`
+}
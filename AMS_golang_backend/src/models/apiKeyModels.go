@@ -0,0 +1,117 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Prefijo de las API keys; AuthMiddleware lo usa para distinguirlas de un JWT
+const APIKeyPrefix = "linsi_"
+
+// Scopes especiales que no siguen el formato "<recurso>:read|write"
+const (
+	ScopeNotasImport = "notas:import"
+)
+
+// APIKeyResources son los recursos (primer segmento de la ruta) que se pueden
+// habilitar en una API key como "<recurso>:read" o "<recurso>:write".
+// /auth y /admin nunca son accesibles con API key.
+var APIKeyResources = []string{
+	"alumnos",
+	"anexos",
+	"clases",
+	"comisiones",
+	"competencias",
+	"cursadas",
+	"entregas",
+	"evaluaciones",
+	"horarios",
+	"materias",
+	"mis-entregas",
+	"notificaciones",
+	"periodos",
+	"profesores",
+	"tps",
+}
+
+// IsValidAPIKeyScope indica si el scope puede asignarse a una API key
+func IsValidAPIKeyScope(scope string) bool {
+	if scope == ScopeNotasImport {
+		return true
+	}
+	resource, access, ok := strings.Cut(scope, ":")
+	if !ok || (access != "read" && access != "write") {
+		return false
+	}
+	for _, r := range APIKeyResources {
+		if r == resource {
+			return true
+		}
+	}
+	return false
+}
+
+// ApiKey es una credencial para scripts e integraciones. Actúa en nombre del
+// perfil indicado (UserID + Role) pero solo sobre los scopes habilitados.
+type ApiKey struct {
+	ID         int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	Nombre     string     `json:"nombre" gorm:"column:nombre;type:varchar(100);not null"`
+	Prefix     string     `json:"prefix" gorm:"column:prefix;type:varchar(20);not null"`
+	KeyHash    string     `json:"-" gorm:"column:key_hash;type:varchar(64);uniqueIndex;not null"`
+	UserID     int        `json:"user_id" gorm:"column:user_id;not null;index:idx_api_keys_user"`
+	Role       Role       `json:"role" gorm:"column:role;type:varchar(20);not null;index:idx_api_keys_user"`
+	Scopes     string     `json:"-" gorm:"column:scopes;type:text;not null"`
+	CreatedBy  int        `json:"created_by" gorm:"column:created_by"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"column:created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" gorm:"column:expires_at;default:null"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" gorm:"column:last_used_at;default:null"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" gorm:"column:revoked_at;default:null"`
+}
+
+func (k ApiKey) ScopeList() []string {
+	if k.Scopes == "" {
+		return []string{}
+	}
+	return strings.Split(k.Scopes, ",")
+}
+
+type ApiKeyCreateRequest struct {
+	Nombre    string     `json:"nombre" binding:"required"`
+	UserID    int        `json:"user_id" binding:"required"`
+	Role      Role       `json:"role" binding:"required"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type ApiKeyResponse struct {
+	ID         int        `json:"id"`
+	Nombre     string     `json:"nombre"`
+	Prefix     string     `json:"prefix"`
+	UserID     int        `json:"user_id"`
+	Role       Role       `json:"role"`
+	Scopes     []string   `json:"scopes"`
+	CreatedBy  int        `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// ApiKeyCreatedResponse incluye la key en texto plano: solo se muestra al crearla
+type ApiKeyCreatedResponse struct {
+	ApiKeyResponse
+	Key string `json:"key"`
+}
+
+// APIKeyIdentity es lo que AuthMiddleware obtiene al validar una API key
+type APIKeyIdentity struct {
+	KeyID     int
+	UsuarioID int
+	UserID    int
+	Role      Role
+	Email     string
+	Nombre    string
+	Apellido  string
+	Legajo    string
+	Scopes    []string
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Recursos a los que puede pertenecer un archivo (RecursoType de ArchivoMetadata)
+const (
+	RecursoAnexo      = "anexo"
+	RecursoEntrega    = "entrega"
+	RecursoEntregaTP  = "entrega_tp"
+	RecursoEvaluacion = "evaluacion"
+)
+
+// ArchivoMetadata describe un archivo subido. El contenido vive en el Storage
+// bajo su SHA-256, así que varias filas pueden compartir el mismo blob.
+type ArchivoMetadata struct {
+	ID     int    `json:"id" gorm:"primaryKey;autoIncrement"`
+	SHA256 string `json:"sha256" gorm:"column:sha256;type:varchar(64);not null;index"`
+	Size   int64  `json:"size" gorm:"column:size;not null"`
+	// ContentType es el tipo detectado a partir del contenido; el que informó el cliente queda en ContentTypeDeclarado
+	ContentType          string `json:"contentType" gorm:"column:content_type;type:varchar(255)"`
+	ContentTypeDeclarado string `json:"contentTypeDeclarado" gorm:"column:content_type_declarado;type:varchar(255)"`
+	OriginalName         string `json:"originalName" gorm:"column:original_name;type:varchar(255)"`
+	// Recurso dueño del archivo; RecursoID es 0 mientras no está vinculado (ej: entrega de TP todavía no creada)
+	RecursoType   string    `json:"recurso" gorm:"column:recurso_type;type:varchar(30);not null;index:idx_archivo_recurso"`
+	RecursoID     int       `json:"recurso_id" gorm:"column:recurso_id;not null;default:0;index:idx_archivo_recurso"`
+	SubidoPor     int       `json:"subido_por" gorm:"column:subido_por;type:int"`
+	SubidoPorRole Role      `json:"subido_por_role" gorm:"column:subido_por_role;type:varchar(20)"`
+	CreatedAt     time.Time `json:"createdAt" gorm:"column:created_at"`
+	URL           string    `json:"url" gorm:"-"`
+}
+
+func (ArchivoMetadata) TableName() string {
+	return "archivos_metadata"
+}
+
+func (a *ArchivoMetadata) AfterFind(tx *gorm.DB) error {
+	a.URL = ArchivoURL(a.ID)
+	return nil
+}
+
+func (a *ArchivoMetadata) AfterCreate(tx *gorm.DB) error {
+	a.URL = ArchivoURL(a.ID)
+	return nil
+}
+
+// ArchivoURL es la ruta de descarga de un archivo, la que se guarda en archivo_url
+func ArchivoURL(id int) string {
+	return "/archivos/" + strconv.Itoa(id)
+}
+
+// ArchivoIDFromURL obtiene el ID de una URL "/archivos/:id"
+func ArchivoIDFromURL(url string) (int, bool) {
+	const prefix = "/archivos/"
+	if len(url) <= len(prefix) || url[:len(prefix)] != prefix {
+		return 0, false
+	}
+	id, err := strconv.Atoi(url[len(prefix):])
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
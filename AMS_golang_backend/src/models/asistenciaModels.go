@@ -0,0 +1,130 @@
+package models
+
+import "time"
+
+// EstadoAsistencia es la situación de un alumno en una clase
+type EstadoAsistencia string
+
+const (
+	AsistenciaPresente    EstadoAsistencia = "presente"
+	AsistenciaAusente     EstadoAsistencia = "ausente"
+	AsistenciaTarde       EstadoAsistencia = "tarde"
+	AsistenciaJustificado EstadoAsistencia = "justificado"
+)
+
+func (e EstadoAsistencia) IsValid() bool {
+	switch e {
+	case AsistenciaPresente, AsistenciaAusente, AsistenciaTarde, AsistenciaJustificado:
+		return true
+	}
+	return false
+}
+
+// Clase es un encuentro de la comisión. Se generan a partir de los horarios de
+// la comisión (o se cargan a mano) y en cada una se toma asistencia.
+type Clase struct {
+	ID         int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	ComisionId int       `json:"comision_id" gorm:"column:comision_id;type:int;not null;uniqueIndex:idx_clase_comision_inicio"`
+	Inicio     time.Time `json:"inicio" gorm:"column:inicio;type:timestamp;not null;uniqueIndex:idx_clase_comision_inicio"`
+	Fin        time.Time `json:"fin" gorm:"column:fin;type:timestamp;not null"`
+	Tema       string    `json:"tema" gorm:"column:tema;type:text"`
+	// Solo las clases con asistencia tomada cuentan para el porcentaje
+	AsistenciaTomada bool      `json:"asistencia_tomada" gorm:"column:asistencia_tomada;not null;default:false"`
+	CreatedAt        time.Time `json:"created_at" gorm:"column:created_at"`
+}
+
+// Asistencia es el registro de un alumno en una clase
+type Asistencia struct {
+	ID            int              `json:"id" gorm:"primaryKey;autoIncrement"`
+	ClaseId       int              `json:"clase_id" gorm:"column:clase_id;type:int;not null;uniqueIndex:idx_asistencia_clase_alumno"`
+	AlumnoId      int              `json:"alumno_id" gorm:"column:alumno_id;type:int;not null;uniqueIndex:idx_asistencia_clase_alumno"`
+	Estado        EstadoAsistencia `json:"estado" gorm:"column:estado;type:varchar(20);not null"`
+	Observaciones *string          `json:"observaciones" gorm:"column:observaciones;type:text"`
+	ActorID       int              `json:"actor_id" gorm:"column:actor_id;type:int;not null"`
+	ActorRole     Role             `json:"actor_role" gorm:"column:actor_role;type:varchar(20);not null"`
+	UpdatedAt     time.Time        `json:"updated_at" gorm:"column:updated_at"`
+}
+
+func (Asistencia) TableName() string {
+	return "asistencias"
+}
+
+// GenerarClasesRequest crea las clases de la comisión entre dos fechas (AAAA-MM-DD, inclusive)
+type GenerarClasesRequest struct {
+	Desde string `json:"desde" binding:"required"`
+	Hasta string `json:"hasta" binding:"required"`
+}
+
+type ClaseRequest struct {
+	Inicio time.Time `json:"inicio" binding:"required"`
+	Fin    time.Time `json:"fin" binding:"required"`
+	Tema   string    `json:"tema"`
+}
+
+type ClaseUpdateRequest struct {
+	Inicio *time.Time `json:"inicio,omitempty"`
+	Fin    *time.Time `json:"fin,omitempty"`
+	Tema   *string    `json:"tema,omitempty"`
+}
+
+type RegistroAsistenciaRequest struct {
+	AlumnoId      int              `json:"alumno_id" binding:"required"`
+	Estado        EstadoAsistencia `json:"estado" binding:"required"`
+	Observaciones *string          `json:"observaciones"`
+}
+
+// TomarAsistenciaRequest registra la asistencia de una clase; los alumnos que no
+// figuran conservan su registro anterior (o quedan ausentes si no tenían)
+type TomarAsistenciaRequest struct {
+	Asistencias []RegistroAsistenciaRequest `json:"asistencias" binding:"required,min=1,dive"`
+}
+
+// AsistenciaAlumno es la fila de un alumno en la planilla de una clase. Estado
+// queda vacío si todavía no se tomó asistencia.
+type AsistenciaAlumno struct {
+	CursadaId     int              `json:"cursada_id"`
+	AlumnoId      int              `json:"alumno_id"`
+	Legajo        string           `json:"legajo"`
+	Apellido      string           `json:"apellido"`
+	Nombre        string           `json:"nombre"`
+	Estado        EstadoAsistencia `json:"estado"`
+	Observaciones *string          `json:"observaciones"`
+}
+
+// PlanillaAsistencia es una clase con la asistencia de todos sus alumnos
+type PlanillaAsistencia struct {
+	Clase   Clase              `json:"clase"`
+	Alumnos []AsistenciaAlumno `json:"alumnos"`
+}
+
+// ResumenAsistencia es la asistencia de una cursada. En las clases con
+// asistencia tomada sin registro del alumno cuenta como ausente; la llegada
+// tarde cuenta como presente y las justificadas no entran en el porcentaje.
+type ResumenAsistencia struct {
+	CursadaId         int `json:"cursada_id"`
+	ClasesProgramadas int `json:"clases_programadas"`
+	ClasesDictadas    int `json:"clases_dictadas"`
+	Presentes         int `json:"presentes"`
+	Tardes            int `json:"tardes"`
+	Ausentes          int `json:"ausentes"`
+	Justificadas      int `json:"justificadas"`
+	// nil mientras no haya clases con asistencia tomada
+	Porcentaje *float64 `json:"porcentaje"`
+	// Asistencia mínima para regularizar (reglas de condición de la materia) y
+	// faltas que admite sobre el total de clases programadas; nil si no se exige
+	AsistenciaMinima float64 `json:"asistencia_minima"`
+	FaltasPermitidas *int    `json:"faltas_permitidas"`
+	FaltasRestantes  *int    `json:"faltas_restantes"`
+}
+
+// RegistroAsistenciaCursada es una clase tal como la ve el alumno
+type RegistroAsistenciaCursada struct {
+	Clase         Clase            `json:"clase"`
+	Estado        EstadoAsistencia `json:"estado"`
+	Observaciones *string          `json:"observaciones"`
+}
+
+type AsistenciaCursada struct {
+	Resumen ResumenAsistencia           `json:"resumen"`
+	Clases  []RegistroAsistenciaCursada `json:"clases"`
+}
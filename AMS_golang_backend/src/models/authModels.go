@@ -8,12 +8,15 @@ const (
     RoleAlumno    Role = "alumno"
 )
 
+// BaseUser son los datos comunes de los perfiles. Las credenciales viven en
+// Usuario; Password solo se usa para recibir la contraseña al crear el perfil.
 type BaseUser struct {
-    ID       int    `json:"id" gorm:"primaryKey;autoIncrement"`
-    Nombre   string `json:"nombre" gorm:"column:nombre;type:varchar(60);not null"`
-    Apellido string `json:"apellido" gorm:"column:apellido;type:varchar(60);not null"`
-    Email    string `json:"email" gorm:"column:email;type:varchar(60);unique;not null"`
-    Password string `json:"password" gorm:"column:password;type:varchar(70);not null"`
+    ID        int    `json:"id" gorm:"primaryKey;autoIncrement"`
+    UsuarioID *int   `json:"usuario_id,omitempty" gorm:"column:usuario_id;index"`
+    Nombre    string `json:"nombre" gorm:"column:nombre;type:varchar(60);not null"`
+    Apellido  string `json:"apellido" gorm:"column:apellido;type:varchar(60);not null"`
+    Email     string `json:"email" gorm:"column:email;type:varchar(60);unique;not null"`
+    Password  string `json:"password" gorm:"column:password;type:varchar(70);not null"`
 }
 
 type UserRoleProvider interface {
@@ -23,10 +26,13 @@ type UserRoleProvider interface {
 type LoginRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required"`
+	// Rol con el que se quiere ingresar; requerido solo si el usuario tiene varios
+	Role     Role   `json:"role,omitempty"`
 }
 
 type WhoAmIResponse struct {
-	ID       interface{} `json:"id"`
+	ID        interface{} `json:"id"`
+	UsuarioID interface{} `json:"usuario_id,omitempty"`
 	Email    string      `json:"email"`
 	Role     string      `json:"role"`
 	Nombre   *string     `json:"nombre,omitempty"`
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// CalendarioToken habilita el feed .ics de un alumno o profesor. El token va en
+// la URL de suscripción (las aplicaciones de calendario no mandan headers), por
+// eso cada perfil tiene uno solo y se guarda hasheado.
+type CalendarioToken struct {
+	ID         int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID     int        `json:"user_id" gorm:"column:user_id;not null;uniqueIndex:idx_calendario_tokens_user"`
+	Role       Role       `json:"role" gorm:"column:role;type:varchar(20);not null;uniqueIndex:idx_calendario_tokens_user"`
+	TokenHash  string     `json:"-" gorm:"column:token_hash;type:varchar(64);uniqueIndex;not null"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"column:created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" gorm:"column:last_used_at;default:null"`
+}
+
+// SuscripcionCalendario describe el feed del usuario; URL y Token solo se
+// devuelven al generarlo
+type SuscripcionCalendario struct {
+	Activa     bool       `json:"activa"`
+	URL        string     `json:"url,omitempty"`
+	Token      string     `json:"token,omitempty"`
+	CreatedAt  *time.Time `json:"created_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
@@ -1,15 +1,22 @@
 package models
 
 type Comision struct {
-	ID        int     `json:"id" gorm:"primaryKey;autoIncrement"`
-	Nombre    string  `json:"nombre" gorm:"column:nombre;type:varchar(100);not null"`
+	ID     int    `json:"id" gorm:"primaryKey;autoIncrement"`
+	Nombre string `json:"nombre" gorm:"column:nombre;type:varchar(100);not null"`
+	// Resumen en texto de Franjas (ver HorarioService)
 	Horarios  string  `json:"horarios" gorm:"column:horarios;type:varchar(255);not null"`
 	MateriaId int     `json:"materia_id" gorm:"column:materia_id;type:int;not null"`
 	Materia   Materia `json:"materia" gorm:"foreignKey:MateriaId;references:ID"`
+	// Período académico de la comisión; las previas a los períodos no tienen
+	PeriodoId *int              `json:"periodo_id" gorm:"column:periodo_id;type:int;index"`
+	Periodo   *PeriodoAcademico `json:"periodo,omitempty" gorm:"foreignKey:PeriodoId;references:ID"`
+	// Franjas semanales de clase; solo se incluyen en el detalle de la comisión
+	Franjas []HorarioComision `json:"franjas,omitempty" gorm:"foreignKey:ComisionId;references:ID"`
 }
 
 type ComisionUpdateRequest struct {
 	Nombre    *string `json:"nombre" gorm:"column:nombre;type:varchar(100)"`
 	Horarios  *string `json:"horarios" gorm:"column:horarios;type:varchar(255)"`
 	MateriaId *int    `json:"materia_id" gorm:"column:materia_id;type:int"`
+	PeriodoId *int    `json:"periodo_id" gorm:"column:periodo_id;type:int"`
 }
@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+// ReglasCondicion definen, para todas las comisiones de una materia, qué hace falta
+// para regularizar o promocionar la cursada. Si una materia las tiene, la condición
+// de sus cursadas sale de estas reglas y no de los umbrales del esquema de calificación.
+type ReglasCondicion struct {
+	ID        int `json:"id" gorm:"primaryKey;autoIncrement"`
+	MateriaId int `json:"materia_id" gorm:"column:materia_id;type:int;not null;uniqueIndex"`
+	// Parciales: nota para aprobarlos (regularidad) y para promocionarlos
+	NotaAprobacionParcial float64 `json:"nota_aprobacion_parcial" gorm:"column:nota_aprobacion_parcial;type:float;not null;default:4"`
+	NotaPromocionParcial  float64 `json:"nota_promocion_parcial" gorm:"column:nota_promocion_parcial;type:float;not null;default:7"`
+	// Promedio mínimo de los parciales para promocionar (0 = no se exige)
+	PromedioPromocion float64 `json:"promedio_promocion" gorm:"column:promedio_promocion;type:float;not null;default:7"`
+	// Cantidad de parciales que se pueden aprobar con recuperatorio sin quedar libre,
+	// y si un parcial recuperado todavía permite promocionar
+	RecuperatoriosMaximos         int  `json:"recuperatorios_maximos" gorm:"column:recuperatorios_maximos;type:int;not null;default:1"`
+	RecuperatorioPermitePromocion bool `json:"recuperatorio_permite_promocion" gorm:"column:recuperatorio_permite_promocion;not null;default:false"`
+	// TPs: nota de aprobación y porcentaje de TPs aprobados para regularizar
+	NotaAprobacionTp       float64 `json:"nota_aprobacion_tp" gorm:"column:nota_aprobacion_tp;type:float;not null;default:4"`
+	PorcentajeTpsAprobados float64 `json:"porcentaje_tps_aprobados" gorm:"column:porcentaje_tps_aprobados;type:float;not null;default:75"`
+	// Asistencia mínima (en %) para regularizar y para promocionar (0 = no se exige)
+	AsistenciaRegular   float64   `json:"asistencia_regular" gorm:"column:asistencia_regular;type:float;not null;default:75"`
+	AsistenciaPromocion float64   `json:"asistencia_promocion" gorm:"column:asistencia_promocion;type:float;not null;default:80"`
+	UpdatedAt           time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+func (ReglasCondicion) TableName() string {
+	return "reglas_condicion"
+}
+
+// ReglasCondicionRequest reemplaza las reglas completas; los campos omitidos toman su valor por defecto
+type ReglasCondicionRequest struct {
+	NotaAprobacionParcial         *float64 `json:"nota_aprobacion_parcial"`
+	NotaPromocionParcial          *float64 `json:"nota_promocion_parcial"`
+	PromedioPromocion             *float64 `json:"promedio_promocion"`
+	RecuperatoriosMaximos         *int     `json:"recuperatorios_maximos"`
+	RecuperatorioPermitePromocion bool     `json:"recuperatorio_permite_promocion"`
+	NotaAprobacionTp              *float64 `json:"nota_aprobacion_tp"`
+	PorcentajeTpsAprobados        *float64 `json:"porcentaje_tps_aprobados"`
+	AsistenciaRegular             *float64 `json:"asistencia_regular"`
+	AsistenciaPromocion           *float64 `json:"asistencia_promocion"`
+}
+
+// ParcialCondicion es una evaluación tal como la miran las reglas de condición
+type ParcialCondicion struct {
+	EvaluacionId int       `json:"evaluacion_id"`
+	Fecha        time.Time `json:"fecha"`
+	Nota         *float64  `json:"nota"`
+	// Nota del recuperatorio del parcial, si lo rindió
+	Recuperatorio *float64 `json:"recuperatorio"`
+	Pendiente     bool     `json:"pendiente"`
+}
+
+// DatosCondicion reúne lo que evalúan las reglas para una cursada
+type DatosCondicion struct {
+	Parciales     []ParcialCondicion
+	TpsTotal      int
+	TpsAprobados  int
+	TpsPendientes int
+	// Porcentaje de asistencia; nil si todavía no se tomó asistencia en ninguna clase
+	Asistencia *float64
+}
+
+// ResultadoCondicion es la condición según las reglas y, si no es promoción,
+// los requisitos que no se cumplieron
+type ResultadoCondicion struct {
+	Condicion  CondicionCursada `json:"condicion"`
+	Provisoria bool             `json:"provisoria"`
+	Motivos    []string         `json:"motivos"`
+}
@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+// RequisitoCorrelativa es lo que hace falta haber logrado en la materia correlativa
+type RequisitoCorrelativa string
+
+const (
+	// Regularizada o promocionada
+	CorrelativaRegular RequisitoCorrelativa = "regular"
+	// Promocionada (no se registran exámenes finales)
+	CorrelativaAprobada RequisitoCorrelativa = "aprobada"
+)
+
+func (r RequisitoCorrelativa) IsValid() bool {
+	return r == CorrelativaRegular || r == CorrelativaAprobada
+}
+
+// Correlatividad indica que para inscribirse en MateriaId el alumno necesita
+// tener RequiereMateriaId en la condición del Requisito
+type Correlatividad struct {
+	ID                int                  `json:"id" gorm:"primaryKey;autoIncrement"`
+	MateriaId         int                  `json:"materia_id" gorm:"column:materia_id;type:int;not null;uniqueIndex:idx_correlatividad"`
+	RequiereMateriaId int                  `json:"requiere_materia_id" gorm:"column:requiere_materia_id;type:int;not null;uniqueIndex:idx_correlatividad"`
+	RequiereMateria   Materia              `json:"requiere_materia" gorm:"foreignKey:RequiereMateriaId;references:ID"`
+	Requisito         RequisitoCorrelativa `json:"requisito" gorm:"column:requisito;type:varchar(10);not null"`
+}
+
+func (Correlatividad) TableName() string {
+	return "correlatividades"
+}
+
+type CorrelativaRequest struct {
+	MateriaId int                  `json:"materia_id" binding:"required"`
+	Requisito RequisitoCorrelativa `json:"requisito" binding:"required"`
+}
+
+// CorrelatividadesRequest reemplaza todas las correlativas de la materia
+type CorrelatividadesRequest struct {
+	Correlativas []CorrelativaRequest `json:"correlativas" binding:"dive"`
+}
+
+// CorrelativaFaltante es una correlativa que el alumno no cumple; Condicion es
+// la mejor que alcanzó en esa materia (vacía si nunca la cursó)
+type CorrelativaFaltante struct {
+	MateriaId int                  `json:"materia_id"`
+	Materia   string               `json:"materia"`
+	Requisito RequisitoCorrelativa `json:"requisito"`
+	Condicion CondicionCursada     `json:"condicion,omitempty"`
+}
+
+type EstadoCorrelatividades struct {
+	AlumnoId  int                   `json:"alumno_id"`
+	MateriaId int                   `json:"materia_id"`
+	Cumple    bool                  `json:"cumple"`
+	Faltantes []CorrelativaFaltante `json:"faltantes"`
+}
+
+// ExcepcionCorrelatividad registra una inscripción que un admin hizo sin que el
+// alumno cumpliera las correlatividades
+type ExcepcionCorrelatividad struct {
+	ID        int `json:"id" gorm:"primaryKey;autoIncrement"`
+	CursadaId int `json:"cursada_id" gorm:"column:cursada_id;type:int;not null;uniqueIndex"`
+	AlumnoId  int `json:"alumno_id" gorm:"column:alumno_id;type:int;not null;index"`
+	MateriaId int `json:"materia_id" gorm:"column:materia_id;type:int;not null;index"`
+	// Explicación de las correlativas que no se cumplían al inscribirlo
+	Faltantes string    `json:"faltantes" gorm:"column:faltantes;type:text;not null"`
+	Motivo    string    `json:"motivo" gorm:"column:motivo;type:text;not null"`
+	ActorID   int       `json:"actor_id" gorm:"column:actor_id;type:int"`
+	ActorRole Role      `json:"actor_role" gorm:"column:actor_role;type:varchar(20)"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+}
+
+func (ExcepcionCorrelatividad) TableName() string {
+	return "excepciones_correlatividad"
+}
@@ -10,8 +10,26 @@ type Cursada struct {
 	Alumno         Alumno   `json:"alumno" gorm:"foreignKey:AlumnoID;references:ID"`
 	ComisionID     int      `json:"comision_id" gorm:"column:comision_id;type:int;not null"`
 	Comision       Comision `json:"comision" gorm:"foreignKey:ComisionID;references:ID"`
+	// Resultado del esquema de calificación (ver NotaFinalService). Si un profesor
+	// ajustó la nota, NotaFinal y Condicion dejan de seguir al cálculo.
+	Condicion           CondicionCursada `json:"condicion" gorm:"column:condicion;type:varchar(20);default:null"`
+	NotaFinalCalculada  *float64         `json:"nota_final_calculada" gorm:"column:nota_final_calculada;type:decimal(4,2);default:null"`
+	CondicionCalculada  CondicionCursada `json:"condicion_calculada" gorm:"column:condicion_calculada;type:varchar(20);default:null"`
+	NotaFinalProvisoria bool             `json:"nota_final_provisoria" gorm:"column:nota_final_provisoria;not null;default:false"`
+	NotaFinalAjustada   bool             `json:"nota_final_ajustada" gorm:"column:nota_final_ajustada;not null;default:false"`
+	// NotaConceptual no admite null y 0 es una nota válida: indica si se cargó
+	NotaConceptualCargada bool `json:"-" gorm:"column:nota_conceptual_cargada;not null;default:false"`
 }
 
+// CursadaCreateRequest es una cursada nueva. Con MotivoExcepcion un admin puede
+// inscribir al alumno aunque no cumpla las correlatividades de la materia.
+type CursadaCreateRequest struct {
+	Cursada
+	MotivoExcepcion string `json:"motivo_excepcion"`
+}
+
+// CursadaUpdateRequest modifica una cursada. NotaFinal se rechaza: la nota final
+// se cambia con un ajuste (POST /cursadas/:id/nota-final/ajuste).
 type CursadaUpdateRequest struct {
 	AnoLectivo     *int     `json:"ano_lectivo,omitempty"`
 	NotaFinal      *float64 `json:"nota_final,omitempty"`
@@ -19,16 +37,23 @@ type CursadaUpdateRequest struct {
 	Feedback       *string  `json:"feedback,omitempty"`
 	AlumnoID       *int     `json:"alumno_id,omitempty"`
 	ComisionID     *int     `json:"comision_id,omitempty"`
+	// Al cambiar alumno o comisión, un admin puede saltear las correlatividades indicando el motivo
+	MotivoExcepcion string `json:"motivo_excepcion,omitempty"`
 }
 
 type CursadaResponse struct {
-	ID             int            `json:"id"`
-	AnoLectivo     int            `json:"ano_lectivo"`
-	NotaFinal      float64        `json:"nota_final"`
-	NotaConceptual float64        `json:"nota_conceptual"`
-	Feedback       string         `json:"feedback"`
-	AlumnoID       int            `json:"alumno_id"`
-	Alumno         AlumnoResponse `json:"alumno"`
-	ComisionID     int            `json:"comision_id"`
-	Comision       Comision       `json:"comision"`
+	ID                  int              `json:"id"`
+	AnoLectivo          int              `json:"ano_lectivo"`
+	NotaFinal           float64          `json:"nota_final"`
+	NotaConceptual      float64          `json:"nota_conceptual"`
+	Feedback            string           `json:"feedback"`
+	AlumnoID            int              `json:"alumno_id"`
+	Alumno              AlumnoResponse   `json:"alumno"`
+	ComisionID          int              `json:"comision_id"`
+	Comision            Comision         `json:"comision"`
+	Condicion           CondicionCursada `json:"condicion"`
+	NotaFinalProvisoria bool             `json:"nota_final_provisoria"`
+	NotaFinalAjustada   bool             `json:"nota_final_ajustada"`
+	// Asistencia sobre las clases con asistencia tomada (ver AsistenciaService)
+	PorcentajeAsistencia *float64 `json:"porcentaje_asistencia"`
 }
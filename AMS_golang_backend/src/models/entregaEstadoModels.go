@@ -0,0 +1,88 @@
+package models
+
+import "time"
+
+// EstadoEntrega es el estado del ciclo de vida de una EntregaTP
+type EstadoEntrega string
+
+const (
+	EstadoPendiente       EstadoEntrega = "pendiente"
+	EstadoEnCorreccion    EstadoEntrega = "en_correccion"
+	EstadoRequiereRehacer EstadoEntrega = "requiere_rehacer"
+	EstadoAprobado        EstadoEntrega = "aprobado"
+	EstadoDesaprobado     EstadoEntrega = "desaprobado"
+	EstadoCalificado      EstadoEntrega = "calificado"
+)
+
+func (e EstadoEntrega) IsValid() bool {
+	switch e {
+	case EstadoPendiente, EstadoEnCorreccion, EstadoRequiereRehacer, EstadoAprobado, EstadoDesaprobado, EstadoCalificado:
+		return true
+	}
+	return false
+}
+
+// transicionesDocente son los cambios de estado que puede hacer un profesor o admin
+var transicionesDocente = map[EstadoEntrega][]EstadoEntrega{
+	EstadoPendiente:       {EstadoEnCorreccion, EstadoRequiereRehacer, EstadoAprobado, EstadoDesaprobado, EstadoCalificado},
+	EstadoEnCorreccion:    {EstadoPendiente, EstadoRequiereRehacer, EstadoAprobado, EstadoDesaprobado, EstadoCalificado},
+	EstadoRequiereRehacer: {EstadoEnCorreccion},
+	EstadoCalificado:      {EstadoEnCorreccion, EstadoRequiereRehacer, EstadoAprobado, EstadoDesaprobado},
+	EstadoAprobado:        {EstadoEnCorreccion},
+	EstadoDesaprobado:     {EstadoEnCorreccion},
+}
+
+// transicionesAlumno: el alumno solo cambia el estado al reentregar
+var transicionesAlumno = map[EstadoEntrega][]EstadoEntrega{
+	EstadoPendiente:       {EstadoPendiente},
+	EstadoRequiereRehacer: {EstadoPendiente},
+}
+
+// PuedeTransicionar indica si el rol puede llevar una entrega de un estado a otro
+func PuedeTransicionar(role Role, desde, hasta EstadoEntrega) bool {
+	var permitidas []EstadoEntrega
+	switch role {
+	case RoleAdmin, RoleProfesor:
+		permitidas = transicionesDocente[desde]
+	case RoleAlumno:
+		permitidas = transicionesAlumno[desde]
+	}
+	for _, estado := range permitidas {
+		if estado == hasta {
+			return true
+		}
+	}
+	return false
+}
+
+// TransicionesPermitidas lista los estados a los que el rol puede pasar la entrega
+func TransicionesPermitidas(role Role, desde EstadoEntrega) []EstadoEntrega {
+	switch role {
+	case RoleAdmin, RoleProfesor:
+		return transicionesDocente[desde]
+	case RoleAlumno:
+		return transicionesAlumno[desde]
+	}
+	return nil
+}
+
+// TransicionEntregaTP registra cada cambio de estado de una entrega
+type TransicionEntregaTP struct {
+	ID         int           `json:"id" gorm:"primaryKey;autoIncrement"`
+	EntregaId  int           `json:"entrega_id" gorm:"column:entrega_id;type:int;not null;index"`
+	Desde      EstadoEntrega `json:"desde" gorm:"column:desde;type:varchar(50)"`
+	Hasta      EstadoEntrega `json:"hasta" gorm:"column:hasta;type:varchar(50);not null"`
+	ActorID    int           `json:"actor_id" gorm:"column:actor_id;type:int"`
+	ActorRole  Role          `json:"actor_role" gorm:"column:actor_role;type:varchar(20)"`
+	Comentario string        `json:"comentario,omitempty" gorm:"column:comentario;type:text"`
+	CreatedAt  time.Time     `json:"created_at" gorm:"column:created_at"`
+}
+
+func (TransicionEntregaTP) TableName() string {
+	return "transiciones_entrega_tp"
+}
+
+type CambioEstadoRequest struct {
+	Estado     EstadoEntrega `json:"estado" binding:"required"`
+	Comentario string        `json:"comentario"`
+}
@@ -0,0 +1,68 @@
+package models
+
+import "testing"
+
+func TestPuedeTransicionar(t *testing.T) {
+	tests := []struct {
+		role        Role
+		desde       EstadoEntrega
+		hasta       EstadoEntrega
+		want        bool
+		descripcion string
+	}{
+		{RoleProfesor, EstadoPendiente, EstadoCalificado, true, "calificar una entrega nueva"},
+		{RoleProfesor, EstadoEnCorreccion, EstadoRequiereRehacer, true, "pedir que se rehaga"},
+		{RoleProfesor, EstadoCalificado, EstadoAprobado, true, "aprobar una entrega calificada"},
+		{RoleProfesor, EstadoAprobado, EstadoEnCorreccion, true, "reabrir la corrección"},
+		{RoleProfesor, EstadoAprobado, EstadoDesaprobado, false, "cambiar el resultado sin reabrir"},
+		{RoleProfesor, EstadoRequiereRehacer, EstadoAprobado, false, "aprobar sin la reentrega"},
+		{RoleProfesor, EstadoCalificado, EstadoCalificado, false, "el mismo estado no es una transición"},
+		{RoleAdmin, EstadoPendiente, EstadoEnCorreccion, true, "el admin corrige como un profesor"},
+		{RoleAlumno, EstadoRequiereRehacer, EstadoPendiente, true, "reentregar"},
+		{RoleAlumno, EstadoPendiente, EstadoPendiente, true, "reentregar antes de la corrección"},
+		{RoleAlumno, EstadoPendiente, EstadoAprobado, false, "el alumno no se aprueba"},
+		{RoleAlumno, EstadoCalificado, EstadoPendiente, false, "reentregar una entrega calificada"},
+		{RoleProfesor, EstadoEntrega("entregado"), EstadoCalificado, false, "estado desconocido"},
+		{Role("invitado"), EstadoPendiente, EstadoCalificado, false, "rol desconocido"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.descripcion, func(t *testing.T) {
+			if got := PuedeTransicionar(tt.role, tt.desde, tt.hasta); got != tt.want {
+				t.Errorf("PuedeTransicionar(%s, %s, %s) = %v, want %v", tt.role, tt.desde, tt.hasta, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTablasDeTransiciones(t *testing.T) {
+	tablas := map[string]map[EstadoEntrega][]EstadoEntrega{
+		"docente": transicionesDocente,
+		"alumno":  transicionesAlumno,
+	}
+	for nombre, tabla := range tablas {
+		for desde, hastas := range tabla {
+			if !desde.IsValid() {
+				t.Errorf("%s: estado de origen inválido %q", nombre, desde)
+			}
+			for _, hasta := range hastas {
+				if !hasta.IsValid() {
+					t.Errorf("%s: %s -> estado inválido %q", nombre, desde, hasta)
+				}
+			}
+		}
+	}
+
+	// Un docente siempre puede salir de cualquier estado, y nunca al mismo
+	for _, desde := range []EstadoEntrega{EstadoPendiente, EstadoEnCorreccion, EstadoRequiereRehacer, EstadoAprobado, EstadoDesaprobado, EstadoCalificado} {
+		permitidas := TransicionesPermitidas(RoleProfesor, desde)
+		if len(permitidas) == 0 {
+			t.Errorf("no hay transiciones desde %s", desde)
+		}
+		for _, hasta := range permitidas {
+			if hasta == desde {
+				t.Errorf("transición de %s a sí mismo", desde)
+			}
+		}
+	}
+}
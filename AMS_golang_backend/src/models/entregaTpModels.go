@@ -3,18 +3,29 @@ package models
 import "time"
 
 type EntregaTP struct {
-	ID           int       `json:"id" gorm:"primaryKey;autoIncrement"`
-	TpId         int       `json:"tp_id" gorm:"column:tp_id;type:int;not null"`
-	AlumnoId     int       `json:"alumno_id" gorm:"column:alumno_id;type:int;not null"`
-	CursadaId    int       `json:"cursada_id" gorm:"column:cursada_id;type:int;not null"`
-	ArchivoURL   string    `json:"archivo_url" gorm:"column:archivo_url;type:text"`
-	FechaEntrega time.Time `json:"fecha_entrega" gorm:"column:fecha_entrega;type:timestamp;not null"`
-	Nota         *float64  `json:"nota" gorm:"column:nota;type:float;default:null"`
-	Devolucion   string    `json:"devolucion" gorm:"column:devolucion;type:text;default:null"`
-	Estado       string    `json:"estado" gorm:"column:estado;type:varchar(50);not null;default:'pendiente'"`
-	Tp           TpModel   `json:"tp" gorm:"foreignKey:TpId;references:ID"`
-	Alumno       Alumno    `json:"alumno" gorm:"foreignKey:AlumnoId;references:ID"`
-	Cursada      Cursada   `json:"cursada" gorm:"foreignKey:CursadaId;references:ID"`
+	ID           int           `json:"id" gorm:"primaryKey;autoIncrement"`
+	TpId         int           `json:"tp_id" gorm:"column:tp_id;type:int;not null"`
+	AlumnoId     int           `json:"alumno_id" gorm:"column:alumno_id;type:int;not null"`
+	CursadaId    int           `json:"cursada_id" gorm:"column:cursada_id;type:int;not null"`
+	ArchivoURL   string        `json:"archivo_url" gorm:"column:archivo_url;type:text"`
+	FechaEntrega time.Time     `json:"fecha_entrega" gorm:"column:fecha_entrega;type:timestamp;not null"`
+	Nota         *float64      `json:"nota" gorm:"column:nota;type:float;default:null"`
+	Devolucion   string        `json:"devolucion" gorm:"column:devolucion;type:text;default:null"`
+	Estado       EstadoEntrega `json:"estado" gorm:"column:estado;type:varchar(50);not null;default:'pendiente'"`
+	// Resultado del control de plazo al momento de entregar (ver PlazoService)
+	FechaLimite         *time.Time `json:"fecha_limite" gorm:"column:fecha_limite;type:timestamptz;default:null"`
+	Tardia              bool       `json:"tardia" gorm:"column:tardia;not null;default:false"`
+	DiasTardanza        int        `json:"dias_tardanza" gorm:"column:dias_tardanza;not null;default:0"`
+	Penalizacion        float64    `json:"penalizacion" gorm:"column:penalizacion;type:float;not null;default:0"`
+	NotaSinPenalizacion *float64   `json:"nota_sin_penalizacion,omitempty" gorm:"column:nota_sin_penalizacion;type:float;default:null"`
+	// Número del intento vigente; los campos de archivo, fecha, plazo y nota reflejan ese intento
+	IntentoActual int                `json:"intento_actual" gorm:"column:intento_actual;not null;default:1"`
+	Intentos      []IntentoEntregaTP `json:"intentos,omitempty" gorm:"foreignKey:EntregaId;constraint:OnDelete:CASCADE"`
+	// En un TP grupal cada integrante tiene su entrega, sincronizada con las del resto del grupo
+	GrupoId *int    `json:"grupo_id" gorm:"column:grupo_id;type:int;index;default:null"`
+	Tp      TpModel `json:"tp" gorm:"foreignKey:TpId;references:ID"`
+	Alumno  Alumno  `json:"alumno" gorm:"foreignKey:AlumnoId;references:ID"`
+	Cursada Cursada `json:"cursada" gorm:"foreignKey:CursadaId;references:ID"`
 }
 
 func (EntregaTP) TableName() string {
@@ -28,8 +39,16 @@ type EntregaTPCreateRequest struct {
 }
 
 type EntregaTPUpdateRequest struct {
-	ArchivoURL *string  `json:"archivo_url"`
-	Nota       *float64 `json:"nota"`
-	Devolucion *string  `json:"devolucion"`
-	Estado     *string  `json:"estado"`
+	ArchivoURL *string        `json:"archivo_url"`
+	Nota       *float64       `json:"nota"`
+	Devolucion *string        `json:"devolucion"`
+	Estado     *EstadoEntrega `json:"estado"`
+}
+
+// EntregasZipFiltro son los filtros de la descarga en ZIP de las entregas de un TP
+type EntregasZipFiltro struct {
+	// Solo entregas sin nota
+	SinCorregir bool
+	// Solo el intento vigente de cada entrega (por defecto se incluyen todos)
+	SoloUltimoIntento bool
 }
@@ -12,6 +12,35 @@ type EvaluacionModel struct {
 	Observaciones   string        `json:"observaciones" gorm:"column:observaciones;type:text;null"`
 	ComisionId      int           `json:"comision_id" gorm:"column:comision_id;type:int;not null"`
 	Comision        Comision `json:"comision" gorm:"foreignKey:ComisionId;references:ID"`
+	// Si es un recuperatorio, la evaluación que recupera. Solo se inscriben los
+	// alumnos que la desaprobaron o estuvieron ausentes.
+	RecuperatorioDeId     *int                  `json:"recuperatorio_de_id" gorm:"column:recuperatorio_de_id;type:int;null;uniqueIndex"`
+	CriterioRecuperatorio CriterioRecuperatorio `json:"criterio_recuperatorio,omitempty" gorm:"column:criterio_recuperatorio;type:varchar(10);null"`
+}
+
+// CriterioRecuperatorio indica qué nota reemplaza a la de la evaluación recuperada
+type CriterioRecuperatorio string
+
+const (
+	// La mejor entre la nota original y la del recuperatorio
+	RecuperatorioMejorNota CriterioRecuperatorio = "mejor"
+	// La del recuperatorio, aunque sea menor
+	RecuperatorioUltimaNota CriterioRecuperatorio = "ultima"
+)
+
+func (c CriterioRecuperatorio) IsValid() bool {
+	return c == RecuperatorioMejorNota || c == RecuperatorioUltimaNota
+}
+
+// InscripcionRecuperatorio resume la actualización de los inscriptos de un recuperatorio
+type InscripcionRecuperatorio struct {
+	EvaluacionId      int `json:"evaluacion_id"`
+	RecuperatorioDeId int `json:"recuperatorio_de_id"`
+	// Nota por debajo de la cual la evaluación original se considera desaprobada
+	NotaAprobacion float64 `json:"nota_aprobacion"`
+	Inscriptos     []int   `json:"inscriptos"`
+	Agregados      []int   `json:"agregados"`
+	Quitados       []int   `json:"quitados"`
 }
 
 type EvaluacionUpdateRequest struct {
@@ -22,6 +51,7 @@ type EvaluacionUpdateRequest struct {
 	Devolucion      *string  `json:"devolucion,omitempty"`
 	Observaciones   *string  `json:"observaciones,omitempty"`
 	ComisionId      *int     `json:"comision_id,omitempty"`
+	CriterioRecuperatorio *CriterioRecuperatorio `json:"criterio_recuperatorio,omitempty"`
 }
 
 type EntregaEvaluacion struct {
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// GrupoTP agrupa a los alumnos que entregan un TP en conjunto: una entrega de
+// cualquier integrante cuenta para todos.
+type GrupoTP struct {
+	ID            int                 `json:"id" gorm:"primaryKey;autoIncrement"`
+	TpId          int                 `json:"tp_id" gorm:"column:tp_id;type:int;not null;index"`
+	Nombre        string              `json:"nombre" gorm:"column:nombre;type:varchar(100)"`
+	CreadoPor     int                 `json:"creado_por" gorm:"column:creado_por;type:int"`
+	CreadoPorRole Role                `json:"creado_por_role" gorm:"column:creado_por_role;type:varchar(20)"`
+	CreatedAt     time.Time           `json:"created_at" gorm:"column:created_at"`
+	Tp            TpModel             `json:"-" gorm:"foreignKey:TpId;references:ID;constraint:OnDelete:CASCADE"`
+	Integrantes   []IntegranteGrupoTP `json:"integrantes" gorm:"foreignKey:GrupoId;constraint:OnDelete:CASCADE"`
+}
+
+func (GrupoTP) TableName() string {
+	return "grupos_tp"
+}
+
+// IntegranteGrupoTP: un alumno pertenece a lo sumo a un grupo por TP
+type IntegranteGrupoTP struct {
+	ID       int    `json:"id" gorm:"primaryKey;autoIncrement"`
+	GrupoId  int    `json:"grupo_id" gorm:"column:grupo_id;type:int;not null;index"`
+	TpId     int    `json:"tp_id" gorm:"column:tp_id;type:int;not null;uniqueIndex:idx_integrante_tp_alumno"`
+	AlumnoId int    `json:"alumno_id" gorm:"column:alumno_id;type:int;not null;uniqueIndex:idx_integrante_tp_alumno"`
+	Alumno   Alumno `json:"alumno" gorm:"foreignKey:AlumnoId;references:ID"`
+}
+
+func (IntegranteGrupoTP) TableName() string {
+	return "integrantes_grupo_tp"
+}
+
+type GrupoCreateRequest struct {
+	Nombre    string `json:"nombre"`
+	AlumnoIds []int  `json:"alumno_ids" binding:"required"`
+}
+
+type GrupoUpdateRequest struct {
+	Nombre    *string `json:"nombre,omitempty"`
+	AlumnoIds []int   `json:"alumno_ids,omitempty"`
+}
@@ -0,0 +1,79 @@
+package models
+
+import "time"
+
+type ModalidadClase string
+
+const (
+	ModalidadPresencial ModalidadClase = "presencial"
+	ModalidadVirtual    ModalidadClase = "virtual"
+	ModalidadHibrida    ModalidadClase = "hibrida"
+)
+
+func (m ModalidadClase) IsValid() bool {
+	return m == ModalidadPresencial || m == ModalidadVirtual || m == ModalidadHibrida
+}
+
+// HorarioComision es una franja semanal de clases de la comisión. Vale entre
+// VigenteDesde y VigenteHasta (por ejemplo, un cuatrimestre); sin fechas vale
+// siempre. Comision.Horarios se mantiene como resumen en texto de las franjas.
+type HorarioComision struct {
+	ID         int `json:"id" gorm:"primaryKey;autoIncrement"`
+	ComisionId int `json:"comision_id" gorm:"column:comision_id;type:int;not null;index"`
+	// 0 = domingo, 1 = lunes ... 6 = sábado
+	Dia          int            `json:"dia" gorm:"column:dia;type:int;not null"`
+	HoraInicio   string         `json:"hora_inicio" gorm:"column:hora_inicio;type:varchar(5);not null"`
+	HoraFin      string         `json:"hora_fin" gorm:"column:hora_fin;type:varchar(5);not null"`
+	Aula         string         `json:"aula" gorm:"column:aula;type:varchar(50)"`
+	Modalidad    ModalidadClase `json:"modalidad" gorm:"column:modalidad;type:varchar(20);not null;default:presencial"`
+	VigenteDesde *time.Time     `json:"vigente_desde" gorm:"column:vigente_desde;type:date"`
+	VigenteHasta *time.Time     `json:"vigente_hasta" gorm:"column:vigente_hasta;type:date"`
+}
+
+func (HorarioComision) TableName() string {
+	return "horarios_comision"
+}
+
+// VigenteEn indica si la franja vale en la fecha dada
+func (h HorarioComision) VigenteEn(fecha time.Time) bool {
+	dia := fecha.Format("2006-01-02")
+	if h.VigenteDesde != nil && dia < h.VigenteDesde.Format("2006-01-02") {
+		return false
+	}
+	if h.VigenteHasta != nil && dia > h.VigenteHasta.Format("2006-01-02") {
+		return false
+	}
+	return true
+}
+
+// HorarioComisionRequest crea o reemplaza una franja; horas "HH:MM" y fechas "AAAA-MM-DD"
+type HorarioComisionRequest struct {
+	Dia          *int           `json:"dia" binding:"required"`
+	HoraInicio   string         `json:"hora_inicio" binding:"required"`
+	HoraFin      string         `json:"hora_fin" binding:"required"`
+	Aula         string         `json:"aula"`
+	Modalidad    ModalidadClase `json:"modalidad"`
+	VigenteDesde *string        `json:"vigente_desde"`
+	VigenteHasta *string        `json:"vigente_hasta"`
+}
+
+// Tipos de choque de horario
+const (
+	ChoqueProfesor = "profesor"
+	ChoqueAlumno   = "alumno"
+	ChoqueComision = "comision"
+)
+
+// ChoqueHorario es una franja que se superpone con la que se quiere guardar:
+// de la misma comisión o de otra a la que asiste el mismo profesor o alumno
+type ChoqueHorario struct {
+	Tipo       string `json:"tipo"`
+	PersonaId  int    `json:"persona_id,omitempty"`
+	Persona    string `json:"persona,omitempty"`
+	ComisionId int    `json:"comision_id"`
+	Comision   string `json:"comision"`
+	HorarioId  int    `json:"horario_id"`
+	Dia        int    `json:"dia"`
+	HoraInicio string `json:"hora_inicio"`
+	HoraFin    string `json:"hora_fin"`
+}
@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// IntentoEntregaTP es una versión de una entrega. Cada reentrega crea un intento
+// nuevo con su propio archivo, fecha, control de plazo y nota; los anteriores se conservan.
+type IntentoEntregaTP struct {
+	ID                  int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	EntregaId           int        `json:"entrega_id" gorm:"column:entrega_id;type:int;not null;uniqueIndex:idx_intento_entrega_numero"`
+	Numero              int        `json:"numero" gorm:"column:numero;type:int;not null;uniqueIndex:idx_intento_entrega_numero"`
+	ArchivoURL          string     `json:"archivo_url" gorm:"column:archivo_url;type:text"`
+	FechaEntrega        time.Time  `json:"fecha_entrega" gorm:"column:fecha_entrega;type:timestamptz;not null"`
+	FechaLimite         *time.Time `json:"fecha_limite" gorm:"column:fecha_limite;type:timestamptz;default:null"`
+	Tardia              bool       `json:"tardia" gorm:"column:tardia;not null;default:false"`
+	DiasTardanza        int        `json:"dias_tardanza" gorm:"column:dias_tardanza;not null;default:0"`
+	Penalizacion        float64    `json:"penalizacion" gorm:"column:penalizacion;type:float;not null;default:0"`
+	Nota                *float64   `json:"nota" gorm:"column:nota;type:float;default:null"`
+	NotaSinPenalizacion *float64   `json:"nota_sin_penalizacion,omitempty" gorm:"column:nota_sin_penalizacion;type:float;default:null"`
+	Devolucion          string     `json:"devolucion" gorm:"column:devolucion;type:text;default:null"`
+	CreatedAt           time.Time  `json:"created_at" gorm:"column:created_at"`
+}
+
+func (IntentoEntregaTP) TableName() string {
+	return "intentos_entrega_tp"
+}
+
+// ArchivoInfo describe el archivo de un intento para la comparación
+type ArchivoInfo struct {
+	ArchivoURL string `json:"archivo_url"`
+	Nombre     string `json:"nombre"`
+	Tamano     int64  `json:"tamano"`
+	SHA256     string `json:"sha256,omitempty"`
+	Disponible bool   `json:"disponible"`
+}
+
+// IntentoDiff es la comparación entre dos intentos de una entrega
+type IntentoDiff struct {
+	EntregaId       int         `json:"entrega_id"`
+	Desde           int         `json:"desde"`
+	Hasta           int         `json:"hasta"`
+	ArchivoDesde    ArchivoInfo `json:"archivo_desde"`
+	ArchivoHasta    ArchivoInfo `json:"archivo_hasta"`
+	MismoArchivo    bool        `json:"mismo_archivo"`
+	Campos          []CampoDiff `json:"campos"`
+	DiffTexto       string      `json:"diff_texto,omitempty"`
+	DiffOmitido     string      `json:"diff_omitido,omitempty"`
+	LineasAgregadas int         `json:"lineas_agregadas"`
+	LineasQuitadas  int         `json:"lineas_quitadas"`
+}
+
+type CampoDiff struct {
+	Campo string      `json:"campo"`
+	Desde interface{} `json:"desde"`
+	Hasta interface{} `json:"hasta"`
+}
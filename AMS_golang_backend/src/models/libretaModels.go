@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Tipos de columna de la libreta de calificaciones
+const (
+	ColumnaLibretaTp         = "tp"
+	ColumnaLibretaEvaluacion = "evaluacion"
+)
+
+// Formatos de exportación de la libreta
+const (
+	FormatoLibretaJSON = "json"
+	FormatoLibretaCSV  = "csv"
+	FormatoLibretaXLSX = "xlsx"
+	FormatoLibretaPDF  = "pdf"
+)
+
+// ColumnaLibreta es un TP o una evaluación de la comisión. En un recuperatorio,
+// RecuperatorioDe es la evaluación que recupera.
+type ColumnaLibreta struct {
+	Tipo            string    `json:"tipo"`
+	ID              int       `json:"id"`
+	Titulo          string    `json:"titulo"`
+	Descripcion     string    `json:"descripcion"`
+	Fecha           time.Time `json:"fecha"`
+	RecuperatorioDe *int      `json:"recuperatorio_de,omitempty"`
+}
+
+// FilaLibreta es una cursada: Notas sigue el orden de Libreta.Columnas y
+// queda en null si el alumno no tiene nota en ese TP o evaluación
+type FilaLibreta struct {
+	CursadaId      int              `json:"cursada_id"`
+	AlumnoId       int              `json:"alumno_id"`
+	Legajo         string           `json:"legajo"`
+	Apellido       string           `json:"apellido"`
+	Nombre         string           `json:"nombre"`
+	AnoLectivo     int              `json:"ano_lectivo"`
+	Notas          []*float64       `json:"notas"`
+	NotaConceptual *float64         `json:"nota_conceptual"`
+	NotaFinal      *float64         `json:"nota_final"`
+	Condicion      CondicionCursada `json:"condicion"`
+}
+
+// Libreta es la vista consolidada de las notas de una comisión
+type Libreta struct {
+	Comision   Comision         `json:"comision"`
+	AnoLectivo *int             `json:"ano_lectivo"`
+	Columnas   []ColumnaLibreta `json:"columnas"`
+	Filas      []FilaLibreta    `json:"filas"`
+	Generada   time.Time        `json:"generada"`
+}
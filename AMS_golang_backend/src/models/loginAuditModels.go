@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// LoginAttempt es el registro de auditoría de cada intento de login
+type LoginAttempt struct {
+	ID        int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	Email     string    `json:"email" gorm:"column:email;type:varchar(60);not null;index"`
+	IP        string    `json:"ip" gorm:"column:ip;type:varchar(45);index"`
+	UserAgent string    `json:"user_agent" gorm:"column:user_agent;type:varchar(255)"`
+	Success   bool      `json:"success" gorm:"column:success;not null"`
+	Reason    string    `json:"reason,omitempty" gorm:"column:reason;type:varchar(100)"`
+	UsuarioID *int      `json:"usuario_id,omitempty" gorm:"column:usuario_id;index"`
+	Role      Role      `json:"role,omitempty" gorm:"column:role;type:varchar(20)"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;index"`
+}
+
+// LoginLock lleva la cuenta de fallos consecutivos por email o por IP
+// (Key = "email:<email>" o "ip:<ip>") y hasta cuándo está bloqueado el login.
+type LoginLock struct {
+	ID          int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	Key         string     `json:"key" gorm:"column:lock_key;type:varchar(120);uniqueIndex;not null"`
+	FailedCount int        `json:"failed_count" gorm:"column:failed_count;not null;default:0"`
+	LockedUntil *time.Time `json:"locked_until,omitempty" gorm:"column:locked_until;default:null"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"column:updated_at"`
+}
+
+type LoginAuditFilter struct {
+	Email   string
+	IP      string
+	Success *bool
+	Desde   *time.Time
+	Hasta   *time.Time
+	Limit   int
+}
+
+// UnlockLoginRequest desbloquea por email, por IP o ambos
+type UnlockLoginRequest struct {
+	Email string `json:"email"`
+	IP    string `json:"ip"`
+}
@@ -0,0 +1,166 @@
+package models
+
+import "time"
+
+// CondicionCursada es el resultado de la cursada según el esquema de calificación
+type CondicionCursada string
+
+const (
+	CondicionPromocionado CondicionCursada = "promocionado"
+	CondicionRegular      CondicionCursada = "regular"
+	CondicionLibre        CondicionCursada = "libre"
+)
+
+func (c CondicionCursada) IsValid() bool {
+	return c == CondicionPromocionado || c == CondicionRegular || c == CondicionLibre
+}
+
+// TratamientoFaltante define cómo cuenta un TP o evaluación sin nota cuyo plazo ya pasó
+type TratamientoFaltante string
+
+const (
+	// El trabajo faltante cuenta como 0 en el promedio
+	FaltanteCero TratamientoFaltante = "cero"
+	// El trabajo faltante no entra en el promedio
+	FaltanteExcluir TratamientoFaltante = "excluir"
+)
+
+func (t TratamientoFaltante) IsValid() bool {
+	return t == FaltanteCero || t == FaltanteExcluir
+}
+
+// Tipos de ítem calificable de una cursada
+const (
+	ItemTp         = "tp"
+	ItemEvaluacion = "evaluacion"
+)
+
+// EsquemaCalificacion define cómo se calcula la nota final de una cursada. Puede
+// definirse para una materia (vale para todas sus comisiones) o para una comisión
+// puntual, que tiene prioridad. La nota final es el promedio de los TPs, el de las
+// evaluaciones y la nota conceptual, ponderados por PesoTps, PesoEvaluaciones y
+// PesoConceptual; dentro de cada grupo los ítems pesan 1 salvo que Pesos diga otra cosa.
+type EsquemaCalificacion struct {
+	ID               int     `json:"id" gorm:"primaryKey;autoIncrement"`
+	ComisionId       *int    `json:"comision_id" gorm:"column:comision_id;type:int;uniqueIndex;default:null"`
+	MateriaId        *int    `json:"materia_id" gorm:"column:materia_id;type:int;uniqueIndex;default:null"`
+	PesoTps          float64 `json:"peso_tps" gorm:"column:peso_tps;type:float;not null;default:0"`
+	PesoEvaluaciones float64 `json:"peso_evaluaciones" gorm:"column:peso_evaluaciones;type:float;not null;default:0"`
+	PesoConceptual   float64 `json:"peso_conceptual" gorm:"column:peso_conceptual;type:float;not null;default:0"`
+	// Cantidad de TPs con la nota más baja que no cuentan para el promedio
+	TpsDescartados int `json:"tps_descartados" gorm:"column:tps_descartados;type:int;not null;default:0"`
+	// Nota mínima de cada evaluación; con una evaluación por debajo la condición es libre
+	NotaMinimaParcial float64             `json:"nota_minima_parcial" gorm:"column:nota_minima_parcial;type:float;not null;default:4"`
+	NotaRegular       float64             `json:"nota_regular" gorm:"column:nota_regular;type:float;not null;default:4"`
+	NotaPromocion     float64             `json:"nota_promocion" gorm:"column:nota_promocion;type:float;not null;default:7"`
+	Faltantes         TratamientoFaltante `json:"faltantes" gorm:"column:faltantes;type:varchar(20);not null;default:'cero'"`
+	Pesos             []PesoItemEsquema   `json:"pesos" gorm:"foreignKey:EsquemaId;constraint:OnDelete:CASCADE"`
+	UpdatedAt         time.Time           `json:"updated_at" gorm:"column:updated_at"`
+}
+
+func (EsquemaCalificacion) TableName() string {
+	return "esquemas_calificacion"
+}
+
+// PesoItemEsquema es el peso de un TP o una evaluación dentro de su grupo (0 = no cuenta)
+type PesoItemEsquema struct {
+	ID        int     `json:"-" gorm:"primaryKey;autoIncrement"`
+	EsquemaId int     `json:"-" gorm:"column:esquema_id;type:int;not null;index"`
+	Tipo      string  `json:"tipo" gorm:"column:tipo;type:varchar(20);not null"`
+	RecursoId int     `json:"id" gorm:"column:recurso_id;type:int;not null"`
+	Peso      float64 `json:"peso" gorm:"column:peso;type:float;not null"`
+}
+
+func (PesoItemEsquema) TableName() string {
+	return "pesos_esquema_calificacion"
+}
+
+// EsquemaCalificacionRequest reemplaza el esquema completo; los campos omitidos toman su valor por defecto
+type EsquemaCalificacionRequest struct {
+	PesoTps           float64             `json:"peso_tps"`
+	PesoEvaluaciones  float64             `json:"peso_evaluaciones"`
+	PesoConceptual    float64             `json:"peso_conceptual"`
+	TpsDescartados    int                 `json:"tps_descartados"`
+	NotaMinimaParcial *float64            `json:"nota_minima_parcial"`
+	NotaRegular       *float64            `json:"nota_regular"`
+	NotaPromocion     *float64            `json:"nota_promocion"`
+	Faltantes         TratamientoFaltante `json:"faltantes"`
+	Pesos             []PesoItemEsquema   `json:"pesos"`
+}
+
+// Estados de un ítem en el cálculo de la nota final
+const (
+	ItemCalificado = "calificado"
+	ItemFaltante   = "faltante"
+	ItemPendiente  = "pendiente"
+	ItemDescartado = "descartado"
+	ItemSinPeso    = "sin_peso"
+)
+
+// ItemNotaFinal es un TP o una evaluación tal como entró en el cálculo. Si el
+// alumno rindió el recuperatorio de la evaluación, Nota es la que reemplaza a
+// la original según el criterio del recuperatorio.
+type ItemNotaFinal struct {
+	Tipo              string    `json:"tipo"`
+	ID                int       `json:"id"`
+	Fecha             time.Time `json:"fecha"`
+	Nota              *float64  `json:"nota"`
+	NotaOriginal      *float64  `json:"nota_original,omitempty"`
+	NotaRecuperatorio *float64  `json:"nota_recuperatorio,omitempty"`
+	Peso              float64   `json:"peso"`
+	Estado            string    `json:"estado"`
+}
+
+// ResultadoNotaFinal es el cálculo de la nota final de una cursada. Si la materia
+// tiene reglas de condición, la condición sale de ellas. Es provisorio
+// mientras quede algún ítem pendiente (sin corregir o con el plazo abierto).
+type ResultadoNotaFinal struct {
+	CursadaId            int              `json:"cursada_id"`
+	AlumnoId             int              `json:"alumno_id"`
+	EsquemaId            int              `json:"esquema_id"`
+	PromedioTps          *float64         `json:"promedio_tps"`
+	PromedioEvaluaciones *float64         `json:"promedio_evaluaciones"`
+	NotaConceptual       *float64         `json:"nota_conceptual"`
+	NotaFinal            *float64         `json:"nota_final"`
+	Condicion            CondicionCursada `json:"condicion"`
+	// Requisitos de las reglas de condición de la materia que no se cumplieron
+	MotivosCondicion    []string        `json:"motivos_condicion,omitempty"`
+	Provisoria          bool            `json:"provisoria"`
+	ParcialesBajoMinimo int             `json:"parciales_bajo_minimo"`
+	Items               []ItemNotaFinal `json:"items"`
+	// Valores vigentes en la cursada (distintos del cálculo si un profesor los ajustó)
+	Ajustada         bool             `json:"ajustada"`
+	NotaFinalVigente float64          `json:"nota_final_vigente"`
+	CondicionVigente CondicionCursada `json:"condicion_vigente"`
+}
+
+// AjusteNotaFinal registra cada vez que un profesor reemplaza (o restablece) la nota calculada
+type AjusteNotaFinal struct {
+	ID                int              `json:"id" gorm:"primaryKey;autoIncrement"`
+	CursadaId         int              `json:"cursada_id" gorm:"column:cursada_id;type:int;not null;index"`
+	NotaAnterior      float64          `json:"nota_anterior" gorm:"column:nota_anterior;type:float"`
+	NotaNueva         float64          `json:"nota_nueva" gorm:"column:nota_nueva;type:float"`
+	CondicionAnterior CondicionCursada `json:"condicion_anterior" gorm:"column:condicion_anterior;type:varchar(20)"`
+	CondicionNueva    CondicionCursada `json:"condicion_nueva" gorm:"column:condicion_nueva;type:varchar(20)"`
+	NotaCalculada     *float64         `json:"nota_calculada" gorm:"column:nota_calculada;type:float;default:null"`
+	// Revertido indica que se quitó el ajuste y volvió a valer el cálculo
+	Revertido bool      `json:"revertido" gorm:"column:revertido;not null;default:false"`
+	Motivo    string    `json:"motivo" gorm:"column:motivo;type:text;not null"`
+	ActorID   int       `json:"actor_id" gorm:"column:actor_id;type:int"`
+	ActorRole Role      `json:"actor_role" gorm:"column:actor_role;type:varchar(20)"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+}
+
+func (AjusteNotaFinal) TableName() string {
+	return "ajustes_nota_final"
+}
+
+type AjusteNotaFinalRequest struct {
+	NotaFinal float64          `json:"nota_final" binding:"min=0,max=10"`
+	Condicion CondicionCursada `json:"condicion" binding:"required"`
+	Motivo    string           `json:"motivo" binding:"required"`
+}
+
+type QuitarAjusteRequest struct {
+	Motivo string `json:"motivo"`
+}
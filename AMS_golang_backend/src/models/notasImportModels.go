@@ -0,0 +1,30 @@
+package models
+
+// Resultado de cada fila de una importación de notas
+const (
+	FilaImportCambio     = "cambio"
+	FilaImportSinCambios = "sin_cambios"
+	FilaImportError      = "error"
+)
+
+// NotasImportResultado es el diff de una importación de notas desde planilla.
+// Si alguna fila tiene error no se aplica ninguna (Aplicado = false).
+type NotasImportResultado struct {
+	DryRun     bool              `json:"dry_run"`
+	Aplicado   bool              `json:"aplicado"`
+	Cambios    int               `json:"cambios"`
+	SinCambios int               `json:"sin_cambios"`
+	Errores    int               `json:"errores"`
+	Filas      []NotasImportFila `json:"filas"`
+}
+
+type NotasImportFila struct {
+	// Número de fila en la planilla (la fila 1 es el encabezado)
+	Fila     int         `json:"fila"`
+	Legajo   string      `json:"legajo"`
+	AlumnoId int         `json:"alumno_id,omitempty"`
+	Alumno   string      `json:"alumno,omitempty"`
+	Estado   string      `json:"estado"`
+	Error    string      `json:"error,omitempty"`
+	Campos   []CampoDiff `json:"campos,omitempty"`
+}
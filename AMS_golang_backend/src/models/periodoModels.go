@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// PeriodoAcademico es un cuatrimestre (o un ciclo lectivo completo) al que
+// pertenecen las comisiones. Cuatrimestre 0 indica una cursada anual.
+type PeriodoAcademico struct {
+	ID           int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	Nombre       string    `json:"nombre" gorm:"column:nombre;type:varchar(50);not null"`
+	AnoLectivo   int       `json:"ano_lectivo" gorm:"column:ano_lectivo;type:int;not null;uniqueIndex:idx_periodo_ano_cuatrimestre"`
+	Cuatrimestre int       `json:"cuatrimestre" gorm:"column:cuatrimestre;type:int;not null;default:0;uniqueIndex:idx_periodo_ano_cuatrimestre"`
+	FechaInicio  time.Time `json:"fecha_inicio" gorm:"column:fecha_inicio;type:date;not null"`
+	FechaFin     time.Time `json:"fecha_fin" gorm:"column:fecha_fin;type:date;not null"`
+}
+
+func (PeriodoAcademico) TableName() string {
+	return "periodos_academicos"
+}
+
+// PeriodoRequest crea o reemplaza un período; fechas "AAAA-MM-DD"
+type PeriodoRequest struct {
+	Nombre       string `json:"nombre"`
+	AnoLectivo   int    `json:"ano_lectivo" binding:"required"`
+	Cuatrimestre *int   `json:"cuatrimestre"`
+	FechaInicio  string `json:"fecha_inicio" binding:"required"`
+	FechaFin     string `json:"fecha_fin" binding:"required"`
+}
+
+// RolloverRequest copia la estructura de una comisión al período indicado (o
+// al siguiente del de la comisión)
+type RolloverRequest struct {
+	PeriodoId *int   `json:"periodo_id"`
+	Nombre    string `json:"nombre"`
+}
+
+// RolloverResultado resume lo copiado a la nueva comisión
+type RolloverResultado struct {
+	Comision     Comision `json:"comision"`
+	Tps          int      `json:"tps"`
+	Competencias int      `json:"competencias"`
+	Anexos       int      `json:"anexos"`
+	Archivos     int      `json:"archivos"`
+	Profesores   int      `json:"profesores"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// ProrrogaTP extiende la fecha límite de un TP para un alumno puntual
+type ProrrogaTP struct {
+	ID          int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	TpId        int       `json:"tp_id" gorm:"column:tp_id;type:int;not null;uniqueIndex:idx_prorroga_tp_alumno"`
+	AlumnoId    int       `json:"alumno_id" gorm:"column:alumno_id;type:int;not null;uniqueIndex:idx_prorroga_tp_alumno"`
+	NuevaFecha  time.Time `json:"nueva_fecha" gorm:"column:nueva_fecha;type:timestamptz;not null"`
+	Motivo      string    `json:"motivo" gorm:"column:motivo;type:text"`
+	OtorgadaPor int       `json:"otorgada_por" gorm:"column:otorgada_por;type:int"`
+	CreatedAt   time.Time `json:"created_at" gorm:"column:created_at"`
+	Tp          TpModel   `json:"-" gorm:"foreignKey:TpId;references:ID;constraint:OnDelete:CASCADE"`
+	Alumno      Alumno    `json:"alumno" gorm:"foreignKey:AlumnoId;references:ID"`
+}
+
+func (ProrrogaTP) TableName() string {
+	return "prorrogas_tp"
+}
+
+type ProrrogaCreateRequest struct {
+	AlumnoId   int       `json:"alumno_id" binding:"required"`
+	NuevaFecha time.Time `json:"nueva_fecha" binding:"required"`
+	Motivo     string    `json:"motivo"`
+}
+
+// PlazoEntrega es el plazo efectivo de un alumno para un TP y, si ya entregó
+// (o entregara ahora), cuánto se atrasó
+type PlazoEntrega struct {
+	TpId           int                   `json:"tp_id"`
+	AlumnoId       int                   `json:"alumno_id"`
+	FechaLimite    time.Time             `json:"fecha_limite"`
+	ConProrroga    bool                  `json:"con_prorroga"`
+	Politica       PoliticaEntregaTardia `json:"politica_tardia"`
+	Tardia         bool                  `json:"tardia"`
+	DiasTardanza   int                   `json:"dias_tardanza"`
+	Penalizacion   float64               `json:"penalizacion"`
+	AceptaEntregas bool                  `json:"acepta_entregas"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// Session representa un refresh token activo de un usuario (un dispositivo/navegador).
+// Los tokens se guardan hasheados; el refresh token rota en cada uso.
+type Session struct {
+	ID                int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID            int        `json:"user_id" gorm:"column:user_id;type:int;not null;index:idx_sessions_user"`
+	Role              Role       `json:"role" gorm:"column:role;type:varchar(20);not null;index:idx_sessions_user"`
+	RefreshTokenHash  string     `json:"-" gorm:"column:refresh_token_hash;type:varchar(64);uniqueIndex;not null"`
+	PreviousTokenHash string     `json:"-" gorm:"column:previous_token_hash;type:varchar(64);index"`
+	AccessTokenID     string     `json:"-" gorm:"column:access_token_id;type:varchar(64)"`
+	AccessExpiresAt   time.Time  `json:"-" gorm:"column:access_expires_at"`
+	UserAgent         string     `json:"user_agent" gorm:"column:user_agent;type:varchar(255)"`
+	IP                string     `json:"ip" gorm:"column:ip;type:varchar(45)"`
+	CreatedAt         time.Time  `json:"created_at" gorm:"column:created_at"`
+	LastUsedAt        time.Time  `json:"last_used_at" gorm:"column:last_used_at"`
+	ExpiresAt         time.Time  `json:"expires_at" gorm:"column:expires_at;not null"`
+	RevokedAt         *time.Time `json:"revoked_at,omitempty" gorm:"column:revoked_at;default:null"`
+}
+
+// RevokedToken es la lista de access tokens (por jti) revocados antes de expirar.
+// AuthMiddleware la consulta en cada request.
+type RevokedToken struct {
+	JTI       string    `json:"jti" gorm:"column:jti;type:varchar(64);primaryKey"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"column:expires_at;not null;index"`
+}
+
+type SessionResponse struct {
+	ID         int       `json:"id"`
+	UserID     int       `json:"user_id"`
+	Role       Role      `json:"role"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Actual     bool      `json:"actual"`
+}
+
+type ForceLogoutRequest struct {
+	UserID int  `json:"user_id" binding:"required"`
+	Role   Role `json:"role" binding:"required"`
+}
+
+// TokenPair es lo que devuelve el login y el refresh
+type TokenPair struct {
+	AccessToken      string
+	AccessExpiresAt  time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
@@ -2,6 +2,19 @@ package models
 
 import "time"
 
+// PoliticaEntregaTardia define qué pasa con las entregas posteriores a la fecha límite
+type PoliticaEntregaTardia string
+
+const (
+	PoliticaRechazar     PoliticaEntregaTardia = "rechazar"
+	PoliticaMarcarTardia PoliticaEntregaTardia = "marcar_tardia"
+	PoliticaPenalizar    PoliticaEntregaTardia = "penalizar"
+)
+
+func (p PoliticaEntregaTardia) IsValid() bool {
+	return p == PoliticaRechazar || p == PoliticaMarcarTardia || p == PoliticaPenalizar
+}
+
 type TpModel struct {
 	ID               int       `json:"id" gorm:"primaryKey;autoIncrement"`
 	Consigna         string    `json:"consigna" gorm:"column:consigna;type:text;not null"`
@@ -11,6 +24,18 @@ type TpModel struct {
 	Devolucion       string    `json:"devolucion" gorm:"column:devolucion;type:text;default:null"`
 	ComisionId       int       `json:"comision_id" gorm:"column:comision_id;type:int;not null"`
 	Comision         Comision  `json:"comision" gorm:"foreignKey:ComisionId;references:ID"`
+	// Política de entregas tardías; con "penalizar" se descuenta PenalizacionPorDia de la nota por cada día de atraso
+	PoliticaTardia     PoliticaEntregaTardia `json:"politica_tardia" gorm:"column:politica_tardia;type:varchar(20);not null;default:'marcar_tardia'"`
+	PenalizacionPorDia float64               `json:"penalizacion_por_dia" gorm:"column:penalizacion_por_dia;type:float;not null;default:0"`
+	// Cantidad máxima de intentos de entrega por alumno (0 = sin límite)
+	MaxIntentos int `json:"max_intentos" gorm:"column:max_intentos;type:int;not null;default:0"`
+	// TP grupal: tamaño máximo de los grupos (0 = TP individual) y si los alumnos pueden armarlos
+	TamanoMaxGrupo   int  `json:"tamano_max_grupo" gorm:"column:tamano_max_grupo;type:int;not null;default:0"`
+	GruposPorAlumnos bool `json:"grupos_por_alumnos" gorm:"column:grupos_por_alumnos;not null;default:false"`
+	// Restricciones de los archivos entregados: tamaño máximo en MB (0 = el límite global)
+	// y extensiones permitidas separadas por coma, ej: ".py,.zip" (vacío = cualquiera)
+	MaxTamanoMB           int    `json:"max_tamano_mb" gorm:"column:max_tamano_mb;type:int;not null;default:0"`
+	ExtensionesPermitidas string `json:"extensiones_permitidas" gorm:"column:extensiones_permitidas;type:varchar(255);not null;default:''"`
 }
 
 type TpUpdateRequest struct {
@@ -20,4 +45,12 @@ type TpUpdateRequest struct {
 	Nota             *float64   `json:"nota,omitempty"`
 	Devolucion       *string    `json:"devolucion,omitempty"`
 	ComisionId       *int       `json:"comision_id,omitempty"`
-}
\ No newline at end of file
+
+	PoliticaTardia        *PoliticaEntregaTardia `json:"politica_tardia,omitempty"`
+	PenalizacionPorDia    *float64               `json:"penalizacion_por_dia,omitempty"`
+	MaxIntentos           *int                   `json:"max_intentos,omitempty"`
+	TamanoMaxGrupo        *int                   `json:"tamano_max_grupo,omitempty"`
+	GruposPorAlumnos      *bool                  `json:"grupos_por_alumnos,omitempty"`
+	MaxTamanoMB           *int                   `json:"max_tamano_mb,omitempty"`
+	ExtensionesPermitidas *string                `json:"extensiones_permitidas,omitempty"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Usuario es la identidad/credencial única de una persona. Los perfiles
+// (Admin, Profesor, Alumno) apuntan a ella vía usuario_id, por lo que una
+// misma persona puede tener varios roles con un único email y contraseña.
+type Usuario struct {
+	ID       int    `json:"id" gorm:"primaryKey;autoIncrement"`
+	Email    string `json:"email" gorm:"column:email;type:varchar(60);uniqueIndex;not null"`
+	Password string `json:"-" gorm:"column:password;type:varchar(70);not null"`
+	// Pendiente indica que la cuenta fue creada sin contraseña y espera la activación por invitación
+	Pendiente bool      `json:"pendiente" gorm:"column:pendiente;not null;default:false"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// RoleSelectionResponse se devuelve en el login cuando el usuario tiene más
+// de un rol y no indicó con cuál quiere ingresar
+type RoleSelectionResponse struct {
+	Error string `json:"error"`
+	Roles []Role `json:"roles"`
+}
@@ -8,8 +8,8 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupAdminsRoutes(router *gin.Engine, service *services.AdminService) {
-	adminController := controllers.NewAdminController(service)
+func SetupAdminsRoutes(router *gin.Engine, service *services.AdminService, accountService *services.AccountService) {
+	adminController := controllers.NewAdminController(service, accountService)
 
 	// Rutas solo para administradores
 	protectedAdmins := router.Group("/admins")
@@ -8,15 +8,15 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupAlumnosRoutes(router *gin.Engine, service *services.AlumnoService) {
-	alumnoController := controllers.NewAlumnoController(service)
+func SetupAlumnosRoutes(router *gin.Engine, service *services.AlumnoService, accountService *services.AccountService) {
+	alumnoController := controllers.NewAlumnoController(service, accountService)
 
 	// Rutas protegidas (tanto alumnos como admins pueden acceder)
 	protectedAlumnos := router.Group("/alumnos")
 	protectedAlumnos.Use(middleware.AuthMiddleware())
 	protectedAlumnos.Use(middleware.RequireRole(models.RoleAdmin, models.RoleAlumno))
 	{
-		protectedAlumnos.PATCH("/:id", alumnoController.UpdateAlumno)
+		protectedAlumnos.PATCH("/:id", middleware.RequireAlumnoSelf(middleware.SelfParam("id")), alumnoController.UpdateAlumno)
 	}
 
 	// Rutas exclusivas para administradores
@@ -8,8 +8,17 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupAnexoRoutes(router *gin.Engine, service *services.AnexoService) {
-	anexoController := controllers.NewAnexoController(service)
+func SetupAnexoRoutes(router *gin.Engine, service *services.AnexoService, policy *services.PolicyService, archivoService *services.ArchivoService) {
+	anexoController := controllers.NewAnexoController(service, archivoService)
+
+	// Profesores solo pueden operar sobre anexos de TPs de sus comisiones
+	anexoAccess := middleware.RequireComisionAccess(policy, middleware.FromParam("id", policy.ComisionIDForAnexo))
+	tpAccess := middleware.RequireComisionAccess(policy, middleware.FromParam("tp_id", policy.ComisionIDForTp))
+	tpBodyAccess := middleware.RequireComisionAccess(policy, middleware.FromBody("tp_id", policy.ComisionIDForTp))
+
+	// Alumnos solo pueden ver anexos de TPs de comisiones en las que cursan
+	anexoInscripto := middleware.RequireAlumnoInscripto(policy, middleware.FromParam("id", policy.ComisionIDForAnexo))
+	tpInscripto := middleware.RequireAlumnoInscripto(policy, middleware.FromParam("tp_id", policy.ComisionIDForTp))
 
 	// Rutas para administradores (acceso completo)
 	adminOnlyAnexos := router.Group("/anexos")
@@ -28,10 +37,10 @@ func SetupAnexoRoutes(router *gin.Engine, service *services.AnexoService) {
 	anexoArchivoRoutes.Use(middleware.AuthMiddleware())
 	anexoArchivoRoutes.Use(middleware.RequireRole(models.RoleAdmin, models.RoleProfesor))
 	{
-		anexoArchivoRoutes.POST("/:id/upload", anexoController.UploadAnexoArchivo)
-		anexoArchivoRoutes.GET("/:id/archivos", anexoController.GetAnexoArchivosByAnexoID)
-		anexoArchivoRoutes.GET("/:id/archivo/download", anexoController.DownloadAnexoArchivo)
-		anexoArchivoRoutes.DELETE("/:id/archivos", anexoController.DeleteAnexoArchivo)
+		anexoArchivoRoutes.POST("/:id/upload", anexoAccess, anexoController.UploadAnexoArchivo)
+		anexoArchivoRoutes.GET("/:id/archivos", anexoAccess, anexoController.GetAnexoArchivosByAnexoID)
+		anexoArchivoRoutes.GET("/:id/archivo/download", anexoAccess, anexoController.DownloadAnexoArchivo)
+		anexoArchivoRoutes.DELETE("/:id/archivos", anexoAccess, anexoController.DeleteAnexoArchivo)
 	}
 
 	// Rutas para profesores (crear anexos y subir archivos)
@@ -39,15 +48,15 @@ func SetupAnexoRoutes(router *gin.Engine, service *services.AnexoService) {
 	profesorAnexos.Use(middleware.AuthMiddleware())
 	profesorAnexos.Use(middleware.RequireRole(models.RoleProfesor))
 	{
-		profesorAnexos.POST("/", anexoController.CreateAnexo)
-		profesorAnexos.GET("/tp/:tp_id", anexoController.GetAnexosByTpID)
-		profesorAnexos.GET("/:id", anexoController.GetAnexoByID)
-		profesorAnexos.PATCH("/:id", anexoController.UpdateAnexo)
-		profesorAnexos.DELETE("/:id", anexoController.DeleteAnexo)
-		profesorAnexos.POST("/:id/upload", anexoController.UploadAnexoArchivo)
-		profesorAnexos.GET("/:id/archivos", anexoController.GetAnexoArchivosByAnexoID)
-		profesorAnexos.GET("/:id/archivo/download", anexoController.DownloadAnexoArchivo)
-		profesorAnexos.DELETE("/:id/archivos", anexoController.DeleteAnexoArchivo)
+		profesorAnexos.POST("/", tpBodyAccess, anexoController.CreateAnexo)
+		profesorAnexos.GET("/tp/:tp_id", tpAccess, anexoController.GetAnexosByTpID)
+		profesorAnexos.GET("/:id", anexoAccess, anexoController.GetAnexoByID)
+		profesorAnexos.PATCH("/:id", anexoAccess, tpBodyAccess, anexoController.UpdateAnexo)
+		profesorAnexos.DELETE("/:id", anexoAccess, anexoController.DeleteAnexo)
+		profesorAnexos.POST("/:id/upload", anexoAccess, anexoController.UploadAnexoArchivo)
+		profesorAnexos.GET("/:id/archivos", anexoAccess, anexoController.GetAnexoArchivosByAnexoID)
+		profesorAnexos.GET("/:id/archivo/download", anexoAccess, anexoController.DownloadAnexoArchivo)
+		profesorAnexos.DELETE("/:id/archivos", anexoAccess, anexoController.DeleteAnexoArchivo)
 	}
 
 	// Rutas para alumnos (solo lectura de anexos y descarga)
@@ -55,9 +64,9 @@ func SetupAnexoRoutes(router *gin.Engine, service *services.AnexoService) {
 	alumnoAnexos.Use(middleware.AuthMiddleware())
 	alumnoAnexos.Use(middleware.RequireRole(models.RoleAlumno))
 	{
-		alumnoAnexos.GET("/tp/:tp_id", anexoController.GetAnexosByTpID)
-		alumnoAnexos.GET("/:id", anexoController.GetAnexoByID)
-		alumnoAnexos.GET("/:id/archivos", anexoController.GetAnexoArchivosByAnexoID)
-		alumnoAnexos.GET("/:id/archivo/download", anexoController.DownloadAnexoArchivo)
+		alumnoAnexos.GET("/tp/:tp_id", tpInscripto, anexoController.GetAnexosByTpID)
+		alumnoAnexos.GET("/:id", anexoInscripto, anexoController.GetAnexoByID)
+		alumnoAnexos.GET("/:id/archivos", anexoInscripto, anexoController.GetAnexoArchivosByAnexoID)
+		alumnoAnexos.GET("/:id/archivo/download", anexoInscripto, anexoController.DownloadAnexoArchivo)
 	}
 }
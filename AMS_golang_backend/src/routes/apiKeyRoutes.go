@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"github.com/LINSITrack/backend/src/controllers"
+	"github.com/LINSITrack/backend/src/middleware"
+	"github.com/LINSITrack/backend/src/models"
+	"github.com/LINSITrack/backend/src/services"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupApiKeyRoutes(router *gin.Engine, service *services.ApiKeyService) {
+	apiKeyController := controllers.NewApiKeyController(service)
+
+	// Solo administradores pueden emitir y revocar API keys
+	apiKeys := router.Group("/admin/api-keys")
+	apiKeys.Use(middleware.AuthMiddleware(), middleware.RequireRole(models.RoleAdmin))
+	{
+		apiKeys.GET("/", apiKeyController.GetApiKeys)
+		apiKeys.POST("/", apiKeyController.CreateApiKey)
+		apiKeys.DELETE("/:id", apiKeyController.RevokeApiKey)
+	}
+}
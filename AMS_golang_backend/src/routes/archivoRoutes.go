@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"github.com/LINSITrack/backend/src/controllers"
+	"github.com/LINSITrack/backend/src/middleware"
+	"github.com/LINSITrack/backend/src/models"
+	"github.com/LINSITrack/backend/src/services"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupArchivoRoutes(router *gin.Engine, archivoService *services.ArchivoService, policy *services.PolicyService) {
+	archivoController := controllers.NewArchivoController(archivoService, policy)
+
+	// La descarga acepta sesión o una URL firmada; el acceso al archivo lo decide PolicyService.PuedeVerArchivo
+	router.GET("/archivos/:id", middleware.AuthOrSignedURL(archivoController.VerificarFirma), archivoController.DownloadArchivo)
+
+	archivos := router.Group("/archivos")
+	archivos.Use(middleware.AuthMiddleware())
+	archivos.Use(middleware.RequireRole(models.RoleAdmin, models.RoleProfesor, models.RoleAlumno))
+	{
+		archivos.GET("/:id/metadata", archivoController.GetArchivo)
+		archivos.GET("/:id/url", archivoController.GetURLFirmada)
+	}
+}
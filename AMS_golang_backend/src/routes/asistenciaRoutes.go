@@ -0,0 +1,47 @@
+package routes
+
+import (
+	"github.com/LINSITrack/backend/src/controllers"
+	"github.com/LINSITrack/backend/src/middleware"
+	"github.com/LINSITrack/backend/src/models"
+	"github.com/LINSITrack/backend/src/services"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupAsistenciaRoutes(router *gin.Engine, service *services.AsistenciaService, policy *services.PolicyService) {
+	asistenciaController := controllers.NewAsistenciaController(service)
+
+	// Profesores solo pueden operar sobre sus comisiones
+	comisionAccess := middleware.RequireComisionAccess(policy, middleware.FromParam("id", policy.ComisionIDForComision))
+	claseAccess := middleware.RequireComisionAccess(policy, middleware.FromParam("id", policy.ComisionIDForClase))
+	cursadaAccess := middleware.RequireComisionAccess(policy, middleware.FromParam("id", policy.ComisionIDForCursada))
+
+	// Alumnos solo pueden ver la asistencia de sus propias cursadas
+	cursadaOwner := middleware.RequireAlumnoSelf(middleware.FromParam("id", policy.AlumnoIDForCursada))
+
+	comisiones := router.Group("/comisiones")
+	comisiones.Use(middleware.AuthMiddleware())
+	comisiones.Use(middleware.RequireRole(models.RoleAdmin, models.RoleProfesor))
+	{
+		comisiones.GET("/:id/clases", comisionAccess, asistenciaController.GetClases)
+		comisiones.POST("/:id/clases", comisionAccess, asistenciaController.CrearClase)
+		comisiones.POST("/:id/clases/generar", comisionAccess, asistenciaController.GenerarClases)
+	}
+
+	clases := router.Group("/clases")
+	clases.Use(middleware.AuthMiddleware())
+	clases.Use(middleware.RequireRole(models.RoleAdmin, models.RoleProfesor))
+	{
+		clases.PATCH("/:id", claseAccess, asistenciaController.UpdateClase)
+		clases.DELETE("/:id", claseAccess, asistenciaController.DeleteClase)
+		clases.GET("/:id/asistencia", claseAccess, asistenciaController.GetPlanilla)
+		clases.PUT("/:id/asistencia", claseAccess, asistenciaController.TomarAsistencia)
+	}
+
+	cursadas := router.Group("/cursadas")
+	cursadas.Use(middleware.AuthMiddleware())
+	cursadas.Use(middleware.RequireRole(models.RoleAdmin, models.RoleProfesor, models.RoleAlumno))
+	{
+		cursadas.GET("/:id/asistencia", cursadaAccess, cursadaOwner, asistenciaController.GetAsistenciaCursada)
+	}
+}
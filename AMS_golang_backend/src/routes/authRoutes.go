@@ -3,17 +3,44 @@ package routes
 import (
 	"github.com/LINSITrack/backend/src/controllers"
 	"github.com/LINSITrack/backend/src/middleware"
+	"github.com/LINSITrack/backend/src/models"
 	"github.com/LINSITrack/backend/src/services"
 	"github.com/gin-gonic/gin"
 )
 
-func SetupAuthRoutes(router *gin.Engine, service *services.AuthService) {
+func SetupAuthRoutes(router *gin.Engine, service *services.AuthService, accountService *services.AccountService) {
 	authController := controllers.NewAuthController(service)
+	accountController := controllers.NewAccountController(accountService)
 
 	auth := router.Group("/auth")
 	{
 		auth.POST("/login", authController.Login)
 		auth.POST("/logout", middleware.AuthMiddleware(), authController.Logout)
 		auth.GET("/whoami", middleware.AuthMiddleware(), authController.WhoAmI)
+		auth.POST("/refresh", authController.Refresh)
+		auth.GET("/sessions", middleware.AuthMiddleware(), authController.GetSessions)
+		auth.DELETE("/sessions/:id", middleware.AuthMiddleware(), authController.RevokeSession)
+
+		// Recuperación de contraseña y activación de cuentas (enlaces enviados por email)
+		auth.POST("/password/forgot", accountController.ForgotPassword)
+		auth.POST("/password/reset", accountController.ResetPassword)
+		auth.POST("/activate", accountController.ActivateAccount)
+		auth.POST("/invitations/:usuarioId", middleware.AuthMiddleware(), middleware.RequireRole(models.RoleAdmin), accountController.SendInvitation)
+	}
+
+	adminSessions := router.Group("/admin/sessions")
+	adminSessions.Use(middleware.AuthMiddleware(), middleware.RequireRole(models.RoleAdmin))
+	{
+		adminSessions.GET("/", authController.GetUserSessions)
+		adminSessions.DELETE("/:id", authController.AdminRevokeSession)
+		adminSessions.POST("/force-logout", authController.ForceLogout)
+	}
+
+	loginAudit := router.Group("/admin/login-audit")
+	loginAudit.Use(middleware.AuthMiddleware(), middleware.RequireRole(models.RoleAdmin))
+	{
+		loginAudit.GET("/", authController.GetLoginAttempts)
+		loginAudit.GET("/locks", authController.GetLoginLocks)
+		loginAudit.POST("/unlock", authController.UnlockLogin)
 	}
 }
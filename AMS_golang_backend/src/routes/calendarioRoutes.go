@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"github.com/LINSITrack/backend/src/controllers"
+	"github.com/LINSITrack/backend/src/middleware"
+	"github.com/LINSITrack/backend/src/models"
+	"github.com/LINSITrack/backend/src/services"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupCalendarioRoutes(router *gin.Engine, service *services.CalendarioService) {
+	calendarioController := controllers.NewCalendarioController(service)
+
+	calendario := router.Group("/calendario")
+	{
+		// Las aplicaciones de calendario no mandan headers: el token va en la URL
+		calendario.GET("/feed/:token", calendarioController.GetFeed)
+	}
+
+	// Cada alumno o profesor administra su propio feed
+	suscripcion := router.Group("/calendario/suscripcion")
+	suscripcion.Use(middleware.AuthMiddleware())
+	suscripcion.Use(middleware.RequireRole(models.RoleAlumno, models.RoleProfesor))
+	{
+		suscripcion.GET("", calendarioController.GetSuscripcion)
+		suscripcion.POST("", calendarioController.GenerarSuscripcion)
+		suscripcion.DELETE("", calendarioController.RevocarSuscripcion)
+	}
+}
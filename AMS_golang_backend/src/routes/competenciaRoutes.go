@@ -8,19 +8,24 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupCompetenciaRoutes(router *gin.Engine, service *services.CompetenciaService) {
+func SetupCompetenciaRoutes(router *gin.Engine, service *services.CompetenciaService, policy *services.PolicyService) {
 	competenciaController := controllers.NewCompetenciaController(service)
 
+	// Profesores solo pueden operar sobre competencias de TPs de sus comisiones
+	competenciaAccess := middleware.RequireComisionAccess(policy, middleware.FromParam("id", policy.ComisionIDForCompetencia))
+	comisionAccess := middleware.RequireComisionAccess(policy, middleware.FromParam("comisionId", policy.ComisionIDForComision))
+	tpBodyAccess := middleware.RequireComisionAccess(policy, middleware.FromBody("tp_id", policy.ComisionIDForTp))
+
 	competenciasGroup := router.Group("/competencias")
 	competenciasGroup.Use(middleware.AuthMiddleware())
 
 	competenciasGroup.GET("/", middleware.RequireRole(models.RoleProfesor, models.RoleAlumno, models.RoleAdmin), competenciaController.GetAllCompetencias)
-	competenciasGroup.GET("/:id", middleware.RequireRole(models.RoleProfesor, models.RoleAlumno, models.RoleAdmin), competenciaController.GetCompetenciaByID)
-	competenciasGroup.GET("/comision/:comisionId", middleware.RequireRole(models.RoleProfesor, models.RoleAlumno, models.RoleAdmin), competenciaController.GetCompetenciasByComisionID)
+	competenciasGroup.GET("/:id", middleware.RequireRole(models.RoleProfesor, models.RoleAlumno, models.RoleAdmin), competenciaAccess, competenciaController.GetCompetenciaByID)
+	competenciasGroup.GET("/comision/:comisionId", middleware.RequireRole(models.RoleProfesor, models.RoleAlumno, models.RoleAdmin), comisionAccess, competenciaController.GetCompetenciasByComisionID)
 
-	competenciasGroup.POST("/", middleware.RequireRole(models.RoleProfesor, models.RoleAdmin), competenciaController.CreateCompetencia)
-	competenciasGroup.POST("/comision/:comisionId", middleware.RequireRole(models.RoleProfesor, models.RoleAdmin), competenciaController.CreateCompetenciaForComision)
-	competenciasGroup.PATCH("/:id", middleware.RequireRole(models.RoleProfesor, models.RoleAdmin), competenciaController.UpdateCompetencia)
+	competenciasGroup.POST("/", middleware.RequireRole(models.RoleProfesor, models.RoleAdmin), tpBodyAccess, competenciaController.CreateCompetencia)
+	competenciasGroup.POST("/comision/:comisionId", middleware.RequireRole(models.RoleProfesor, models.RoleAdmin), comisionAccess, competenciaController.CreateCompetenciaForComision)
+	competenciasGroup.PATCH("/:id", middleware.RequireRole(models.RoleProfesor, models.RoleAdmin), competenciaAccess, tpBodyAccess, competenciaController.UpdateCompetencia)
 
 	competenciasGroup.DELETE("/:id", middleware.RequireRole(models.RoleAdmin), competenciaController.DeleteCompetencia)
 }
@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"github.com/LINSITrack/backend/src/controllers"
+	"github.com/LINSITrack/backend/src/middleware"
+	"github.com/LINSITrack/backend/src/models"
+	"github.com/LINSITrack/backend/src/services"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupCondicionRoutes(router *gin.Engine, service *services.CondicionService) {
+	condicionController := controllers.NewCondicionController(service)
+
+	materias := router.Group("/materias")
+	materias.Use(middleware.AuthMiddleware())
+	materias.Use(middleware.RequireRole(models.RoleAdmin, models.RoleProfesor))
+	{
+		materias.GET("/:id/reglas-condicion", condicionController.GetReglas)
+	}
+
+	// Las reglas valen para todas las comisiones de la materia: solo las define un admin
+	adminMaterias := router.Group("/materias")
+	adminMaterias.Use(middleware.AuthMiddleware())
+	adminMaterias.Use(middleware.RequireRole(models.RoleAdmin))
+	{
+		adminMaterias.PUT("/:id/reglas-condicion", condicionController.GuardarReglas)
+		adminMaterias.DELETE("/:id/reglas-condicion", condicionController.BorrarReglas)
+	}
+}
@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"github.com/LINSITrack/backend/src/controllers"
+	"github.com/LINSITrack/backend/src/middleware"
+	"github.com/LINSITrack/backend/src/models"
+	"github.com/LINSITrack/backend/src/services"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupCorrelatividadRoutes(router *gin.Engine, service *services.CorrelatividadService) {
+	correlatividadController := controllers.NewCorrelatividadController(service)
+
+	materias := router.Group("/materias")
+	materias.Use(middleware.AuthMiddleware())
+	materias.Use(middleware.RequireRole(models.RoleAdmin, models.RoleProfesor))
+	{
+		materias.GET("/:id/correlatividades", correlatividadController.GetCorrelatividades)
+		materias.GET("/:id/correlatividades/alumnos/:alumnoId", correlatividadController.GetEstado)
+	}
+
+	// Las correlativas valen para todas las comisiones de la materia: solo las define un admin
+	adminMaterias := router.Group("/materias")
+	adminMaterias.Use(middleware.AuthMiddleware())
+	adminMaterias.Use(middleware.RequireRole(models.RoleAdmin))
+	{
+		adminMaterias.PUT("/:id/correlatividades", correlatividadController.GuardarCorrelatividades)
+		adminMaterias.GET("/:id/correlatividades/excepciones", correlatividadController.GetExcepciones)
+	}
+}
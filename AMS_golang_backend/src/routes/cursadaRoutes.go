@@ -8,17 +8,26 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupCursadasRoutes(router *gin.Engine, service *services.CursadaService) {
+func SetupCursadasRoutes(router *gin.Engine, service *services.CursadaService, policy *services.PolicyService) {
 	cursadaController := controllers.NewCursadaController(service)
 
+	// Profesores solo pueden operar sobre cursadas de sus comisiones
+	cursadaAccess := middleware.RequireComisionAccess(policy, middleware.FromParam("id", policy.ComisionIDForCursada))
+	comisionAccess := middleware.RequireComisionAccess(policy, middleware.FromParam("comisionId", policy.ComisionIDForComision))
+	comisionBodyAccess := middleware.RequireComisionAccess(policy, middleware.FromBody("comision_id", policy.ComisionIDForComision))
+
+	// Alumnos solo pueden operar sobre sus propias cursadas
+	alumnoSelf := middleware.RequireAlumnoSelf(middleware.SelfParam("alumnoId"))
+	cursadaOwner := middleware.RequireAlumnoSelf(middleware.FromParam("id", policy.AlumnoIDForCursada))
+
 	// Rutas para Admin y Profesor (GET, POST)
 	adminProfesorCursadas := router.Group("/cursadas")
 	adminProfesorCursadas.Use(middleware.AuthMiddleware())
 	adminProfesorCursadas.Use(middleware.RequireRole(models.RoleAdmin, models.RoleProfesor))
 	{
 		adminProfesorCursadas.GET("", cursadaController.GetAllCursadas)
-		adminProfesorCursadas.POST("", cursadaController.CreateCursada)
-		adminProfesorCursadas.GET("/:id", cursadaController.GetCursadaByID)
+		adminProfesorCursadas.POST("", comisionBodyAccess, cursadaController.CreateCursada)
+		adminProfesorCursadas.GET("/:id", cursadaAccess, cursadaController.GetCursadaByID)
 	}
 	
 	// Ruta PATCH compartida para Admin, Profesor y Alumno
@@ -26,8 +35,8 @@ func SetupCursadasRoutes(router *gin.Engine, service *services.CursadaService) {
 	cursadasPatch.Use(middleware.AuthMiddleware())
 	cursadasPatch.Use(middleware.RequireRole(models.RoleAdmin, models.RoleProfesor, models.RoleAlumno))
 	{
-		cursadasPatch.PATCH("/:id", cursadaController.UpdateCursada)
-		cursadasPatch.GET("/alumno/:alumnoId", cursadaController.GetCursadaByAlumnoID)
+		cursadasPatch.PATCH("/:id", cursadaAccess, cursadaOwner, comisionBodyAccess, cursadaController.UpdateCursada)
+		cursadasPatch.GET("/alumno/:alumnoId", alumnoSelf, cursadaController.GetCursadaByAlumnoID)
 	}
 
 	// Rutas exclusivas para Admin (DELETE)
@@ -44,7 +53,7 @@ func SetupCursadasRoutes(router *gin.Engine, service *services.CursadaService) {
 	profesorCursadas.Use(middleware.RequireRole(models.RoleProfesor))
 	{
 		profesorCursadas.GET("/mis-comisiones", cursadaController.GetCursadasByProfesor)
-		profesorCursadas.GET("/comision/:comisionId", cursadaController.GetCursadasByProfesorAndComision)
+		profesorCursadas.GET("/comision/:comisionId", comisionAccess, cursadaController.GetCursadasByProfesorAndComision)
 	}
 
 }
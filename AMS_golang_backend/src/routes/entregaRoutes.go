@@ -8,8 +8,11 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupEntregaRoutes(router *gin.Engine, service *services.EntregaService) {
-	entregaController := controllers.NewEntregaController(service)
+func SetupEntregaRoutes(router *gin.Engine, service *services.EntregaService, policy *services.PolicyService, archivoService *services.ArchivoService) {
+	entregaController := controllers.NewEntregaController(service, archivoService)
+
+	// Profesores solo pueden operar sobre entregas de TPs de sus comisiones
+	entregaAccess := middleware.RequireComisionAccess(policy, middleware.FromParam("id", policy.ComisionIDForEntrega))
 
 	// Rutas para administradores (acceso completo)
 	adminOnlyEntregas := router.Group("/entregas")
@@ -28,10 +31,10 @@ func SetupEntregaRoutes(router *gin.Engine, service *services.EntregaService) {
 	archivoRoutes.Use(middleware.AuthMiddleware())
 	archivoRoutes.Use(middleware.RequireRole(models.RoleAdmin, models.RoleProfesor))
 	{
-		archivoRoutes.POST("/:id/upload", entregaController.UploadArchivo)
-		archivoRoutes.GET("/:id/archivos", entregaController.GetArchivosByEntregaID)
-		archivoRoutes.GET("/:id/archivo/download", entregaController.DownloadArchivo)
-		archivoRoutes.DELETE("/:id/archivos", entregaController.DeleteArchivo)
+		archivoRoutes.POST("/:id/upload", entregaAccess, entregaController.UploadArchivo)
+		archivoRoutes.GET("/:id/archivos", entregaAccess, entregaController.GetArchivosByEntregaID)
+		archivoRoutes.GET("/:id/archivo/download", entregaAccess, entregaController.DownloadArchivo)
+		archivoRoutes.DELETE("/:id/archivos", entregaAccess, entregaController.DeleteArchivo)
 	}
 
 	// Rutas adicionales para alumnos (solo lectura de sus propias entregas)
@@ -4,12 +4,20 @@ import (
 	"github.com/LINSITrack/backend/src/controllers"
 	"github.com/LINSITrack/backend/src/middleware"
 	"github.com/LINSITrack/backend/src/models"
+	"github.com/LINSITrack/backend/src/services"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
-func SetupEntregaTPRoutes(router *gin.Engine, db *gorm.DB) {
-	entregaTPController := controllers.NewEntregaTPController(db)
+func SetupEntregaTPRoutes(router *gin.Engine, db *gorm.DB, policy *services.PolicyService, archivoService *services.ArchivoService) {
+	entregaTPController := controllers.NewEntregaTPController(db, archivoService)
+
+	// Profesores solo pueden ver y corregir entregas de sus comisiones
+	tpAccess := middleware.RequireComisionAccess(policy, middleware.FromParam("tp_id", policy.ComisionIDForTp))
+	entregaAccess := middleware.RequireComisionAccess(policy, middleware.FromParam("id", policy.ComisionIDForEntregaTP))
+
+	// Alumnos solo pueden ver y reentregar sus propias entregas
+	entregaOwner := middleware.RequireAlumnoSelf(middleware.FromParam("id", policy.AlumnoIDForEntregaTP))
 
 	entregas := router.Group("/entregas")
 	entregas.Use(middleware.AuthMiddleware())
@@ -18,13 +26,16 @@ func SetupEntregaTPRoutes(router *gin.Engine, db *gorm.DB) {
 		entregas.GET("/", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor), entregaTPController.GetAllEntregas)
 
 		// Get submissions for a specific TP (teachers/admin)
-		entregas.GET("/tp/:tp_id", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor), entregaTPController.GetEntregasByTP)
+		entregas.GET("/tp/:tp_id", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor), tpAccess, entregaTPController.GetEntregasByTP)
+
+		// Download every submission of a TP as a ZIP with a CSV manifest (teachers/admin)
+		entregas.GET("/tp/:tp_id/zip", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor), tpAccess, entregaTPController.DownloadEntregasZip)
 
 		// Get student's own submissions (students)
 		entregas.GET("/mis-entregas", middleware.RequireRole(models.RoleAlumno), entregaTPController.GetEntregasByAlumno)
 
-		// Get specific submission by ID (all authenticated users, controller checks ownership for students)
-		entregas.GET("/:id", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor, models.RoleAlumno), entregaTPController.GetEntregaByID)
+		// Get specific submission by ID (all authenticated users, students only their own)
+		entregas.GET("/:id", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor, models.RoleAlumno), entregaAccess, entregaOwner, entregaTPController.GetEntregaByID)
 
 		// Create submission (students)
 		entregas.POST("/", middleware.RequireRole(models.RoleAlumno), entregaTPController.CreateEntrega)
@@ -33,7 +44,20 @@ func SetupEntregaTPRoutes(router *gin.Engine, db *gorm.DB) {
 		entregas.POST("/upload", middleware.RequireRole(models.RoleAlumno), entregaTPController.UploadArchivoForAlumno)
 
 		// Update submission (students can resubmit, teachers can grade)
-		entregas.PATCH("/:id", entregaTPController.UpdateEntrega)
+		entregas.PATCH("/:id", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor, models.RoleAlumno), entregaAccess, entregaOwner, entregaTPController.UpdateEntrega)
+
+		// Change the state of a submission (teachers/admin)
+		entregas.POST("/:id/estado", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor), entregaAccess, entregaTPController.CambiarEstado)
+
+		// State history (teachers/admin, students only their own)
+		entregas.GET("/:id/transiciones", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor, models.RoleAlumno), entregaAccess, entregaOwner, entregaTPController.GetTransiciones)
+
+		// Attempt history (teachers/admin, students only their own)
+		entregas.GET("/:id/intentos", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor, models.RoleAlumno), entregaAccess, entregaOwner, entregaTPController.GetIntentos)
+		entregas.GET("/:id/intentos/:numero", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor, models.RoleAlumno), entregaAccess, entregaOwner, entregaTPController.GetIntento)
+
+		// Compare two attempts (teachers/admin)
+		entregas.GET("/:id/diff", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor), entregaAccess, entregaTPController.DiffIntentos)
 
 		// Delete submission (admin only)
 		entregas.DELETE("/:id", middleware.RequireRole(models.RoleAdmin), entregaTPController.DeleteEntrega)
@@ -8,20 +8,26 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupEvaluacionRoutes(router *gin.Engine, service *services.EvaluacionService) {
+func SetupEvaluacionRoutes(router *gin.Engine, service *services.EvaluacionService, policy *services.PolicyService) {
 	evaluacionController := controllers.NewEvaluacionController(service)
 
+	// Profesores solo pueden operar sobre evaluaciones de sus comisiones
+	evaluacionAccess := middleware.RequireComisionAccess(policy, middleware.FromParam("id", policy.ComisionIDForEvaluacion))
+	comisionAccess := middleware.RequireComisionAccess(policy, middleware.FromParam("comisionId", policy.ComisionIDForComision))
+	comisionBodyAccess := middleware.RequireComisionAccess(policy, middleware.FromBody("comision_id", policy.ComisionIDForComision))
+
 	evaluaciones := router.Group("/evaluaciones")
 	evaluaciones.Use(middleware.AuthMiddleware())
 	{
 		evaluaciones.GET("/", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor, models.RoleAlumno), evaluacionController.GetAllEvaluaciones)
-		evaluaciones.GET("/:id/entregas", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor), evaluacionController.GetEntregasByEvaluacionID)
-		evaluaciones.GET("/:id/entregas/:alumnoId", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor), evaluacionController.GetEntregaEvaluacion)
-		evaluaciones.PATCH("/:id/entregas/:alumnoId", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor), evaluacionController.UpdateEntregaEvaluacion)
-		evaluaciones.POST("/", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor), evaluacionController.CreateEvaluacion)
-		evaluaciones.GET("/comision/:comisionId", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor, models.RoleAlumno), evaluacionController.GetEvaluacionesByComisionID)
-		evaluaciones.GET("/:id", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor, models.RoleAlumno), evaluacionController.GetEvaluacionByID)
-		evaluaciones.PATCH("/:id", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor), evaluacionController.UpdateEvaluacion)
+		evaluaciones.GET("/:id/entregas", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor), evaluacionAccess, evaluacionController.GetEntregasByEvaluacionID)
+		evaluaciones.GET("/:id/entregas/:alumnoId", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor), evaluacionAccess, evaluacionController.GetEntregaEvaluacion)
+		evaluaciones.PATCH("/:id/entregas/:alumnoId", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor), evaluacionAccess, evaluacionController.UpdateEntregaEvaluacion)
+		evaluaciones.POST("/", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor), comisionBodyAccess, evaluacionController.CreateEvaluacion)
+		evaluaciones.POST("/:id/inscriptos", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor), evaluacionAccess, evaluacionController.InscribirRecuperatorio)
+		evaluaciones.GET("/comision/:comisionId", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor, models.RoleAlumno), comisionAccess, evaluacionController.GetEvaluacionesByComisionID)
+		evaluaciones.GET("/:id", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor, models.RoleAlumno), evaluacionAccess, evaluacionController.GetEvaluacionByID)
+		evaluaciones.PATCH("/:id", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor), evaluacionAccess, comisionBodyAccess, evaluacionController.UpdateEvaluacion)
 		evaluaciones.DELETE("/:id", middleware.RequireRole(models.RoleAdmin), evaluacionController.DeleteEvaluacion)
 	}
 
@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"github.com/LINSITrack/backend/src/controllers"
+	"github.com/LINSITrack/backend/src/middleware"
+	"github.com/LINSITrack/backend/src/models"
+	"github.com/LINSITrack/backend/src/services"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupHorarioRoutes(router *gin.Engine, service *services.HorarioService, policy *services.PolicyService) {
+	horarioController := controllers.NewHorarioController(service)
+
+	// Profesores solo pueden ver las franjas de sus comisiones
+	comisionAccess := middleware.RequireComisionAccess(policy, middleware.FromParam("id", policy.ComisionIDForComision))
+
+	comisiones := router.Group("/comisiones")
+	comisiones.Use(middleware.AuthMiddleware())
+	{
+		comisiones.GET("/:id/horarios", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor), comisionAccess, horarioController.GetHorarios)
+		comisiones.POST("/:id/horarios", middleware.RequireRole(models.RoleAdmin), horarioController.CrearHorario)
+	}
+
+	// Las franjas las administra solo el admin
+	horarios := router.Group("/horarios")
+	horarios.Use(middleware.AuthMiddleware())
+	horarios.Use(middleware.RequireRole(models.RoleAdmin))
+	{
+		horarios.PUT("/:id", horarioController.UpdateHorario)
+		horarios.DELETE("/:id", horarioController.DeleteHorario)
+	}
+}
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/LINSITrack/backend/src/controllers"
+	"github.com/LINSITrack/backend/src/middleware"
+	"github.com/LINSITrack/backend/src/models"
+	"github.com/LINSITrack/backend/src/services"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupLibretaRoutes(router *gin.Engine, service *services.LibretaService, policy *services.PolicyService) {
+	libretaController := controllers.NewLibretaController(service)
+
+	// Profesores solo pueden ver la libreta de sus comisiones
+	comisionAccess := middleware.RequireComisionAccess(policy, middleware.FromParam("id", policy.ComisionIDForComision))
+
+	libreta := router.Group("/comisiones")
+	libreta.Use(middleware.AuthMiddleware())
+	libreta.Use(middleware.RequireRole(models.RoleAdmin, models.RoleProfesor))
+	{
+		libreta.GET("/:id/libreta", comisionAccess, libretaController.GetLibreta)
+	}
+}
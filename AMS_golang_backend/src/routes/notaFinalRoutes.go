@@ -0,0 +1,62 @@
+package routes
+
+import (
+	"github.com/LINSITrack/backend/src/controllers"
+	"github.com/LINSITrack/backend/src/middleware"
+	"github.com/LINSITrack/backend/src/models"
+	"github.com/LINSITrack/backend/src/services"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupNotaFinalRoutes(router *gin.Engine, service *services.NotaFinalService, policy *services.PolicyService) {
+	notaFinalController := controllers.NewNotaFinalController(service)
+
+	// Profesores solo pueden operar sobre sus comisiones
+	comisionAccess := middleware.RequireComisionAccess(policy, middleware.FromParam("id", policy.ComisionIDForComision))
+	cursadaAccess := middleware.RequireComisionAccess(policy, middleware.FromParam("id", policy.ComisionIDForCursada))
+
+	// Alumnos solo pueden ver el cálculo de sus propias cursadas
+	cursadaOwner := middleware.RequireAlumnoSelf(middleware.FromParam("id", policy.AlumnoIDForCursada))
+
+	comisiones := router.Group("/comisiones")
+	comisiones.Use(middleware.AuthMiddleware())
+	comisiones.Use(middleware.RequireRole(models.RoleAdmin, models.RoleProfesor))
+	{
+		comisiones.GET("/:id/esquema-calificacion", comisionAccess, notaFinalController.GetEsquemaComision)
+		comisiones.PUT("/:id/esquema-calificacion", comisionAccess, notaFinalController.GuardarEsquemaComision)
+		comisiones.DELETE("/:id/esquema-calificacion", comisionAccess, notaFinalController.BorrarEsquemaComision)
+		comisiones.POST("/:id/notas-finales", comisionAccess, notaFinalController.RecalcularComision)
+	}
+
+	materias := router.Group("/materias")
+	materias.Use(middleware.AuthMiddleware())
+	materias.Use(middleware.RequireRole(models.RoleAdmin, models.RoleProfesor))
+	{
+		materias.GET("/:id/esquema-calificacion", notaFinalController.GetEsquemaMateria)
+	}
+
+	// El esquema de una materia vale para todas sus comisiones: solo lo define un admin
+	adminMaterias := router.Group("/materias")
+	adminMaterias.Use(middleware.AuthMiddleware())
+	adminMaterias.Use(middleware.RequireRole(models.RoleAdmin))
+	{
+		adminMaterias.PUT("/:id/esquema-calificacion", notaFinalController.GuardarEsquemaMateria)
+		adminMaterias.DELETE("/:id/esquema-calificacion", notaFinalController.BorrarEsquemaMateria)
+	}
+
+	cursadas := router.Group("/cursadas")
+	cursadas.Use(middleware.AuthMiddleware())
+	cursadas.Use(middleware.RequireRole(models.RoleAdmin, models.RoleProfesor, models.RoleAlumno))
+	{
+		cursadas.GET("/:id/nota-final", cursadaAccess, cursadaOwner, notaFinalController.GetNotaFinal)
+	}
+
+	ajustes := router.Group("/cursadas")
+	ajustes.Use(middleware.AuthMiddleware())
+	ajustes.Use(middleware.RequireRole(models.RoleAdmin, models.RoleProfesor))
+	{
+		ajustes.GET("/:id/nota-final/ajustes", cursadaAccess, notaFinalController.GetAjustes)
+		ajustes.POST("/:id/nota-final/ajuste", cursadaAccess, notaFinalController.AjustarNotaFinal)
+		ajustes.DELETE("/:id/nota-final/ajuste", cursadaAccess, notaFinalController.QuitarAjuste)
+	}
+}
@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"github.com/LINSITrack/backend/src/controllers"
+	"github.com/LINSITrack/backend/src/middleware"
+	"github.com/LINSITrack/backend/src/models"
+	"github.com/LINSITrack/backend/src/services"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupNotasImportRoutes(router *gin.Engine, service *services.NotasImportService, policy *services.PolicyService) {
+	notasImportController := controllers.NewNotasImportController(service)
+
+	// Profesores solo pueden calificar TPs y evaluaciones de sus comisiones
+	tpAccess := middleware.RequireComisionAccess(policy, middleware.FromParam("id", policy.ComisionIDForTp))
+	evaluacionAccess := middleware.RequireComisionAccess(policy, middleware.FromParam("id", policy.ComisionIDForEvaluacion))
+
+	// Con API key requieren el scope notas:import
+	middleware.RouteScope("POST", "/tps/:id/notas/import", models.ScopeNotasImport)
+	middleware.RouteScope("POST", "/evaluaciones/:id/notas/import", models.ScopeNotasImport)
+
+	notas := router.Group("/")
+	notas.Use(middleware.AuthMiddleware())
+	notas.Use(middleware.RequireRole(models.RoleAdmin, models.RoleProfesor))
+	{
+		notas.POST("/tps/:id/notas/import", tpAccess, notasImportController.ImportarNotasTp)
+		notas.POST("/evaluaciones/:id/notas/import", evaluacionAccess, notasImportController.ImportarNotasEvaluacion)
+	}
+}
@@ -8,9 +8,13 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupNotificacionRoutes(router *gin.Engine, service *services.NotificacionService) {
+func SetupNotificacionRoutes(router *gin.Engine, service *services.NotificacionService, policy *services.PolicyService) {
 	notificacionController := controllers.NewNotificacionController(service)
 
+	// Alumnos solo pueden ver y modificar sus propias notificaciones
+	alumnoSelf := middleware.RequireAlumnoSelf(middleware.SelfParam("alumnoId"))
+	notificacionOwner := middleware.RequireAlumnoSelf(middleware.FromParam("id", policy.AlumnoIDForNotificacion))
+
 	// Rutas para Admin solamente
 	adminOnlyNotificaciones := router.Group("/notificaciones")
 	adminOnlyNotificaciones.Use(middleware.AuthMiddleware())
@@ -30,12 +34,12 @@ func SetupNotificacionRoutes(router *gin.Engine, service *services.NotificacionS
 	alumnoNotificaciones.Use(middleware.RequireRole(models.RoleAdmin, models.RoleAlumno))
 	{
 		// Rutas específicas de alumno
-		alumnoNotificaciones.GET("/alumnos/:alumnoId", notificacionController.GetNotificacionesByAlumnoID)
-		alumnoNotificaciones.GET("/alumnos/:alumnoId/unread", notificacionController.GetUnreadNotificacionesByAlumnoID)
-		alumnoNotificaciones.GET("/alumnos/:alumnoId/read", notificacionController.GetReadNotificacionesByAlumnoID)
+		alumnoNotificaciones.GET("/alumnos/:alumnoId", alumnoSelf, notificacionController.GetNotificacionesByAlumnoID)
+		alumnoNotificaciones.GET("/alumnos/:alumnoId/unread", alumnoSelf, notificacionController.GetUnreadNotificacionesByAlumnoID)
+		alumnoNotificaciones.GET("/alumnos/:alumnoId/read", alumnoSelf, notificacionController.GetReadNotificacionesByAlumnoID)
 
 		// Acciones
-		alumnoNotificaciones.PATCH("/:id/mark-read", notificacionController.MarkNotificacionAsRead)
-		alumnoNotificaciones.PATCH("/alumnos/:alumnoId/mark-all-read", notificacionController.MarkAllNotificacionAsReadByAlumnoID)
+		alumnoNotificaciones.PATCH("/:id/mark-read", notificacionOwner, notificacionController.MarkNotificacionAsRead)
+		alumnoNotificaciones.PATCH("/alumnos/:alumnoId/mark-all-read", alumnoSelf, notificacionController.MarkAllNotificacionAsReadByAlumnoID)
 	}
 }
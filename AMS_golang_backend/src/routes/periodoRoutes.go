@@ -0,0 +1,37 @@
+package routes
+
+import (
+	"github.com/LINSITrack/backend/src/controllers"
+	"github.com/LINSITrack/backend/src/middleware"
+	"github.com/LINSITrack/backend/src/models"
+	"github.com/LINSITrack/backend/src/services"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupPeriodoRoutes(router *gin.Engine, service *services.PeriodoService) {
+	periodoController := controllers.NewPeriodoController(service)
+
+	// Todos los usuarios pueden consultar los períodos para filtrar
+	readPeriodos := router.Group("/periodos")
+	readPeriodos.Use(middleware.AuthMiddleware())
+	{
+		readPeriodos.GET("/", periodoController.GetPeriodos)
+		readPeriodos.GET("/:id", periodoController.GetPeriodo)
+	}
+
+	adminOnlyPeriodos := router.Group("/periodos")
+	adminOnlyPeriodos.Use(middleware.AuthMiddleware())
+	adminOnlyPeriodos.Use(middleware.RequireRole(models.RoleAdmin))
+	{
+		adminOnlyPeriodos.POST("/", periodoController.CrearPeriodo)
+		adminOnlyPeriodos.PUT("/:id", periodoController.UpdatePeriodo)
+		adminOnlyPeriodos.DELETE("/:id", periodoController.DeletePeriodo)
+	}
+
+	rollover := router.Group("/comisiones")
+	rollover.Use(middleware.AuthMiddleware())
+	rollover.Use(middleware.RequireRole(models.RoleAdmin))
+	{
+		rollover.POST("/:id/rollover", periodoController.Rollover)
+	}
+}
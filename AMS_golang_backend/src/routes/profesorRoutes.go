@@ -8,8 +8,8 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupProfesoresRoutes(router *gin.Engine, service *services.ProfesorService) {
-	profesorController := controllers.NewProfesorController(service)
+func SetupProfesoresRoutes(router *gin.Engine, service *services.ProfesorService, accountService *services.AccountService) {
+	profesorController := controllers.NewProfesorController(service, accountService)
 
 	// Rutas protegidas (tanto profesores como admins pueden acceder)
 	protectedProfesores := router.Group("/profesores")
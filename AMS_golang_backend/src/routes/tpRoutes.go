@@ -8,8 +8,14 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupTpRoutes(router *gin.Engine, service *services.TpService) {
+func SetupTpRoutes(router *gin.Engine, service *services.TpService, policy *services.PolicyService, plazoService *services.PlazoService, grupoService *services.GrupoService) {
 	tpController := controllers.NewTpController(service)
+	prorrogaController := controllers.NewProrrogaController(plazoService, service)
+	grupoController := controllers.NewGrupoController(grupoService)
+
+	// Profesores solo pueden operar sobre TPs de sus comisiones
+	tpAccess := middleware.RequireComisionAccess(policy, middleware.FromParam("id", policy.ComisionIDForTp))
+	comisionBodyAccess := middleware.RequireComisionAccess(policy, middleware.FromBody("comision_id", policy.ComisionIDForComision))
 
 	tps := router.Group("/tps")
 	tps.Use(middleware.AuthMiddleware())
@@ -18,10 +24,25 @@ func SetupTpRoutes(router *gin.Engine, service *services.TpService) {
 		tps.GET("/", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor, models.RoleAlumno), tpController.GetAllTps)
 
 		// Only teachers and admins can create/update/delete TPs
-		tps.POST("/", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor), tpController.CreateTp)
-		tps.PATCH("/:id", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor), tpController.UpdateTp)
+		tps.POST("/", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor), comisionBodyAccess, tpController.CreateTp)
+		tps.PATCH("/:id", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor), tpAccess, comisionBodyAccess, tpController.UpdateTp)
 		tps.DELETE("/:id", middleware.RequireRole(models.RoleAdmin), tpController.DeleteTp)
-		tps.GET("/:id", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor, models.RoleAlumno), tpController.GetTpByID)
+		tps.GET("/:id", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor, models.RoleAlumno), tpAccess, tpController.GetTpByID)
+
+		// Prórrogas por alumno (profesores de la comisión y admins)
+		tps.GET("/:id/prorrogas", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor), tpAccess, prorrogaController.GetProrrogasByTp)
+		tps.POST("/:id/prorrogas", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor), tpAccess, prorrogaController.OtorgarProrroga)
+		tps.DELETE("/:id/prorrogas/:alumnoId", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor), tpAccess, prorrogaController.RevocarProrroga)
+
+		// Plazo efectivo del alumno autenticado (fecha del TP o su prórroga)
+		tps.GET("/:id/mi-plazo", middleware.RequireRole(models.RoleAlumno), prorrogaController.GetMiPlazo)
+
+		// Grupos de TPs grupales: los arman profesores o, si el TP lo permite, los alumnos
+		tps.GET("/:id/grupos", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor), tpAccess, grupoController.GetGruposByTp)
+		tps.POST("/:id/grupos", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor, models.RoleAlumno), tpAccess, grupoController.CrearGrupo)
+		tps.PATCH("/:id/grupos/:grupoId", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor), tpAccess, grupoController.ActualizarGrupo)
+		tps.DELETE("/:id/grupos/:grupoId", middleware.RequireRole(models.RoleAdmin, models.RoleProfesor, models.RoleAlumno), tpAccess, grupoController.EliminarGrupo)
+		tps.GET("/:id/mi-grupo", middleware.RequireRole(models.RoleAlumno), grupoController.GetMiGrupo)
 	}
 
 	// Profesor-specific endpoint at different path to avoid /:id conflict
@@ -4,13 +4,13 @@ import (
 	"log"
 	"os"
 	"path/filepath"
-	"time"
 
 	"github.com/LINSITrack/backend/src/models"
+	"github.com/LINSITrack/backend/src/services"
 	"gorm.io/gorm"
 )
 
-func AnexoSeed(db *gorm.DB) {
+func AnexoSeed(db *gorm.DB, archivoService *services.ArchivoService) {
 	log.Println("Iniciando seed de anexos...")
 
 	// Recupera TPs existentes
@@ -80,10 +80,10 @@ func AnexoSeed(db *gorm.DB) {
 		log.Printf("Procesando archivo: %s para TP %d", filename, tpID)
 
 		// Verificar si ya existe un anexo con este archivo
-		var existingArchivo models.AnexoArchivo
+		var existingArchivo models.ArchivoMetadata
 
 		// Buscar si ya existe un archivo con este nombre
-		if err := db.Where("original_name = ?", filename).First(&existingArchivo).Error; err == nil {
+		if err := db.Where("recurso_type = ? AND original_name = ?", models.RecursoAnexo, filename).First(&existingArchivo).Error; err == nil {
 			log.Printf("Anexo con archivo %s ya existe, saltando...", filename)
 			anexoCounter++
 			continue
@@ -99,28 +99,18 @@ func AnexoSeed(db *gorm.DB) {
 			continue
 		}
 
-		// Crear archivo asociado directamente
+		// Guardar el archivo en el storage asociado al anexo
 		filePath := filepath.Join(seedDir, filename)
-		fileInfo, err := os.Stat(filePath)
+		content, err := os.Open(filePath)
 		if err != nil {
-			log.Printf("Error obteniendo info del archivo %s: %v", filename, err)
+			log.Printf("Error abriendo el archivo %s: %v", filename, err)
 			db.Delete(&anexo) // Limpiar anexo creado
 			continue
 		}
-
-		anexoArchivo := models.AnexoArchivo{
-			AnexoID:      anexo.ID,
-			Filename:     filename,
-			OriginalName: filename,
-			FilePath:     filePath,
-			ContentType:  contentType,
-			Size:         fileInfo.Size(),
-			CreatedAt:    time.Now(),
-			UpdatedAt:    time.Now(),
-		}
-
-		if err := db.Create(&anexoArchivo).Error; err != nil {
-			log.Printf("Error creando archivo en BD: %v", err)
+		_, err = archivoService.Guardar(content, filename, contentType, models.RecursoAnexo, anexo.ID, 0, "")
+		content.Close()
+		if err != nil {
+			log.Printf("Error guardando archivo en el storage: %v", err)
 			db.Delete(&anexo) // Limpiar anexo creado
 			continue
 		}
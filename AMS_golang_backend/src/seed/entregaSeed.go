@@ -4,13 +4,13 @@ import (
 	"log"
 	"os"
 	"path/filepath"
-	"time"
 
 	"github.com/LINSITrack/backend/src/models"
+	"github.com/LINSITrack/backend/src/services"
 	"gorm.io/gorm"
 )
 
-func EntregaSeed(db *gorm.DB) {
+func EntregaSeed(db *gorm.DB, archivoService *services.ArchivoService) {
 	log.Println("Iniciando seed de entregas...")
 
 	// Recupera alumnos existentes
@@ -106,8 +106,8 @@ func EntregaSeed(db *gorm.DB) {
 		log.Printf("Procesando archivo: %s para Alumno %d, TP %d", filename, alumnoID, tpID)
 
 		// Verificar si ya existe una entrega con este archivo
-		var existingArchivo models.Archivo
-		if err := db.Where("original_name = ?", filename).First(&existingArchivo).Error; err == nil {
+		var existingArchivo models.ArchivoMetadata
+		if err := db.Where("recurso_type = ? AND original_name = ?", models.RecursoEntrega, filename).First(&existingArchivo).Error; err == nil {
 			log.Printf("Entrega con archivo %s ya existe, saltando...", filename)
 			fileCounter++
 			continue
@@ -125,29 +125,19 @@ func EntregaSeed(db *gorm.DB) {
 			continue
 		}
 
-		// Crear archivo asociado directamente
+		// Guardar el archivo en el storage asociado al entrega
 		filePath := filepath.Join(seedDir, filename)
-		fileInfo, err := os.Stat(filePath)
+		content, err := os.Open(filePath)
 		if err != nil {
-			log.Printf("Error obteniendo info del archivo %s: %v", filename, err)
-			db.Delete(&entrega) // Limpiar entrega creada
+			log.Printf("Error abriendo el archivo %s: %v", filename, err)
+			db.Delete(&entrega) // Limpiar entrega creado
 			continue
 		}
-
-		archivo := models.Archivo{
-			EntregaID:    entrega.ID,
-			Filename:     filename,
-			OriginalName: filename,
-			FilePath:     filePath,
-			ContentType:  contentType,
-			Size:         fileInfo.Size(),
-			CreatedAt:    time.Now(),
-			UpdatedAt:    time.Now(),
-		}
-
-		if err := db.Create(&archivo).Error; err != nil {
-			log.Printf("Error creando archivo en BD: %v", err)
-			db.Delete(&entrega) // Limpiar entrega creada
+		_, err = archivoService.Guardar(content, filename, contentType, models.RecursoEntrega, entrega.ID, 0, "")
+		content.Close()
+		if err != nil {
+			log.Printf("Error guardando archivo en el storage: %v", err)
+			db.Delete(&entrega) // Limpiar entrega creado
 			continue
 		}
 
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/LINSITrack/backend/src/models"
+	"github.com/LINSITrack/backend/utils/mailer"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	PasswordResetTTL = 1 * time.Hour
+	InvitationTTL    = 72 * time.Hour
+)
+
+var (
+	ErrInvalidAccountToken = errors.New("el enlace es inválido o expiró")
+	ErrUsuarioNoPendiente  = errors.New("la cuenta ya está activa")
+)
+
+// AccountService maneja la recuperación de contraseña y la activación de
+// cuentas creadas sin contraseña, enviando los enlaces por email.
+type AccountService struct {
+	db          *gorm.DB
+	mailer      mailer.Mailer
+	auth        *AuthService
+	frontendURL string
+}
+
+func NewAccountService(db *gorm.DB, m mailer.Mailer, auth *AuthService, frontendURL string) *AccountService {
+	return &AccountService{db: db, mailer: m, auth: auth, frontendURL: frontendURL}
+}
+
+// RequestPasswordReset envía el enlace de recuperación. Si el email no existe
+// no devuelve error, para no revelar qué cuentas están registradas.
+func (s *AccountService) RequestPasswordReset(email string) error {
+	var usuario models.Usuario
+	err := s.db.Where("email = ?", normalizeEmail(email)).First(&usuario).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	token, err := s.issueToken(usuario.ID, models.TokenPasswordReset, PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf(
+		"Recibimos un pedido para restablecer tu contraseña de LINSI.\n\n"+
+			"Ingresá al siguiente enlace para elegir una nueva (vence en %d minutos):\n%s\n\n"+
+			"Si no lo pediste, ignorá este mensaje.",
+		int(PasswordResetTTL.Minutes()), s.link("/reset-password", token),
+	)
+	return s.mailer.Send(usuario.Email, "Restablecer contraseña", body)
+}
+
+// SendInvitation envía el enlace de activación a una cuenta pendiente
+func (s *AccountService) SendInvitation(usuarioID int) error {
+	var usuario models.Usuario
+	if err := s.db.First(&usuario, usuarioID).Error; err != nil {
+		return err
+	}
+	if !usuario.Pendiente {
+		return ErrUsuarioNoPendiente
+	}
+
+	token, err := s.issueToken(usuario.ID, models.TokenInvitation, InvitationTTL)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf(
+		"Se creó tu cuenta en LINSI.\n\n"+
+			"Para activarla y elegir tu contraseña ingresá al siguiente enlace (vence en %d días):\n%s",
+		int(InvitationTTL.Hours()/24), s.link("/activar-cuenta", token),
+	)
+	return s.mailer.Send(usuario.Email, "Activá tu cuenta", body)
+}
+
+// SendInvitationIfPending se usa al crear perfiles: solo invita si la cuenta
+// quedó sin contraseña. Un error al enviar no deshace la creación.
+func (s *AccountService) SendInvitationIfPending(usuarioID *int) {
+	if usuarioID == nil {
+		return
+	}
+	if err := s.SendInvitation(*usuarioID); err != nil && !errors.Is(err, ErrUsuarioNoPendiente) {
+		log.Printf("No se pudo enviar la invitación al usuario %d: %v", *usuarioID, err)
+	}
+}
+
+// ResetPassword canjea un token de recuperación y cierra todas las sesiones abiertas
+func (s *AccountService) ResetPassword(token, password string) error {
+	return s.redeem(token, models.TokenPasswordReset, password)
+}
+
+// ActivateAccount canjea un token de invitación y deja la cuenta activa
+func (s *AccountService) ActivateAccount(token, password string) error {
+	return s.redeem(token, models.TokenInvitation, password)
+}
+
+func (s *AccountService) redeem(token string, purpose models.AccountTokenPurpose, password string) error {
+	if len(password) < 8 {
+		return errors.New("la contraseña debe tener al menos 8 caracteres")
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	var usuarioID int
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var accountToken models.AccountToken
+		err := tx.Where("token_hash = ? AND purpose = ?", hashToken(token), purpose).First(&accountToken).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidAccountToken
+		}
+		if err != nil {
+			return err
+		}
+		if accountToken.UsedAt != nil || time.Now().After(accountToken.ExpiresAt) {
+			return ErrInvalidAccountToken
+		}
+
+		// Marcar como usado de forma condicional para que dos canjes simultáneos no pasen ambos
+		now := time.Now()
+		result := tx.Model(&models.AccountToken{}).
+			Where("id = ? AND used_at IS NULL", accountToken.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvalidAccountToken
+		}
+
+		usuarioID = accountToken.UsuarioID
+		return tx.Model(&models.Usuario{}).Where("id = ?", usuarioID).Updates(map[string]interface{}{
+			"password":  string(hashedPassword),
+			"pendiente": false,
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	// Con la contraseña nueva, las sesiones anteriores dejan de ser válidas
+	return s.auth.RevokeAllSessionsForUsuario(usuarioID)
+}
+
+// issueToken genera un token nuevo e invalida los anteriores sin usar del mismo tipo
+func (s *AccountService) issueToken(usuarioID int, purpose models.AccountTokenPurpose, ttl time.Duration) (string, error) {
+	token, err := generateRandomToken()
+	if err != nil {
+		return "", err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&models.AccountToken{}).
+			Where("usuario_id = ? AND purpose = ? AND used_at IS NULL", usuarioID, purpose).
+			Update("used_at", now).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&models.AccountToken{
+			UsuarioID: usuarioID,
+			Purpose:   purpose,
+			TokenHash: hashToken(token),
+			ExpiresAt: now.Add(ttl),
+		}).Error
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (s *AccountService) link(path, token string) string {
+	return s.frontendURL + path + "?token=" + url.QueryEscape(token)
+}
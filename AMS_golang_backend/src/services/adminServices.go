@@ -6,7 +6,6 @@ import (
 
 	"github.com/LINSITrack/backend/utils/validation"
 	"github.com/LINSITrack/backend/src/models"
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
@@ -62,26 +61,25 @@ func (s *AdminService) CreateAdmin(admin *models.Admin) (*models.Admin, error) {
 	if strings.TrimSpace(admin.Email) == "" {
 		return nil, errors.New("el email es requerido")
 	}
-	if strings.TrimSpace(admin.Password) == "" {
-		return nil, errors.New("la contraseña es requerida")
-	}
-	if len(admin.Password) < 8 {
-		return nil, errors.New("la contraseña debe tener al menos 8 caracteres")
-	}
 
-	// Validar unicidad de email contra todas las tablas
-	if err := validation.ValidateEmailUniqueness(s.db, admin.Email, "", ""); err != nil {
+	// El email es único por rol; entre roles identifica a la misma persona
+	if err := validation.ValidateEmailUniqueness(s.db, admin.Email, "admin", ""); err != nil {
 		return nil, err
 	}
 
-	// Hasheo de password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(admin.Password), bcrypt.DefaultCost)
-	if err != nil {
-		return nil, err
-	}
-	admin.Password = string(hashedPassword)
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Vincular con la identidad existente o crear una nueva
+		usuario, err := resolveUsuario(tx, admin.Email, admin.Password)
+		if err != nil {
+			return err
+		}
+		admin.UsuarioID = &usuario.ID
+		admin.Email = usuario.Email
+		admin.Password = ""
 
-	if err := s.db.Create(admin).Error; err != nil {
+		return tx.Create(admin).Error
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -116,11 +114,17 @@ func (s *AdminService) UpdateAdmin(id string, updatedData *models.AdminUpdateReq
 		if strings.TrimSpace(*updatedData.Email) == "" {
 			return nil, errors.New("el email no puede estar vacío")
 		}
-		// Validar unicidad de email contra todas las tablas
+		// Validar unicidad de email en el rol y contra otras identidades
 		if err := validation.ValidateEmailUniqueness(s.db, *updatedData.Email, "admin", id); err != nil {
 			return nil, err
 		}
-		admin.Email = *updatedData.Email
+		usuarioID := 0
+		if admin.UsuarioID != nil {
+			usuarioID = *admin.UsuarioID
+		}
+		if err := validation.ValidateUsuarioEmailUniqueness(s.db, *updatedData.Email, usuarioID); err != nil {
+			return nil, err
+		}
 	}
 
 	if updatedData.Password != nil {
@@ -130,14 +134,19 @@ func (s *AdminService) UpdateAdmin(id string, updatedData *models.AdminUpdateReq
 		if len(*updatedData.Password) < 8 {
 			return nil, errors.New("la contraseña debe tener al menos 8 caracteres")
 		}
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(*updatedData.Password), bcrypt.DefaultCost)
-		if err != nil {
-			return nil, err
-		}
-		admin.Password = string(hashedPassword)
 	}
 
-	if err := s.db.Save(&admin).Error; err != nil {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Email y contraseña pertenecen al usuario (compartido entre roles)
+		if err := updateUsuarioCredentials(tx, admin.UsuarioID, updatedData.Email, updatedData.Password); err != nil {
+			return err
+		}
+		if updatedData.Email != nil {
+			admin.Email = normalizeEmail(*updatedData.Email)
+		}
+		return tx.Omit("password").Save(&admin).Error
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -148,6 +157,18 @@ func (s *AdminService) UpdateAdmin(id string, updatedData *models.AdminUpdateReq
 }
 
 func (s *AdminService) DeleteAdmin(id string) error {
-	result := s.db.Delete(&models.Admin{}, "id = ?", id)
-	return result.Error
+	var admin models.Admin
+	if err := s.db.Select("id", "usuario_id").First(&admin, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.Admin{}, "id = ?", id).Error; err != nil {
+			return err
+		}
+		return deleteUsuarioIfOrphan(tx, admin.UsuarioID)
+	})
 }
@@ -6,7 +6,6 @@ import (
 
 	"github.com/LINSITrack/backend/utils/validation"
 	"github.com/LINSITrack/backend/src/models"
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
@@ -32,29 +31,28 @@ func (s *AlumnoService) CreateAlumno(alumno *models.Alumno) (*models.Alumno, err
 	if strings.TrimSpace(alumno.Email) == "" {
 		return nil, errors.New("el email es requerido")
 	}
-	if strings.TrimSpace(alumno.Password) == "" {
-		return nil, errors.New("la contraseña es requerida")
-	}
-	if len(alumno.Password) < 8 {
-		return nil, errors.New("la contraseña debe tener al menos 8 caracteres")
-	}
 
-	// Validar unicidad contra todas las tablas
-	if err := validation.ValidateEmailUniqueness(s.db, alumno.Email, "", ""); err != nil {
+	// El email es único por rol; entre roles identifica a la misma persona
+	if err := validation.ValidateEmailUniqueness(s.db, alumno.Email, "alumno", ""); err != nil {
 		return nil, err
 	}
 	if err := validation.ValidateLegajoUniqueness(s.db, alumno.Legajo, "", ""); err != nil {
 		return nil, err
 	}
 
-	// Hasheo de password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(alumno.Password), bcrypt.DefaultCost)
-	if err != nil {
-		return nil, err
-	}
-	alumno.Password = string(hashedPassword)
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Vincular con la identidad existente o crear una nueva
+		usuario, err := resolveUsuario(tx, alumno.Email, alumno.Password)
+		if err != nil {
+			return err
+		}
+		alumno.UsuarioID = &usuario.ID
+		alumno.Email = usuario.Email
+		alumno.Password = ""
 
-	if err := s.db.Create(alumno).Error; err != nil {
+		return tx.Create(alumno).Error
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -100,11 +98,17 @@ func (s *AlumnoService) UpdateAlumno(id string, updatedData *models.AlumnoUpdate
 		if strings.TrimSpace(*updatedData.Email) == "" {
 			return nil, errors.New("el email no puede estar vacío")
 		}
-		// Validar unicidad de email contra todas las tablas
+		// Validar unicidad de email en el rol y contra otras identidades
 		if err := validation.ValidateEmailUniqueness(s.db, *updatedData.Email, "alumno", id); err != nil {
 			return nil, err
 		}
-		alumno.Email = *updatedData.Email
+		usuarioID := 0
+		if alumno.UsuarioID != nil {
+			usuarioID = *alumno.UsuarioID
+		}
+		if err := validation.ValidateUsuarioEmailUniqueness(s.db, *updatedData.Email, usuarioID); err != nil {
+			return nil, err
+		}
 	}
 
 	if updatedData.Password != nil {
@@ -114,14 +118,19 @@ func (s *AlumnoService) UpdateAlumno(id string, updatedData *models.AlumnoUpdate
 		if len(*updatedData.Password) < 8 {
 			return nil, errors.New("la contraseña debe tener al menos 8 caracteres")
 		}
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(*updatedData.Password), bcrypt.DefaultCost)
-		if err != nil {
-			return nil, err
-		}
-		alumno.Password = string(hashedPassword)
 	}
 
-	if err := s.db.Save(&alumno).Error; err != nil {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Email y contraseña pertenecen al usuario (compartido entre roles)
+		if err := updateUsuarioCredentials(tx, alumno.UsuarioID, updatedData.Email, updatedData.Password); err != nil {
+			return err
+		}
+		if updatedData.Email != nil {
+			alumno.Email = normalizeEmail(*updatedData.Email)
+		}
+		return tx.Omit("password").Save(&alumno).Error
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -167,6 +176,18 @@ func (s *AlumnoService) GetAlumnoByID(id int) (*models.AlumnoResponse, error) {
 }
 
 func (s *AlumnoService) DeleteAlumno(id string) error {
-	result := s.db.Delete(&models.Alumno{}, "id = ?", id)
-	return result.Error
+	var alumno models.Alumno
+	if err := s.db.Select("id", "usuario_id").First(&alumno, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.Alumno{}, "id = ?", id).Error; err != nil {
+			return err
+		}
+		return deleteUsuarioIfOrphan(tx, alumno.UsuarioID)
+	})
 }
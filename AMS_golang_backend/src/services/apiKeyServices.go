@@ -0,0 +1,159 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/LINSITrack/backend/src/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidAPIKey  = errors.New("API key inválida, expirada o revocada")
+	ErrApiKeyNotFound = errors.New("API key no encontrada")
+)
+
+type ApiKeyService struct {
+	db   *gorm.DB
+	auth *AuthService
+}
+
+func NewApiKeyService(db *gorm.DB, auth *AuthService) *ApiKeyService {
+	return &ApiKeyService{db: db, auth: auth}
+}
+
+// CreateApiKey genera una key nueva. La key en texto plano solo se devuelve acá;
+// en la base queda su hash.
+func (s *ApiKeyService) CreateApiKey(req *models.ApiKeyCreateRequest, createdBy int) (*models.ApiKeyCreatedResponse, error) {
+	if strings.TrimSpace(req.Nombre) == "" {
+		return nil, errors.New("el nombre es requerido")
+	}
+	for _, scope := range req.Scopes {
+		if !models.IsValidAPIKeyScope(scope) {
+			return nil, errors.New("scope inválido: " + scope)
+		}
+	}
+	if req.ExpiresAt != nil && req.ExpiresAt.Before(time.Now()) {
+		return nil, errors.New("la fecha de expiración ya pasó")
+	}
+
+	// La key actúa en nombre de un perfil existente
+	if _, err := s.auth.loadSubject(req.Role, req.UserID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("usuario no encontrado")
+		}
+		return nil, err
+	}
+
+	secret, err := generateRandomToken()
+	if err != nil {
+		return nil, err
+	}
+	key := models.APIKeyPrefix + secret
+
+	apiKey := models.ApiKey{
+		Nombre:    req.Nombre,
+		Prefix:    key[:len(models.APIKeyPrefix)+6],
+		KeyHash:   hashToken(key),
+		UserID:    req.UserID,
+		Role:      req.Role,
+		Scopes:    strings.Join(req.Scopes, ","),
+		CreatedBy: createdBy,
+		ExpiresAt: req.ExpiresAt,
+	}
+	if err := s.db.Create(&apiKey).Error; err != nil {
+		return nil, err
+	}
+
+	return &models.ApiKeyCreatedResponse{ApiKeyResponse: toApiKeyResponse(apiKey), Key: key}, nil
+}
+
+// GetApiKeys lista las keys, opcionalmente filtradas por perfil
+func (s *ApiKeyService) GetApiKeys(userID int, role models.Role) ([]models.ApiKeyResponse, error) {
+	query := s.db.Model(&models.ApiKey{})
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	if role != "" {
+		query = query.Where("role = ?", role)
+	}
+
+	var keys []models.ApiKey
+	if err := query.Order("created_at DESC").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+
+	resp := make([]models.ApiKeyResponse, 0, len(keys))
+	for _, k := range keys {
+		resp = append(resp, toApiKeyResponse(k))
+	}
+	return resp, nil
+}
+
+func (s *ApiKeyService) RevokeApiKey(id int) error {
+	var apiKey models.ApiKey
+	if err := s.db.First(&apiKey, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrApiKeyNotFound
+		}
+		return err
+	}
+	if apiKey.RevokedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	apiKey.RevokedAt = &now
+	return s.db.Save(&apiKey).Error
+}
+
+// Authenticate valida una key presentada como Bearer. Se registra en
+// middleware con SetAPIKeyResolver.
+func (s *ApiKeyService) Authenticate(key string) (*models.APIKeyIdentity, error) {
+	var apiKey models.ApiKey
+	if err := s.db.Where("key_hash = ?", hashToken(key)).First(&apiKey).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+	if apiKey.RevokedAt != nil || (apiKey.ExpiresAt != nil && time.Now().After(*apiKey.ExpiresAt)) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	subject, err := s.auth.loadSubject(apiKey.Role, apiKey.UserID)
+	if err != nil {
+		return nil, ErrInvalidAPIKey
+	}
+
+	s.db.Model(&apiKey).Update("last_used_at", time.Now())
+
+	return &models.APIKeyIdentity{
+		KeyID:     apiKey.ID,
+		UsuarioID: subject.UsuarioID,
+		UserID:    subject.ID,
+		Role:      subject.Role,
+		Email:     subject.Email,
+		Nombre:    subject.Nombre,
+		Apellido:  subject.Apellido,
+		Legajo:    subject.Legajo,
+		Scopes:    apiKey.ScopeList(),
+	}, nil
+}
+
+func toApiKeyResponse(k models.ApiKey) models.ApiKeyResponse {
+	return models.ApiKeyResponse{
+		ID:         k.ID,
+		Nombre:     k.Nombre,
+		Prefix:     k.Prefix,
+		UserID:     k.UserID,
+		Role:       k.Role,
+		Scopes:     k.ScopeList(),
+		CreatedBy:  k.CreatedBy,
+		CreatedAt:  k.CreatedAt,
+		ExpiresAt:  k.ExpiresAt,
+		LastUsedAt: k.LastUsedAt,
+		RevokedAt:  k.RevokedAt,
+	}
+}
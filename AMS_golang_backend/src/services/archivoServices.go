@@ -0,0 +1,415 @@
+package services
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LINSITrack/backend/src/middleware"
+	"github.com/LINSITrack/backend/src/models"
+	"github.com/LINSITrack/backend/utils/storage"
+	"github.com/LINSITrack/backend/utils/upload"
+	"gorm.io/gorm"
+)
+
+// ArchivoService es el único punto de entrada para guardar y leer archivos
+// subidos: el contenido va al Storage (por SHA-256) y la metadata a archivos_metadata.
+type ArchivoService struct {
+	db    *gorm.DB
+	store storage.Storage
+	// Tamaño máximo global de un archivo subido, en bytes (0 = sin límite)
+	maxSize int64
+}
+
+func NewArchivoService(db *gorm.DB, store storage.Storage, maxSize int64) *ArchivoService {
+	return &ArchivoService{db: db, store: store, maxSize: maxSize}
+}
+
+// ReglasGlobales son las restricciones que aplican a cualquier archivo subido
+func (s *ArchivoService) ReglasGlobales() upload.Reglas {
+	return upload.Reglas{MaxSize: s.maxSize}
+}
+
+// ReglasTp combina el límite global con las restricciones del TP (gana el límite más chico)
+func (s *ArchivoService) ReglasTp(tp *models.TpModel) upload.Reglas {
+	reglas := s.ReglasGlobales()
+	if tp.MaxTamanoMB > 0 {
+		maxTp := int64(tp.MaxTamanoMB) << 20
+		if reglas.MaxSize == 0 || maxTp < reglas.MaxSize {
+			reglas.MaxSize = maxTp
+		}
+	}
+	reglas.Extensiones = upload.ParseExtensiones(tp.ExtensionesPermitidas)
+	return reglas
+}
+
+// GuardarUpload valida y guarda un archivo recibido en un form multipart.
+// Los rechazos de validación son *upload.Error.
+func (s *ArchivoService) GuardarUpload(file *multipart.FileHeader, reglas upload.Reglas, recurso string, recursoID, subidoPor int, role models.Role) (*models.ArchivoMetadata, error) {
+	src, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("error opening uploaded file: %v", err)
+	}
+	defer src.Close()
+
+	r, head, err := peekHead(src)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo el archivo: %v", err)
+	}
+	tipo, err := upload.Validar(file.Filename, file.Size, head, reglas)
+	if err != nil {
+		return nil, err
+	}
+	return s.guardar(r, file.Filename, tipo.String(), file.Header.Get("Content-Type"), recurso, recursoID, subidoPor, role)
+}
+
+// Guardar sube el contenido sin validarlo (seeds y migraciones), guardando igualmente el tipo detectado
+func (s *ArchivoService) Guardar(r io.Reader, nombre, contentType, recurso string, recursoID, subidoPor int, role models.Role) (*models.ArchivoMetadata, error) {
+	r, head, err := peekHead(r)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo el archivo: %v", err)
+	}
+	return s.guardar(r, nombre, upload.Detectar(head).String(), contentType, recurso, recursoID, subidoPor, role)
+}
+
+// guardar sube el contenido (si el blob no existía) y registra su metadata
+func (s *ArchivoService) guardar(r io.Reader, nombre, detectado, declarado, recurso string, recursoID, subidoPor int, role models.Role) (*models.ArchivoMetadata, error) {
+	hash, size, err := storage.SaveContent(s.store, r)
+	if err != nil {
+		return nil, fmt.Errorf("error guardando el archivo: %v", err)
+	}
+
+	archivo := &models.ArchivoMetadata{
+		SHA256:               hash,
+		Size:                 size,
+		ContentType:          detectado,
+		ContentTypeDeclarado: declarado,
+		OriginalName:         path.Base(filepath.ToSlash(nombre)),
+		RecursoType:          recurso,
+		RecursoID:            recursoID,
+		SubidoPor:            subidoPor,
+		SubidoPorRole:        role,
+	}
+	if err := s.db.Create(archivo).Error; err != nil {
+		return nil, err
+	}
+	return archivo, nil
+}
+
+// peekHead lee los primeros bytes para detectar el tipo sin consumirlos del reader devuelto
+func peekHead(r io.Reader) (io.Reader, []byte, error) {
+	br := bufio.NewReaderSize(r, upload.SniffLen)
+	head, err := br.Peek(upload.SniffLen)
+	if err != nil && err != io.EOF {
+		return nil, nil, err
+	}
+	return br, head, nil
+}
+
+// ValidarParaTp controla que un archivo ya subido cumpla las restricciones del TP al entregarlo.
+// Si el TP restringe los archivos, la archivo_url tiene que ser un archivo subido al sistema.
+func (s *ArchivoService) ValidarParaTp(archivoURL string, tp *models.TpModel) error {
+	if tp.MaxTamanoMB == 0 && tp.ExtensionesPermitidas == "" {
+		return nil
+	}
+	archivo, err := s.GetArchivoByURL(archivoURL)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &upload.Error{Code: upload.CodeArchivoNoDisponible, Message: "El archivo entregado debe subirse al sistema para validar las restricciones del TP"}
+		}
+		return err
+	}
+	return upload.ValidarTamanoYExtension(archivo.OriginalName, archivo.Size, s.ReglasTp(tp))
+}
+
+// ErrArchivoAjeno indica que se quiso entregar un archivo subido por otra persona
+var ErrArchivoAjeno = errors.New("el archivo no fue subido por el alumno ni por su grupo")
+
+// ValidarAutoria controla que un archivo del sistema entregado en el TP sea de
+// los alumnos indicados (quien entrega y su grupo): si todavía no está vinculado
+// tiene que haberlo subido uno de ellos, y si ya lo está tiene que ser a una de
+// sus entregas de ese TP. Las URLs externas no se controlan.
+func (s *ArchivoService) ValidarAutoria(archivoURL string, tp *models.TpModel, alumnoIDs []int) error {
+	id, ok := models.ArchivoIDFromURL(archivoURL)
+	if !ok {
+		return nil
+	}
+	archivo, err := s.GetArchivo(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrArchivoAjeno
+		}
+		return err
+	}
+	if archivo.RecursoType != models.RecursoEntregaTP {
+		return ErrArchivoAjeno
+	}
+	if archivo.RecursoID == 0 {
+		if archivo.SubidoPorRole != models.RoleAlumno || !slices.Contains(alumnoIDs, archivo.SubidoPor) {
+			return ErrArchivoAjeno
+		}
+		return nil
+	}
+	var count int64
+	if err := s.db.Model(&models.EntregaTP{}).
+		Where("id = ? AND tp_id = ? AND alumno_id IN ?", archivo.RecursoID, tp.ID, alumnoIDs).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrArchivoAjeno
+	}
+	return nil
+}
+
+// Vigencia de las URLs de descarga firmadas
+const DownloadURLTTL = 5 * time.Minute
+
+// URLFirmada devuelve una URL de descarga que no requiere autenticación y vence
+// a los DownloadURLTTL (para links directos, reproductores o visores externos)
+func (s *ArchivoService) URLFirmada(archivo *models.ArchivoMetadata) (string, time.Time) {
+	expira := time.Now().Add(DownloadURLTTL).Truncate(time.Second)
+	expires := strconv.FormatInt(expira.Unix(), 10)
+	query := url.Values{"expires": {expires}, "signature": {firmarDescarga(archivo.ID, expires)}}
+	return archivo.URL + "?" + query.Encode(), expira
+}
+
+// VerificarFirma controla la firma y el vencimiento de una URL de descarga firmada
+func (s *ArchivoService) VerificarFirma(id int, expires, signature string) bool {
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > unix {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(firmarDescarga(id, expires)))
+}
+
+// firmarDescarga firma "id:expires" con una clave derivada de SECRET_KEY
+func firmarDescarga(id int, expires string) string {
+	key := hmac.New(sha256.New, []byte(middleware.GetSecretKey()))
+	key.Write([]byte("archivos-descarga"))
+	mac := hmac.New(sha256.New, key.Sum(nil))
+	mac.Write([]byte(strconv.Itoa(id) + ":" + expires))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (s *ArchivoService) GetArchivo(id int) (*models.ArchivoMetadata, error) {
+	var archivo models.ArchivoMetadata
+	if err := s.db.First(&archivo, id).Error; err != nil {
+		return nil, err
+	}
+	return &archivo, nil
+}
+
+// GetArchivoByURL resuelve una archivo_url ("/archivos/:id") a su metadata
+func (s *ArchivoService) GetArchivoByURL(url string) (*models.ArchivoMetadata, error) {
+	id, ok := models.ArchivoIDFromURL(url)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return s.GetArchivo(id)
+}
+
+func (s *ArchivoService) GetArchivosDe(recurso string, recursoID int) ([]models.ArchivoMetadata, error) {
+	var archivos []models.ArchivoMetadata
+	if err := s.db.Where("recurso_type = ? AND recurso_id = ?", recurso, recursoID).Order("created_at, id").Find(&archivos).Error; err != nil {
+		return nil, err
+	}
+	return archivos, nil
+}
+
+// Abrir devuelve el contenido del archivo; quien llama debe cerrarlo
+func (s *ArchivoService) Abrir(archivo *models.ArchivoMetadata) (io.ReadCloser, error) {
+	return s.store.Get(storage.KeyFor(archivo.SHA256))
+}
+
+// AbrirRango devuelve length bytes del archivo a partir de offset; quien llama debe cerrarlo
+func (s *ArchivoService) AbrirRango(archivo *models.ArchivoMetadata, offset, length int64) (io.ReadCloser, error) {
+	return s.store.GetRange(storage.KeyFor(archivo.SHA256), offset, length)
+}
+
+// Vincular asocia un archivo subido antes de que existiera su recurso (ej: la
+// entrega de un TP); solo se vinculan archivos subidos por alguno de subidoPor
+func (s *ArchivoService) Vincular(tx *gorm.DB, archivoURL, recurso string, recursoID int, subidoPor []int) error {
+	id, ok := models.ArchivoIDFromURL(archivoURL)
+	if !ok {
+		return nil
+	}
+	return tx.Model(&models.ArchivoMetadata{}).
+		Where("id = ? AND recurso_type = ? AND recurso_id = 0 AND subido_por IN ?", id, recurso, subidoPor).
+		Update("recurso_id", recursoID).Error
+}
+
+// Eliminar borra la metadata y, si ningún otro archivo comparte el contenido, el blob
+func (s *ArchivoService) Eliminar(id int) error {
+	archivo, err := s.GetArchivo(id)
+	if err != nil {
+		return err
+	}
+	if err := s.db.Delete(archivo).Error; err != nil {
+		return err
+	}
+	s.eliminarBlobSiHuerfano(archivo.SHA256)
+	return nil
+}
+
+// EliminarDe borra todos los archivos de un recurso
+func (s *ArchivoService) EliminarDe(recurso string, recursoID int) error {
+	archivos, err := s.GetArchivosDe(recurso, recursoID)
+	if err != nil {
+		return err
+	}
+	for _, archivo := range archivos {
+		if err := s.Eliminar(archivo.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *ArchivoService) eliminarBlobSiHuerfano(hash string) {
+	var count int64
+	if err := s.db.Model(&models.ArchivoMetadata{}).Where("sha256 = ?", hash).Count(&count).Error; err != nil || count > 0 {
+		return
+	}
+	if err := s.store.Delete(storage.KeyFor(hash)); err != nil {
+		log.Printf("Warning: could not delete blob %s: %v", hash, err)
+	}
+}
+
+// MigrateArchivos pasa al Storage los archivos guardados antes de la capa de
+// storage: las tablas anexo_archivos y archivos (que quedan renombradas con
+// sufijo _migrados) y las archivo_url "/uploads/..." de entregas y evaluaciones.
+// Es idempotente.
+func MigrateArchivos(db *gorm.DB, archivos *ArchivoService) error {
+	legacy := []struct {
+		tabla   string
+		columna string
+		recurso string
+	}{
+		{"anexo_archivos", "anexo_id", models.RecursoAnexo},
+		{"archivos", "entrega_id", models.RecursoEntrega},
+	}
+	for _, l := range legacy {
+		if !db.Migrator().HasTable(l.tabla) || !db.Migrator().HasColumn(l.tabla, "file_path") {
+			continue
+		}
+		if err := migrateTablaArchivos(db, archivos, l.tabla, l.columna, l.recurso); err != nil {
+			return err
+		}
+	}
+
+	// Una misma URL puede aparecer en la entrega y en sus intentos: se sube una sola vez
+	migradas := map[string]*models.ArchivoMetadata{}
+	urls := []struct {
+		tabla   string
+		columna string
+		recurso string
+	}{
+		{"entregas_tp", "id", models.RecursoEntregaTP},
+		{"intentos_entrega_tp", "entrega_id", models.RecursoEntregaTP},
+		{"entrega_evaluaciones", "id", models.RecursoEvaluacion},
+	}
+	for _, u := range urls {
+		if !db.Migrator().HasTable(u.tabla) {
+			continue
+		}
+		if err := migrateArchivoURLs(db, archivos, u.tabla, u.columna, u.recurso, migradas); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func migrateTablaArchivos(db *gorm.DB, archivos *ArchivoService, tabla, columna, recurso string) error {
+	var rows []struct {
+		RecursoID    int
+		OriginalName string
+		FilePath     string
+		ContentType  string
+		CreatedAt    time.Time
+	}
+	if err := db.Table(tabla).
+		Select(columna + " AS recurso_id, original_name, file_path, content_type, created_at").
+		Order("id").Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		file, err := os.Open(row.FilePath)
+		if err != nil {
+			log.Printf("Warning: archivo %s no encontrado, no se migra: %v", row.FilePath, err)
+			continue
+		}
+		archivo, err := archivos.Guardar(file, row.OriginalName, row.ContentType, recurso, row.RecursoID, 0, "")
+		file.Close()
+		if err != nil {
+			return err
+		}
+		db.Model(archivo).Update("created_at", row.CreatedAt)
+	}
+
+	log.Printf("Migrados %d archivos de %s al storage", len(rows), tabla)
+	return db.Migrator().RenameTable(tabla, tabla+"_migrados")
+}
+
+// migrateArchivoURLs reemplaza cada archivo_url "/uploads/..." por la URL de su archivo en el storage
+func migrateArchivoURLs(db *gorm.DB, archivos *ArchivoService, tabla, columna, recurso string, migradas map[string]*models.ArchivoMetadata) error {
+	var rows []struct {
+		RecursoID  int
+		ArchivoURL string
+	}
+	if err := db.Table(tabla).
+		Select(columna+" AS recurso_id, archivo_url").
+		Where("archivo_url LIKE ?", "/uploads/%").
+		Order("id").Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		archivo, ok := migradas[row.ArchivoURL]
+		if !ok {
+			localPath, valid := legacyUploadPath(row.ArchivoURL)
+			if !valid {
+				continue
+			}
+			file, err := os.Open(localPath)
+			if err != nil {
+				log.Printf("Warning: archivo %s no encontrado, no se migra: %v", localPath, err)
+				continue
+			}
+			archivo, err = archivos.Guardar(file, path.Base(localPath), "", recurso, row.RecursoID, 0, "")
+			file.Close()
+			if err != nil {
+				return err
+			}
+			migradas[row.ArchivoURL] = archivo
+		}
+		if err := db.Table(tabla).Where("archivo_url = ?", row.ArchivoURL).Update("archivo_url", archivo.URL).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// legacyUploadPath traduce "/uploads/..." a la ruta local, sin permitir salir del directorio
+func legacyUploadPath(archivoURL string) (string, bool) {
+	clean := path.Clean(archivoURL)
+	if !strings.HasPrefix(clean, "/uploads/") {
+		return "", false
+	}
+	return filepath.FromSlash(strings.TrimPrefix(clean, "/")), true
+}
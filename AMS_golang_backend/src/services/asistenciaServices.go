@@ -0,0 +1,520 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/LINSITrack/backend/src/models"
+	"github.com/LINSITrack/backend/utils/horario"
+	"gorm.io/gorm"
+)
+
+var ErrClaseDuplicada = errors.New("la comisión ya tiene una clase que empieza en ese horario")
+
+const (
+	// Asistencia mínima para regularizar si la materia no tiene reglas de condición
+	asistenciaRegularPorDefecto = 75
+	// Se avisa al alumno cuando le quedan estas faltas (o menos) antes del límite
+	avisoFaltasRestantes = 2
+)
+
+// AsistenciaService administra las clases de cada comisión y la asistencia de
+// sus alumnos. El porcentaje de asistencia se usa en las reglas de condición.
+type AsistenciaService struct {
+	db *gorm.DB
+}
+
+func NewAsistenciaService(db *gorm.DB) *AsistenciaService {
+	return &AsistenciaService{db: db}
+}
+
+// GenerarClases crea, según las franjas vigentes de la comisión, las clases
+// entre las dos fechas que todavía no existan
+func (s *AsistenciaService) GenerarClases(comisionID int, req *models.GenerarClasesRequest) ([]models.Clase, error) {
+	desde, err := time.ParseInLocation("2006-01-02", req.Desde, time.Local)
+	if err != nil {
+		return nil, errors.New("fecha desde inválida (AAAA-MM-DD)")
+	}
+	hasta, err := time.ParseInLocation("2006-01-02", req.Hasta, time.Local)
+	if err != nil {
+		return nil, errors.New("fecha hasta inválida (AAAA-MM-DD)")
+	}
+	if hasta.Before(desde) {
+		return nil, errors.New("la fecha hasta no puede ser anterior a desde")
+	}
+	if hasta.After(desde.AddDate(1, 0, 0)) {
+		return nil, errors.New("no se pueden generar clases para más de un año")
+	}
+
+	var comision models.Comision
+	if err := s.db.First(&comision, comisionID).Error; err != nil {
+		return nil, err
+	}
+	franjas, err := franjasDeComision(s.db, comisionID)
+	if err != nil {
+		return nil, err
+	}
+	if len(franjas) == 0 {
+		return nil, errors.New("la comisión no tiene franjas horarias cargadas")
+	}
+
+	creadas := []models.Clase{}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		for dia := desde; !dia.After(hasta); dia = dia.AddDate(0, 0, 1) {
+			for _, franja := range franjas {
+				if time.Weekday(franja.Dia) != dia.Weekday() || !franja.VigenteEn(dia) {
+					continue
+				}
+				horaInicio, _ := horario.ParseHora(franja.HoraInicio)
+				horaFin, _ := horario.ParseHora(franja.HoraFin)
+				inicio, fin := horario.Franja{Dia: dia.Weekday(), Inicio: horaInicio, Fin: horaFin}.En(dia)
+				var existentes int64
+				if err := tx.Model(&models.Clase{}).Where("comision_id = ? AND inicio = ?", comisionID, inicio).Count(&existentes).Error; err != nil {
+					return err
+				}
+				if existentes > 0 {
+					continue
+				}
+				clase := models.Clase{ComisionId: comisionID, Inicio: inicio, Fin: fin}
+				if err := tx.Create(&clase).Error; err != nil {
+					return err
+				}
+				creadas = append(creadas, clase)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return creadas, nil
+}
+
+// GetClases devuelve las clases de la comisión en orden cronológico (opcionalmente de un año lectivo)
+func (s *AsistenciaService) GetClases(comisionID int, anoLectivo *int) ([]models.Clase, error) {
+	if err := s.db.Select("id").First(&models.Comision{}, comisionID).Error; err != nil {
+		return nil, err
+	}
+	query := s.db.Where("comision_id = ?", comisionID).Order("inicio, id")
+	if anoLectivo != nil {
+		query = query.Where("EXTRACT(YEAR FROM inicio) = ?", *anoLectivo)
+	}
+	clases := []models.Clase{}
+	if err := query.Find(&clases).Error; err != nil {
+		return nil, err
+	}
+	return clases, nil
+}
+
+// CrearClase agrega una clase fuera de los horarios habituales (por ejemplo, una clase de consulta)
+func (s *AsistenciaService) CrearClase(comisionID int, req *models.ClaseRequest) (*models.Clase, error) {
+	if !req.Fin.After(req.Inicio) {
+		return nil, errors.New("la clase debe terminar después de empezar")
+	}
+	if err := s.db.Select("id").First(&models.Comision{}, comisionID).Error; err != nil {
+		return nil, err
+	}
+	if err := s.validarInicioLibre(comisionID, req.Inicio, 0); err != nil {
+		return nil, err
+	}
+	clase := models.Clase{ComisionId: comisionID, Inicio: req.Inicio, Fin: req.Fin, Tema: req.Tema}
+	if err := s.db.Create(&clase).Error; err != nil {
+		return nil, err
+	}
+	return &clase, nil
+}
+
+func (s *AsistenciaService) UpdateClase(id int, req *models.ClaseUpdateRequest) (*models.Clase, error) {
+	var clase models.Clase
+	if err := s.db.First(&clase, id).Error; err != nil {
+		return nil, err
+	}
+	if req.Inicio != nil && !req.Inicio.Equal(clase.Inicio) {
+		if err := s.validarInicioLibre(clase.ComisionId, *req.Inicio, clase.ID); err != nil {
+			return nil, err
+		}
+		clase.Inicio = *req.Inicio
+	}
+	if req.Fin != nil {
+		clase.Fin = *req.Fin
+	}
+	if req.Tema != nil {
+		clase.Tema = *req.Tema
+	}
+	if !clase.Fin.After(clase.Inicio) {
+		return nil, errors.New("la clase debe terminar después de empezar")
+	}
+	if err := s.db.Save(&clase).Error; err != nil {
+		return nil, err
+	}
+	return &clase, nil
+}
+
+func (s *AsistenciaService) validarInicioLibre(comisionID int, inicio time.Time, excepto int) error {
+	var existentes int64
+	if err := s.db.Model(&models.Clase{}).Where("comision_id = ? AND inicio = ? AND id <> ?", comisionID, inicio, excepto).Count(&existentes).Error; err != nil {
+		return err
+	}
+	if existentes > 0 {
+		return ErrClaseDuplicada
+	}
+	return nil
+}
+
+// DeleteClase elimina la clase junto con su asistencia (por ejemplo, un feriado)
+func (s *AsistenciaService) DeleteClase(id int) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var clase models.Clase
+		if err := tx.First(&clase, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("clase_id = ?", id).Delete(&models.Asistencia{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&clase).Error
+	})
+}
+
+// GetPlanilla devuelve la clase con la asistencia de cada alumno inscripto en
+// la comisión ese año lectivo
+func (s *AsistenciaService) GetPlanilla(claseID int) (*models.PlanillaAsistencia, error) {
+	var clase models.Clase
+	if err := s.db.First(&clase, claseID).Error; err != nil {
+		return nil, err
+	}
+	cursadas, err := cursadasDeClase(s.db, &clase)
+	if err != nil {
+		return nil, err
+	}
+	var registros []models.Asistencia
+	if err := s.db.Where("clase_id = ?", claseID).Find(&registros).Error; err != nil {
+		return nil, err
+	}
+	registroDe := map[int]models.Asistencia{}
+	for _, registro := range registros {
+		registroDe[registro.AlumnoId] = registro
+	}
+
+	planilla := &models.PlanillaAsistencia{Clase: clase, Alumnos: make([]models.AsistenciaAlumno, 0, len(cursadas))}
+	for _, cursada := range cursadas {
+		fila := models.AsistenciaAlumno{
+			CursadaId: cursada.ID,
+			AlumnoId:  cursada.AlumnoID,
+			Legajo:    cursada.Alumno.Legajo,
+			Apellido:  cursada.Alumno.Apellido,
+			Nombre:    cursada.Alumno.Nombre,
+		}
+		if registro, ok := registroDe[cursada.AlumnoID]; ok {
+			fila.Estado = registro.Estado
+			fila.Observaciones = registro.Observaciones
+		} else if clase.AsistenciaTomada {
+			fila.Estado = models.AsistenciaAusente
+		}
+		planilla.Alumnos = append(planilla.Alumnos, fila)
+	}
+	return planilla, nil
+}
+
+// TomarAsistencia registra la asistencia de la clase. Avisa a los alumnos que
+// quedaron ausentes si se acercan al límite de faltas o lo superan.
+func (s *AsistenciaService) TomarAsistencia(claseID int, req *models.TomarAsistenciaRequest, actorID int, role models.Role) (*models.PlanillaAsistencia, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var clase models.Clase
+		if err := tx.First(&clase, claseID).Error; err != nil {
+			return err
+		}
+		cursadas, err := cursadasDeClase(tx, &clase)
+		if err != nil {
+			return err
+		}
+		cursadaDe := map[int]models.Cursada{}
+		for _, cursada := range cursadas {
+			cursadaDe[cursada.AlumnoID] = cursada
+		}
+
+		var registros []models.Asistencia
+		if err := tx.Where("clase_id = ?", claseID).Find(&registros).Error; err != nil {
+			return err
+		}
+		registroDe := map[int]models.Asistencia{}
+		for _, registro := range registros {
+			registroDe[registro.AlumnoId] = registro
+		}
+
+		incluidos := map[int]bool{}
+		for _, item := range req.Asistencias {
+			if !item.Estado.IsValid() {
+				return fmt.Errorf("estado inválido para el alumno %d (presente, ausente, tarde o justificado)", item.AlumnoId)
+			}
+			if _, ok := cursadaDe[item.AlumnoId]; !ok {
+				return fmt.Errorf("el alumno %d no cursa en la comisión ese año", item.AlumnoId)
+			}
+			if incluidos[item.AlumnoId] {
+				return fmt.Errorf("el alumno %d figura más de una vez", item.AlumnoId)
+			}
+			incluidos[item.AlumnoId] = true
+		}
+
+		// Quienes pasan a estar ausentes: los marcados ahora y, la primera vez que
+		// se toma asistencia, los que no figuran
+		var nuevosAusentes []models.Cursada
+		for _, item := range req.Asistencias {
+			anterior, existe := registroDe[item.AlumnoId]
+			eraAusente := (existe && anterior.Estado == models.AsistenciaAusente) || (!existe && clase.AsistenciaTomada)
+
+			registro := models.Asistencia{
+				ID:            anterior.ID,
+				ClaseId:       claseID,
+				AlumnoId:      item.AlumnoId,
+				Estado:        item.Estado,
+				Observaciones: item.Observaciones,
+				ActorID:       actorID,
+				ActorRole:     role,
+			}
+			if err := tx.Save(&registro).Error; err != nil {
+				return err
+			}
+			if item.Estado == models.AsistenciaAusente && !eraAusente {
+				nuevosAusentes = append(nuevosAusentes, cursadaDe[item.AlumnoId])
+			}
+		}
+		if !clase.AsistenciaTomada {
+			for _, cursada := range cursadas {
+				if _, existe := registroDe[cursada.AlumnoID]; !existe && !incluidos[cursada.AlumnoID] {
+					nuevosAusentes = append(nuevosAusentes, cursada)
+				}
+			}
+			if err := tx.Model(&clase).Update("asistencia_tomada", true).Error; err != nil {
+				return err
+			}
+		}
+
+		resumenes, err := resumenesAsistencia(tx, nuevosAusentes)
+		if err != nil {
+			return err
+		}
+		for i := range nuevosAusentes {
+			if err := notificarFaltas(tx, &nuevosAusentes[i], resumenes[nuevosAusentes[i].ID]); err != nil {
+				return err
+			}
+		}
+
+		// La asistencia entra en la condición de cada alumno de la clase
+		alumnos := make([]int, len(cursadas))
+		for i, cursada := range cursadas {
+			alumnos[i] = cursada.AlumnoID
+		}
+		return RecalcularCursadas(tx, clase.ComisionId, alumnos)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetPlanilla(claseID)
+}
+
+// GetAsistenciaCursada devuelve el resumen de asistencia de la cursada y su registro en cada clase
+func (s *AsistenciaService) GetAsistenciaCursada(cursadaID int) (*models.AsistenciaCursada, error) {
+	var cursada models.Cursada
+	if err := s.db.First(&cursada, cursadaID).Error; err != nil {
+		return nil, err
+	}
+	resumenes, err := resumenesAsistencia(s.db, []models.Cursada{cursada})
+	if err != nil {
+		return nil, err
+	}
+	clases, err := clasesDelAno(s.db, cursada.ComisionID, cursada.AnoLectivo)
+	if err != nil {
+		return nil, err
+	}
+	var registros []models.Asistencia
+	if err := s.db.Joins("JOIN clases ON clases.id = asistencias.clase_id").
+		Where("clases.comision_id = ? AND asistencias.alumno_id = ?", cursada.ComisionID, cursada.AlumnoID).
+		Find(&registros).Error; err != nil {
+		return nil, err
+	}
+	registroDe := map[int]models.Asistencia{}
+	for _, registro := range registros {
+		registroDe[registro.ClaseId] = registro
+	}
+
+	asistencia := &models.AsistenciaCursada{Resumen: *resumenes[cursada.ID], Clases: make([]models.RegistroAsistenciaCursada, 0, len(clases))}
+	for _, clase := range clases {
+		fila := models.RegistroAsistenciaCursada{Clase: clase}
+		if registro, ok := registroDe[clase.ID]; ok {
+			fila.Estado = registro.Estado
+			fila.Observaciones = registro.Observaciones
+		} else if clase.AsistenciaTomada {
+			fila.Estado = models.AsistenciaAusente
+		}
+		asistencia.Clases = append(asistencia.Clases, fila)
+	}
+	return asistencia, nil
+}
+
+// cursadasDeClase devuelve las cursadas de la comisión en el año de la clase, con su alumno
+func cursadasDeClase(db *gorm.DB, clase *models.Clase) ([]models.Cursada, error) {
+	var cursadas []models.Cursada
+	err := db.Preload("Alumno").
+		Joins("JOIN alumnos ON alumnos.id = cursadas.alumno_id").
+		Where("cursadas.comision_id = ? AND cursadas.ano_lectivo = ?", clase.ComisionId, clase.Inicio.Year()).
+		Order("alumnos.apellido, alumnos.nombre, alumnos.legajo").
+		Find(&cursadas).Error
+	if err != nil {
+		return nil, err
+	}
+	return cursadas, nil
+}
+
+func clasesDelAno(db *gorm.DB, comisionID, anoLectivo int) ([]models.Clase, error) {
+	var clases []models.Clase
+	err := db.Where("comision_id = ? AND EXTRACT(YEAR FROM inicio) = ?", comisionID, anoLectivo).
+		Order("inicio, id").Find(&clases).Error
+	if err != nil {
+		return nil, err
+	}
+	return clases, nil
+}
+
+// asistenciaMinima es la asistencia para regularizar según las reglas de la materia
+func asistenciaMinima(db *gorm.DB, comisionID int) (float64, error) {
+	var comision models.Comision
+	if err := db.Select("id", "materia_id").First(&comision, comisionID).Error; err != nil {
+		return 0, err
+	}
+	reglas, err := reglasDeMateria(db, comision.MateriaId)
+	if err != nil {
+		return 0, err
+	}
+	if reglas == nil {
+		return asistenciaRegularPorDefecto, nil
+	}
+	return reglas.AsistenciaRegular, nil
+}
+
+// resumenesAsistencia calcula la asistencia de cada cursada (indexada por ID),
+// con las clases de su comisión en su año lectivo
+func resumenesAsistencia(db *gorm.DB, cursadas []models.Cursada) (map[int]*models.ResumenAsistencia, error) {
+	type grupo struct{ comisionID, ano int }
+	porGrupo := map[grupo][]models.Cursada{}
+	for _, cursada := range cursadas {
+		g := grupo{cursada.ComisionID, cursada.AnoLectivo}
+		porGrupo[g] = append(porGrupo[g], cursada)
+	}
+
+	resumenes := make(map[int]*models.ResumenAsistencia, len(cursadas))
+	for g, delGrupo := range porGrupo {
+		clases, err := clasesDelAno(db, g.comisionID, g.ano)
+		if err != nil {
+			return nil, err
+		}
+		minimo, err := asistenciaMinima(db, g.comisionID)
+		if err != nil {
+			return nil, err
+		}
+
+		estados := map[notaKey]models.EstadoAsistencia{}
+		if len(clases) > 0 {
+			claseIDs := make([]int, len(clases))
+			for i, clase := range clases {
+				claseIDs[i] = clase.ID
+			}
+			alumnoIDs := make([]int, len(delGrupo))
+			for i, cursada := range delGrupo {
+				alumnoIDs[i] = cursada.AlumnoID
+			}
+			var registros []models.Asistencia
+			if err := db.Select("clase_id", "alumno_id", "estado").
+				Where("clase_id IN ? AND alumno_id IN ?", claseIDs, alumnoIDs).Find(&registros).Error; err != nil {
+				return nil, err
+			}
+			for _, registro := range registros {
+				estados[notaKey{registro.ClaseId, registro.AlumnoId}] = registro.Estado
+			}
+		}
+
+		for _, cursada := range delGrupo {
+			resumen := resumirAsistencia(clases, estados, cursada.AlumnoID, minimo)
+			resumen.CursadaId = cursada.ID
+			resumenes[cursada.ID] = &resumen
+		}
+	}
+	return resumenes, nil
+}
+
+// resumirAsistencia cuenta la asistencia de un alumno en las clases con
+// asistencia tomada. Las faltas permitidas salen del total de clases
+// programadas (sin las justificadas) y de la asistencia mínima.
+func resumirAsistencia(clases []models.Clase, estados map[notaKey]models.EstadoAsistencia, alumnoID int, minimo float64) models.ResumenAsistencia {
+	resumen := models.ResumenAsistencia{ClasesProgramadas: len(clases), AsistenciaMinima: minimo}
+	for _, clase := range clases {
+		if !clase.AsistenciaTomada {
+			continue
+		}
+		resumen.ClasesDictadas++
+		switch estados[notaKey{clase.ID, alumnoID}] {
+		case models.AsistenciaPresente:
+			resumen.Presentes++
+		case models.AsistenciaTarde:
+			resumen.Tardes++
+		case models.AsistenciaJustificado:
+			resumen.Justificadas++
+		default:
+			resumen.Ausentes++
+		}
+	}
+
+	if computables := resumen.ClasesDictadas - resumen.Justificadas; computables > 0 {
+		porcentaje := math.Round(float64(resumen.Presentes+resumen.Tardes)*10000/float64(computables)) / 100
+		resumen.Porcentaje = &porcentaje
+	}
+	if minimo > 0 {
+		permitidas := int(math.Floor(float64(resumen.ClasesProgramadas-resumen.Justificadas)*(100-minimo)/100 + 1e-9))
+		restantes := permitidas - resumen.Ausentes
+		resumen.FaltasPermitidas = &permitidas
+		resumen.FaltasRestantes = &restantes
+	}
+	return resumen
+}
+
+// notificarFaltas avisa al alumno que acaba de quedar ausente si se acerca al
+// límite de faltas, llegó a él o lo acaba de superar
+func notificarFaltas(tx *gorm.DB, cursada *models.Cursada, resumen *models.ResumenAsistencia) error {
+	if resumen == nil || resumen.FaltasRestantes == nil {
+		return nil
+	}
+	restantes := *resumen.FaltasRestantes
+	if restantes > avisoFaltasRestantes || restantes < -1 {
+		return nil
+	}
+
+	var comision models.Comision
+	if err := tx.Preload("Materia").First(&comision, cursada.ComisionID).Error; err != nil {
+		return err
+	}
+	materiaNombre := comision.Nombre
+	if comision.Materia.Nombre != "" {
+		materiaNombre = comision.Materia.Nombre
+	}
+
+	var mensaje string
+	switch {
+	case restantes < 0:
+		mensaje = fmt.Sprintf("Superaste el límite de faltas en %s: no alcanzás la asistencia mínima del %.0f%%", materiaNombre, resumen.AsistenciaMinima)
+	case restantes == 0:
+		mensaje = fmt.Sprintf("Llegaste al límite de faltas en %s: con una falta más no alcanzás la asistencia mínima del %.0f%%", materiaNombre, resumen.AsistenciaMinima)
+	case restantes == 1:
+		mensaje = fmt.Sprintf("Te queda 1 falta en %s antes de no alcanzar la asistencia mínima del %.0f%%", materiaNombre, resumen.AsistenciaMinima)
+	default:
+		mensaje = fmt.Sprintf("Te quedan %d faltas en %s antes de no alcanzar la asistencia mínima del %.0f%%", restantes, materiaNombre, resumen.AsistenciaMinima)
+	}
+
+	notificacion := models.Notificacion{
+		Mensaje:   mensaje,
+		FechaHora: time.Now(),
+		Leida:     false,
+		AlumnoID:  cursada.AlumnoID,
+	}
+	return tx.Create(&notificacion).Error
+}
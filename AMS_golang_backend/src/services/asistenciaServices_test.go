@@ -0,0 +1,125 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/LINSITrack/backend/src/models"
+)
+
+func TestResumirAsistencia(t *testing.T) {
+	const alumnoID = 1
+	// clases devuelve n clases programadas; las primeras tomadas tienen la asistencia tomada
+	clases := func(n, tomadas int) []models.Clase {
+		lista := make([]models.Clase, n)
+		for i := range lista {
+			lista[i] = models.Clase{ID: i + 1, AsistenciaTomada: i < tomadas}
+		}
+		return lista
+	}
+	estados := func(lista ...models.EstadoAsistencia) map[notaKey]models.EstadoAsistencia {
+		m := map[notaKey]models.EstadoAsistencia{}
+		for i, estado := range lista {
+			if estado != "" {
+				m[notaKey{i + 1, alumnoID}] = estado
+			}
+		}
+		return m
+	}
+	entero := func(v int) *int {
+		return &v
+	}
+
+	tests := []struct {
+		name           string
+		clases         []models.Clase
+		estados        map[notaKey]models.EstadoAsistencia
+		minimo         float64
+		want           models.ResumenAsistencia
+		wantPorcentaje *float64
+		wantPermitidas *int
+		wantRestantes  *int
+	}{
+		{
+			name:   "sin clases tomadas",
+			clases: clases(10, 0),
+			minimo: 75,
+			want:   models.ResumenAsistencia{ClasesProgramadas: 10, AsistenciaMinima: 75},
+			// 25% de 10 clases
+			wantPermitidas: entero(2),
+			wantRestantes:  entero(2),
+		},
+		{
+			name:           "tarde cuenta como presente y justificada no computa",
+			clases:         clases(10, 4),
+			estados:        estados(models.AsistenciaPresente, models.AsistenciaTarde, models.AsistenciaJustificado, models.AsistenciaAusente),
+			minimo:         75,
+			want:           models.ResumenAsistencia{ClasesProgramadas: 10, ClasesDictadas: 4, Presentes: 1, Tardes: 1, Justificadas: 1, Ausentes: 1, AsistenciaMinima: 75},
+			wantPorcentaje: nota(66.67),
+			wantPermitidas: entero(2),
+			wantRestantes:  entero(1),
+		},
+		{
+			name:           "sin registro cuenta como ausente",
+			clases:         clases(8, 2),
+			estados:        estados(models.AsistenciaPresente),
+			minimo:         75,
+			want:           models.ResumenAsistencia{ClasesProgramadas: 8, ClasesDictadas: 2, Presentes: 1, Ausentes: 1, AsistenciaMinima: 75},
+			wantPorcentaje: nota(50),
+			wantPermitidas: entero(2),
+			wantRestantes:  entero(1),
+		},
+		{
+			name:           "faltas justo en el límite",
+			clases:         clases(8, 4),
+			estados:        estados(models.AsistenciaPresente, models.AsistenciaPresente, models.AsistenciaAusente, models.AsistenciaAusente),
+			minimo:         75,
+			want:           models.ResumenAsistencia{ClasesProgramadas: 8, ClasesDictadas: 4, Presentes: 2, Ausentes: 2, AsistenciaMinima: 75},
+			wantPorcentaje: nota(50),
+			wantPermitidas: entero(2),
+			wantRestantes:  entero(0),
+		},
+		{
+			name:    "todas justificadas",
+			clases:  clases(4, 2),
+			estados: estados(models.AsistenciaJustificado, models.AsistenciaJustificado),
+			minimo:  75,
+			want:    models.ResumenAsistencia{ClasesProgramadas: 4, ClasesDictadas: 2, Justificadas: 2, AsistenciaMinima: 75},
+			// 25% de las 2 clases que quedan sin las justificadas
+			wantPermitidas: entero(0),
+			wantRestantes:  entero(0),
+		},
+		{
+			name:           "sin asistencia mínima no hay faltas permitidas",
+			clases:         clases(4, 1),
+			estados:        estados(models.AsistenciaPresente),
+			want:           models.ResumenAsistencia{ClasesProgramadas: 4, ClasesDictadas: 1, Presentes: 1},
+			wantPorcentaje: nota(100),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resumen := resumirAsistencia(tt.clases, tt.estados, alumnoID, tt.minimo)
+			if !mismaNota(resumen.Porcentaje, tt.wantPorcentaje) {
+				t.Errorf("Porcentaje = %v, want %v", valorNota(resumen.Porcentaje), valorNota(tt.wantPorcentaje))
+			}
+			if !mismoEntero(resumen.FaltasPermitidas, tt.wantPermitidas) {
+				t.Errorf("FaltasPermitidas = %v, want %v", resumen.FaltasPermitidas, tt.wantPermitidas)
+			}
+			if !mismoEntero(resumen.FaltasRestantes, tt.wantRestantes) {
+				t.Errorf("FaltasRestantes = %v, want %v", resumen.FaltasRestantes, tt.wantRestantes)
+			}
+			resumen.Porcentaje, resumen.FaltasPermitidas, resumen.FaltasRestantes = nil, nil, nil
+			if resumen != tt.want {
+				t.Errorf("resumen = %+v, want %+v", resumen, tt.want)
+			}
+		})
+	}
+}
+
+func mismoEntero(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
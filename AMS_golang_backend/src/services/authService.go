@@ -1,6 +1,10 @@
 package services
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"time"
 
@@ -11,6 +15,18 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 7 * 24 * time.Hour
+)
+
+var (
+	ErrInvalidCredentials  = errors.New("credenciales inválidas")
+	ErrRoleNotAssigned     = errors.New("el usuario no tiene el rol solicitado")
+	ErrInvalidRefreshToken = errors.New("refresh token inválido o expirado")
+	ErrSessionNotFound     = errors.New("sesión no encontrada")
+)
+
 type AuthService struct {
 	db *gorm.DB
 }
@@ -19,44 +35,256 @@ func NewAuthService(db *gorm.DB) *AuthService {
 	return &AuthService{db: db}
 }
 
-func (s *AuthService) Login(email, password string) (string, error) {
-	// Intentar login como profesor primero
-	var profesor models.Profesor
-	err := s.db.Where("email = ?", email).First(&profesor).Error
+// RoleSelectionError indica que el usuario tiene varios roles y debe elegir uno
+type RoleSelectionError struct {
+	Roles []models.Role
+}
+
+func (e *RoleSelectionError) Error() string {
+	return "el usuario tiene varios roles, debe indicar con cuál ingresar"
+}
+
+// tokenSubject son los datos del usuario que viajan en los claims del access token
+type tokenSubject struct {
+	UsuarioID int
+	ID        int
+	Nombre    string
+	Apellido  string
+	Email     string
+	Legajo    string
+	Role      models.Role
+}
+
+// Login valida las credenciales contra la identidad única (models.Usuario) e
+// ingresa con el rol pedido. Si no se indica rol y la persona tiene varios,
+// devuelve un *RoleSelectionError con los roles disponibles. Cada intento queda
+// auditado y los fallos repetidos bloquean temporalmente el email o la IP.
+func (s *AuthService) Login(email, password string, role models.Role, userAgent, ip string) (*models.TokenPair, error) {
+	attempt := models.LoginAttempt{Email: email, IP: ip, UserAgent: userAgent, Role: role}
+
+	if err := s.checkLoginLock(email, ip); err != nil {
+		attempt.Reason = "bloqueado"
+		s.recordLoginAttempt(attempt)
+		return nil, err
+	}
+
+	var usuario models.Usuario
+	err := s.db.Where("email = ?", normalizeEmail(email)).First(&usuario).Error
 	if err == nil {
-		// Es un profesor, verificar contraseña
-		if err := bcrypt.CompareHashAndPassword([]byte(profesor.Password), []byte(password)); err == nil {
-			return s.generateToken(profesor.ID, profesor.Nombre, profesor.Apellido, profesor.Email, profesor.Legajo, profesor.GetRole())
+		err = bcrypt.CompareHashAndPassword([]byte(usuario.Password), []byte(password))
+	}
+	if err != nil {
+		attempt.Reason = "credenciales inválidas"
+		s.recordLoginAttempt(attempt)
+		if err := s.registerLoginFailure(email, ip); err != nil {
+			return nil, err
 		}
-		// Si encontró el profesor pero la contraseña es incorrecta, no continuar con admin
-		return "", errors.New("credenciales inválidas")
+		return nil, ErrInvalidCredentials
 	}
+	attempt.UsuarioID = &usuario.ID
 
-	// Intentar login como admin solo si no se encontró como profesor
-	var admin models.Admin
-	err = s.db.Where("email = ?", email).First(&admin).Error
-	if err == nil {
-		// Es un admin, verificar contraseña
-		if err := bcrypt.CompareHashAndPassword([]byte(admin.Password), []byte(password)); err == nil {
-			return s.generateToken(admin.ID, admin.Nombre, admin.Apellido, admin.Email, "", admin.GetRole())
+	roles, err := rolesForUsuario(s.db, usuario.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(roles) == 0 {
+		attempt.Reason = "sin roles"
+		s.recordLoginAttempt(attempt)
+		return nil, ErrInvalidCredentials
+	}
+
+	// La contraseña fue correcta: los casos de rol no cuentan como fallos
+	if err := s.clearLoginFailures(email, ip); err != nil {
+		return nil, err
+	}
+
+	if role == "" {
+		if len(roles) > 1 {
+			attempt.Reason = "selección de rol requerida"
+			s.recordLoginAttempt(attempt)
+			return nil, &RoleSelectionError{Roles: roles}
 		}
-		return "", errors.New("credenciales inválidas")
+		role = roles[0]
+	} else if !containsRole(roles, role) {
+		attempt.Reason = "rol no asignado"
+		s.recordLoginAttempt(attempt)
+		return nil, ErrRoleNotAssigned
 	}
 
-	// Intentar login como alumno primero
-	var alumno models.Alumno
-	err = s.db.Where("email = ?", email).First(&alumno).Error
-	if err == nil {
-		// Es un alumno, verificar contraseña
-		if err := bcrypt.CompareHashAndPassword([]byte(alumno.Password), []byte(password)); err == nil {
-			return s.generateToken(alumno.ID, alumno.Nombre, alumno.Apellido, alumno.Email, alumno.Legajo, alumno.GetRole())
+	subject, err := s.loadSubjectForUsuario(role, usuario.ID)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := s.createSession(subject, userAgent, ip)
+	if err != nil {
+		return nil, err
+	}
+
+	attempt.Role = role
+	attempt.Success = true
+	s.recordLoginAttempt(attempt)
+	return tokens, nil
+}
+
+// Refresh rota el refresh token de la sesión y emite un nuevo access token.
+// Si se presenta un refresh token ya rotado, se asume robo y se revoca la sesión.
+func (s *AuthService) Refresh(refreshToken, userAgent, ip string) (*models.TokenPair, error) {
+	hash := hashToken(refreshToken)
+
+	var session models.Session
+	err := s.db.Where("refresh_token_hash = ?", hash).First(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Reuso de un token viejo: invalidar la sesión completa
+		var reused models.Session
+		if s.db.Where("previous_token_hash = ?", hash).First(&reused).Error == nil {
+			s.revokeSession(&reused)
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if session.RevokedAt != nil || time.Now().After(session.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	subject, err := s.loadSubject(session.Role, session.UserID)
+	if err != nil {
+		s.revokeSession(&session)
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// El access token anterior deja de ser válido al rotar
+	s.revokeAccessToken(session.AccessTokenID, session.AccessExpiresAt)
+
+	newRefresh, err := generateRandomToken()
+	if err != nil {
+		return nil, err
+	}
+	accessToken, jti, accessExp, err := s.generateToken(subject, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	session.PreviousTokenHash = session.RefreshTokenHash
+	session.RefreshTokenHash = hashToken(newRefresh)
+	session.AccessTokenID = jti
+	session.AccessExpiresAt = accessExp
+	session.LastUsedAt = time.Now()
+	session.UserAgent = truncateString(userAgent, 250)
+	session.IP = ip
+	if err := s.db.Save(&session).Error; err != nil {
+		return nil, err
+	}
+
+	return &models.TokenPair{
+		AccessToken:      accessToken,
+		AccessExpiresAt:  accessExp,
+		RefreshToken:     newRefresh,
+		RefreshExpiresAt: session.ExpiresAt,
+	}, nil
+}
+
+// Logout revoca la sesión actual junto con su access token
+func (s *AuthService) Logout(sessionID int) error {
+	var session models.Session
+	if err := s.db.First(&session, sessionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
 		}
-		// Si encontró el alumno pero la contraseña es incorrecta, no continuar con admin
-		return "", errors.New("credenciales inválidas")
+		return err
 	}
+	return s.revokeSession(&session)
+}
+
+func (s *AuthService) GetActiveSessions(userID int, role models.Role, currentSessionID int) ([]models.SessionResponse, error) {
+	var sessions []models.Session
+	result := s.db.Where("user_id = ? AND role = ? AND revoked_at IS NULL AND expires_at > ?", userID, role, time.Now()).
+		Order("last_used_at DESC").Find(&sessions)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	responses := make([]models.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		responses = append(responses, models.SessionResponse{
+			ID:         session.ID,
+			UserID:     session.UserID,
+			Role:       session.Role,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+			Actual:     session.ID == currentSessionID,
+		})
+	}
+	return responses, nil
+}
+
+// RevokeSession revoca una sesión del usuario indicado (no permite tocar sesiones ajenas)
+func (s *AuthService) RevokeSession(sessionID, userID int, role models.Role) error {
+	var session models.Session
+	err := s.db.Where("id = ? AND user_id = ? AND role = ?", sessionID, userID, role).First(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrSessionNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return s.revokeSession(&session)
+}
 
-	// No se encontró ni como profesor ni como admin
-	return "", errors.New("credenciales inválidas")
+// RevokeSessionByID revoca cualquier sesión (uso administrativo)
+func (s *AuthService) RevokeSessionByID(sessionID int) error {
+	var session models.Session
+	err := s.db.First(&session, sessionID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrSessionNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return s.revokeSession(&session)
+}
+
+// RevokeAllSessions cierra todas las sesiones activas de un usuario (force-logout)
+func (s *AuthService) RevokeAllSessions(userID int, role models.Role) (int, error) {
+	var sessions []models.Session
+	if err := s.db.Where("user_id = ? AND role = ? AND revoked_at IS NULL", userID, role).Find(&sessions).Error; err != nil {
+		return 0, err
+	}
+	for i := range sessions {
+		if err := s.revokeSession(&sessions[i]); err != nil {
+			return 0, err
+		}
+	}
+	return len(sessions), nil
+}
+
+// RevokeAllSessionsForUsuario cierra las sesiones de todos los roles de la persona
+func (s *AuthService) RevokeAllSessionsForUsuario(usuarioID int) error {
+	roles, err := rolesForUsuario(s.db, usuarioID)
+	if err != nil {
+		return err
+	}
+	for _, role := range roles {
+		subject, err := s.loadSubjectForUsuario(role, usuarioID)
+		if err != nil {
+			return err
+		}
+		if _, err := s.RevokeAllSessions(subject.ID, role); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsTokenRevoked es el chequeo que usa AuthMiddleware sobre el jti del access token
+func (s *AuthService) IsTokenRevoked(jti string) bool {
+	var count int64
+	s.db.Model(&models.RevokedToken{}).Where("jti = ?", jti).Count(&count)
+	return count > 0
 }
 
 func (s *AuthService) GetCurrentUser(userID interface{}, userEmail, userRole string, userName, userSurname, userLegajo interface{}) *models.WhoAmIResponse {
@@ -86,21 +314,164 @@ func (s *AuthService) GetCurrentUser(userID interface{}, userEmail, userRole str
 	return response
 }
 
-func (s *AuthService) generateToken(id int, nombre, apellido, email, legajo string, role models.Role) (string, error) {
+func (s *AuthService) createSession(subject tokenSubject, userAgent, ip string) (*models.TokenPair, error) {
+	refreshToken, err := generateRandomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	session := models.Session{
+		UserID:           subject.ID,
+		Role:             subject.Role,
+		RefreshTokenHash: hashToken(refreshToken),
+		UserAgent:        truncateString(userAgent, 250),
+		IP:               ip,
+		CreatedAt:        now,
+		LastUsedAt:       now,
+		ExpiresAt:        now.Add(RefreshTokenTTL),
+	}
+	if err := s.db.Create(&session).Error; err != nil {
+		return nil, err
+	}
+
+	accessToken, jti, accessExp, err := s.generateToken(subject, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	session.AccessTokenID = jti
+	session.AccessExpiresAt = accessExp
+	if err := s.db.Save(&session).Error; err != nil {
+		return nil, err
+	}
+
+	return &models.TokenPair{
+		AccessToken:      accessToken,
+		AccessExpiresAt:  accessExp,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: session.ExpiresAt,
+	}, nil
+}
+
+func (s *AuthService) revokeSession(session *models.Session) error {
+	if session.RevokedAt == nil {
+		now := time.Now()
+		session.RevokedAt = &now
+		if err := s.db.Save(session).Error; err != nil {
+			return err
+		}
+	}
+	return s.revokeAccessToken(session.AccessTokenID, session.AccessExpiresAt)
+}
+
+func (s *AuthService) revokeAccessToken(jti string, expiresAt time.Time) error {
+	if jti == "" || time.Now().After(expiresAt) {
+		return nil
+	}
+
+	// Limpiar entradas que ya no sirven (el token expiró de todas formas)
+	s.db.Where("expires_at < ?", time.Now()).Delete(&models.RevokedToken{})
+
+	return s.db.Where(models.RevokedToken{JTI: jti}).
+		Attrs(models.RevokedToken{ExpiresAt: expiresAt}).
+		FirstOrCreate(&models.RevokedToken{}).Error
+}
+
+// loadSubject carga el perfil del rol a partir de su ID (el de la sesión)
+func (s *AuthService) loadSubject(role models.Role, id int) (tokenSubject, error) {
+	return s.findSubject(role, "id = ?", id)
+}
+
+// loadSubjectForUsuario carga el perfil del rol que pertenece a la identidad
+func (s *AuthService) loadSubjectForUsuario(role models.Role, usuarioID int) (tokenSubject, error) {
+	return s.findSubject(role, "usuario_id = ?", usuarioID)
+}
+
+func (s *AuthService) findSubject(role models.Role, query string, arg int) (tokenSubject, error) {
+	var base models.BaseUser
+	legajo := ""
+
+	switch role {
+	case models.RoleProfesor:
+		var profesor models.Profesor
+		if err := s.db.Where(query, arg).First(&profesor).Error; err != nil {
+			return tokenSubject{}, err
+		}
+		base, legajo = profesor.BaseUser, profesor.Legajo
+	case models.RoleAdmin:
+		var admin models.Admin
+		if err := s.db.Where(query, arg).First(&admin).Error; err != nil {
+			return tokenSubject{}, err
+		}
+		base = admin.BaseUser
+	case models.RoleAlumno:
+		var alumno models.Alumno
+		if err := s.db.Where(query, arg).First(&alumno).Error; err != nil {
+			return tokenSubject{}, err
+		}
+		base, legajo = alumno.BaseUser, alumno.Legajo
+	default:
+		return tokenSubject{}, errors.New("rol inválido")
+	}
+
+	if base.UsuarioID == nil {
+		return tokenSubject{}, errors.New("el perfil no está vinculado a un usuario")
+	}
+	return tokenSubject{*base.UsuarioID, base.ID, base.Nombre, base.Apellido, base.Email, legajo, role}, nil
+}
+
+func containsRole(roles []models.Role, role models.Role) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *AuthService) generateToken(subject tokenSubject, sessionID int) (string, string, time.Time, error) {
+	jti, err := generateRandomToken()
+	if err != nil {
+		return "", "", time.Time{}, err
+	}
+	expiresAt := time.Now().Add(AccessTokenTTL)
+
+	// "id" es el ID del perfil del rol activo (lo que usan los controllers);
+	// "uid" identifica a la persona sin ambigüedad entre tablas
 	claims := jwt.MapClaims{
-		"id":       id,
-		"nombre":   nombre,
-		"apellido": apellido,
-		"email":    email,
-		"role":     string(role),
-		"exp":      jwt.NewNumericDate(time.Now().Add(24 * time.Hour)).Unix(),
+		"uid":      subject.UsuarioID,
+		"id":       subject.ID,
+		"nombre":   subject.Nombre,
+		"apellido": subject.Apellido,
+		"email":    subject.Email,
+		"role":     string(subject.Role),
+		"sid":      sessionID,
+		"jti":      jti,
+		"exp":      jwt.NewNumericDate(expiresAt).Unix(),
 	}
 
 	// Agregar legajo solo si no está vacío (profesores)
-	if legajo != "" {
-		claims["legajo"] = legajo
+	if subject.Legajo != "" {
+		claims["legajo"] = subject.Legajo
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(middleware.GetSecretKey()))
+	signed, err := token.SignedString([]byte(middleware.GetSecretKey()))
+	if err != nil {
+		return "", "", time.Time{}, err
+	}
+	return signed, jti, expiresAt, nil
+}
+
+func generateRandomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
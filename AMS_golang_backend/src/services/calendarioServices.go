@@ -0,0 +1,289 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/LINSITrack/backend/src/models"
+	"github.com/LINSITrack/backend/utils/horario"
+	"github.com/LINSITrack/backend/utils/ical"
+	"gorm.io/gorm"
+)
+
+// ErrCalendarioRol indica que el rol no tiene comisiones para armar un calendario
+var ErrCalendarioRol = errors.New("solo alumnos y profesores tienen calendario")
+
+// Cada cuánto se sugiere a los clientes volver a descargar el feed
+const refrescoCalendario = time.Hour
+
+// CalendarioService administra los feeds .ics con las fechas de entrega de
+// TPs, las evaluaciones y las clases de las comisiones de cada usuario. El
+// feed se arma en cada descarga, así que refleja siempre los datos actuales.
+type CalendarioService struct {
+	db *gorm.DB
+}
+
+func NewCalendarioService(db *gorm.DB) *CalendarioService {
+	return &CalendarioService{db: db}
+}
+
+func (s *CalendarioService) GetSuscripcion(userID int, role models.Role) (*models.SuscripcionCalendario, error) {
+	if role != models.RoleAlumno && role != models.RoleProfesor {
+		return nil, ErrCalendarioRol
+	}
+	var token models.CalendarioToken
+	err := s.db.Where("user_id = ? AND role = ?", userID, role).First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.SuscripcionCalendario{Activa: false}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &models.SuscripcionCalendario{Activa: true, CreatedAt: &token.CreatedAt, LastUsedAt: token.LastUsedAt}, nil
+}
+
+// GenerarSuscripcion crea el token del feed; si ya había uno, el anterior deja
+// de funcionar. El token en texto plano solo se devuelve acá.
+func (s *CalendarioService) GenerarSuscripcion(userID int, role models.Role) (*models.SuscripcionCalendario, error) {
+	if role != models.RoleAlumno && role != models.RoleProfesor {
+		return nil, ErrCalendarioRol
+	}
+	plain, err := generateRandomToken()
+	if err != nil {
+		return nil, err
+	}
+	token := models.CalendarioToken{UserID: userID, Role: role, TokenHash: hashToken(plain)}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND role = ?", userID, role).Delete(&models.CalendarioToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&token).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &models.SuscripcionCalendario{
+		Activa:    true,
+		URL:       "/calendario/feed/" + plain + ".ics",
+		Token:     plain,
+		CreatedAt: &token.CreatedAt,
+	}, nil
+}
+
+func (s *CalendarioService) RevocarSuscripcion(userID int, role models.Role) error {
+	result := s.db.Where("user_id = ? AND role = ?", userID, role).Delete(&models.CalendarioToken{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// EscribirFeed escribe el calendario del dueño del token
+func (s *CalendarioService) EscribirFeed(w io.Writer, plain string) error {
+	var token models.CalendarioToken
+	if err := s.db.Where("token_hash = ?", hashToken(plain)).First(&token).Error; err != nil {
+		return err
+	}
+	ahora := time.Now()
+	s.db.Model(&token).Update("last_used_at", ahora)
+
+	var eventos []ical.Evento
+	var err error
+	switch token.Role {
+	case models.RoleAlumno:
+		eventos, err = s.eventosAlumno(token.UserID, ahora)
+	case models.RoleProfesor:
+		eventos, err = s.eventosProfesor(token.UserID, ahora)
+	default:
+		err = ErrCalendarioRol
+	}
+	if err != nil {
+		return err
+	}
+	return ical.Write(w, ical.Calendario{Nombre: "LINSITrack", Refresco: refrescoCalendario, Eventos: eventos}, ahora)
+}
+
+// eventosAlumno arma los eventos de cada cursada con los TPs y evaluaciones de
+// su año lectivo; las clases solo se incluyen para las cursadas del año actual
+func (s *CalendarioService) eventosAlumno(alumnoID int, ahora time.Time) ([]ical.Evento, error) {
+	var cursadas []models.Cursada
+	if err := s.db.Preload("Comision.Materia").Where("alumno_id = ?", alumnoID).Order("ano_lectivo, id").Find(&cursadas).Error; err != nil {
+		return nil, err
+	}
+
+	// Los recuperatorios solo figuran para los alumnos inscriptos
+	var inscripto []int
+	if err := s.db.Model(&models.EntregaEvaluacion{}).Where("alumno_id = ?", alumnoID).Pluck("evaluacion_id", &inscripto).Error; err != nil {
+		return nil, err
+	}
+	inscriptoEn := map[int]bool{}
+	for _, id := range inscripto {
+		inscriptoEn[id] = true
+	}
+
+	eventos := []ical.Evento{}
+	conClases := map[int]bool{}
+	for _, cursada := range cursadas {
+		comision := cursada.Comision
+		ano := cursada.AnoLectivo
+		tps, err := tpsDeComision(s.db, comision.ID, &ano)
+		if err != nil {
+			return nil, err
+		}
+		evaluaciones, err := evaluacionesDeComision(s.db, comision.ID, &ano)
+		if err != nil {
+			return nil, err
+		}
+		for _, tp := range tps {
+			if tp.Vigente {
+				eventos = append(eventos, eventoTp(tp, comision))
+			}
+		}
+		for _, evaluacion := range evaluaciones {
+			if evaluacion.RecuperatorioDeId != nil && !inscriptoEn[evaluacion.ID] {
+				continue
+			}
+			eventos = append(eventos, eventosEvaluacion(evaluacion, comision)...)
+		}
+		if ano == ahora.Year() && !conClases[comision.ID] {
+			conClases[comision.ID] = true
+			clases, err := s.eventosClases(comision, ahora)
+			if err != nil {
+				return nil, err
+			}
+			eventos = append(eventos, clases...)
+		}
+	}
+	return eventos, nil
+}
+
+// eventosProfesor arma los eventos de las comisiones asignadas al profesor,
+// desde un año atrás
+func (s *CalendarioService) eventosProfesor(profesorID int, ahora time.Time) ([]ical.Evento, error) {
+	var asignaciones []models.ProfesorXComision
+	if err := s.db.Preload("Comision.Materia").Where("profesor_id = ?", profesorID).Find(&asignaciones).Error; err != nil {
+		return nil, err
+	}
+	desde := ahora.AddDate(-1, 0, 0)
+
+	eventos := []ical.Evento{}
+	vistas := map[int]bool{}
+	for _, asignacion := range asignaciones {
+		comision := asignacion.Comision
+		if vistas[comision.ID] {
+			continue
+		}
+		vistas[comision.ID] = true
+
+		var tps []models.TpModel
+		if err := s.db.Where("comision_id = ? AND vigente AND fecha_entrega >= ?", comision.ID, desde).Order("fecha_entrega, id").Find(&tps).Error; err != nil {
+			return nil, err
+		}
+		var evaluaciones []models.EvaluacionModel
+		if err := s.db.Where("comision_id = ? AND fecha_evaluacion >= ?", comision.ID, desde.Format("2006-01-02")).Order("fecha_evaluacion, id").Find(&evaluaciones).Error; err != nil {
+			return nil, err
+		}
+		for _, tp := range tps {
+			eventos = append(eventos, eventoTp(tp, comision))
+		}
+		for _, evaluacion := range evaluaciones {
+			eventos = append(eventos, eventosEvaluacion(evaluacion, comision)...)
+		}
+		clases, err := s.eventosClases(comision, ahora)
+		if err != nil {
+			return nil, err
+		}
+		eventos = append(eventos, clases...)
+	}
+	return eventos, nil
+}
+
+// eventosClases arma un evento semanal por franja de la comisión. Las franjas
+// sin vigencia arrancan el 1 de enero del año actual.
+func (s *CalendarioService) eventosClases(comision models.Comision, ahora time.Time) ([]ical.Evento, error) {
+	franjas, err := franjasDeComision(s.db, comision.ID)
+	if err != nil {
+		return nil, err
+	}
+	eventos := make([]ical.Evento, 0, len(franjas))
+	for _, franja := range franjas {
+		desde := time.Date(ahora.Year(), time.January, 1, 0, 0, 0, 0, time.Local)
+		if franja.VigenteDesde != nil {
+			desde = time.Date(franja.VigenteDesde.Year(), franja.VigenteDesde.Month(), franja.VigenteDesde.Day(), 0, 0, 0, 0, time.Local)
+		}
+		for desde.Weekday() != time.Weekday(franja.Dia) {
+			desde = desde.AddDate(0, 0, 1)
+		}
+		if franja.VigenteHasta != nil && desde.After(*franja.VigenteHasta) {
+			continue
+		}
+		horaInicio, _ := horario.ParseHora(franja.HoraInicio)
+		horaFin, _ := horario.ParseHora(franja.HoraFin)
+		inicio, fin := horario.Franja{Dia: desde.Weekday(), Inicio: horaInicio, Fin: horaFin}.En(desde)
+
+		lugar := franja.Aula
+		if franja.Modalidad != models.ModalidadPresencial {
+			lugar = strings.TrimSpace(lugar + " (" + string(franja.Modalidad) + ")")
+		}
+		eventos = append(eventos, ical.Evento{
+			UID:     fmt.Sprintf("horario-%d@linsitrack", franja.ID),
+			Resumen: "Clase " + nombreComision(comision),
+			Lugar:   lugar,
+			Inicio:  inicio,
+			Fin:     fin,
+			Semanal: true,
+			Hasta:   franja.VigenteHasta,
+		})
+	}
+	return eventos, nil
+}
+
+func eventoTp(tp models.TpModel, comision models.Comision) ical.Evento {
+	return ical.Evento{
+		UID:         fmt.Sprintf("tp-%d@linsitrack", tp.ID),
+		Resumen:     "Entrega de TP " + nombreComision(comision),
+		Descripcion: tp.Consigna,
+		Inicio:      tp.FechaHoraEntrega,
+	}
+}
+
+// eventosEvaluacion arma los eventos de la fecha de la evaluación y de su devolución
+func eventosEvaluacion(evaluacion models.EvaluacionModel, comision models.Comision) []ical.Evento {
+	tipo := "Evaluación"
+	if evaluacion.RecuperatorioDeId != nil {
+		tipo = "Recuperatorio"
+	}
+	var eventos []ical.Evento
+	if fecha, err := time.ParseInLocation("2006-01-02", prefijoFecha(evaluacion.FechaEvaluacion), time.Local); err == nil {
+		eventos = append(eventos, ical.Evento{
+			UID:         fmt.Sprintf("evaluacion-%d@linsitrack", evaluacion.ID),
+			Resumen:     tipo + " " + nombreComision(comision),
+			Descripcion: evaluacion.Temas,
+			Inicio:      fecha,
+			DiaCompleto: true,
+		})
+	}
+	if fecha, err := time.ParseInLocation("2006-01-02", prefijoFecha(evaluacion.FechaDevolucion), time.Local); err == nil {
+		eventos = append(eventos, ical.Evento{
+			UID:         fmt.Sprintf("devolucion-%d@linsitrack", evaluacion.ID),
+			Resumen:     "Devolución de " + strings.ToLower(tipo[:1]) + tipo[1:] + " " + nombreComision(comision),
+			Inicio:      fecha,
+			DiaCompleto: true,
+		})
+	}
+	return eventos
+}
+
+// nombreComision devuelve "Materia (Comisión)" para los títulos de los eventos
+func nombreComision(comision models.Comision) string {
+	if comision.Materia.Nombre == "" {
+		return comision.Nombre
+	}
+	return comision.Materia.Nombre + " (" + comision.Nombre + ")"
+}
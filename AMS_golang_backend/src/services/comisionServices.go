@@ -1,10 +1,18 @@
 package services
 
 import (
+	"errors"
+
 	"github.com/LINSITrack/backend/src/models"
 	"gorm.io/gorm"
 )
 
+// ErrHorariosEstructurados indica que los horarios de la comisión se editan por franja
+var ErrHorariosEstructurados = errors.New("la comisión tiene franjas horarias: editarlas desde /comisiones/:id/horarios")
+
+// ErrPeriodoInexistente indica que el período asignado a la comisión no existe
+var ErrPeriodoInexistente = errors.New("el período académico no existe")
+
 type ComisionService struct {
 	db *gorm.DB
 }
@@ -13,9 +21,10 @@ func NewComisionService(db *gorm.DB) *ComisionService {
 	return &ComisionService{db: db}
 }
 
-func (s *ComisionService) GetAllComisiones() ([]models.Comision, error) {
+// GetAllComisiones devuelve las comisiones, opcionalmente solo las de un período
+func (s *ComisionService) GetAllComisiones(periodoID *int) ([]models.Comision, error) {
 	var comisiones []models.Comision
-	result := s.db.Preload("Materia").Find(&comisiones)
+	result := s.db.Preload("Materia").Preload("Periodo").Scopes(filtroPeriodo(periodoID, "id")).Find(&comisiones)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -24,25 +33,38 @@ func (s *ComisionService) GetAllComisiones() ([]models.Comision, error) {
 
 func (s *ComisionService) GetComisionByID(id int) (*models.Comision, error) {
 	var comision models.Comision
-	result := s.db.Preload("Materia").First(&comision, id)
+	result := s.db.Preload("Materia").Preload("Periodo").Preload("Franjas", func(db *gorm.DB) *gorm.DB {
+		return db.Order(ordenFranjas)
+	}).First(&comision, id)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return &comision, nil
 }
 
-func (s *ComisionService) GetComisionesByMateriaID(materiaID int) ([]models.Comision, error) {
+func (s *ComisionService) GetComisionesByMateriaID(materiaID int, periodoID *int) ([]models.Comision, error) {
     var comisiones []models.Comision
-    result := s.db.Preload("Materia").Where("materia_id = ?", materiaID).Find(&comisiones)
+    result := s.db.Preload("Materia").Preload("Periodo").Scopes(filtroPeriodo(periodoID, "id")).Where("materia_id = ?", materiaID).Find(&comisiones)
     if result.Error != nil {
         return nil, result.Error
     }
     return comisiones, nil
 }
 
+// CreateComision crea la comisión y, si se puede interpretar, las franjas de su texto de horarios
 func (s *ComisionService) CreateComision(comision *models.Comision) error {
-	result := s.db.Create(comision)
-	return result.Error
+	// Las franjas se cargan por HorarioService para validar choques
+	comision.Franjas = nil
+	comision.Periodo = nil
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := validarPeriodoComision(tx, comision.PeriodoId); err != nil {
+			return err
+		}
+		if err := tx.Create(comision).Error; err != nil {
+			return err
+		}
+		return franjasDesdeTexto(tx, comision, true)
+	})
 }
 
 func (s *ComisionService) UpdateComision(id int, updateRequest *models.ComisionUpdateRequest) (*models.Comision, error) {
@@ -55,20 +77,52 @@ func (s *ComisionService) UpdateComision(id int, updateRequest *models.ComisionU
 	if updateRequest.Nombre != nil {
 		comision.Nombre = *updateRequest.Nombre
 	}
-	if updateRequest.Horarios != nil {
+	if updateRequest.Horarios != nil && *updateRequest.Horarios != comision.Horarios {
+		var franjas int64
+		if err := s.db.Model(&models.HorarioComision{}).Where("comision_id = ?", id).Count(&franjas).Error; err != nil {
+			return nil, err
+		}
+		if franjas > 0 {
+			return nil, ErrHorariosEstructurados
+		}
 		comision.Horarios = *updateRequest.Horarios
 	}
 	if updateRequest.MateriaId != nil {
 		comision.MateriaId = *updateRequest.MateriaId
 	}
+	if updateRequest.PeriodoId != nil {
+		if err := validarPeriodoComision(s.db, updateRequest.PeriodoId); err != nil {
+			return nil, err
+		}
+		comision.PeriodoId = updateRequest.PeriodoId
+	}
 
-	result = s.db.Save(&comision)
-	if result.Error != nil {
-		return nil, result.Error
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&comision).Error; err != nil {
+			return err
+		}
+		return franjasDesdeTexto(tx, &comision, true)
+	})
+	if err != nil {
+		return nil, err
 	}
 	return &comision, nil
 }
 
+// validarPeriodoComision controla que exista el período asignado a una comisión
+func validarPeriodoComision(db *gorm.DB, periodoID *int) error {
+	if periodoID == nil {
+		return nil
+	}
+	if err := db.Select("id").First(&models.PeriodoAcademico{}, *periodoID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrPeriodoInexistente
+		}
+		return err
+	}
+	return nil
+}
+
 func (s *ComisionService) DeleteComision(id int) error {
 	result := s.db.Delete(&models.Comision{}, id)
 	return result.Error
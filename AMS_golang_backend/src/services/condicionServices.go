@@ -0,0 +1,274 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/LINSITrack/backend/src/models"
+	"gorm.io/gorm"
+)
+
+// CondicionService administra las reglas de condición (promoción, regularidad y
+// libre) de cada materia. Las reglas se aplican al calcular la nota final (ver NotaFinalService).
+type CondicionService struct {
+	db *gorm.DB
+}
+
+func NewCondicionService(db *gorm.DB) *CondicionService {
+	return &CondicionService{db: db}
+}
+
+func (s *CondicionService) GetReglas(materiaID int) (*models.ReglasCondicion, error) {
+	var reglas models.ReglasCondicion
+	if err := s.db.Where("materia_id = ?", materiaID).First(&reglas).Error; err != nil {
+		return nil, err
+	}
+	return &reglas, nil
+}
+
+// GuardarReglas crea o reemplaza las reglas de la materia
+func (s *CondicionService) GuardarReglas(materiaID int, req *models.ReglasCondicionRequest) (*models.ReglasCondicion, error) {
+	var materia models.Materia
+	if err := s.db.First(&materia, materiaID).Error; err != nil {
+		return nil, err
+	}
+	reglas, err := reglasDesdeRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	reglas.MateriaId = materiaID
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var existente models.ReglasCondicion
+		err := tx.Where("materia_id = ?", materiaID).First(&existente).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		reglas.ID = existente.ID
+		return tx.Save(reglas).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reglas, nil
+}
+
+func (s *CondicionService) BorrarReglas(materiaID int) error {
+	result := s.db.Where("materia_id = ?", materiaID).Delete(&models.ReglasCondicion{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// reglasDesdeRequest valida el pedido y completa los valores por defecto
+func reglasDesdeRequest(req *models.ReglasCondicionRequest) (*models.ReglasCondicion, error) {
+	reglas := &models.ReglasCondicion{
+		NotaAprobacionParcial:         4,
+		NotaPromocionParcial:          7,
+		PromedioPromocion:             7,
+		RecuperatoriosMaximos:         1,
+		RecuperatorioPermitePromocion: req.RecuperatorioPermitePromocion,
+		NotaAprobacionTp:              4,
+		PorcentajeTpsAprobados:        75,
+		AsistenciaRegular:             75,
+		AsistenciaPromocion:           80,
+	}
+	for _, campo := range []struct {
+		valor   *float64
+		destino *float64
+	}{
+		{req.NotaAprobacionParcial, &reglas.NotaAprobacionParcial},
+		{req.NotaPromocionParcial, &reglas.NotaPromocionParcial},
+		{req.PromedioPromocion, &reglas.PromedioPromocion},
+		{req.NotaAprobacionTp, &reglas.NotaAprobacionTp},
+		{req.PorcentajeTpsAprobados, &reglas.PorcentajeTpsAprobados},
+		{req.AsistenciaRegular, &reglas.AsistenciaRegular},
+		{req.AsistenciaPromocion, &reglas.AsistenciaPromocion},
+	} {
+		if campo.valor != nil {
+			*campo.destino = *campo.valor
+		}
+	}
+	if req.RecuperatoriosMaximos != nil {
+		reglas.RecuperatoriosMaximos = *req.RecuperatoriosMaximos
+	}
+
+	for _, nota := range []float64{reglas.NotaAprobacionParcial, reglas.NotaPromocionParcial, reglas.PromedioPromocion, reglas.NotaAprobacionTp} {
+		if nota < 0 || nota > 10 {
+			return nil, errors.New("las notas deben estar entre 0 y 10")
+		}
+	}
+	for _, porcentaje := range []float64{reglas.PorcentajeTpsAprobados, reglas.AsistenciaRegular, reglas.AsistenciaPromocion} {
+		if porcentaje < 0 || porcentaje > 100 {
+			return nil, errors.New("los porcentajes deben estar entre 0 y 100")
+		}
+	}
+	if reglas.NotaPromocionParcial < reglas.NotaAprobacionParcial {
+		return nil, errors.New("nota_promocion_parcial no puede ser menor que nota_aprobacion_parcial")
+	}
+	if reglas.AsistenciaPromocion < reglas.AsistenciaRegular {
+		return nil, errors.New("asistencia_promocion no puede ser menor que asistencia_regular")
+	}
+	if reglas.RecuperatoriosMaximos < 0 {
+		return nil, errors.New("recuperatorios_maximos no puede ser negativo")
+	}
+	return reglas, nil
+}
+
+// reglasDeMateria devuelve las reglas de la materia o nil si no tiene
+func reglasDeMateria(db *gorm.DB, materiaID int) (*models.ReglasCondicion, error) {
+	var reglas models.ReglasCondicion
+	err := db.Where("materia_id = ?", materiaID).First(&reglas).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &reglas, nil
+}
+
+// datosCondicion resume los ítems de una cursada para evaluar las reglas.
+// Un TP faltante cuenta como no aprobado; una evaluación faltante, como ausente.
+func datosCondicion(reglas *models.ReglasCondicion, items []models.ItemNotaFinal) models.DatosCondicion {
+	var datos models.DatosCondicion
+	for _, item := range items {
+		switch item.Tipo {
+		case models.ItemTp:
+			datos.TpsTotal++
+			switch {
+			case item.Estado == models.ItemPendiente:
+				datos.TpsPendientes++
+			case item.Nota != nil && *item.Nota >= reglas.NotaAprobacionTp:
+				datos.TpsAprobados++
+			}
+		case models.ItemEvaluacion:
+			parcial := models.ParcialCondicion{
+				EvaluacionId: item.ID,
+				Fecha:        item.Fecha,
+				Nota:         item.Nota,
+				Pendiente:    item.Estado == models.ItemPendiente,
+			}
+			if item.NotaRecuperatorio != nil {
+				parcial.Nota = item.NotaOriginal
+				parcial.Recuperatorio = item.NotaRecuperatorio
+			}
+			datos.Parciales = append(datos.Parciales, parcial)
+		}
+	}
+	return datos
+}
+
+// EvaluarCondicion clasifica la cursada según las reglas:
+//   - libre si algún parcial quedó desaprobado (o ausente) aun después del
+//     recuperatorio, si recuperó más parciales de los permitidos, o si no llega
+//     al porcentaje de TPs aprobados o a la asistencia para regularizar
+//   - promocionado si además todos los parciales alcanzan la nota de promoción
+//     (sin recuperatorio, salvo que las reglas lo permitan), su promedio llega a
+//     PromedioPromocion y la asistencia a AsistenciaPromocion
+//   - regular en otro caso
+//
+// Mientras haya parciales o TPs pendientes el resultado es provisorio.
+func EvaluarCondicion(reglas *models.ReglasCondicion, datos models.DatosCondicion) models.ResultadoCondicion {
+	var resultado models.ResultadoCondicion
+	var noRegular, noPromocion []string
+
+	var recuperados, evaluados int
+	var sumaParciales float64
+	promocionaParciales := true
+	for _, parcial := range datos.Parciales {
+		if parcial.Pendiente {
+			resultado.Provisoria = true
+			continue
+		}
+		evaluados++
+		nota := valorNota(parcial.Nota)
+		efectiva := nota
+		promociona := parcial.Nota != nil && nota >= reglas.NotaPromocionParcial
+
+		if parcial.Nota == nil || nota < reglas.NotaAprobacionParcial {
+			recuperatorio := valorNota(parcial.Recuperatorio)
+			if parcial.Recuperatorio == nil || recuperatorio < reglas.NotaAprobacionParcial {
+				noRegular = append(noRegular, "parcial del "+parcial.Fecha.Format("02/01/2006")+" desaprobado")
+			} else {
+				recuperados++
+			}
+			efectiva = recuperatorio
+			promociona = reglas.RecuperatorioPermitePromocion && parcial.Recuperatorio != nil && recuperatorio >= reglas.NotaPromocionParcial
+		}
+		if !promociona {
+			promocionaParciales = false
+		}
+		sumaParciales += efectiva
+	}
+	if recuperados > reglas.RecuperatoriosMaximos {
+		noRegular = append(noRegular, fmt.Sprintf("recuperó %d parciales (máximo %d)", recuperados, reglas.RecuperatoriosMaximos))
+	}
+	if !promocionaParciales {
+		noPromocion = append(noPromocion, "no todos los parciales alcanzan la nota de promoción")
+	}
+	if evaluados > 0 && reglas.PromedioPromocion > 0 && sumaParciales/float64(evaluados) < reglas.PromedioPromocion {
+		noPromocion = append(noPromocion, fmt.Sprintf("promedio de parciales %.2f menor a %.2f", sumaParciales/float64(evaluados), reglas.PromedioPromocion))
+	}
+
+	if datos.TpsPendientes > 0 {
+		resultado.Provisoria = true
+	}
+	if datos.TpsTotal > 0 {
+		porcentaje := float64(datos.TpsAprobados) * 100 / float64(datos.TpsTotal)
+		// Con TPs pendientes solo es definitivo si ni aprobándolos se llega al mínimo
+		maximo := float64(datos.TpsAprobados+datos.TpsPendientes) * 100 / float64(datos.TpsTotal)
+		if porcentaje < reglas.PorcentajeTpsAprobados && (datos.TpsPendientes == 0 || maximo < reglas.PorcentajeTpsAprobados) {
+			noRegular = append(noRegular, fmt.Sprintf("TPs aprobados %.0f%% (mínimo %.0f%%)", porcentaje, reglas.PorcentajeTpsAprobados))
+		}
+	}
+
+	if datos.Asistencia != nil {
+		if *datos.Asistencia < reglas.AsistenciaRegular {
+			noRegular = append(noRegular, fmt.Sprintf("asistencia %.0f%% (mínimo %.0f%%)", *datos.Asistencia, reglas.AsistenciaRegular))
+		} else if *datos.Asistencia < reglas.AsistenciaPromocion {
+			noPromocion = append(noPromocion, fmt.Sprintf("asistencia %.0f%% (mínimo para promocionar %.0f%%)", *datos.Asistencia, reglas.AsistenciaPromocion))
+		}
+	}
+
+	switch {
+	case len(noRegular) > 0:
+		resultado.Condicion = models.CondicionLibre
+		resultado.Motivos = noRegular
+	case len(noPromocion) > 0 || evaluados == 0:
+		resultado.Condicion = models.CondicionRegular
+		resultado.Motivos = noPromocion
+	default:
+		resultado.Condicion = models.CondicionPromocionado
+	}
+	return resultado
+}
+
+// notificarCambioCondicion avisa al alumno que cambió la condición de su cursada
+func notificarCambioCondicion(tx *gorm.DB, cursada *models.Cursada, nueva models.CondicionCursada, provisoria bool) error {
+	var comision models.Comision
+	if err := tx.Preload("Materia").First(&comision, cursada.ComisionID).Error; err != nil {
+		return err
+	}
+	materiaNombre := comision.Nombre
+	if comision.Materia.Nombre != "" {
+		materiaNombre = comision.Materia.Nombre
+	}
+
+	mensaje := fmt.Sprintf("Tu condición en %s cambió a: %s", materiaNombre, nueva)
+	if provisoria {
+		mensaje += " (provisoria)"
+	}
+	notificacion := models.Notificacion{
+		Mensaje:   mensaje,
+		FechaHora: time.Now(),
+		Leida:     false,
+		AlumnoID:  cursada.AlumnoID,
+	}
+	return tx.Create(&notificacion).Error
+}
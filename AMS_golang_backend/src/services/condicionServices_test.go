@@ -0,0 +1,195 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/LINSITrack/backend/src/models"
+)
+
+func TestEvaluarCondicion(t *testing.T) {
+	base := models.ReglasCondicion{
+		NotaAprobacionParcial:  4,
+		NotaPromocionParcial:   7,
+		PromedioPromocion:      7,
+		RecuperatoriosMaximos:  1,
+		NotaAprobacionTp:       4,
+		PorcentajeTpsAprobados: 75,
+		AsistenciaRegular:      75,
+		AsistenciaPromocion:    80,
+	}
+	fecha := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	parcial := func(n, recuperatorio *float64) models.ParcialCondicion {
+		return models.ParcialCondicion{Fecha: fecha, Nota: n, Recuperatorio: recuperatorio}
+	}
+	pendiente := models.ParcialCondicion{Fecha: fecha, Pendiente: true}
+
+	tests := []struct {
+		name           string
+		reglas         models.ReglasCondicion
+		datos          models.DatosCondicion
+		wantCondicion  models.CondicionCursada
+		wantProvisoria bool
+		wantMotivos    int
+	}{
+		{
+			name:          "promociona",
+			reglas:        base,
+			datos:         models.DatosCondicion{Parciales: []models.ParcialCondicion{parcial(nota(8), nil), parcial(nota(9), nil)}, TpsTotal: 4, TpsAprobados: 4, Asistencia: nota(90)},
+			wantCondicion: models.CondicionPromocionado,
+		},
+		{
+			name:          "un parcial bajo la nota de promoción",
+			reglas:        base,
+			datos:         models.DatosCondicion{Parciales: []models.ParcialCondicion{parcial(nota(6), nil), parcial(nota(9), nil)}, Asistencia: nota(90)},
+			wantCondicion: models.CondicionRegular,
+			wantMotivos:   1,
+		},
+		{
+			name:          "promedio de parciales bajo el de promoción",
+			reglas:        func() models.ReglasCondicion { r := base; r.PromedioPromocion = 8; return r }(),
+			datos:         models.DatosCondicion{Parciales: []models.ParcialCondicion{parcial(nota(7), nil), parcial(nota(8), nil)}},
+			wantCondicion: models.CondicionRegular,
+			wantMotivos:   1,
+		},
+		{
+			name:          "parcial desaprobado sin recuperatorio",
+			reglas:        base,
+			datos:         models.DatosCondicion{Parciales: []models.ParcialCondicion{parcial(nota(3), nil), parcial(nota(8), nil)}},
+			wantCondicion: models.CondicionLibre,
+			wantMotivos:   1,
+		},
+		{
+			name:          "ausente en un parcial sin recuperatorio",
+			reglas:        base,
+			datos:         models.DatosCondicion{Parciales: []models.ParcialCondicion{parcial(nil, nil), parcial(nota(8), nil)}},
+			wantCondicion: models.CondicionLibre,
+			wantMotivos:   1,
+		},
+		{
+			name:          "parcial aprobado con recuperatorio",
+			reglas:        base,
+			datos:         models.DatosCondicion{Parciales: []models.ParcialCondicion{parcial(nota(3), nota(6)), parcial(nota(8), nil)}},
+			wantCondicion: models.CondicionRegular,
+			wantMotivos:   1,
+		},
+		{
+			name:          "recuperatorio desaprobado",
+			reglas:        base,
+			datos:         models.DatosCondicion{Parciales: []models.ParcialCondicion{parcial(nota(3), nota(2)), parcial(nota(8), nil)}},
+			wantCondicion: models.CondicionLibre,
+			wantMotivos:   1,
+		},
+		{
+			name:          "recuperatorio que permite promocionar",
+			reglas:        func() models.ReglasCondicion { r := base; r.RecuperatorioPermitePromocion = true; return r }(),
+			datos:         models.DatosCondicion{Parciales: []models.ParcialCondicion{parcial(nota(3), nota(8)), parcial(nota(8), nil)}},
+			wantCondicion: models.CondicionPromocionado,
+		},
+		{
+			name:          "más recuperatorios que los permitidos",
+			reglas:        base,
+			datos:         models.DatosCondicion{Parciales: []models.ParcialCondicion{parcial(nota(2), nota(5)), parcial(nota(3), nota(6))}},
+			wantCondicion: models.CondicionLibre,
+			wantMotivos:   1,
+		},
+		{
+			name:           "parcial pendiente deja el resultado provisorio",
+			reglas:         base,
+			datos:          models.DatosCondicion{Parciales: []models.ParcialCondicion{parcial(nota(8), nil), pendiente}},
+			wantCondicion:  models.CondicionPromocionado,
+			wantProvisoria: true,
+		},
+		{
+			name:          "sin parciales evaluados no promociona",
+			reglas:        base,
+			datos:         models.DatosCondicion{TpsTotal: 2, TpsAprobados: 2},
+			wantCondicion: models.CondicionRegular,
+		},
+		{
+			name:          "TPs aprobados bajo el porcentaje",
+			reglas:        base,
+			datos:         models.DatosCondicion{Parciales: []models.ParcialCondicion{parcial(nota(8), nil)}, TpsTotal: 4, TpsAprobados: 2},
+			wantCondicion: models.CondicionLibre,
+			wantMotivos:   1,
+		},
+		{
+			name:           "TPs pendientes que todavía alcanzan el porcentaje",
+			reglas:         base,
+			datos:          models.DatosCondicion{Parciales: []models.ParcialCondicion{parcial(nota(8), nil)}, TpsTotal: 4, TpsAprobados: 2, TpsPendientes: 2},
+			wantCondicion:  models.CondicionPromocionado,
+			wantProvisoria: true,
+		},
+		{
+			name:           "TPs pendientes que ya no alcanzan el porcentaje",
+			reglas:         base,
+			datos:          models.DatosCondicion{Parciales: []models.ParcialCondicion{parcial(nota(8), nil)}, TpsTotal: 4, TpsAprobados: 1, TpsPendientes: 1},
+			wantCondicion:  models.CondicionLibre,
+			wantProvisoria: true,
+			wantMotivos:    1,
+		},
+		{
+			name:          "asistencia bajo la mínima para regularizar",
+			reglas:        base,
+			datos:         models.DatosCondicion{Parciales: []models.ParcialCondicion{parcial(nota(8), nil)}, Asistencia: nota(70)},
+			wantCondicion: models.CondicionLibre,
+			wantMotivos:   1,
+		},
+		{
+			name:          "asistencia bajo la mínima para promocionar",
+			reglas:        base,
+			datos:         models.DatosCondicion{Parciales: []models.ParcialCondicion{parcial(nota(8), nil)}, Asistencia: nota(78)},
+			wantCondicion: models.CondicionRegular,
+			wantMotivos:   1,
+		},
+		{
+			name:          "sin asistencia tomada no se controla",
+			reglas:        base,
+			datos:         models.DatosCondicion{Parciales: []models.ParcialCondicion{parcial(nota(8), nil)}},
+			wantCondicion: models.CondicionPromocionado,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resultado := EvaluarCondicion(&tt.reglas, tt.datos)
+			if resultado.Condicion != tt.wantCondicion {
+				t.Errorf("Condicion = %q, want %q (motivos: %v)", resultado.Condicion, tt.wantCondicion, resultado.Motivos)
+			}
+			if resultado.Provisoria != tt.wantProvisoria {
+				t.Errorf("Provisoria = %v, want %v", resultado.Provisoria, tt.wantProvisoria)
+			}
+			if len(resultado.Motivos) != tt.wantMotivos {
+				t.Errorf("Motivos = %v, want %d", resultado.Motivos, tt.wantMotivos)
+			}
+		})
+	}
+}
+
+func TestDatosCondicion(t *testing.T) {
+	reglas := models.ReglasCondicion{NotaAprobacionTp: 4}
+	items := []models.ItemNotaFinal{
+		itemTp(nota(6), models.ItemCalificado),
+		itemTp(nota(3), models.ItemCalificado),
+		itemTp(nil, models.ItemFaltante),
+		itemTp(nil, models.ItemPendiente),
+		// Descartado por el esquema: para las reglas sigue contando su nota
+		itemTp(nota(4), models.ItemDescartado),
+		{Tipo: models.ItemEvaluacion, ID: 1, Nota: nota(6), NotaOriginal: nota(3), NotaRecuperatorio: nota(6), Estado: models.ItemCalificado},
+		{Tipo: models.ItemEvaluacion, ID: 2, Estado: models.ItemPendiente},
+	}
+
+	datos := datosCondicion(&reglas, items)
+	if datos.TpsTotal != 5 || datos.TpsAprobados != 2 || datos.TpsPendientes != 1 {
+		t.Errorf("TPs = %d total, %d aprobados, %d pendientes; want 5, 2, 1", datos.TpsTotal, datos.TpsAprobados, datos.TpsPendientes)
+	}
+	if len(datos.Parciales) != 2 {
+		t.Fatalf("len(Parciales) = %d, want 2", len(datos.Parciales))
+	}
+	if recuperado := datos.Parciales[0]; !mismaNota(recuperado.Nota, nota(3)) || !mismaNota(recuperado.Recuperatorio, nota(6)) {
+		t.Errorf("parcial recuperado = %v / %v, want 3 / 6", valorNota(recuperado.Nota), valorNota(recuperado.Recuperatorio))
+	}
+	if !datos.Parciales[1].Pendiente {
+		t.Errorf("Parciales[1].Pendiente = false, want true")
+	}
+}
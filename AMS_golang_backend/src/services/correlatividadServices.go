@@ -0,0 +1,249 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/LINSITrack/backend/src/models"
+	"gorm.io/gorm"
+)
+
+// CorrelatividadesError indica que el alumno no cumple las correlatividades de
+// la materia en la que se lo quiere inscribir
+type CorrelatividadesError struct {
+	Faltantes []models.CorrelativaFaltante
+}
+
+func (e *CorrelatividadesError) Error() string {
+	return "el alumno no cumple las correlatividades de la materia: " + explicarFaltantes(e.Faltantes)
+}
+
+// CorrelatividadService administra las correlativas de cada materia. Se
+// controlan al crear una cursada (ver CursadaService.CreateCursada).
+type CorrelatividadService struct {
+	db *gorm.DB
+}
+
+func NewCorrelatividadService(db *gorm.DB) *CorrelatividadService {
+	return &CorrelatividadService{db: db}
+}
+
+func (s *CorrelatividadService) GetCorrelatividades(materiaID int) ([]models.Correlatividad, error) {
+	if err := s.db.Select("id").First(&models.Materia{}, materiaID).Error; err != nil {
+		return nil, err
+	}
+	correlatividades := []models.Correlatividad{}
+	if err := s.db.Preload("RequiereMateria").Where("materia_id = ?", materiaID).Order("requiere_materia_id").Find(&correlatividades).Error; err != nil {
+		return nil, err
+	}
+	return correlatividades, nil
+}
+
+// GuardarCorrelatividades reemplaza las correlativas de la materia
+func (s *CorrelatividadService) GuardarCorrelatividades(materiaID int, req *models.CorrelatividadesRequest) ([]models.Correlatividad, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Select("id").First(&models.Materia{}, materiaID).Error; err != nil {
+			return err
+		}
+		vistas := map[int]bool{}
+		for _, correlativa := range req.Correlativas {
+			if !correlativa.Requisito.IsValid() {
+				return fmt.Errorf("requisito inválido para la materia %d (regular o aprobada)", correlativa.MateriaId)
+			}
+			if correlativa.MateriaId == materiaID {
+				return errors.New("una materia no puede ser correlativa de sí misma")
+			}
+			if vistas[correlativa.MateriaId] {
+				return fmt.Errorf("la materia %d figura más de una vez", correlativa.MateriaId)
+			}
+			vistas[correlativa.MateriaId] = true
+			if err := tx.Select("id").First(&models.Materia{}, correlativa.MateriaId).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return fmt.Errorf("la materia %d no existe", correlativa.MateriaId)
+				}
+				return err
+			}
+			if err := validarSinCiclo(tx, materiaID, correlativa.MateriaId); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("materia_id = ?", materiaID).Delete(&models.Correlatividad{}).Error; err != nil {
+			return err
+		}
+		for _, correlativa := range req.Correlativas {
+			nueva := models.Correlatividad{MateriaId: materiaID, RequiereMateriaId: correlativa.MateriaId, Requisito: correlativa.Requisito}
+			if err := tx.Omit("RequiereMateria").Create(&nueva).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetCorrelatividades(materiaID)
+}
+
+// GetEstado indica si el alumno cumple hoy las correlatividades de la materia
+func (s *CorrelatividadService) GetEstado(materiaID, alumnoID int) (*models.EstadoCorrelatividades, error) {
+	if err := s.db.Select("id").First(&models.Materia{}, materiaID).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Select("id").First(&models.Alumno{}, alumnoID).Error; err != nil {
+		return nil, err
+	}
+	faltantes, err := correlativasFaltantes(s.db, alumnoID, materiaID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.EstadoCorrelatividades{AlumnoId: alumnoID, MateriaId: materiaID, Cumple: len(faltantes) == 0, Faltantes: faltantes}, nil
+}
+
+// GetExcepciones devuelve las inscripciones a la materia hechas sin cumplir las correlatividades
+func (s *CorrelatividadService) GetExcepciones(materiaID int) ([]models.ExcepcionCorrelatividad, error) {
+	excepciones := []models.ExcepcionCorrelatividad{}
+	if err := s.db.Where("materia_id = ?", materiaID).Order("created_at DESC, id DESC").Find(&excepciones).Error; err != nil {
+		return nil, err
+	}
+	return excepciones, nil
+}
+
+// validarSinCiclo rechaza la correlativa si requerida ya depende (directa o
+// indirectamente) de materiaID
+func validarSinCiclo(tx *gorm.DB, materiaID, requerida int) error {
+	visitadas := map[int]bool{}
+	pendientes := []int{requerida}
+	for len(pendientes) > 0 {
+		actual := pendientes[0]
+		pendientes = pendientes[1:]
+		if actual == materiaID {
+			return fmt.Errorf("la materia %d ya requiere (directa o indirectamente) a esta materia", requerida)
+		}
+		if visitadas[actual] {
+			continue
+		}
+		visitadas[actual] = true
+		var siguientes []int
+		if err := tx.Model(&models.Correlatividad{}).Where("materia_id = ?", actual).Pluck("requiere_materia_id", &siguientes).Error; err != nil {
+			return err
+		}
+		pendientes = append(pendientes, siguientes...)
+	}
+	return nil
+}
+
+// rangoCondicion ordena las condiciones de menor a mayor
+var rangoCondicion = map[models.CondicionCursada]int{
+	models.CondicionLibre:        1,
+	models.CondicionRegular:      2,
+	models.CondicionPromocionado: 3,
+}
+
+// correlativasFaltantes compara las correlativas de la materia con la mejor
+// condición que el alumno alcanzó en cada una. Solo cuentan las condiciones
+// definitivas: las provisorias (cursada en curso) no habilitan. Las cursadas
+// anteriores al cálculo de condición (sin condición pero con nota final) se
+// clasifican con su nota (ver condicionPorNota).
+func correlativasFaltantes(db *gorm.DB, alumnoID, materiaID int) ([]models.CorrelativaFaltante, error) {
+	var correlatividades []models.Correlatividad
+	if err := db.Preload("RequiereMateria").Where("materia_id = ?", materiaID).Order("requiere_materia_id").Find(&correlatividades).Error; err != nil {
+		return nil, err
+	}
+	faltantes := []models.CorrelativaFaltante{}
+	if len(correlatividades) == 0 {
+		return faltantes, nil
+	}
+
+	materiaIDs := make([]int, len(correlatividades))
+	for i, correlatividad := range correlatividades {
+		materiaIDs[i] = correlatividad.RequiereMateriaId
+	}
+	var cursadas []struct {
+		ComisionId          int
+		MateriaId           int
+		NotaFinal           float64
+		Condicion           models.CondicionCursada
+		NotaFinalProvisoria bool
+		NotaFinalAjustada   bool
+	}
+	if err := db.Table("cursadas").
+		Select("cursadas.comision_id, comisions.materia_id, cursadas.nota_final, cursadas.condicion, cursadas.nota_final_provisoria, cursadas.nota_final_ajustada").
+		Joins("JOIN comisions ON comisions.id = cursadas.comision_id").
+		Where("cursadas.alumno_id = ? AND comisions.materia_id IN ?", alumnoID, materiaIDs).
+		Scan(&cursadas).Error; err != nil {
+		return nil, err
+	}
+	mejor := map[int]models.CondicionCursada{}
+	for _, cursada := range cursadas {
+		if cursada.NotaFinalProvisoria && !cursada.NotaFinalAjustada {
+			continue
+		}
+		condicion := cursada.Condicion
+		if condicion == "" {
+			if cursada.NotaFinal <= 0 {
+				continue
+			}
+			var err error
+			if condicion, err = condicionPorNota(db, cursada.ComisionId, cursada.MateriaId, cursada.NotaFinal); err != nil {
+				return nil, err
+			}
+		}
+		if rangoCondicion[condicion] > rangoCondicion[mejor[cursada.MateriaId]] {
+			mejor[cursada.MateriaId] = condicion
+		}
+	}
+
+	for _, correlatividad := range correlatividades {
+		condicion := mejor[correlatividad.RequiereMateriaId]
+		necesaria := models.CondicionRegular
+		if correlatividad.Requisito == models.CorrelativaAprobada {
+			necesaria = models.CondicionPromocionado
+		}
+		if rangoCondicion[condicion] >= rangoCondicion[necesaria] {
+			continue
+		}
+		faltantes = append(faltantes, models.CorrelativaFaltante{
+			MateriaId: correlatividad.RequiereMateriaId,
+			Materia:   correlatividad.RequiereMateria.Nombre,
+			Requisito: correlatividad.Requisito,
+			Condicion: condicion,
+		})
+	}
+	return faltantes, nil
+}
+
+// condicionPorNota clasifica una nota final con los umbrales del esquema de la
+// comisión (o de su materia); sin esquema se usan los valores por defecto
+func condicionPorNota(db *gorm.DB, comisionID, materiaID int, nota float64) (models.CondicionCursada, error) {
+	notaRegular, notaPromocion := 4.0, 7.0
+	esquema, err := esquemaEfectivo(db, &models.Comision{ID: comisionID, MateriaId: materiaID})
+	switch {
+	case err == nil:
+		notaRegular, notaPromocion = esquema.NotaRegular, esquema.NotaPromocion
+	case !errors.Is(err, ErrSinEsquema):
+		return "", err
+	}
+	switch {
+	case nota >= notaPromocion:
+		return models.CondicionPromocionado, nil
+	case nota >= notaRegular:
+		return models.CondicionRegular, nil
+	}
+	return models.CondicionLibre, nil
+}
+
+// explicarFaltantes describe las correlativas faltantes, por ejemplo
+// "necesita regular en Análisis I (está libre)"
+func explicarFaltantes(faltantes []models.CorrelativaFaltante) string {
+	partes := make([]string, 0, len(faltantes))
+	for _, faltante := range faltantes {
+		estado := "no la cursó"
+		if faltante.Condicion != "" {
+			estado = "está " + string(faltante.Condicion)
+		}
+		partes = append(partes, fmt.Sprintf("necesita %s en %s (%s)", faltante.Requisito, faltante.Materia, estado))
+	}
+	return strings.Join(partes, "; ")
+}
@@ -2,11 +2,15 @@ package services
 
 import (
 	"errors"
+	"strings"
 
 	"github.com/LINSITrack/backend/src/models"
 	"gorm.io/gorm"
 )
 
+// ErrNotaFinalDirecta indica que se quiso cambiar la nota final sin pasar por un ajuste
+var ErrNotaFinalDirecta = errors.New("la nota final no se modifica directamente: usar POST /cursadas/:id/nota-final/ajuste con un motivo")
+
 type CursadaService struct {
 	db *gorm.DB
 }
@@ -15,13 +19,19 @@ func NewCursadaService(db *gorm.DB) *CursadaService {
 	return &CursadaService{db: db}
 }
 
-func (s *CursadaService) GetAllCursadas() ([]models.CursadaResponse, error) {
+// GetAllCursadas devuelve las cursadas, opcionalmente solo las de las comisiones de un período
+func (s *CursadaService) GetAllCursadas(periodoID *int) ([]models.CursadaResponse, error) {
 	var cursadas []models.Cursada
-	result := s.db.Preload("Alumno").Preload("Comision").Preload("Comision.Materia").Find(&cursadas)
+	result := s.db.Preload("Alumno").Preload("Comision").Preload("Comision.Materia").Scopes(filtroPeriodo(periodoID, "comision_id")).Find(&cursadas)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 
+	asistencias, err := resumenesAsistencia(s.db, cursadas)
+	if err != nil {
+		return nil, err
+	}
+
 	responses := make([]models.CursadaResponse, 0, len(cursadas))
 	for _, cursada := range cursadas {
 		response := models.CursadaResponse{
@@ -38,8 +48,12 @@ func (s *CursadaService) GetAllCursadas() ([]models.CursadaResponse, error) {
 				Legajo:   cursada.Alumno.Legajo,
 				Email:    cursada.Alumno.Email,
 			},
-			ComisionID: cursada.ComisionID,
-			Comision:   cursada.Comision,
+			ComisionID:           cursada.ComisionID,
+			Comision:             cursada.Comision,
+			Condicion:            cursada.Condicion,
+			NotaFinalProvisoria:  cursada.NotaFinalProvisoria,
+			NotaFinalAjustada:    cursada.NotaFinalAjustada,
+			PorcentajeAsistencia: asistencias[cursada.ID].Porcentaje,
 		}
 		responses = append(responses, response)
 	}
@@ -54,6 +68,11 @@ func (s *CursadaService) GetCursadaByID(id int) (*models.CursadaResponse, error)
 		return nil, result.Error
 	}
 
+	asistencias, err := resumenesAsistencia(s.db, []models.Cursada{cursada})
+	if err != nil {
+		return nil, err
+	}
+
 	response := &models.CursadaResponse{
 		ID:             cursada.ID,
 		AnoLectivo:     cursada.AnoLectivo,
@@ -68,20 +87,29 @@ func (s *CursadaService) GetCursadaByID(id int) (*models.CursadaResponse, error)
 			Legajo:   cursada.Alumno.Legajo,
 			Email:    cursada.Alumno.Email,
 		},
-		ComisionID: cursada.ComisionID,
-		Comision:   cursada.Comision,
+		ComisionID:           cursada.ComisionID,
+		Comision:             cursada.Comision,
+		Condicion:            cursada.Condicion,
+		NotaFinalProvisoria:  cursada.NotaFinalProvisoria,
+		NotaFinalAjustada:    cursada.NotaFinalAjustada,
+		PorcentajeAsistencia: asistencias[cursada.ID].Porcentaje,
 	}
 
 	return response, nil
 }
 
-func (s *CursadaService) GetCursadaByAlumnoID(alumnoID int) ([]models.CursadaResponse, error) {
+func (s *CursadaService) GetCursadaByAlumnoID(alumnoID int, periodoID *int) ([]models.CursadaResponse, error) {
 	var cursadas []models.Cursada
-	result := s.db.Preload("Alumno").Preload("Comision").Preload("Comision.Materia").Where("alumno_id = ?", alumnoID).Find(&cursadas)
+	result := s.db.Preload("Alumno").Preload("Comision").Preload("Comision.Materia").Scopes(filtroPeriodo(periodoID, "comision_id")).Where("alumno_id = ?", alumnoID).Find(&cursadas)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 
+	asistencias, err := resumenesAsistencia(s.db, cursadas)
+	if err != nil {
+		return nil, err
+	}
+
 	responses := make([]models.CursadaResponse, 0, len(cursadas))
 	for _, cursada := range cursadas {
 		response := models.CursadaResponse{
@@ -98,8 +126,12 @@ func (s *CursadaService) GetCursadaByAlumnoID(alumnoID int) ([]models.CursadaRes
 				Legajo:   cursada.Alumno.Legajo,
 				Email:    cursada.Alumno.Email,
 			},
-			ComisionID: cursada.ComisionID,
-			Comision:   cursada.Comision,
+			ComisionID:           cursada.ComisionID,
+			Comision:             cursada.Comision,
+			Condicion:            cursada.Condicion,
+			NotaFinalProvisoria:  cursada.NotaFinalProvisoria,
+			NotaFinalAjustada:    cursada.NotaFinalAjustada,
+			PorcentajeAsistencia: asistencias[cursada.ID].Porcentaje,
 		}
 		responses = append(responses, response)
 	}
@@ -107,7 +139,10 @@ func (s *CursadaService) GetCursadaByAlumnoID(alumnoID int) ([]models.CursadaRes
 	return responses, nil
 }
 
-func (s *CursadaService) CreateCursada(cursada *models.Cursada) error {
+// CreateCursada inscribe al alumno si cumple las correlatividades de la materia.
+// Si no las cumple, solo un admin puede inscribirlo indicando el motivo, que
+// queda registrado como ExcepcionCorrelatividad.
+func (s *CursadaService) CreateCursada(cursada *models.Cursada, motivoExcepcion string, actorID int, role models.Role) error {
 	var alumno models.Alumno
 	if err := s.db.First(&alumno, cursada.AlumnoID).Error; err != nil {
 		return errors.New("el alumno especificado no existe")
@@ -118,24 +153,43 @@ func (s *CursadaService) CreateCursada(cursada *models.Cursada) error {
 		return errors.New("la comisión especificada no existe")
 	}
 
-	return s.db.Create(cursada).Error
+	motivo := strings.TrimSpace(motivoExcepcion)
+	faltantes, err := verificarCorrelatividades(s.db, cursada.AlumnoID, comision.MateriaId, motivo, role)
+	if err != nil {
+		return err
+	}
+
+	cursada.NotaConceptualCargada = cursada.NotaConceptual != 0
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(cursada).Error; err != nil {
+			return err
+		}
+		return registrarExcepcion(tx, cursada, comision.MateriaId, faltantes, motivo, actorID, role)
+	})
 }
 
-func (s *CursadaService) UpdateCursada(id int, updateRequest *models.CursadaUpdateRequest) (*models.Cursada, error) {
+// UpdateCursada modifica la cursada. Si cambia el alumno o la comisión se
+// vuelven a controlar las correlatividades, igual que al inscribir. La nota
+// final no se cambia acá sino con NotaFinalService.AjustarNotaFinal, que
+// registra el motivo y la condición.
+func (s *CursadaService) UpdateCursada(id int, updateRequest *models.CursadaUpdateRequest, actorID int, role models.Role) (*models.Cursada, error) {
+	if updateRequest.NotaFinal != nil {
+		return nil, ErrNotaFinalDirecta
+	}
+
 	var cursada models.Cursada
 	result := s.db.First(&cursada, id)
 	if result.Error != nil {
 		return nil, result.Error
 	}
+	alumnoAnterior, comisionAnterior := cursada.AlumnoID, cursada.ComisionID
 
 	if updateRequest.AnoLectivo != nil {
 		cursada.AnoLectivo = *updateRequest.AnoLectivo
 	}
-	if updateRequest.NotaFinal != nil {
-		cursada.NotaFinal = *updateRequest.NotaFinal
-	}
 	if updateRequest.NotaConceptual != nil {
 		cursada.NotaConceptual = *updateRequest.NotaConceptual
+		cursada.NotaConceptualCargada = true
 	}
 	if updateRequest.Feedback != nil {
 		cursada.Feedback = *updateRequest.Feedback
@@ -147,19 +201,72 @@ func (s *CursadaService) UpdateCursada(id int, updateRequest *models.CursadaUpda
 		cursada.ComisionID = *updateRequest.ComisionID
 	}
 
-	result = s.db.Save(&cursada)
-	if result.Error != nil {
-		return nil, result.Error
+	var faltantes []models.CorrelativaFaltante
+	var materiaID int
+	motivo := strings.TrimSpace(updateRequest.MotivoExcepcion)
+	if cursada.AlumnoID != alumnoAnterior || cursada.ComisionID != comisionAnterior {
+		if err := s.db.Select("id").First(&models.Alumno{}, cursada.AlumnoID).Error; err != nil {
+			return nil, errors.New("el alumno especificado no existe")
+		}
+		var comision models.Comision
+		if err := s.db.First(&comision, cursada.ComisionID).Error; err != nil {
+			return nil, errors.New("la comisión especificada no existe")
+		}
+		materiaID = comision.MateriaId
+		var err error
+		if faltantes, err = verificarCorrelatividades(s.db, cursada.AlumnoID, materiaID, motivo, role); err != nil {
+			return nil, err
+		}
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&cursada).Error; err != nil {
+			return err
+		}
+		return registrarExcepcion(tx, &cursada, materiaID, faltantes, motivo, actorID, role)
+	})
+	if err != nil {
+		return nil, err
 	}
 	return &cursada, nil
 }
 
+// verificarCorrelatividades devuelve las correlativas que le faltan al alumno
+// para cursar la materia. Si faltan, solo un admin que indique el motivo puede
+// seguir; en otro caso devuelve un *CorrelatividadesError.
+func verificarCorrelatividades(db *gorm.DB, alumnoID, materiaID int, motivo string, role models.Role) ([]models.CorrelativaFaltante, error) {
+	faltantes, err := correlativasFaltantes(db, alumnoID, materiaID)
+	if err != nil {
+		return nil, err
+	}
+	if len(faltantes) > 0 && (motivo == "" || role != models.RoleAdmin) {
+		return nil, &CorrelatividadesError{Faltantes: faltantes}
+	}
+	return faltantes, nil
+}
+
+// registrarExcepcion deja constancia de una inscripción hecha sin cumplir las correlatividades
+func registrarExcepcion(tx *gorm.DB, cursada *models.Cursada, materiaID int, faltantes []models.CorrelativaFaltante, motivo string, actorID int, role models.Role) error {
+	if len(faltantes) == 0 {
+		return nil
+	}
+	return tx.Create(&models.ExcepcionCorrelatividad{
+		CursadaId: cursada.ID,
+		AlumnoId:  cursada.AlumnoID,
+		MateriaId: materiaID,
+		Faltantes: explicarFaltantes(faltantes),
+		Motivo:    motivo,
+		ActorID:   actorID,
+		ActorRole: role,
+	}).Error
+}
+
 func (s *CursadaService) DeleteCursada(id int) error {
 	result := s.db.Delete(&models.Cursada{}, id)
 	return result.Error
 }
 
-func (s *CursadaService) GetCursadasByProfesorID(profesorID int) ([]models.CursadaResponse, error) {
+func (s *CursadaService) GetCursadasByProfesorID(profesorID int, periodoID *int) ([]models.CursadaResponse, error) {
 	var cursadas []models.Cursada
 
 	// Query para obtener todas las cursadas de las comisiones donde el profesor está asignado
@@ -169,12 +276,18 @@ func (s *CursadaService) GetCursadasByProfesorID(profesorID int) ([]models.Cursa
 		Joins("JOIN comisions ON cursadas.comision_id = comisions.id").
 		Joins("JOIN profesor_x_comisions ON comisions.id = profesor_x_comisions.comision_id").
 		Where("profesor_x_comisions.profesor_id = ?", profesorID).
+		Scopes(filtroPeriodo(periodoID, "cursadas.comision_id")).
 		Find(&cursadas)
 
 	if result.Error != nil {
 		return nil, result.Error
 	}
 
+	asistencias, err := resumenesAsistencia(s.db, cursadas)
+	if err != nil {
+		return nil, err
+	}
+
 	responses := make([]models.CursadaResponse, 0, len(cursadas))
 	for _, cursada := range cursadas {
 		response := models.CursadaResponse{
@@ -191,8 +304,12 @@ func (s *CursadaService) GetCursadasByProfesorID(profesorID int) ([]models.Cursa
 				Legajo:   cursada.Alumno.Legajo,
 				Email:    cursada.Alumno.Email,
 			},
-			ComisionID: cursada.ComisionID,
-			Comision:   cursada.Comision,
+			ComisionID:           cursada.ComisionID,
+			Comision:             cursada.Comision,
+			Condicion:            cursada.Condicion,
+			NotaFinalProvisoria:  cursada.NotaFinalProvisoria,
+			NotaFinalAjustada:    cursada.NotaFinalAjustada,
+			PorcentajeAsistencia: asistencias[cursada.ID].Porcentaje,
 		}
 		responses = append(responses, response)
 	}
@@ -224,6 +341,11 @@ func (s *CursadaService) GetCursadasByProfesorAndComision(profesorID, comisionID
 		return nil, result.Error
 	}
 
+	asistencias, err := resumenesAsistencia(s.db, cursadas)
+	if err != nil {
+		return nil, err
+	}
+
 	responses := make([]models.CursadaResponse, 0, len(cursadas))
 	for _, cursada := range cursadas {
 		response := models.CursadaResponse{
@@ -240,11 +362,22 @@ func (s *CursadaService) GetCursadasByProfesorAndComision(profesorID, comisionID
 				Legajo:   cursada.Alumno.Legajo,
 				Email:    cursada.Alumno.Email,
 			},
-			ComisionID: cursada.ComisionID,
-			Comision:   cursada.Comision,
+			ComisionID:           cursada.ComisionID,
+			Comision:             cursada.Comision,
+			Condicion:            cursada.Condicion,
+			NotaFinalProvisoria:  cursada.NotaFinalProvisoria,
+			NotaFinalAjustada:    cursada.NotaFinalAjustada,
+			PorcentajeAsistencia: asistencias[cursada.ID].Porcentaje,
 		}
 		responses = append(responses, response)
 	}
 
 	return responses, nil
-}
\ No newline at end of file
+}
+
+// MigrateNotasConceptuales marca como cargadas las notas conceptuales previas a
+// Cursada.NotaConceptualCargada (las distintas de 0)
+func MigrateNotasConceptuales(db *gorm.DB) error {
+	return db.Model(&models.Cursada{}).Where("nota_conceptual <> 0 AND NOT nota_conceptual_cargada").
+		Update("nota_conceptual_cargada", true).Error
+}
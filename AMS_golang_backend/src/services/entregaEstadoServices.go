@@ -0,0 +1,189 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LINSITrack/backend/src/models"
+	"gorm.io/gorm"
+)
+
+var ErrTransicionInvalida = errors.New("transición de estado no permitida")
+
+// EntregaEstadoService aplica la máquina de estados de EntregaTP: valida cada
+// transición según el rol, la registra y notifica al alumno cuando corresponde.
+type EntregaEstadoService struct {
+	db *gorm.DB
+}
+
+func NewEntregaEstadoService(db *gorm.DB) *EntregaEstadoService {
+	return &EntregaEstadoService{db: db}
+}
+
+// Transicionar cambia el estado de la entrega (y lo guarda) dentro de tx.
+// Un role vacío indica una transición automática del sistema, que no se valida.
+func (s *EntregaEstadoService) Transicionar(tx *gorm.DB, entrega *models.EntregaTP, hasta models.EstadoEntrega, actorID int, role models.Role, comentario string) error {
+	desde := entrega.Estado
+	if desde == "" {
+		desde = models.EstadoPendiente
+	}
+	if !hasta.IsValid() {
+		return fmt.Errorf("%w: estado desconocido %q", ErrTransicionInvalida, hasta)
+	}
+	if role != "" && !models.PuedeTransicionar(role, desde, hasta) {
+		return fmt.Errorf("%w: de %s a %s", ErrTransicionInvalida, desde, hasta)
+	}
+
+	if err := tx.Model(&models.EntregaTP{}).Where("id = ?", entrega.ID).Update("estado", hasta).Error; err != nil {
+		return err
+	}
+	entrega.Estado = hasta
+
+	transicion := models.TransicionEntregaTP{
+		EntregaId:  entrega.ID,
+		Desde:      desde,
+		Hasta:      hasta,
+		ActorID:    actorID,
+		ActorRole:  role,
+		Comentario: comentario,
+	}
+	if err := tx.Create(&transicion).Error; err != nil {
+		return err
+	}
+
+	return s.notificar(tx, entrega, hasta, comentario)
+}
+
+// CambiarEstado es el cambio de estado manual pedido por un profesor o admin.
+// En un TP grupal se aplica a las entregas de todos los integrantes.
+func (s *EntregaEstadoService) CambiarEstado(entregaID int, req *models.CambioEstadoRequest, actorID int, role models.Role) (*models.EntregaTP, error) {
+	var entrega models.EntregaTP
+	if err := s.db.First(&entrega, entregaID).Error; err != nil {
+		return nil, err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.Transicionar(tx, &entrega, req.Estado, actorID, role, req.Comentario); err != nil {
+			return err
+		}
+		companeros, err := entregasDelGrupo(tx, &entrega)
+		if err != nil {
+			return err
+		}
+		for i := range companeros {
+			if companeros[i].Estado == req.Estado {
+				continue
+			}
+			if err := s.Transicionar(tx, &companeros[i], req.Estado, actorID, role, req.Comentario); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &entrega, nil
+}
+
+func (s *EntregaEstadoService) GetTransiciones(entregaID int) ([]models.TransicionEntregaTP, error) {
+	var transiciones []models.TransicionEntregaTP
+	if err := s.db.Where("entrega_id = ?", entregaID).Order("created_at, id").Find(&transiciones).Error; err != nil {
+		return nil, err
+	}
+	return transiciones, nil
+}
+
+// notificar avisa al alumno de los cambios de estado que lo afectan
+func (s *EntregaEstadoService) notificar(tx *gorm.DB, entrega *models.EntregaTP, hasta models.EstadoEntrega, comentario string) error {
+	var tp models.TpModel
+	if err := tx.Preload("Comision.Materia").First(&tp, entrega.TpId).Error; err != nil {
+		return err
+	}
+	materiaNombre := "TP"
+	if tp.Comision.Materia.Nombre != "" {
+		materiaNombre = tp.Comision.Materia.Nombre
+	}
+
+	var mensaje string
+	switch hasta {
+	case models.EstadoCalificado:
+		mensaje = "Tu TP de " + materiaNombre + " ha sido calificado"
+		if entrega.Nota != nil {
+			mensaje += " con nota: " + strconv.FormatFloat(*entrega.Nota, 'f', 1, 64)
+		}
+	case models.EstadoAprobado:
+		mensaje = "Tu TP de " + materiaNombre + " fue aprobado"
+	case models.EstadoDesaprobado:
+		mensaje = "Tu TP de " + materiaNombre + " fue desaprobado"
+	case models.EstadoRequiereRehacer:
+		mensaje = "Tu TP de " + materiaNombre + " requiere que lo rehagas"
+		if comentario != "" {
+			mensaje += ": " + truncateString(comentario, 100)
+		}
+	default:
+		return nil
+	}
+
+	notificacion := models.Notificacion{
+		Mensaje:   mensaje,
+		FechaHora: time.Now(),
+		Leida:     false,
+		AlumnoID:  entrega.AlumnoId,
+	}
+	return tx.Create(&notificacion).Error
+}
+
+// estadosLegacy traduce los valores de texto libre que usaba Estado antes de la
+// máquina de estados
+var estadosLegacy = map[string]models.EstadoEntrega{
+	"entregado":     models.EstadoPendiente,
+	"entregada":     models.EstadoPendiente,
+	"en correccion": models.EstadoEnCorreccion,
+	"en corrección": models.EstadoEnCorreccion,
+	"corregido":     models.EstadoCalificado,
+	"corregida":     models.EstadoCalificado,
+	"rehacer":       models.EstadoRequiereRehacer,
+	"aprobada":      models.EstadoAprobado,
+	"desaprobada":   models.EstadoDesaprobado,
+}
+
+// MigrateEstadosEntrega normaliza los estados de las entregas previas a la
+// máquina de estados: sin esto no se les puede aplicar ninguna transición. Los
+// valores que no se reconocen pasan a calificado si la entrega tiene nota y si
+// no a pendiente.
+func MigrateEstadosEntrega(db *gorm.DB) error {
+	validos := []models.EstadoEntrega{
+		models.EstadoPendiente, models.EstadoEnCorreccion, models.EstadoRequiereRehacer,
+		models.EstadoAprobado, models.EstadoDesaprobado, models.EstadoCalificado,
+	}
+	var entregas []models.EntregaTP
+	if err := db.Select("id", "estado", "nota").Where("estado NOT IN ?", validos).Find(&entregas).Error; err != nil {
+		return err
+	}
+
+	for _, entrega := range entregas {
+		valor := strings.ToLower(strings.TrimSpace(string(entrega.Estado)))
+		estado := models.EstadoEntrega(strings.ReplaceAll(valor, " ", "_"))
+		if !estado.IsValid() {
+			estado = estadosLegacy[valor]
+		}
+		if !estado.IsValid() {
+			estado = models.EstadoPendiente
+			if entrega.Nota != nil {
+				estado = models.EstadoCalificado
+			}
+		}
+		if err := db.Model(&models.EntregaTP{}).Where("id = ?", entrega.ID).Update("estado", estado).Error; err != nil {
+			return err
+		}
+	}
+	if len(entregas) > 0 {
+		log.Printf("Normalizados los estados de %d entregas", len(entregas))
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LINSITrack/backend/src/models"
+	"gorm.io/gorm"
+)
+
+// EntregaZipService arma la descarga en ZIP de las entregas de un TP: una carpeta
+// por alumno ("<legajo>_<apellido>") con el archivo de cada intento y un manifest.csv
+type EntregaZipService struct {
+	db       *gorm.DB
+	archivos *ArchivoService
+}
+
+func NewEntregaZipService(db *gorm.DB, archivos *ArchivoService) *EntregaZipService {
+	return &EntregaZipService{db: db, archivos: archivos}
+}
+
+var manifestHeader = []string{
+	"legajo", "apellido", "nombre", "entrega_id", "intento", "intento_vigente", "archivo",
+	"fecha_entrega", "fecha_limite", "tardia", "dias_tardanza", "penalizacion", "estado", "nota", "observaciones",
+}
+
+// GetEntregas devuelve las entregas del TP que van en el ZIP, con sus intentos
+func (s *EntregaZipService) GetEntregas(tpID int, filtro models.EntregasZipFiltro) ([]models.EntregaTP, error) {
+	query := s.db.Preload("Alumno").
+		Preload("Intentos", func(db *gorm.DB) *gorm.DB { return db.Order("numero") }).
+		Joins("JOIN alumnos ON alumnos.id = entregas_tp.alumno_id").
+		Where("entregas_tp.tp_id = ?", tpID).
+		Order("alumnos.apellido, alumnos.legajo")
+	if filtro.SinCorregir {
+		query = query.Where("entregas_tp.nota IS NULL")
+	}
+
+	var entregas []models.EntregaTP
+	if err := query.Find(&entregas).Error; err != nil {
+		return nil, err
+	}
+	return entregas, nil
+}
+
+// EscribirZip escribe el ZIP en w a medida que lee los archivos del storage.
+// Los archivos que no se pueden incluir quedan anotados en el manifest.
+func (s *EntregaZipService) EscribirZip(w io.Writer, entregas []models.EntregaTP, filtro models.EntregasZipFiltro) error {
+	zw := zip.NewWriter(w)
+	var filas [][]string
+
+	for _, entrega := range entregas {
+		carpeta := nombreSeguro(entrega.Alumno.Legajo + "_" + entrega.Alumno.Apellido)
+		for _, intento := range intentosParaZip(entrega, filtro) {
+			nombre, observaciones, err := s.agregarArchivo(zw, carpeta, intento)
+			if err != nil {
+				return err
+			}
+			filas = append(filas, filaManifest(entrega, intento, nombre, observaciones))
+		}
+	}
+
+	manifest, err := zw.CreateHeader(&zip.FileHeader{Name: "manifest.csv", Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	writer := csv.NewWriter(manifest)
+	writer.Write(manifestHeader)
+	writer.WriteAll(filas)
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// intentosParaZip devuelve los intentos a incluir; las entregas sin historial
+// de intentos se toman como un único intento
+func intentosParaZip(entrega models.EntregaTP, filtro models.EntregasZipFiltro) []models.IntentoEntregaTP {
+	intentos := entrega.Intentos
+	if len(intentos) == 0 {
+		intentos = []models.IntentoEntregaTP{{
+			Numero:       entrega.IntentoActual,
+			ArchivoURL:   entrega.ArchivoURL,
+			FechaEntrega: entrega.FechaEntrega,
+			FechaLimite:  entrega.FechaLimite,
+			Tardia:       entrega.Tardia,
+			DiasTardanza: entrega.DiasTardanza,
+			Penalizacion: entrega.Penalizacion,
+			Nota:         entrega.Nota,
+		}}
+	}
+	if !filtro.SoloUltimoIntento {
+		return intentos
+	}
+	for _, intento := range intentos {
+		if intento.Numero == entrega.IntentoActual {
+			return []models.IntentoEntregaTP{intento}
+		}
+	}
+	return intentos[len(intentos)-1:]
+}
+
+// agregarArchivo copia al ZIP el archivo del intento. Devuelve la ruta dentro del
+// ZIP, o una observación si el archivo no está en el storage.
+func (s *EntregaZipService) agregarArchivo(zw *zip.Writer, carpeta string, intento models.IntentoEntregaTP) (string, string, error) {
+	if intento.ArchivoURL == "" {
+		return "", "sin archivo", nil
+	}
+	archivo, err := s.archivos.GetArchivoByURL(intento.ArchivoURL)
+	if err != nil {
+		return "", "archivo externo: " + intento.ArchivoURL, nil
+	}
+	content, err := s.archivos.Abrir(archivo)
+	if err != nil {
+		return "", "archivo no disponible en el storage", nil
+	}
+	defer content.Close()
+
+	nombre := path.Join(carpeta, fmt.Sprintf("intento-%d_%s", intento.Numero, nombreSeguro(archivo.OriginalName)))
+	dest, err := zw.CreateHeader(&zip.FileHeader{Name: nombre, Method: zip.Deflate, Modified: intento.FechaEntrega})
+	if err != nil {
+		return "", "", err
+	}
+	if _, err := io.Copy(dest, content); err != nil {
+		return "", "", err
+	}
+	return nombre, "", nil
+}
+
+func filaManifest(entrega models.EntregaTP, intento models.IntentoEntregaTP, archivo, observaciones string) []string {
+	fechaLimite := ""
+	if intento.FechaLimite != nil {
+		fechaLimite = intento.FechaLimite.Format(time.RFC3339)
+	}
+	nota := ""
+	if intento.Nota != nil {
+		nota = strconv.FormatFloat(*intento.Nota, 'f', -1, 64)
+	}
+	return []string{
+		entrega.Alumno.Legajo,
+		entrega.Alumno.Apellido,
+		entrega.Alumno.Nombre,
+		strconv.Itoa(entrega.ID),
+		strconv.Itoa(intento.Numero),
+		siNo(intento.Numero == entrega.IntentoActual),
+		archivo,
+		intento.FechaEntrega.Format(time.RFC3339),
+		fechaLimite,
+		siNo(intento.Tardia),
+		strconv.Itoa(intento.DiasTardanza),
+		strconv.FormatFloat(intento.Penalizacion, 'f', -1, 64),
+		string(entrega.Estado),
+		nota,
+		observaciones,
+	}
+}
+
+func siNo(value bool) string {
+	if value {
+		return "si"
+	}
+	return "no"
+}
+
+// nombreSeguro deja un nombre usable como carpeta o archivo dentro del ZIP
+func nombreSeguro(nombre string) string {
+	nombre = strings.Map(func(r rune) rune {
+		switch {
+		case r < 0x20, strings.ContainsRune(`/\:*?"<>|`, r):
+			return '_'
+		case r == ' ':
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(nombre))
+	nombre = strings.Trim(nombre, ".")
+	if nombre == "" {
+		return "sin_nombre"
+	}
+	return nombre
+}
@@ -1,6 +1,8 @@
 package services
 
 import (
+	"errors"
+	"fmt"
 	"strconv"
 	"time"
 
@@ -8,6 +10,11 @@ import (
 	"gorm.io/gorm"
 )
 
+var (
+	ErrRecuperatorioInvalido = errors.New("recuperatorio inválido")
+	ErrTieneRecuperatorio    = errors.New("la evaluación tiene un recuperatorio; eliminalo primero")
+)
+
 type EvaluacionService struct {
 	db *gorm.DB
 }
@@ -16,16 +23,17 @@ func NewEvaluacionService(db *gorm.DB) *EvaluacionService {
 	return &EvaluacionService{db: db}
 }
 
-func (s *EvaluacionService) GetAllEvaluaciones() ([]models.EvaluacionModel, error) {
+// GetAllEvaluaciones devuelve las evaluaciones, opcionalmente solo las de las comisiones de un período
+func (s *EvaluacionService) GetAllEvaluaciones(periodoID *int) ([]models.EvaluacionModel, error) {
 	var evaluaciones []models.EvaluacionModel
-	result := s.db.Preload("Comision").Preload("Comision.Materia").Find(&evaluaciones)
+	result := s.db.Preload("Comision").Preload("Comision.Materia").Scopes(filtroPeriodo(periodoID, "comision_id")).Find(&evaluaciones)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return evaluaciones, nil
 }
 
-func (s *EvaluacionService) GetEvaluacionesByProfesorID(profesorID int) ([]models.EvaluacionModel, error) {
+func (s *EvaluacionService) GetEvaluacionesByProfesorID(profesorID int, periodoID *int) ([]models.EvaluacionModel, error) {
 	var profesorComisiones []models.ProfesorXComision
 	if err := s.db.Where("profesor_id = ?", profesorID).Find(&profesorComisiones).Error; err != nil {
 		return nil, err
@@ -41,7 +49,19 @@ func (s *EvaluacionService) GetEvaluacionesByProfesorID(profesorID int) ([]model
 	}
 
 	var evaluaciones []models.EvaluacionModel
-	result := s.db.Preload("Comision").Preload("Comision.Materia").Where("comision_id IN ?", comisionIDs).Find(&evaluaciones)
+	result := s.db.Preload("Comision").Preload("Comision.Materia").Scopes(filtroPeriodo(periodoID, "comision_id")).Where("comision_id IN ?", comisionIDs).Find(&evaluaciones)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return evaluaciones, nil
+}
+
+// GetEvaluacionesByAlumnoID devuelve las evaluaciones de las comisiones que cursa el alumno
+func (s *EvaluacionService) GetEvaluacionesByAlumnoID(alumnoID int, periodoID *int) ([]models.EvaluacionModel, error) {
+	var evaluaciones []models.EvaluacionModel
+	result := s.db.Preload("Comision").Preload("Comision.Materia").Scopes(filtroPeriodo(periodoID, "comision_id")).
+		Where("comision_id IN (SELECT comision_id FROM cursadas WHERE alumno_id = ?)", alumnoID).
+		Find(&evaluaciones)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -67,6 +87,11 @@ func (s *EvaluacionService) GetEvaluacionesByComisionID(comisionID int) ([]model
 }
 
 func (s *EvaluacionService) CreateEvaluacion(evaluacion *models.EvaluacionModel) error {
+	if evaluacion.RecuperatorioDeId != nil {
+		return s.crearRecuperatorio(evaluacion)
+	}
+	evaluacion.CriterioRecuperatorio = ""
+
 	result := s.db.Create(evaluacion)
 	if result.Error != nil {
 		return result.Error
@@ -116,9 +141,26 @@ func (s *EvaluacionService) UpdateEvaluacion(id int, updateRequest *models.Evalu
 	if updateRequest.Observaciones != nil {
 		evaluacion.Observaciones = *updateRequest.Observaciones
 	}
-	if updateRequest.ComisionId != nil {
+	if updateRequest.ComisionId != nil && *updateRequest.ComisionId != evaluacion.ComisionId {
+		// El recuperatorio y la evaluación que recupera comparten la comisión
+		var recuperatorios int64
+		if err := s.db.Model(&models.EvaluacionModel{}).Where("recuperatorio_de_id = ?", evaluacion.ID).Count(&recuperatorios).Error; err != nil {
+			return nil, err
+		}
+		if evaluacion.RecuperatorioDeId != nil || recuperatorios > 0 {
+			return nil, fmt.Errorf("%w: no se puede cambiar la comisión de un recuperatorio ni de una evaluación recuperada", ErrRecuperatorioInvalido)
+		}
 		evaluacion.ComisionId = *updateRequest.ComisionId
 	}
+	if updateRequest.CriterioRecuperatorio != nil {
+		if evaluacion.RecuperatorioDeId == nil {
+			return nil, fmt.Errorf("%w: la evaluación no es un recuperatorio", ErrRecuperatorioInvalido)
+		}
+		if !updateRequest.CriterioRecuperatorio.IsValid() {
+			return nil, fmt.Errorf("%w: criterio_recuperatorio debe ser mejor o ultima", ErrRecuperatorioInvalido)
+		}
+		evaluacion.CriterioRecuperatorio = *updateRequest.CriterioRecuperatorio
+	}
 
 	result = s.db.Save(&evaluacion)
 	if result.Error != nil {
@@ -128,6 +170,13 @@ func (s *EvaluacionService) UpdateEvaluacion(id int, updateRequest *models.Evalu
 }
 
 func (s *EvaluacionService) DeleteEvaluacion(id int) error {
+	var recuperatorios int64
+	if err := s.db.Model(&models.EvaluacionModel{}).Where("recuperatorio_de_id = ?", id).Count(&recuperatorios).Error; err != nil {
+		return err
+	}
+	if recuperatorios > 0 {
+		return ErrTieneRecuperatorio
+	}
 	result := s.db.Delete(&models.EvaluacionModel{}, id)
 	return result.Error
 }
@@ -170,6 +219,7 @@ func (s *EvaluacionService) UpdateEntregaEvaluacion(evaluacionID, alumnoID int,
 	}
 
 	isGrading := updateRequest.Nota != nil && entrega.Nota == nil
+	cambiaNota := updateRequest.Nota != nil && (entrega.Nota == nil || *entrega.Nota != *updateRequest.Nota)
 
 	if updateRequest.Nota != nil {
 		entrega.Nota = updateRequest.Nota
@@ -181,30 +231,53 @@ func (s *EvaluacionService) UpdateEntregaEvaluacion(evaluacionID, alumnoID int,
 		entrega.Observaciones = updateRequest.Observaciones
 	}
 
-	if err := s.db.Save(&entrega).Error; err != nil {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&entrega).Error; err != nil {
+			return err
+		}
+		if !cambiaNota {
+			return nil
+		}
+		// La nota puede cambiar quién tiene que rendir el recuperatorio y la condición del alumno
+		if err := inscribirRecuperatoriosDe(tx, evaluacionID); err != nil {
+			return err
+		}
+		var evaluacion models.EvaluacionModel
+		if err := tx.Select("id", "comision_id").First(&evaluacion, evaluacionID).Error; err != nil {
+			return err
+		}
+		return RecalcularCursadas(tx, evaluacion.ComisionId, []int{alumnoID})
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	s.db.Preload("Evaluacion").Preload("Evaluacion.Comision").Preload("Evaluacion.Comision.Materia").Preload("Alumno").First(&entrega, entrega.ID)
 
 	if isGrading && entrega.Nota != nil {
-		materiaNombre := "Evaluación"
-		if entrega.Evaluacion.Comision.Materia.Nombre != "" {
-			materiaNombre = entrega.Evaluacion.Comision.Materia.Nombre
-		}
-
-		notificacion := models.Notificacion{
-			Mensaje:   "Tu evaluación de " + materiaNombre + " ha sido calificada con nota: " + strconv.FormatFloat(*entrega.Nota, 'f', 1, 64),
-			FechaHora: time.Now(),
-			Leida:     false,
-			AlumnoID:  entrega.AlumnoId,
-		}
-		s.db.Create(&notificacion)
+		notificarEvaluacionCalificada(s.db, &entrega.Evaluacion, entrega.AlumnoId, *entrega.Nota)
 	}
 
 	return &entrega, nil
 }
 
+// notificarEvaluacionCalificada avisa al alumno la nota de una evaluación
+// (la evaluación debe tener precargada Comision.Materia)
+func notificarEvaluacionCalificada(tx *gorm.DB, evaluacion *models.EvaluacionModel, alumnoID int, nota float64) error {
+	materiaNombre := "Evaluación"
+	if evaluacion.Comision.Materia.Nombre != "" {
+		materiaNombre = evaluacion.Comision.Materia.Nombre
+	}
+
+	notificacion := models.Notificacion{
+		Mensaje:   "Tu evaluación de " + materiaNombre + " ha sido calificada con nota: " + strconv.FormatFloat(nota, 'f', 1, 64),
+		FechaHora: time.Now(),
+		Leida:     false,
+		AlumnoID:  alumnoID,
+	}
+	return tx.Create(&notificacion).Error
+}
+
 func (s *EvaluacionService) SyncEntregasEvaluaciones() error {
 	var evaluaciones []models.EvaluacionModel
 	if err := s.db.Find(&evaluaciones).Error; err != nil {
@@ -212,6 +285,13 @@ func (s *EvaluacionService) SyncEntregasEvaluaciones() error {
 	}
 
 	for _, evaluacion := range evaluaciones {
+		if evaluacion.RecuperatorioDeId != nil {
+			if _, err := inscribirRecuperatorio(s.db, &evaluacion, time.Now()); err != nil {
+				return err
+			}
+			continue
+		}
+
 		var cursadas []models.Cursada
 		if err := s.db.Where("comision_id = ?", evaluacion.ComisionId).Find(&cursadas).Error; err != nil {
 			return err
@@ -235,3 +315,210 @@ func (s *EvaluacionService) SyncEntregasEvaluaciones() error {
 
 	return nil
 }
+
+// crearRecuperatorio crea el recuperatorio e inscribe solo a los alumnos que
+// desaprobaron o estuvieron ausentes en la evaluación que recupera
+func (s *EvaluacionService) crearRecuperatorio(recuperatorio *models.EvaluacionModel) error {
+	if recuperatorio.CriterioRecuperatorio == "" {
+		recuperatorio.CriterioRecuperatorio = models.RecuperatorioMejorNota
+	}
+	if !recuperatorio.CriterioRecuperatorio.IsValid() {
+		return fmt.Errorf("%w: criterio_recuperatorio debe ser mejor o ultima", ErrRecuperatorioInvalido)
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var original models.EvaluacionModel
+		err := tx.First(&original, *recuperatorio.RecuperatorioDeId).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: la evaluación %d no existe", ErrRecuperatorioInvalido, *recuperatorio.RecuperatorioDeId)
+		}
+		if err != nil {
+			return err
+		}
+		switch {
+		case original.ComisionId != recuperatorio.ComisionId:
+			return fmt.Errorf("%w: la evaluación a recuperar es de otra comisión", ErrRecuperatorioInvalido)
+		case original.RecuperatorioDeId != nil:
+			return fmt.Errorf("%w: no se puede recuperar un recuperatorio", ErrRecuperatorioInvalido)
+		case prefijoFecha(recuperatorio.FechaEvaluacion) < prefijoFecha(original.FechaEvaluacion):
+			return fmt.Errorf("%w: el recuperatorio no puede ser anterior a la evaluación", ErrRecuperatorioInvalido)
+		}
+		var existentes int64
+		if err := tx.Model(&models.EvaluacionModel{}).Where("recuperatorio_de_id = ?", original.ID).Count(&existentes).Error; err != nil {
+			return err
+		}
+		if existentes > 0 {
+			return fmt.Errorf("%w: la evaluación ya tiene un recuperatorio", ErrRecuperatorioInvalido)
+		}
+
+		if err := tx.Create(recuperatorio).Error; err != nil {
+			return err
+		}
+		_, err = inscribirRecuperatorio(tx, recuperatorio, time.Now())
+		return err
+	})
+}
+
+// InscribirRecuperatorio vuelve a calcular quiénes tienen que rendir el
+// recuperatorio (por ejemplo, una vez vencida la devolución de la evaluación
+// original, cuando las notas faltantes pasan a ser ausentes)
+func (s *EvaluacionService) InscribirRecuperatorio(id int) (*models.InscripcionRecuperatorio, error) {
+	var resultado *models.InscripcionRecuperatorio
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var recuperatorio models.EvaluacionModel
+		if err := tx.First(&recuperatorio, id).Error; err != nil {
+			return err
+		}
+		if recuperatorio.RecuperatorioDeId == nil {
+			return fmt.Errorf("%w: la evaluación %d no es un recuperatorio", ErrRecuperatorioInvalido, id)
+		}
+		var err error
+		resultado, err = inscribirRecuperatorio(tx, &recuperatorio, time.Now())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resultado, nil
+}
+
+// inscribirRecuperatoriosDe actualiza los inscriptos de los recuperatorios de una evaluación
+func inscribirRecuperatoriosDe(tx *gorm.DB, evaluacionID int) error {
+	var recuperatorios []models.EvaluacionModel
+	if err := tx.Where("recuperatorio_de_id = ?", evaluacionID).Find(&recuperatorios).Error; err != nil {
+		return err
+	}
+	ahora := time.Now()
+	for i := range recuperatorios {
+		if _, err := inscribirRecuperatorio(tx, &recuperatorios[i], ahora); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inscribirRecuperatorio deja inscriptos en el recuperatorio a los alumnos del
+// año lectivo de la evaluación original que la desaprobaron o, vencida su fecha
+// de devolución, no tienen nota. Quita a los que dejaron de corresponder salvo
+// que ya hayan rendido el recuperatorio.
+func inscribirRecuperatorio(tx *gorm.DB, recuperatorio *models.EvaluacionModel, ahora time.Time) (*models.InscripcionRecuperatorio, error) {
+	var original models.EvaluacionModel
+	if err := tx.Preload("Comision.Materia").First(&original, *recuperatorio.RecuperatorioDeId).Error; err != nil {
+		return nil, err
+	}
+	umbral, err := notaAprobacionParcial(tx, &original.Comision)
+	if err != nil {
+		return nil, err
+	}
+	fecha, err := time.Parse("2006-01-02", prefijoFecha(original.FechaEvaluacion))
+	if err != nil {
+		return nil, fmt.Errorf("fecha de evaluación inválida: %s", original.FechaEvaluacion)
+	}
+	devolucion, err := time.ParseInLocation("2006-01-02", prefijoFecha(original.FechaDevolucion), ahora.Location())
+	ausentes := err == nil && !ahora.Before(devolucion.AddDate(0, 0, 1))
+
+	var cursadas []models.Cursada
+	if err := tx.Where("comision_id = ? AND ano_lectivo = ?", original.ComisionId, fecha.Year()).Order("id").Find(&cursadas).Error; err != nil {
+		return nil, err
+	}
+	var notasOriginal []models.EntregaEvaluacion
+	if err := tx.Select("alumno_id", "nota").Where("evaluacion_id = ?", original.ID).Find(&notasOriginal).Error; err != nil {
+		return nil, err
+	}
+	notaDe := map[int]*float64{}
+	for _, entrega := range notasOriginal {
+		notaDe[entrega.AlumnoId] = entrega.Nota
+	}
+	elegible := map[int]bool{}
+	for _, cursada := range cursadas {
+		nota := notaDe[cursada.AlumnoID]
+		if (nota != nil && *nota < umbral) || (nota == nil && ausentes) {
+			elegible[cursada.AlumnoID] = true
+		}
+	}
+
+	resultado := &models.InscripcionRecuperatorio{
+		EvaluacionId:      recuperatorio.ID,
+		RecuperatorioDeId: original.ID,
+		NotaAprobacion:    umbral,
+		Inscriptos:        []int{},
+		Agregados:         []int{},
+		Quitados:          []int{},
+	}
+
+	var inscriptas []models.EntregaEvaluacion
+	if err := tx.Where("evaluacion_id = ?", recuperatorio.ID).Order("id").Find(&inscriptas).Error; err != nil {
+		return nil, err
+	}
+	inscripto := map[int]bool{}
+	for _, entrega := range inscriptas {
+		if !elegible[entrega.AlumnoId] && entrega.Nota == nil && entrega.ArchivoURL == nil {
+			if err := tx.Delete(&entrega).Error; err != nil {
+				return nil, err
+			}
+			resultado.Quitados = append(resultado.Quitados, entrega.AlumnoId)
+			continue
+		}
+		inscripto[entrega.AlumnoId] = true
+		resultado.Inscriptos = append(resultado.Inscriptos, entrega.AlumnoId)
+	}
+
+	for _, cursada := range cursadas {
+		if !elegible[cursada.AlumnoID] || inscripto[cursada.AlumnoID] {
+			continue
+		}
+		entrega := models.EntregaEvaluacion{EvaluacionId: recuperatorio.ID, AlumnoId: cursada.AlumnoID}
+		if err := tx.Create(&entrega).Error; err != nil {
+			return nil, err
+		}
+		if err := notificarInscripcionRecuperatorio(tx, &original, recuperatorio, cursada.AlumnoID); err != nil {
+			return nil, err
+		}
+		inscripto[cursada.AlumnoID] = true
+		resultado.Inscriptos = append(resultado.Inscriptos, cursada.AlumnoID)
+		resultado.Agregados = append(resultado.Agregados, cursada.AlumnoID)
+	}
+	return resultado, nil
+}
+
+// notaAprobacionParcial es la nota con la que se aprueba una evaluación de la
+// comisión: la de las reglas de condición de la materia o, si no tiene, la
+// mínima del esquema de calificación (4 si tampoco hay esquema)
+func notaAprobacionParcial(db *gorm.DB, comision *models.Comision) (float64, error) {
+	reglas, err := reglasDeMateria(db, comision.MateriaId)
+	if err != nil {
+		return 0, err
+	}
+	if reglas != nil {
+		return reglas.NotaAprobacionParcial, nil
+	}
+	esquema, err := esquemaEfectivo(db, comision)
+	if errors.Is(err, ErrSinEsquema) {
+		return 4, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return esquema.NotaMinimaParcial, nil
+}
+
+// notificarInscripcionRecuperatorio avisa al alumno que tiene que rendir el recuperatorio
+// (la evaluación original debe tener precargada Comision.Materia)
+func notificarInscripcionRecuperatorio(tx *gorm.DB, original, recuperatorio *models.EvaluacionModel, alumnoID int) error {
+	materiaNombre := original.Comision.Nombre
+	if original.Comision.Materia.Nombre != "" {
+		materiaNombre = original.Comision.Materia.Nombre
+	}
+	mensaje := "Se te inscribió en el recuperatorio de " + materiaNombre
+	if fecha, err := time.Parse("2006-01-02", prefijoFecha(recuperatorio.FechaEvaluacion)); err == nil {
+		mensaje += " del " + fecha.Format("02/01/2006")
+	}
+
+	notificacion := models.Notificacion{
+		Mensaje:   mensaje,
+		FechaHora: time.Now(),
+		Leida:     false,
+		AlumnoID:  alumnoID,
+	}
+	return tx.Create(&notificacion).Error
+}
@@ -0,0 +1,331 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/LINSITrack/backend/src/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTpIndividual      = errors.New("el TP no es grupal")
+	ErrGrupoConEntregas  = errors.New("el grupo ya tiene entregas y no se puede modificar")
+	ErrGrupoNoPermitido  = errors.New("los alumnos no pueden armar grupos para este TP")
+	ErrNoIntegranteGrupo = errors.New("no sos integrante de este grupo")
+)
+
+// GrupoService administra los grupos de los TPs grupales y mantiene
+// sincronizadas las entregas de sus integrantes.
+type GrupoService struct {
+	db      *gorm.DB
+	estados *EntregaEstadoService
+}
+
+func NewGrupoService(db *gorm.DB, estados *EntregaEstadoService) *GrupoService {
+	return &GrupoService{db: db, estados: estados}
+}
+
+func (s *GrupoService) GetGruposByTp(tpID int) ([]models.GrupoTP, error) {
+	var grupos []models.GrupoTP
+	if err := s.db.Preload("Integrantes.Alumno").Where("tp_id = ?", tpID).Order("id").Find(&grupos).Error; err != nil {
+		return nil, err
+	}
+	return grupos, nil
+}
+
+// GetGrupoDeAlumno devuelve el grupo del alumno para el TP (gorm.ErrRecordNotFound si no tiene)
+func (s *GrupoService) GetGrupoDeAlumno(tpID, alumnoID int) (*models.GrupoTP, error) {
+	var integrante models.IntegranteGrupoTP
+	if err := s.db.Where("tp_id = ? AND alumno_id = ?", tpID, alumnoID).First(&integrante).Error; err != nil {
+		return nil, err
+	}
+	return s.getGrupo(tpID, integrante.GrupoId)
+}
+
+// IntegrantesDe devuelve los IDs del alumno y de sus compañeros de grupo en el TP
+func (s *GrupoService) IntegrantesDe(tpID, alumnoID int) ([]int, error) {
+	var integrante models.IntegranteGrupoTP
+	err := s.db.Where("tp_id = ? AND alumno_id = ?", tpID, alumnoID).First(&integrante).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return []int{alumnoID}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var ids []int
+	if err := s.db.Model(&models.IntegranteGrupoTP{}).Where("grupo_id = ?", integrante.GrupoId).Pluck("alumno_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// CrearGrupo arma un grupo para el TP. Un alumno solo puede hacerlo si el TP
+// lo permite, y siempre queda como integrante del grupo que arma.
+func (s *GrupoService) CrearGrupo(tpID int, req *models.GrupoCreateRequest, actorID int, role models.Role) (*models.GrupoTP, error) {
+	var tp models.TpModel
+	if err := s.db.First(&tp, tpID).Error; err != nil {
+		return nil, err
+	}
+	if tp.TamanoMaxGrupo == 0 {
+		return nil, ErrTpIndividual
+	}
+
+	alumnoIDs := req.AlumnoIds
+	if role == models.RoleAlumno {
+		if !tp.GruposPorAlumnos {
+			return nil, ErrGrupoNoPermitido
+		}
+		alumnoIDs = append([]int{actorID}, alumnoIDs...)
+	}
+
+	grupo := models.GrupoTP{
+		TpId:          tpID,
+		Nombre:        strings.TrimSpace(req.Nombre),
+		CreadoPor:     actorID,
+		CreadoPorRole: role,
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		ids, err := validarIntegrantes(tx, &tp, alumnoIDs, 0)
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(&grupo).Error; err != nil {
+			return err
+		}
+		return crearIntegrantes(tx, &grupo, ids)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.getGrupo(tpID, grupo.ID)
+}
+
+// ActualizarGrupo cambia el nombre y/o los integrantes. Los integrantes no se
+// pueden cambiar una vez que el grupo entregó.
+func (s *GrupoService) ActualizarGrupo(tpID, grupoID int, req *models.GrupoUpdateRequest) (*models.GrupoTP, error) {
+	grupo, err := s.getGrupo(tpID, grupoID)
+	if err != nil {
+		return nil, err
+	}
+	var tp models.TpModel
+	if err := s.db.First(&tp, tpID).Error; err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if req.Nombre != nil {
+			if err := tx.Model(&models.GrupoTP{}).Where("id = ?", grupo.ID).Update("nombre", strings.TrimSpace(*req.Nombre)).Error; err != nil {
+				return err
+			}
+		}
+		if req.AlumnoIds == nil {
+			return nil
+		}
+
+		if err := verificarSinEntregas(tx, grupo.ID); err != nil {
+			return err
+		}
+		ids, err := validarIntegrantes(tx, &tp, req.AlumnoIds, grupo.ID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("grupo_id = ?", grupo.ID).Delete(&models.IntegranteGrupoTP{}).Error; err != nil {
+			return err
+		}
+		return crearIntegrantes(tx, grupo, ids)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.getGrupo(tpID, grupoID)
+}
+
+// EliminarGrupo disuelve un grupo sin entregas. Un alumno solo puede disolver su propio grupo.
+func (s *GrupoService) EliminarGrupo(tpID, grupoID int, actorID int, role models.Role) error {
+	grupo, err := s.getGrupo(tpID, grupoID)
+	if err != nil {
+		return err
+	}
+	if role == models.RoleAlumno && !esIntegrante(grupo, actorID) {
+		return ErrNoIntegranteGrupo
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := verificarSinEntregas(tx, grupo.ID); err != nil {
+			return err
+		}
+		if err := tx.Where("grupo_id = ?", grupo.ID).Delete(&models.IntegranteGrupoTP{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.GrupoTP{}, grupo.ID).Error
+	})
+}
+
+// EntregasIntegrantes devuelve las entregas del resto del grupo del alumno para
+// el TP, creando en memoria (sin guardar) las que todavía no existen. Devuelve
+// nil si el alumno no tiene grupo.
+func (s *GrupoService) EntregasIntegrantes(tx *gorm.DB, tp *models.TpModel, alumnoID int) (*int, []models.EntregaTP, error) {
+	var integrante models.IntegranteGrupoTP
+	err := tx.Where("tp_id = ? AND alumno_id = ?", tp.ID, alumnoID).First(&integrante).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var companeros []models.IntegranteGrupoTP
+	if err := tx.Where("grupo_id = ? AND alumno_id <> ?", integrante.GrupoId, alumnoID).Order("alumno_id").Find(&companeros).Error; err != nil {
+		return nil, nil, err
+	}
+
+	grupoID := integrante.GrupoId
+	entregas := make([]models.EntregaTP, 0, len(companeros))
+	for _, c := range companeros {
+		entrega := models.EntregaTP{TpId: tp.ID, AlumnoId: c.AlumnoId, GrupoId: &grupoID}
+		err := tx.Where("tp_id = ? AND alumno_id = ?", tp.ID, c.AlumnoId).First(&entrega).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			var cursada models.Cursada
+			if err := tx.Where("alumno_id = ? AND comision_id = ?", c.AlumnoId, tp.ComisionId).First(&cursada).Error; err != nil {
+				return nil, nil, fmt.Errorf("el alumno %d no cursa en la comisión del TP: %w", c.AlumnoId, err)
+			}
+			entrega.CursadaId = cursada.ID
+		} else if err != nil {
+			return nil, nil, err
+		}
+		entrega.GrupoId = &grupoID
+		entregas = append(entregas, entrega)
+	}
+	return &grupoID, entregas, nil
+}
+
+// PropagarCorreccion copia nota, devolución y estado de la entrega a las del
+// resto del grupo (cada integrante recibe su transición y su notificación).
+func (s *GrupoService) PropagarCorreccion(tx *gorm.DB, entrega *models.EntregaTP, nuevoEstado models.EstadoEntrega, actorID int, role models.Role, comentario string) error {
+	companeros, err := entregasDelGrupo(tx, entrega)
+	if err != nil {
+		return err
+	}
+	for i := range companeros {
+		companero := &companeros[i]
+		companero.Nota = entrega.Nota
+		companero.NotaSinPenalizacion = entrega.NotaSinPenalizacion
+		companero.Devolucion = entrega.Devolucion
+		if err := tx.Omit("Estado", "Intentos", "Tp", "Alumno", "Cursada").Save(companero).Error; err != nil {
+			return err
+		}
+		if err := calificarIntento(tx, companero); err != nil {
+			return err
+		}
+		if nuevoEstado != "" && companero.Estado != nuevoEstado {
+			if err := s.estados.Transicionar(tx, companero, nuevoEstado, actorID, role, comentario); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *GrupoService) getGrupo(tpID, grupoID int) (*models.GrupoTP, error) {
+	var grupo models.GrupoTP
+	if err := s.db.Preload("Integrantes.Alumno").Where("tp_id = ?", tpID).First(&grupo, grupoID).Error; err != nil {
+		return nil, err
+	}
+	return &grupo, nil
+}
+
+// entregasDelGrupo devuelve las entregas de los demás integrantes del grupo de la entrega
+func entregasDelGrupo(tx *gorm.DB, entrega *models.EntregaTP) ([]models.EntregaTP, error) {
+	if entrega.GrupoId == nil {
+		return nil, nil
+	}
+	var entregas []models.EntregaTP
+	if err := tx.Where("tp_id = ? AND grupo_id = ? AND id <> ?", entrega.TpId, *entrega.GrupoId, entrega.ID).
+		Order("id").Find(&entregas).Error; err != nil {
+		return nil, err
+	}
+	return entregas, nil
+}
+
+// validarIntegrantes controla tamaño, que todos cursen la comisión del TP, que
+// no estén en otro grupo y que no hayan entregado individualmente. Devuelve los IDs sin repetidos.
+func validarIntegrantes(tx *gorm.DB, tp *models.TpModel, alumnoIDs []int, grupoID int) ([]int, error) {
+	ids := make([]int, 0, len(alumnoIDs))
+	vistos := map[int]bool{}
+	for _, id := range alumnoIDs {
+		if !vistos[id] {
+			vistos[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) < 2 {
+		return nil, errors.New("un grupo necesita al menos 2 integrantes")
+	}
+	if len(ids) > tp.TamanoMaxGrupo {
+		return nil, fmt.Errorf("el grupo supera el máximo de %d integrantes", tp.TamanoMaxGrupo)
+	}
+
+	var cursan int64
+	if err := tx.Model(&models.Cursada{}).
+		Where("comision_id = ? AND alumno_id IN ?", tp.ComisionId, ids).
+		Distinct("alumno_id").Count(&cursan).Error; err != nil {
+		return nil, err
+	}
+	if int(cursan) != len(ids) {
+		return nil, errors.New("todos los integrantes deben cursar en la comisión del TP")
+	}
+
+	var enOtroGrupo int64
+	if err := tx.Model(&models.IntegranteGrupoTP{}).
+		Where("tp_id = ? AND alumno_id IN ? AND grupo_id <> ?", tp.ID, ids, grupoID).
+		Count(&enOtroGrupo).Error; err != nil {
+		return nil, err
+	}
+	if enOtroGrupo > 0 {
+		return nil, errors.New("algún integrante ya pertenece a otro grupo de este TP")
+	}
+
+	var entregados int64
+	if err := tx.Model(&models.EntregaTP{}).
+		Where("tp_id = ? AND alumno_id IN ?", tp.ID, ids).
+		Count(&entregados).Error; err != nil {
+		return nil, err
+	}
+	if entregados > 0 {
+		return nil, errors.New("algún integrante ya entregó este TP")
+	}
+
+	return ids, nil
+}
+
+func crearIntegrantes(tx *gorm.DB, grupo *models.GrupoTP, alumnoIDs []int) error {
+	for _, alumnoID := range alumnoIDs {
+		integrante := models.IntegranteGrupoTP{GrupoId: grupo.ID, TpId: grupo.TpId, AlumnoId: alumnoID}
+		if err := tx.Create(&integrante).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func verificarSinEntregas(tx *gorm.DB, grupoID int) error {
+	var count int64
+	if err := tx.Model(&models.EntregaTP{}).Where("grupo_id = ?", grupoID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrGrupoConEntregas
+	}
+	return nil
+}
+
+func esIntegrante(grupo *models.GrupoTP, alumnoID int) bool {
+	for _, integrante := range grupo.Integrantes {
+		if integrante.AlumnoId == alumnoID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,363 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/LINSITrack/backend/src/models"
+	"github.com/LINSITrack/backend/utils/horario"
+	"gorm.io/gorm"
+)
+
+// ChoquesHorarioError indica que la franja se superpone con otras clases de la
+// misma comisión o de comisiones a las que asisten sus profesores o alumnos
+type ChoquesHorarioError struct {
+	Choques []models.ChoqueHorario
+}
+
+func (e *ChoquesHorarioError) Error() string {
+	return fmt.Sprintf("la franja se superpone con otros horarios (%d choques)", len(e.Choques))
+}
+
+// HorarioService administra las franjas semanales de clase de cada comisión y
+// mantiene Comision.Horarios como su resumen en texto
+type HorarioService struct {
+	db *gorm.DB
+}
+
+func NewHorarioService(db *gorm.DB) *HorarioService {
+	return &HorarioService{db: db}
+}
+
+func (s *HorarioService) GetHorarios(comisionID int) ([]models.HorarioComision, error) {
+	if err := s.db.Select("id").First(&models.Comision{}, comisionID).Error; err != nil {
+		return nil, err
+	}
+	return franjasDeComision(s.db, comisionID)
+}
+
+// CrearHorario agrega una franja a la comisión si no genera choques
+func (s *HorarioService) CrearHorario(comisionID int, req *models.HorarioComisionRequest) (*models.HorarioComision, error) {
+	franja, err := horarioDesdeRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	franja.ComisionId = comisionID
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Select("id").First(&models.Comision{}, comisionID).Error; err != nil {
+			return err
+		}
+		if err := validarChoques(tx, franja); err != nil {
+			return err
+		}
+		if err := tx.Create(franja).Error; err != nil {
+			return err
+		}
+		return actualizarTextoHorarios(tx, comisionID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return franja, nil
+}
+
+// UpdateHorario reemplaza la franja si no genera choques
+func (s *HorarioService) UpdateHorario(id int, req *models.HorarioComisionRequest) (*models.HorarioComision, error) {
+	franja, err := horarioDesdeRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var existente models.HorarioComision
+		if err := tx.First(&existente, id).Error; err != nil {
+			return err
+		}
+		franja.ID = existente.ID
+		franja.ComisionId = existente.ComisionId
+		if err := validarChoques(tx, franja); err != nil {
+			return err
+		}
+		if err := tx.Save(franja).Error; err != nil {
+			return err
+		}
+		return actualizarTextoHorarios(tx, franja.ComisionId)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return franja, nil
+}
+
+func (s *HorarioService) DeleteHorario(id int) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var franja models.HorarioComision
+		if err := tx.First(&franja, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&franja).Error; err != nil {
+			return err
+		}
+		return actualizarTextoHorarios(tx, franja.ComisionId)
+	})
+}
+
+// horarioDesdeRequest valida el pedido y completa los valores por defecto
+func horarioDesdeRequest(req *models.HorarioComisionRequest) (*models.HorarioComision, error) {
+	if *req.Dia < 0 || *req.Dia > 6 {
+		return nil, errors.New("dia inválido (0 = domingo ... 6 = sábado)")
+	}
+	inicio, err := horario.ParseHora(req.HoraInicio)
+	if err != nil {
+		return nil, err
+	}
+	fin, err := horario.ParseHora(req.HoraFin)
+	if err != nil {
+		return nil, err
+	}
+	if fin <= inicio {
+		return nil, errors.New("hora_fin debe ser posterior a hora_inicio")
+	}
+	franja := &models.HorarioComision{
+		Dia:        *req.Dia,
+		HoraInicio: horario.FormatHora(inicio),
+		HoraFin:    horario.FormatHora(fin),
+		Aula:       strings.TrimSpace(req.Aula),
+		Modalidad:  req.Modalidad,
+	}
+	if franja.Modalidad == "" {
+		franja.Modalidad = models.ModalidadPresencial
+	}
+	if !franja.Modalidad.IsValid() {
+		return nil, errors.New("modalidad inválida (presencial, virtual o hibrida)")
+	}
+
+	for _, fecha := range []struct {
+		valor   *string
+		destino **time.Time
+		campo   string
+	}{
+		{req.VigenteDesde, &franja.VigenteDesde, "vigente_desde"},
+		{req.VigenteHasta, &franja.VigenteHasta, "vigente_hasta"},
+	} {
+		if fecha.valor == nil || *fecha.valor == "" {
+			continue
+		}
+		t, err := time.Parse("2006-01-02", *fecha.valor)
+		if err != nil {
+			return nil, fmt.Errorf("%s inválida (AAAA-MM-DD)", fecha.campo)
+		}
+		*fecha.destino = &t
+	}
+	if franja.VigenteDesde != nil && franja.VigenteHasta != nil && franja.VigenteHasta.Before(*franja.VigenteDesde) {
+		return nil, errors.New("vigente_hasta no puede ser anterior a vigente_desde")
+	}
+	return franja, nil
+}
+
+// ordenFranjas ordena las franjas de lunes a domingo
+const ordenFranjas = "(dia + 6) % 7, hora_inicio, id"
+
+// franjasDeComision devuelve las franjas de la comisión ordenadas de lunes a domingo
+func franjasDeComision(db *gorm.DB, comisionID int) ([]models.HorarioComision, error) {
+	franjas := []models.HorarioComision{}
+	if err := db.Where("comision_id = ?", comisionID).Order(ordenFranjas).Find(&franjas).Error; err != nil {
+		return nil, err
+	}
+	return franjas, nil
+}
+
+// validarChoques busca las franjas que se superponen (mismo día, horario y
+// vigencia) con la comisión o con otras comisiones de sus profesores y alumnos
+func validarChoques(tx *gorm.DB, franja *models.HorarioComision) error {
+	query := tx.Model(&models.HorarioComision{}).
+		Joins("JOIN comisions ON comisions.id = horarios_comision.comision_id").
+		Where("horarios_comision.dia = ? AND horarios_comision.hora_inicio < ? AND horarios_comision.hora_fin > ?", franja.Dia, franja.HoraFin, franja.HoraInicio).
+		Where("horarios_comision.id <> ?", franja.ID)
+	if franja.VigenteHasta != nil {
+		query = query.Where("horarios_comision.vigente_desde IS NULL OR horarios_comision.vigente_desde <= ?", *franja.VigenteHasta)
+	}
+	if franja.VigenteDesde != nil {
+		query = query.Where("horarios_comision.vigente_hasta IS NULL OR horarios_comision.vigente_hasta >= ?", *franja.VigenteDesde)
+	}
+	var solapadas []struct {
+		models.HorarioComision
+		ComisionNombre string
+	}
+	if err := query.Select("horarios_comision.*, comisions.nombre AS comision_nombre").
+		Scan(&solapadas).Error; err != nil {
+		return err
+	}
+	if len(solapadas) == 0 {
+		return nil
+	}
+
+	var choques []models.ChoqueHorario
+	choque := func(tipo string, personaID int, persona string, otra models.HorarioComision, comision string) {
+		choques = append(choques, models.ChoqueHorario{
+			Tipo:       tipo,
+			PersonaId:  personaID,
+			Persona:    persona,
+			ComisionId: otra.ComisionId,
+			Comision:   comision,
+			HorarioId:  otra.ID,
+			Dia:        otra.Dia,
+			HoraInicio: otra.HoraInicio,
+			HoraFin:    otra.HoraFin,
+		})
+	}
+
+	otrasPorComision := map[int][]int{}
+	var otrasComisiones []int
+	for i, otra := range solapadas {
+		if otra.ComisionId == franja.ComisionId {
+			choque(models.ChoqueComision, 0, "", otra.HorarioComision, otra.ComisionNombre)
+			continue
+		}
+		if _, ok := otrasPorComision[otra.ComisionId]; !ok {
+			otrasComisiones = append(otrasComisiones, otra.ComisionId)
+		}
+		otrasPorComision[otra.ComisionId] = append(otrasPorComision[otra.ComisionId], i)
+	}
+
+	if len(otrasComisiones) > 0 {
+		// Profesores asignados a esta comisión y a alguna de las otras
+		var profesores []struct {
+			ProfesorId int
+			ComisionId int
+			Nombre     string
+			Apellido   string
+		}
+		if err := tx.Table("profesor_x_comisions AS otra").
+			Select("otra.profesor_id, otra.comision_id, profesors.nombre, profesors.apellido").
+			Joins("JOIN profesor_x_comisions AS propia ON propia.profesor_id = otra.profesor_id AND propia.comision_id = ?", franja.ComisionId).
+			Joins("JOIN profesors ON profesors.id = otra.profesor_id").
+			Where("otra.comision_id IN ?", otrasComisiones).
+			Order("profesors.apellido, profesors.nombre").
+			Scan(&profesores).Error; err != nil {
+			return err
+		}
+		for _, profesor := range profesores {
+			for _, i := range otrasPorComision[profesor.ComisionId] {
+				choque(models.ChoqueProfesor, profesor.ProfesorId, profesor.Apellido+", "+profesor.Nombre, solapadas[i].HorarioComision, solapadas[i].ComisionNombre)
+			}
+		}
+
+		// Alumnos que cursan esta comisión y alguna de las otras el mismo año lectivo
+		alumnosQuery := tx.Table("cursadas AS otra").
+			Select("DISTINCT otra.alumno_id, otra.comision_id, alumnos.nombre, alumnos.apellido").
+			Joins("JOIN cursadas AS propia ON propia.alumno_id = otra.alumno_id AND propia.ano_lectivo = otra.ano_lectivo AND propia.comision_id = ?", franja.ComisionId).
+			Joins("JOIN alumnos ON alumnos.id = otra.alumno_id").
+			Where("otra.comision_id IN ?", otrasComisiones)
+		if franja.VigenteDesde != nil {
+			alumnosQuery = alumnosQuery.Where("otra.ano_lectivo >= ?", franja.VigenteDesde.Year())
+		}
+		if franja.VigenteHasta != nil {
+			alumnosQuery = alumnosQuery.Where("otra.ano_lectivo <= ?", franja.VigenteHasta.Year())
+		}
+		var alumnos []struct {
+			AlumnoId   int
+			ComisionId int
+			Nombre     string
+			Apellido   string
+		}
+		if err := alumnosQuery.Order("alumnos.apellido, alumnos.nombre").Scan(&alumnos).Error; err != nil {
+			return err
+		}
+		for _, alumno := range alumnos {
+			for _, i := range otrasPorComision[alumno.ComisionId] {
+				choque(models.ChoqueAlumno, alumno.AlumnoId, alumno.Apellido+", "+alumno.Nombre, solapadas[i].HorarioComision, solapadas[i].ComisionNombre)
+			}
+		}
+	}
+
+	if len(choques) > 0 {
+		return &ChoquesHorarioError{Choques: choques}
+	}
+	return nil
+}
+
+// actualizarTextoHorarios regenera Comision.Horarios a partir de las franjas
+func actualizarTextoHorarios(tx *gorm.DB, comisionID int) error {
+	franjas, err := franjasDeComision(tx, comisionID)
+	if err != nil {
+		return err
+	}
+	partes := make([]string, 0, len(franjas))
+	for _, franja := range franjas {
+		texto := horario.NombreDia(time.Weekday(franja.Dia)) + " " + franja.HoraInicio + "-" + franja.HoraFin
+		if franja.Aula != "" {
+			texto += " (" + franja.Aula + ")"
+		}
+		partes = append(partes, texto)
+	}
+	texto := strings.Join(partes, ", ")
+	if len(texto) > 255 {
+		texto = strings.ToValidUTF8(texto[:252], "") + "..."
+	}
+	return tx.Model(&models.Comision{}).Where("id = ?", comisionID).Update("horarios", texto).Error
+}
+
+// franjasDesdeTexto crea las franjas de una comisión que todavía no tiene a
+// partir del texto libre de Comision.Horarios. Si el texto no se puede
+// interpretar, la comisión queda sin franjas. Con validar, las franjas que se
+// superponen con otras devuelven un ChoquesHorarioError con todos los choques.
+func franjasDesdeTexto(tx *gorm.DB, comision *models.Comision, validar bool) error {
+	var existentes int64
+	if err := tx.Model(&models.HorarioComision{}).Where("comision_id = ?", comision.ID).Count(&existentes).Error; err != nil {
+		return err
+	}
+	if existentes > 0 || strings.TrimSpace(comision.Horarios) == "" {
+		return nil
+	}
+	parseadas, err := horario.Parse(comision.Horarios)
+	if err != nil {
+		log.Printf("Comisión %d: no se pudieron interpretar los horarios %q: %v", comision.ID, comision.Horarios, err)
+		return nil
+	}
+	var choques []models.ChoqueHorario
+	for _, parseada := range parseadas {
+		franja := models.HorarioComision{
+			ComisionId: comision.ID,
+			Dia:        int(parseada.Dia),
+			HoraInicio: horario.FormatHora(parseada.Inicio),
+			HoraFin:    horario.FormatHora(parseada.Fin),
+			Modalidad:  models.ModalidadPresencial,
+		}
+		if err := tx.Create(&franja).Error; err != nil {
+			return err
+		}
+		if !validar {
+			continue
+		}
+		var choquesFranja *ChoquesHorarioError
+		if err := validarChoques(tx, &franja); errors.As(err, &choquesFranja) {
+			choques = append(choques, choquesFranja.Choques...)
+		} else if err != nil {
+			return err
+		}
+	}
+	if len(choques) > 0 {
+		return &ChoquesHorarioError{Choques: choques}
+	}
+	return nil
+}
+
+// MigrateHorarios crea las franjas de las comisiones que solo tienen el texto libre de horarios
+func MigrateHorarios(db *gorm.DB) error {
+	var comisiones []models.Comision
+	if err := db.Where("id NOT IN (?)", db.Model(&models.HorarioComision{}).Select("comision_id")).Find(&comisiones).Error; err != nil {
+		return err
+	}
+	for i := range comisiones {
+		// Los datos existentes se migran aunque ya tengan superposiciones
+		if err := franjasDesdeTexto(db, &comisiones[i], false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,293 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/LINSITrack/backend/src/models"
+	"github.com/LINSITrack/backend/utils/textdiff"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrMaxIntentos          = errors.New("se alcanzó la cantidad máxima de intentos para este TP")
+	ErrReentregaNoPermitida = errors.New("la entrega ya fue corregida y no admite reentregas")
+)
+
+// Tamaño máximo de archivo para calcular el diff de texto entre intentos
+const maxDiffFileSize = 1 << 20
+
+// IntentoService guarda cada (re)entrega de un TP como un intento versionado
+type IntentoService struct {
+	db       *gorm.DB
+	plazos   *PlazoService
+	estados  *EntregaEstadoService
+	grupos   *GrupoService
+	archivos *ArchivoService
+}
+
+func NewIntentoService(db *gorm.DB, plazos *PlazoService, estados *EntregaEstadoService, grupos *GrupoService, archivos *ArchivoService) *IntentoService {
+	return &IntentoService{db: db, plazos: plazos, estados: estados, grupos: grupos, archivos: archivos}
+}
+
+// RegistrarIntento valida plazo y máximo de intentos, crea el intento nuevo y
+// actualiza la entrega para que refleje ese intento. Si entrega.ID es 0 la
+// entrega también se crea (primer intento). Solo se puede reentregar mientras
+// la entrega está pendiente o requiere rehacer, y el archivo tiene que cumplir
+// las restricciones del TP y haberlo subido el alumno o alguien de su grupo. En un TP grupal el intento se registra también en
+// la entrega de cada integrante del grupo.
+func (s *IntentoService) RegistrarIntento(entrega *models.EntregaTP, tp *models.TpModel, archivoURL string) error {
+	if entrega.ID != 0 && !models.PuedeTransicionar(models.RoleAlumno, entrega.Estado, models.EstadoPendiente) {
+		return ErrReentregaNoPermitida
+	}
+	if err := s.archivos.ValidarParaTp(archivoURL, tp); err != nil {
+		return err
+	}
+	autores, err := s.grupos.IntegrantesDe(tp.ID, entrega.AlumnoId)
+	if err != nil {
+		return err
+	}
+	if err := s.archivos.ValidarAutoria(archivoURL, tp, autores); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	plazo, err := s.plazos.EvaluarEntrega(tp, entrega.AlumnoId, now)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		grupoID, companeros, err := s.grupos.EntregasIntegrantes(tx, tp, entrega.AlumnoId)
+		if err != nil {
+			return err
+		}
+		entrega.GrupoId = grupoID
+
+		if err := s.registrar(tx, entrega, tp, archivoURL, plazo, now, entrega.AlumnoId); err != nil {
+			return err
+		}
+		if err := s.archivos.Vincular(tx, archivoURL, models.RecursoEntregaTP, entrega.ID, autores); err != nil {
+			return err
+		}
+		for i := range companeros {
+			if err := s.registrar(tx, &companeros[i], tp, archivoURL, plazo, now, entrega.AlumnoId); err != nil {
+				return err
+			}
+		}
+		// La reentrega deja el TP sin nota hasta que se corrija
+		return RecalcularCursadas(tx, tp.ComisionId, autores)
+	})
+}
+
+// registrar guarda el intento en una entrega; actorID es el alumno que entregó
+func (s *IntentoService) registrar(tx *gorm.DB, entrega *models.EntregaTP, tp *models.TpModel, archivoURL string, plazo *models.PlazoEntrega, now time.Time, actorID int) error {
+	numero := 1
+	if entrega.ID != 0 {
+		var ultimo int
+		if err := tx.Model(&models.IntentoEntregaTP{}).
+			Where("entrega_id = ?", entrega.ID).
+			Select("COALESCE(MAX(numero), 0)").Scan(&ultimo).Error; err != nil {
+			return err
+		}
+		numero = ultimo + 1
+	}
+	if tp.MaxIntentos > 0 && numero > tp.MaxIntentos {
+		return ErrMaxIntentos
+	}
+
+	// La entrega pasa a reflejar el intento nuevo (sin nota hasta que se corrija)
+	entrega.ArchivoURL = archivoURL
+	entrega.FechaEntrega = now
+	entrega.Nota = nil
+	entrega.NotaSinPenalizacion = nil
+	entrega.Devolucion = ""
+	entrega.IntentoActual = numero
+	AplicarPlazo(entrega, plazo)
+	if err := tx.Omit("Intentos", "Tp", "Alumno", "Cursada").Save(entrega).Error; err != nil {
+		return err
+	}
+	if err := s.estados.Transicionar(tx, entrega, models.EstadoPendiente, actorID, models.RoleAlumno, ""); err != nil {
+		return err
+	}
+
+	intento := models.IntentoEntregaTP{
+		EntregaId:    entrega.ID,
+		Numero:       numero,
+		ArchivoURL:   archivoURL,
+		FechaEntrega: now,
+		FechaLimite:  entrega.FechaLimite,
+		Tardia:       entrega.Tardia,
+		DiasTardanza: entrega.DiasTardanza,
+		Penalizacion: entrega.Penalizacion,
+	}
+	return tx.Create(&intento).Error
+}
+
+// CalificarIntentoActual copia la nota y devolución de la entrega a su intento
+// vigente, dentro de la transacción que guarda la entrega
+func (s *IntentoService) CalificarIntentoActual(tx *gorm.DB, entrega *models.EntregaTP) error {
+	return calificarIntento(tx, entrega)
+}
+
+func calificarIntento(tx *gorm.DB, entrega *models.EntregaTP) error {
+	return tx.Model(&models.IntentoEntregaTP{}).
+		Where("entrega_id = ? AND numero = ?", entrega.ID, entrega.IntentoActual).
+		Updates(map[string]interface{}{
+			"nota":                  entrega.Nota,
+			"nota_sin_penalizacion": entrega.NotaSinPenalizacion,
+			"devolucion":            entrega.Devolucion,
+		}).Error
+}
+
+func (s *IntentoService) GetIntentos(entregaID int) ([]models.IntentoEntregaTP, error) {
+	var intentos []models.IntentoEntregaTP
+	if err := s.db.Where("entrega_id = ?", entregaID).Order("numero").Find(&intentos).Error; err != nil {
+		return nil, err
+	}
+	return intentos, nil
+}
+
+func (s *IntentoService) GetIntento(entregaID, numero int) (*models.IntentoEntregaTP, error) {
+	var intento models.IntentoEntregaTP
+	if err := s.db.Where("entrega_id = ? AND numero = ?", entregaID, numero).First(&intento).Error; err != nil {
+		return nil, err
+	}
+	return &intento, nil
+}
+
+// DiffIntentos compara dos intentos: metadatos, nota, archivo (hash y tamaño)
+// y, si ambos archivos son de texto, un diff unificado de su contenido.
+func (s *IntentoService) DiffIntentos(entregaID, desde, hasta int) (*models.IntentoDiff, error) {
+	a, err := s.GetIntento(entregaID, desde)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.GetIntento(entregaID, hasta)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &models.IntentoDiff{
+		EntregaId: entregaID,
+		Desde:     desde,
+		Hasta:     hasta,
+		Campos:    []models.CampoDiff{},
+	}
+	addCampo := func(campo string, va, vb interface{}, distinto bool) {
+		if distinto {
+			diff.Campos = append(diff.Campos, models.CampoDiff{Campo: campo, Desde: va, Hasta: vb})
+		}
+	}
+	addCampo("archivo_url", a.ArchivoURL, b.ArchivoURL, a.ArchivoURL != b.ArchivoURL)
+	addCampo("fecha_entrega", a.FechaEntrega, b.FechaEntrega, !a.FechaEntrega.Equal(b.FechaEntrega))
+	addCampo("tardia", a.Tardia, b.Tardia, a.Tardia != b.Tardia)
+	addCampo("dias_tardanza", a.DiasTardanza, b.DiasTardanza, a.DiasTardanza != b.DiasTardanza)
+	addCampo("penalizacion", a.Penalizacion, b.Penalizacion, a.Penalizacion != b.Penalizacion)
+	addCampo("nota", a.Nota, b.Nota, !equalFloatPtr(a.Nota, b.Nota))
+	addCampo("devolucion", a.Devolucion, b.Devolucion, a.Devolucion != b.Devolucion)
+
+	contentA, infoA := s.readArchivo(a.ArchivoURL)
+	contentB, infoB := s.readArchivo(b.ArchivoURL)
+	diff.ArchivoDesde = infoA
+	diff.ArchivoHasta = infoB
+	diff.MismoArchivo = infoA.Disponible && infoB.Disponible && infoA.SHA256 == infoB.SHA256
+
+	switch {
+	case diff.MismoArchivo:
+	case contentA == nil || contentB == nil:
+		diff.DiffOmitido = "alguno de los archivos no está disponible o supera el tamaño máximo"
+	case !isText(contentA) || !isText(contentB):
+		diff.DiffOmitido = "los archivos no son de texto"
+	default:
+		text, added, removed, err := textdiff.Unified(infoA.Nombre, infoB.Nombre, string(contentA), string(contentB), 3)
+		if err != nil {
+			diff.DiffOmitido = err.Error()
+		} else {
+			diff.DiffTexto = text
+			diff.LineasAgregadas = added
+			diff.LineasQuitadas = removed
+		}
+	}
+
+	return diff, nil
+}
+
+// MigrateIntentos crea el intento 1 para las entregas anteriores al historial
+// de versiones. Es idempotente: solo toca entregas sin intentos.
+func MigrateIntentos(db *gorm.DB) error {
+	var entregas []models.EntregaTP
+	if err := db.Where("NOT EXISTS (SELECT 1 FROM intentos_entrega_tp i WHERE i.entrega_id = entregas_tp.id)").
+		Find(&entregas).Error; err != nil {
+		return err
+	}
+
+	for _, entrega := range entregas {
+		intento := models.IntentoEntregaTP{
+			EntregaId:           entrega.ID,
+			Numero:              1,
+			ArchivoURL:          entrega.ArchivoURL,
+			FechaEntrega:        entrega.FechaEntrega,
+			FechaLimite:         entrega.FechaLimite,
+			Tardia:              entrega.Tardia,
+			DiasTardanza:        entrega.DiasTardanza,
+			Penalizacion:        entrega.Penalizacion,
+			Nota:                entrega.Nota,
+			NotaSinPenalizacion: entrega.NotaSinPenalizacion,
+			Devolucion:          entrega.Devolucion,
+		}
+		if err := db.Create(&intento).Error; err != nil {
+			return err
+		}
+		if err := db.Model(&entrega).Update("intento_actual", 1).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readArchivo lee un archivo del storage. Devuelve el contenido solo si no supera maxDiffFileSize.
+func (s *IntentoService) readArchivo(archivoURL string) ([]byte, models.ArchivoInfo) {
+	info := models.ArchivoInfo{ArchivoURL: archivoURL, Nombre: path.Base(archivoURL)}
+
+	archivo, err := s.archivos.GetArchivoByURL(archivoURL)
+	if err != nil {
+		return nil, info
+	}
+	info.Nombre = archivo.OriginalName
+	info.Tamano = archivo.Size
+	info.SHA256 = archivo.SHA256
+	info.Disponible = true
+	if archivo.Size > maxDiffFileSize {
+		return nil, info
+	}
+
+	file, err := s.archivos.Abrir(archivo)
+	if err != nil {
+		info.Disponible = false
+		return nil, info
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		info.Disponible = false
+		return nil, info
+	}
+	return content, info
+}
+
+func isText(content []byte) bool {
+	return strings.HasPrefix(http.DetectContentType(content), "text/")
+}
+
+func equalFloatPtr(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
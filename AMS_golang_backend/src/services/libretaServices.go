@@ -0,0 +1,356 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LINSITrack/backend/src/models"
+	"github.com/LINSITrack/backend/utils/pdf"
+	"github.com/LINSITrack/backend/utils/spreadsheet"
+	"gorm.io/gorm"
+)
+
+var ErrFormatoLibreta = errors.New("formato inválido (se acepta json, csv, xlsx o pdf)")
+
+// LibretaService arma la libreta de calificaciones de una comisión: una fila por
+// cursada y una columna por TP y por evaluación, más la nota conceptual y la final
+type LibretaService struct {
+	db *gorm.DB
+}
+
+func NewLibretaService(db *gorm.DB) *LibretaService {
+	return &LibretaService{db: db}
+}
+
+// GetLibreta arma la libreta de la comisión. Con anoLectivo solo incluye las
+// cursadas de ese año y los TPs y evaluaciones con fecha en ese año.
+func (s *LibretaService) GetLibreta(comisionID int, anoLectivo *int) (*models.Libreta, error) {
+	libreta := &models.Libreta{AnoLectivo: anoLectivo, Generada: time.Now()}
+	if err := s.db.Preload("Materia").First(&libreta.Comision, comisionID).Error; err != nil {
+		return nil, err
+	}
+
+	var cursadas []models.Cursada
+	query := s.db.Preload("Alumno").
+		Joins("JOIN alumnos ON alumnos.id = cursadas.alumno_id").
+		Where("cursadas.comision_id = ?", comisionID).
+		Order("alumnos.apellido, alumnos.nombre, alumnos.legajo, cursadas.ano_lectivo")
+	if anoLectivo != nil {
+		query = query.Where("cursadas.ano_lectivo = ?", *anoLectivo)
+	}
+	if err := query.Find(&cursadas).Error; err != nil {
+		return nil, err
+	}
+
+	tps, err := tpsDeComision(s.db, comisionID, anoLectivo)
+	if err != nil {
+		return nil, err
+	}
+	evaluaciones, err := evaluacionesDeComision(s.db, comisionID, anoLectivo)
+	if err != nil {
+		return nil, err
+	}
+
+	notasTp, err := s.notasTp(tps)
+	if err != nil {
+		return nil, err
+	}
+	notasEvaluacion, err := s.notasEvaluacion(evaluaciones)
+	if err != nil {
+		return nil, err
+	}
+
+	libreta.Columnas = make([]models.ColumnaLibreta, 0, len(tps)+len(evaluaciones))
+	for i, tp := range tps {
+		libreta.Columnas = append(libreta.Columnas, models.ColumnaLibreta{
+			Tipo:        models.ColumnaLibretaTp,
+			ID:          tp.ID,
+			Titulo:      fmt.Sprintf("TP %d", i+1),
+			Descripcion: tp.Consigna,
+			Fecha:       tp.FechaHoraEntrega,
+		})
+	}
+	// Los recuperatorios se numeran con la evaluación que recuperan
+	numero := map[int]int{}
+	for _, evaluacion := range evaluaciones {
+		if evaluacion.RecuperatorioDeId == nil {
+			numero[evaluacion.ID] = len(numero) + 1
+		}
+	}
+	for _, evaluacion := range evaluaciones {
+		fecha, _ := time.Parse("2006-01-02", prefijoFecha(evaluacion.FechaEvaluacion))
+		columna := models.ColumnaLibreta{
+			Tipo:            models.ColumnaLibretaEvaluacion,
+			ID:              evaluacion.ID,
+			Titulo:          fmt.Sprintf("Evaluación %d", numero[evaluacion.ID]),
+			Descripcion:     evaluacion.Temas,
+			Fecha:           fecha,
+			RecuperatorioDe: evaluacion.RecuperatorioDeId,
+		}
+		if evaluacion.RecuperatorioDeId != nil {
+			columna.Titulo = "Recuperatorio"
+			if n, ok := numero[*evaluacion.RecuperatorioDeId]; ok {
+				columna.Titulo = fmt.Sprintf("Recup. evaluación %d", n)
+			}
+		}
+		libreta.Columnas = append(libreta.Columnas, columna)
+	}
+
+	libreta.Filas = make([]models.FilaLibreta, 0, len(cursadas))
+	for _, cursada := range cursadas {
+		fila := models.FilaLibreta{
+			CursadaId:      cursada.ID,
+			AlumnoId:       cursada.AlumnoID,
+			Legajo:         cursada.Alumno.Legajo,
+			Apellido:       cursada.Alumno.Apellido,
+			Nombre:         cursada.Alumno.Nombre,
+			AnoLectivo:     cursada.AnoLectivo,
+			Notas:          make([]*float64, 0, len(libreta.Columnas)),
+			NotaConceptual: notaConceptualCargada(&cursada),
+			NotaFinal:      notaFinalCargada(&cursada),
+			Condicion:      cursada.Condicion,
+		}
+		for _, tp := range tps {
+			fila.Notas = append(fila.Notas, notasTp[notaKey{tp.ID, cursada.AlumnoID}])
+		}
+		for _, evaluacion := range evaluaciones {
+			fila.Notas = append(fila.Notas, notasEvaluacion[notaKey{evaluacion.ID, cursada.AlumnoID}])
+		}
+		libreta.Filas = append(libreta.Filas, fila)
+	}
+	return libreta, nil
+}
+
+// tpsDeComision devuelve los TPs de la comisión ordenados por fecha de entrega;
+// con anoLectivo, solo los que vencen ese año
+func tpsDeComision(db *gorm.DB, comisionID int, anoLectivo *int) ([]models.TpModel, error) {
+	var tps []models.TpModel
+	query := db.Where("comision_id = ?", comisionID).Order("fecha_entrega, id")
+	if anoLectivo != nil {
+		query = query.Where("EXTRACT(YEAR FROM fecha_entrega) = ?", *anoLectivo)
+	}
+	if err := query.Find(&tps).Error; err != nil {
+		return nil, err
+	}
+	return tps, nil
+}
+
+// evaluacionesDeComision devuelve las evaluaciones de la comisión ordenadas por
+// fecha; con anoLectivo, solo las de ese año
+func evaluacionesDeComision(db *gorm.DB, comisionID int, anoLectivo *int) ([]models.EvaluacionModel, error) {
+	var evaluaciones []models.EvaluacionModel
+	query := db.Where("comision_id = ?", comisionID).Order("fecha_evaluacion, id")
+	if anoLectivo != nil {
+		query = query.Where("EXTRACT(YEAR FROM fecha_evaluacion) = ?", *anoLectivo)
+	}
+	if err := query.Find(&evaluaciones).Error; err != nil {
+		return nil, err
+	}
+	return evaluaciones, nil
+}
+
+// notaKey identifica la nota de un alumno en un TP o una evaluación
+type notaKey struct {
+	recursoID int
+	alumnoID  int
+}
+
+func (s *LibretaService) notasTp(tps []models.TpModel) (map[notaKey]*float64, error) {
+	notas := map[notaKey]*float64{}
+	if len(tps) == 0 {
+		return notas, nil
+	}
+	ids := make([]int, len(tps))
+	for i, tp := range tps {
+		ids[i] = tp.ID
+	}
+	var entregas []models.EntregaTP
+	if err := s.db.Select("tp_id", "alumno_id", "nota").
+		Where("tp_id IN ? AND nota IS NOT NULL", ids).Find(&entregas).Error; err != nil {
+		return nil, err
+	}
+	for _, entrega := range entregas {
+		notas[notaKey{entrega.TpId, entrega.AlumnoId}] = entrega.Nota
+	}
+	return notas, nil
+}
+
+func (s *LibretaService) notasEvaluacion(evaluaciones []models.EvaluacionModel) (map[notaKey]*float64, error) {
+	notas := map[notaKey]*float64{}
+	if len(evaluaciones) == 0 {
+		return notas, nil
+	}
+	ids := make([]int, len(evaluaciones))
+	for i, evaluacion := range evaluaciones {
+		ids[i] = evaluacion.ID
+	}
+	var entregas []models.EntregaEvaluacion
+	if err := s.db.Select("evaluacion_id", "alumno_id", "nota").
+		Where("evaluacion_id IN ? AND nota IS NOT NULL", ids).Find(&entregas).Error; err != nil {
+		return nil, err
+	}
+	for _, entrega := range entregas {
+		notas[notaKey{entrega.EvaluacionId, entrega.AlumnoId}] = entrega.Nota
+	}
+	return notas, nil
+}
+
+// Escribir exporta la libreta en el formato pedido (csv, xlsx o pdf)
+func (s *LibretaService) Escribir(w io.Writer, libreta *models.Libreta, formato string) error {
+	switch formato {
+	case models.FormatoLibretaCSV:
+		encabezados, filas := tablaLibreta(libreta)
+		rows := [][]string{encabezados}
+		for _, fila := range filas {
+			row := make([]string, len(fila))
+			for i, value := range fila {
+				row[i] = celdaTexto(value)
+			}
+			rows = append(rows, row)
+		}
+		return spreadsheet.WriteCSV(w, rows)
+
+	case models.FormatoLibretaXLSX:
+		encabezados, filas := tablaLibreta(libreta)
+		rows := make([][]interface{}, 0, len(filas)+1)
+		header := make([]interface{}, len(encabezados))
+		for i, h := range encabezados {
+			header[i] = h
+		}
+		rows = append(rows, header)
+		rows = append(rows, filas...)
+		return spreadsheet.WriteXLSX(w, libreta.Comision.Nombre, rows)
+
+	case models.FormatoLibretaPDF:
+		return pdf.WriteTabla(w, actaLibreta(libreta))
+
+	default:
+		return ErrFormatoLibreta
+	}
+}
+
+// NombreArchivo es el nombre sugerido para la descarga, ej: libreta-comision-3-2025.xlsx
+func (s *LibretaService) NombreArchivo(libreta *models.Libreta, formato string) string {
+	nombre := "libreta-comision-" + strconv.Itoa(libreta.Comision.ID)
+	if libreta.AnoLectivo != nil {
+		nombre += "-" + strconv.Itoa(*libreta.AnoLectivo)
+	}
+	return nombre + "." + formato
+}
+
+// tablaLibreta aplana la libreta en encabezados y filas de celdas (string o *float64)
+func tablaLibreta(libreta *models.Libreta) ([]string, [][]interface{}) {
+	encabezados := []string{"Legajo", "Apellido", "Nombre", "Año lectivo"}
+	for _, columna := range libreta.Columnas {
+		encabezados = append(encabezados, columna.Titulo)
+	}
+	encabezados = append(encabezados, "Nota conceptual", "Nota final", "Condición")
+
+	filas := make([][]interface{}, 0, len(libreta.Filas))
+	for _, fila := range libreta.Filas {
+		row := []interface{}{fila.Legajo, fila.Apellido, fila.Nombre, fila.AnoLectivo}
+		for _, nota := range fila.Notas {
+			row = append(row, nota)
+		}
+		row = append(row, fila.NotaConceptual, fila.NotaFinal, string(fila.Condicion))
+		filas = append(filas, row)
+	}
+	return encabezados, filas
+}
+
+// actaLibreta arma el acta imprimible: la misma tabla sin el año lectivo cuando
+// se filtró por uno (va en el encabezado) y con las líneas de firma al pie
+func actaLibreta(libreta *models.Libreta) pdf.Tabla {
+	encabezados, filas := tablaLibreta(libreta)
+
+	materia := libreta.Comision.Materia.Nombre
+	titulo := "Acta de calificaciones"
+	if materia != "" {
+		titulo += " - " + materia
+	}
+	subtitulos := []string{"Comisión: " + libreta.Comision.Nombre}
+	if libreta.AnoLectivo != nil {
+		subtitulos = append(subtitulos, "Año lectivo: "+strconv.Itoa(*libreta.AnoLectivo))
+	}
+	var referencias []string
+	for _, columna := range libreta.Columnas {
+		if !columna.Fecha.IsZero() {
+			referencias = append(referencias, columna.Titulo+": "+columna.Fecha.Format("02/01/2006"))
+		}
+	}
+	if len(referencias) > 0 {
+		subtitulos = append(subtitulos, "Fechas: "+strings.Join(referencias, ", "))
+	}
+	subtitulos = append(subtitulos, "Generada el "+libreta.Generada.Format("02/01/2006 15:04"))
+
+	tabla := pdf.Tabla{
+		Titulo:     titulo,
+		Subtitulos: subtitulos,
+		Firmas:     []string{"Firma del docente", "Aclaración", "Fecha"},
+	}
+	for i, h := range encabezados {
+		if libreta.AnoLectivo != nil && i == 3 {
+			continue
+		}
+		tabla.Encabezados = append(tabla.Encabezados, h)
+		tabla.Derecha = append(tabla.Derecha, i >= 3 && i < len(encabezados)-1)
+	}
+	for _, fila := range filas {
+		row := make([]string, 0, len(fila))
+		for i, value := range fila {
+			if libreta.AnoLectivo != nil && i == 3 {
+				continue
+			}
+			row = append(row, celdaTexto(value))
+		}
+		tabla.Filas = append(tabla.Filas, row)
+	}
+	return tabla
+}
+
+func celdaTexto(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case *float64:
+		if v == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*v, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
+
+// notaConceptualCargada devuelve la nota conceptual de la cursada o nil si todavía no se cargó
+func notaConceptualCargada(cursada *models.Cursada) *float64 {
+	if !cursada.NotaConceptualCargada {
+		return nil
+	}
+	nota := cursada.NotaConceptual
+	return &nota
+}
+
+// notaFinalCargada devuelve la nota final vigente de la cursada o nil si no hay:
+// ni calculada por el esquema, ni ajustada, ni cargada antes de los esquemas
+func notaFinalCargada(cursada *models.Cursada) *float64 {
+	if !cursada.NotaFinalAjustada && cursada.NotaFinalCalculada == nil && cursada.NotaFinal == 0 {
+		return nil
+	}
+	nota := cursada.NotaFinal
+	return &nota
+}
+
+// prefijoFecha devuelve la parte "AAAA-MM-DD" de una fecha guardada como texto
+func prefijoFecha(fecha string) string {
+	if len(fecha) > 10 {
+		return fecha[:10]
+	}
+	return fecha
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/LINSITrack/backend/src/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Límites del login: a partir de cierta cantidad de fallos consecutivos se
+// bloquea el email (o la IP) con un backoff exponencial, hasta un máximo. Los
+// fallos se olvidan tras loginFailureWindow sin fallos nuevos (contado desde el
+// fin del último bloqueo).
+const (
+	maxFailuresPerEmail = 5
+	maxFailuresPerIP    = 20
+	lockBaseDuration    = 1 * time.Minute
+	lockMaxDuration     = 1 * time.Hour
+	loginFailureWindow  = 15 * time.Minute
+)
+
+// LoginLockedError indica que el login está bloqueado temporalmente
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("demasiados intentos fallidos, reintentá en %d segundos", int(math.Ceil(e.RetryAfter.Seconds())))
+}
+
+func emailLockKey(email string) string { return "email:" + normalizeEmail(email) }
+func ipLockKey(ip string) string       { return "ip:" + ip }
+
+// checkLoginLock devuelve un *LoginLockedError si el email o la IP están bloqueados
+func (s *AuthService) checkLoginLock(email, ip string) error {
+	var locks []models.LoginLock
+	if err := s.db.Where("lock_key IN ? AND locked_until > ?", []string{emailLockKey(email), ipLockKey(ip)}, time.Now()).
+		Find(&locks).Error; err != nil {
+		return err
+	}
+
+	var retryAfter time.Duration
+	for _, lock := range locks {
+		if wait := time.Until(*lock.LockedUntil); wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+	if retryAfter > 0 {
+		return &LoginLockedError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// registerLoginFailure suma un fallo al email y a la IP, bloqueando si se supera el límite
+func (s *AuthService) registerLoginFailure(email, ip string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := incrementLock(tx, emailLockKey(email), maxFailuresPerEmail); err != nil {
+			return err
+		}
+		if ip == "" {
+			return nil
+		}
+		return incrementLock(tx, ipLockKey(ip), maxFailuresPerIP)
+	})
+}
+
+// clearLoginFailures reinicia el contador del email tras un login exitoso. La
+// IP puede ser compartida (NAT de la facultad), así que a su contador solo se
+// le descuenta un fallo: un login correcto no borra los intentos de otros.
+func (s *AuthService) clearLoginFailures(email, ip string) error {
+	if err := s.db.Where("lock_key = ?", emailLockKey(email)).Delete(&models.LoginLock{}).Error; err != nil {
+		return err
+	}
+	if ip == "" {
+		return nil
+	}
+	return s.db.Model(&models.LoginLock{}).
+		Where("lock_key = ? AND failed_count > 0", ipLockKey(ip)).
+		Update("failed_count", gorm.Expr("failed_count - 1")).Error
+}
+
+func incrementLock(tx *gorm.DB, key string, maxFailures int) error {
+	// La fila se crea antes de bloquearla: dos primeros fallos simultáneos no
+	// pueden insertarla dos veces y el FOR UPDATE siempre encuentra qué bloquear
+	if err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "lock_key"}}, DoNothing: true}).
+		Create(&models.LoginLock{Key: key}).Error; err != nil {
+		return err
+	}
+	var lock models.LoginLock
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("lock_key = ?", key).First(&lock).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	ultimo := lock.UpdatedAt
+	if lock.LockedUntil != nil && lock.LockedUntil.After(ultimo) {
+		ultimo = *lock.LockedUntil
+	}
+	if now.Sub(ultimo) > loginFailureWindow {
+		lock.FailedCount = 0
+		lock.LockedUntil = nil
+	}
+
+	lock.FailedCount++
+	if lock.FailedCount >= maxFailures {
+		// 1, 2, 4, 8... minutos por cada fallo por encima del límite
+		duration := lockBaseDuration << uint(lock.FailedCount-maxFailures)
+		if duration > lockMaxDuration || duration <= 0 {
+			duration = lockMaxDuration
+		}
+		until := now.Add(duration)
+		lock.LockedUntil = &until
+	}
+	return tx.Save(&lock).Error
+}
+
+func (s *AuthService) recordLoginAttempt(attempt models.LoginAttempt) {
+	attempt.Email = truncateString(normalizeEmail(attempt.Email), 60)
+	attempt.UserAgent = truncateString(attempt.UserAgent, 250)
+	// La auditoría no debe impedir el login
+	s.db.Create(&attempt)
+}
+
+// GetLoginAttempts lista la auditoría de logins, más recientes primero
+func (s *AuthService) GetLoginAttempts(filter models.LoginAuditFilter) ([]models.LoginAttempt, error) {
+	query := s.db.Model(&models.LoginAttempt{})
+	if filter.Email != "" {
+		query = query.Where("email = ?", normalizeEmail(filter.Email))
+	}
+	if filter.IP != "" {
+		query = query.Where("ip = ?", filter.IP)
+	}
+	if filter.Success != nil {
+		query = query.Where("success = ?", *filter.Success)
+	}
+	if filter.Desde != nil {
+		query = query.Where("created_at >= ?", *filter.Desde)
+	}
+	if filter.Hasta != nil {
+		query = query.Where("created_at <= ?", *filter.Hasta)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+
+	var attempts []models.LoginAttempt
+	if err := query.Order("created_at DESC").Limit(limit).Find(&attempts).Error; err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
+
+// GetLoginLocks lista los bloqueos vigentes
+func (s *AuthService) GetLoginLocks() ([]models.LoginLock, error) {
+	var locks []models.LoginLock
+	if err := s.db.Where("locked_until > ?", time.Now()).Order("locked_until DESC").Find(&locks).Error; err != nil {
+		return nil, err
+	}
+	return locks, nil
+}
+
+// UnlockLogin elimina los contadores de fallos del email y/o la IP
+func (s *AuthService) UnlockLogin(email, ip string) (int64, error) {
+	keys := []string{}
+	if email != "" {
+		keys = append(keys, emailLockKey(email))
+	}
+	if ip != "" {
+		keys = append(keys, ipLockKey(ip))
+	}
+	if len(keys) == 0 {
+		return 0, errors.New("se debe indicar un email o una IP")
+	}
+
+	result := s.db.Where("lock_key IN ?", keys).Delete(&models.LoginLock{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,819 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/LINSITrack/backend/src/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSinEsquema        = errors.New("la comisión no tiene esquema de calificación ni reglas de condición")
+	ErrNotaNoAjustada    = errors.New("la nota final de la cursada no tiene un ajuste manual")
+	ErrCondicionInvalida = errors.New("condición inválida (promocionado, regular o libre)")
+)
+
+// NotaFinalService administra los esquemas de calificación y calcula con ellos
+// la nota final y la condición de cada cursada
+type NotaFinalService struct {
+	db *gorm.DB
+}
+
+func NewNotaFinalService(db *gorm.DB) *NotaFinalService {
+	return &NotaFinalService{db: db}
+}
+
+// GetEsquemaComision devuelve el esquema que se aplica a la comisión: el propio
+// o, si no tiene, el de su materia
+func (s *NotaFinalService) GetEsquemaComision(comisionID int) (*models.EsquemaCalificacion, error) {
+	var comision models.Comision
+	if err := s.db.First(&comision, comisionID).Error; err != nil {
+		return nil, err
+	}
+	return esquemaEfectivo(s.db, &comision)
+}
+
+func (s *NotaFinalService) GetEsquemaMateria(materiaID int) (*models.EsquemaCalificacion, error) {
+	var esquema models.EsquemaCalificacion
+	if err := s.db.Preload("Pesos").Where("materia_id = ?", materiaID).First(&esquema).Error; err != nil {
+		return nil, err
+	}
+	return &esquema, nil
+}
+
+// esquemaEfectivo devuelve el esquema propio de la comisión o el de su materia
+func esquemaEfectivo(db *gorm.DB, comision *models.Comision) (*models.EsquemaCalificacion, error) {
+	var esquemas []models.EsquemaCalificacion
+	err := db.Preload("Pesos").
+		Where("comision_id = ? OR materia_id = ?", comision.ID, comision.MateriaId).
+		Find(&esquemas).Error
+	if err != nil {
+		return nil, err
+	}
+	var delaMateria *models.EsquemaCalificacion
+	for i := range esquemas {
+		if esquemas[i].ComisionId != nil {
+			return &esquemas[i], nil
+		}
+		delaMateria = &esquemas[i]
+	}
+	if delaMateria == nil {
+		return nil, ErrSinEsquema
+	}
+	return delaMateria, nil
+}
+
+// GuardarEsquemaComision crea o reemplaza el esquema propio de la comisión
+func (s *NotaFinalService) GuardarEsquemaComision(comisionID int, req *models.EsquemaCalificacionRequest) (*models.EsquemaCalificacion, error) {
+	var comision models.Comision
+	if err := s.db.First(&comision, comisionID).Error; err != nil {
+		return nil, err
+	}
+	esquema, err := esquemaDesdeRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validarPesos(comisionID, esquema.Pesos); err != nil {
+		return nil, err
+	}
+	esquema.ComisionId = &comisionID
+	return esquema, s.guardarEsquema(esquema, "comision_id = ?", comisionID)
+}
+
+// GuardarEsquemaMateria crea o reemplaza el esquema de la materia. Como sus
+// comisiones tienen TPs y evaluaciones distintos, no admite pesos por ítem.
+func (s *NotaFinalService) GuardarEsquemaMateria(materiaID int, req *models.EsquemaCalificacionRequest) (*models.EsquemaCalificacion, error) {
+	var materia models.Materia
+	if err := s.db.First(&materia, materiaID).Error; err != nil {
+		return nil, err
+	}
+	esquema, err := esquemaDesdeRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if len(esquema.Pesos) > 0 {
+		return nil, errors.New("los pesos por TP o evaluación solo se definen en el esquema de una comisión")
+	}
+	esquema.MateriaId = &materiaID
+	return esquema, s.guardarEsquema(esquema, "materia_id = ?", materiaID)
+}
+
+func (s *NotaFinalService) guardarEsquema(esquema *models.EsquemaCalificacion, where string, id int) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var existente models.EsquemaCalificacion
+		err := tx.Where(where, id).First(&existente).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil {
+			esquema.ID = existente.ID
+			if err := tx.Where("esquema_id = ?", existente.ID).Delete(&models.PesoItemEsquema{}).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Save(esquema).Error
+	})
+}
+
+func (s *NotaFinalService) BorrarEsquemaComision(comisionID int) error {
+	return s.borrarEsquema("comision_id = ?", comisionID)
+}
+
+func (s *NotaFinalService) BorrarEsquemaMateria(materiaID int) error {
+	return s.borrarEsquema("materia_id = ?", materiaID)
+}
+
+func (s *NotaFinalService) borrarEsquema(where string, id int) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var esquema models.EsquemaCalificacion
+		if err := tx.Where(where, id).First(&esquema).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("esquema_id = ?", esquema.ID).Delete(&models.PesoItemEsquema{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&esquema).Error
+	})
+}
+
+// esquemaDesdeRequest valida el pedido y completa los valores por defecto
+func esquemaDesdeRequest(req *models.EsquemaCalificacionRequest) (*models.EsquemaCalificacion, error) {
+	esquema := &models.EsquemaCalificacion{
+		PesoTps:           req.PesoTps,
+		PesoEvaluaciones:  req.PesoEvaluaciones,
+		PesoConceptual:    req.PesoConceptual,
+		TpsDescartados:    req.TpsDescartados,
+		NotaMinimaParcial: 4,
+		NotaRegular:       4,
+		NotaPromocion:     7,
+		Faltantes:         req.Faltantes,
+		Pesos:             req.Pesos,
+	}
+	if req.NotaMinimaParcial != nil {
+		esquema.NotaMinimaParcial = *req.NotaMinimaParcial
+	}
+	if req.NotaRegular != nil {
+		esquema.NotaRegular = *req.NotaRegular
+	}
+	if req.NotaPromocion != nil {
+		esquema.NotaPromocion = *req.NotaPromocion
+	}
+	if esquema.Faltantes == "" {
+		esquema.Faltantes = models.FaltanteCero
+	}
+
+	if esquema.PesoTps < 0 || esquema.PesoEvaluaciones < 0 || esquema.PesoConceptual < 0 {
+		return nil, errors.New("los pesos no pueden ser negativos")
+	}
+	if esquema.PesoTps+esquema.PesoEvaluaciones+esquema.PesoConceptual == 0 {
+		return nil, errors.New("al menos uno de peso_tps, peso_evaluaciones o peso_conceptual debe ser mayor a 0")
+	}
+	if esquema.TpsDescartados < 0 {
+		return nil, errors.New("tps_descartados no puede ser negativo")
+	}
+	for _, nota := range []float64{esquema.NotaMinimaParcial, esquema.NotaRegular, esquema.NotaPromocion} {
+		if nota < 0 || nota > 10 {
+			return nil, errors.New("las notas mínimas deben estar entre 0 y 10")
+		}
+	}
+	if esquema.NotaPromocion < esquema.NotaRegular {
+		return nil, errors.New("nota_promocion no puede ser menor que nota_regular")
+	}
+	if !esquema.Faltantes.IsValid() {
+		return nil, errors.New("faltantes inválido (cero o excluir)")
+	}
+	return esquema, nil
+}
+
+// validarPesos verifica que los pesos por ítem sean de TPs y evaluaciones de la comisión
+func (s *NotaFinalService) validarPesos(comisionID int, pesos []models.PesoItemEsquema) error {
+	vistos := map[string]bool{}
+	for i := range pesos {
+		peso := &pesos[i]
+		peso.ID = 0
+		if peso.Peso < 0 {
+			return errors.New("los pesos no pueden ser negativos")
+		}
+
+		var modelo interface{}
+		switch peso.Tipo {
+		case models.ItemTp:
+			modelo = &models.TpModel{}
+		case models.ItemEvaluacion:
+			modelo = &models.EvaluacionModel{}
+		default:
+			return fmt.Errorf("tipo de ítem inválido: %q (tp o evaluacion)", peso.Tipo)
+		}
+		var count int64
+		if err := s.db.Model(modelo).Where("id = ? AND comision_id = ?", peso.RecursoId, comisionID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return fmt.Errorf("el %s %d no pertenece a la comisión", peso.Tipo, peso.RecursoId)
+		}
+		if peso.Tipo == models.ItemEvaluacion {
+			var recuperatorios int64
+			if err := s.db.Model(modelo).Where("id = ? AND recuperatorio_de_id IS NOT NULL", peso.RecursoId).Count(&recuperatorios).Error; err != nil {
+				return err
+			}
+			if recuperatorios > 0 {
+				return fmt.Errorf("la evaluación %d es un recuperatorio: su nota reemplaza a la de la evaluación que recupera", peso.RecursoId)
+			}
+		}
+
+		key := fmt.Sprintf("%s:%d", peso.Tipo, peso.RecursoId)
+		if vistos[key] {
+			return fmt.Errorf("el %s %d tiene más de un peso", peso.Tipo, peso.RecursoId)
+		}
+		vistos[key] = true
+	}
+	return nil
+}
+
+// CalcularCursada calcula la nota final de la cursada sin guardarla
+func (s *NotaFinalService) CalcularCursada(cursadaID int) (*models.ResultadoNotaFinal, error) {
+	var cursada models.Cursada
+	if err := s.db.Preload("Comision").First(&cursada, cursadaID).Error; err != nil {
+		return nil, err
+	}
+	resultados, err := calcularNotasFinales(s.db, &cursada.Comision, []models.Cursada{cursada})
+	if err != nil {
+		return nil, err
+	}
+	return &resultados[0], nil
+}
+
+// RecalcularComision calcula y guarda la nota final de las cursadas de la comisión
+// (opcionalmente de un año lectivo). En las cursadas con un ajuste manual solo se
+// actualiza el valor calculado.
+func (s *NotaFinalService) RecalcularComision(comisionID int, anoLectivo *int) ([]models.ResultadoNotaFinal, error) {
+	var resultados []models.ResultadoNotaFinal
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var comision models.Comision
+		if err := tx.First(&comision, comisionID).Error; err != nil {
+			return err
+		}
+		var cursadas []models.Cursada
+		query := tx.Where("comision_id = ?", comisionID).Order("ano_lectivo, id")
+		if anoLectivo != nil {
+			query = query.Where("ano_lectivo = ?", *anoLectivo)
+		}
+		if err := query.Find(&cursadas).Error; err != nil {
+			return err
+		}
+
+		var err error
+		resultados, err = calcularNotasFinales(tx, &comision, cursadas)
+		if err != nil {
+			return err
+		}
+		for i := range cursadas {
+			if err := guardarResultado(tx, &cursadas[i], &resultados[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resultados, nil
+}
+
+// RecalcularCursadas vuelve a calcular y guardar, dentro de tx, la nota final y
+// la condición de las cursadas de los alumnos en la comisión. Se usa en cada
+// operación que cambia sus notas o su asistencia, así la condición (y su
+// notificación) no depende de un recálculo manual. Si la comisión no tiene
+// esquema ni reglas no hace nada.
+func RecalcularCursadas(tx *gorm.DB, comisionID int, alumnoIDs []int) error {
+	if len(alumnoIDs) == 0 {
+		return nil
+	}
+	var comision models.Comision
+	if err := tx.First(&comision, comisionID).Error; err != nil {
+		return err
+	}
+	var cursadas []models.Cursada
+	if err := tx.Where("comision_id = ? AND alumno_id IN ?", comisionID, alumnoIDs).Order("ano_lectivo, id").Find(&cursadas).Error; err != nil {
+		return err
+	}
+	if len(cursadas) == 0 {
+		return nil
+	}
+	resultados, err := calcularNotasFinales(tx, &comision, cursadas)
+	if errors.Is(err, ErrSinEsquema) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for i := range cursadas {
+		if err := guardarResultado(tx, &cursadas[i], &resultados[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// guardarResultado guarda el cálculo en la cursada y actualiza el resultado con los valores vigentes
+func guardarResultado(tx *gorm.DB, cursada *models.Cursada, resultado *models.ResultadoNotaFinal) error {
+	if !cursada.NotaFinalAjustada && resultado.Condicion != "" && resultado.Condicion != cursada.Condicion {
+		if err := notificarCambioCondicion(tx, cursada, resultado.Condicion, resultado.Provisoria); err != nil {
+			return err
+		}
+	}
+	if err := tx.Model(cursada).Updates(cambiosResultado(cursada, resultado)).Error; err != nil {
+		return err
+	}
+	cursada.CondicionCalculada = resultado.Condicion
+	cursada.NotaFinalProvisoria = resultado.Provisoria
+	if calculaNota(resultado) {
+		cursada.NotaFinalCalculada = resultado.NotaFinal
+	}
+	if !cursada.NotaFinalAjustada {
+		if calculaNota(resultado) {
+			cursada.NotaFinal = valorNota(resultado.NotaFinal)
+		}
+		cursada.Condicion = resultado.Condicion
+	}
+	resultado.NotaFinalVigente = cursada.NotaFinal
+	resultado.CondicionVigente = cursada.Condicion
+	return nil
+}
+
+// cambiosResultado son las columnas de la cursada que actualiza el cálculo. Si
+// un profesor ajustó la nota solo cambian los valores calculados.
+func cambiosResultado(cursada *models.Cursada, resultado *models.ResultadoNotaFinal) map[string]interface{} {
+	cambios := map[string]interface{}{
+		"condicion_calculada":   resultado.Condicion,
+		"nota_final_provisoria": resultado.Provisoria,
+	}
+	if calculaNota(resultado) {
+		cambios["nota_final_calculada"] = resultado.NotaFinal
+	}
+	if !cursada.NotaFinalAjustada {
+		if calculaNota(resultado) {
+			cambios["nota_final"] = valorNota(resultado.NotaFinal)
+		}
+		cambios["condicion"] = resultado.Condicion
+	}
+	return cambios
+}
+
+// calculaNota indica si el resultado trae nota final. Con solo reglas de
+// condición (sin esquema) no la hay y se conserva la nota cargada en la cursada.
+func calculaNota(resultado *models.ResultadoNotaFinal) bool {
+	return resultado.EsquemaId != 0 || resultado.NotaFinal != nil
+}
+
+// calcularNotasFinales arma los ítems de cada cursada (con los TPs y
+// evaluaciones de su año lectivo) y les aplica el esquema de la comisión
+func calcularNotasFinales(db *gorm.DB, comision *models.Comision, cursadas []models.Cursada) ([]models.ResultadoNotaFinal, error) {
+	// Sin esquema no hay nota final, pero la condición puede salir de las reglas de la materia
+	esquema, err := esquemaEfectivo(db, comision)
+	if err != nil && !errors.Is(err, ErrSinEsquema) {
+		return nil, err
+	}
+	reglas, err := reglasDeMateria(db, comision.MateriaId)
+	if err != nil {
+		return nil, err
+	}
+	if esquema == nil && reglas == nil {
+		return nil, ErrSinEsquema
+	}
+
+	var asistencias map[int]*models.ResumenAsistencia
+	if reglas != nil {
+		if asistencias, err = resumenesAsistencia(db, cursadas); err != nil {
+			return nil, err
+		}
+	}
+
+	datosPorAno := map[int]*datosNotaFinal{}
+	resultados := make([]models.ResultadoNotaFinal, len(cursadas))
+	ahora := time.Now()
+	for i, cursada := range cursadas {
+		datos, ok := datosPorAno[cursada.AnoLectivo]
+		if !ok {
+			ano := cursada.AnoLectivo
+			if datos, err = cargarDatosNotaFinal(db, comision.ID, &ano); err != nil {
+				return nil, err
+			}
+			datosPorAno[cursada.AnoLectivo] = datos
+		}
+
+		items := datos.items(esquema, cursada.AlumnoID, ahora)
+		var condicion *models.ResultadoCondicion
+		if reglas != nil {
+			// Antes del cálculo, que marca los TPs descartados
+			datosReglas := datosCondicion(reglas, items)
+			datosReglas.Asistencia = asistencias[cursada.ID].Porcentaje
+			evaluada := EvaluarCondicion(reglas, datosReglas)
+			condicion = &evaluada
+		}
+
+		resultado := models.ResultadoNotaFinal{Items: items, NotaConceptual: notaConceptualCargada(&cursada)}
+		if esquema != nil {
+			resultado = CalcularNotaFinal(esquema, items, notaConceptualCargada(&cursada))
+		}
+		if condicion != nil {
+			resultado.Condicion = condicion.Condicion
+			resultado.MotivosCondicion = condicion.Motivos
+			resultado.Provisoria = resultado.Provisoria || condicion.Provisoria
+		}
+		resultado.CursadaId = cursada.ID
+		resultado.AlumnoId = cursada.AlumnoID
+		resultado.Ajustada = cursada.NotaFinalAjustada
+		resultado.NotaFinalVigente = cursada.NotaFinal
+		resultado.CondicionVigente = cursada.Condicion
+		resultados[i] = resultado
+	}
+	return resultados, nil
+}
+
+// datosNotaFinal son los TPs y evaluaciones de una comisión en un año, con las
+// entregas de todos sus alumnos. Los recuperatorios no son ítems propios: se
+// guardan por evaluación recuperada y su nota reemplaza a la original.
+type datosNotaFinal struct {
+	tps             []models.TpModel
+	evaluaciones    []models.EvaluacionModel
+	recuperatorios  map[int]models.EvaluacionModel
+	entregasTp      map[notaKey]models.EntregaTP
+	notasEvaluacion map[notaKey]*float64
+	// Alumnos con entrega en cada evaluación (en un recuperatorio, los inscriptos)
+	inscriptos map[notaKey]bool
+}
+
+func cargarDatosNotaFinal(db *gorm.DB, comisionID int, anoLectivo *int) (*datosNotaFinal, error) {
+	datos := &datosNotaFinal{
+		recuperatorios:  map[int]models.EvaluacionModel{},
+		entregasTp:      map[notaKey]models.EntregaTP{},
+		notasEvaluacion: map[notaKey]*float64{},
+		inscriptos:      map[notaKey]bool{},
+	}
+	var err error
+	if datos.tps, err = tpsDeComision(db, comisionID, anoLectivo); err != nil {
+		return nil, err
+	}
+	evaluaciones, err := evaluacionesDeComision(db, comisionID, anoLectivo)
+	if err != nil {
+		return nil, err
+	}
+	for _, evaluacion := range evaluaciones {
+		if evaluacion.RecuperatorioDeId == nil {
+			datos.evaluaciones = append(datos.evaluaciones, evaluacion)
+		}
+	}
+
+	if len(datos.tps) > 0 {
+		ids := make([]int, len(datos.tps))
+		for i, tp := range datos.tps {
+			ids[i] = tp.ID
+		}
+		var entregas []models.EntregaTP
+		if err := db.Select("tp_id", "alumno_id", "nota").Where("tp_id IN ?", ids).Find(&entregas).Error; err != nil {
+			return nil, err
+		}
+		for _, entrega := range entregas {
+			datos.entregasTp[notaKey{entrega.TpId, entrega.AlumnoId}] = entrega
+		}
+	}
+
+	if len(datos.evaluaciones) > 0 {
+		ids := make([]int, len(datos.evaluaciones))
+		for i, evaluacion := range datos.evaluaciones {
+			ids[i] = evaluacion.ID
+		}
+		// El recuperatorio puede caer en el año siguiente: se busca por la evaluación que recupera
+		var recuperatorios []models.EvaluacionModel
+		if err := db.Where("recuperatorio_de_id IN ?", ids).Find(&recuperatorios).Error; err != nil {
+			return nil, err
+		}
+		for _, recuperatorio := range recuperatorios {
+			datos.recuperatorios[*recuperatorio.RecuperatorioDeId] = recuperatorio
+			ids = append(ids, recuperatorio.ID)
+		}
+
+		var entregas []models.EntregaEvaluacion
+		if err := db.Select("evaluacion_id", "alumno_id", "nota").Where("evaluacion_id IN ?", ids).Find(&entregas).Error; err != nil {
+			return nil, err
+		}
+		for _, entrega := range entregas {
+			key := notaKey{entrega.EvaluacionId, entrega.AlumnoId}
+			datos.inscriptos[key] = true
+			if entrega.Nota != nil {
+				datos.notasEvaluacion[key] = entrega.Nota
+			}
+		}
+	}
+	return datos, nil
+}
+
+// items arma los ítems calificables de un alumno (sin esquema todos pesan 1). Un TP sin entrega es faltante
+// una vez vencido; entregado y sin corregir queda pendiente. Una evaluación sin
+// nota es faltante después de su fecha de devolución. Si el alumno está inscripto
+// en el recuperatorio de una evaluación, se aplica su nota (ver aplicarRecuperatorio).
+func (d *datosNotaFinal) items(esquema *models.EsquemaCalificacion, alumnoID int, ahora time.Time) []models.ItemNotaFinal {
+	pesos := map[string]map[int]float64{models.ItemTp: {}, models.ItemEvaluacion: {}}
+	if esquema != nil {
+		for _, peso := range esquema.Pesos {
+			if pesos[peso.Tipo] != nil {
+				pesos[peso.Tipo][peso.RecursoId] = peso.Peso
+			}
+		}
+	}
+	pesoDe := func(tipo string, id int) float64 {
+		if peso, ok := pesos[tipo][id]; ok {
+			return peso
+		}
+		return 1
+	}
+
+	items := make([]models.ItemNotaFinal, 0, len(d.tps)+len(d.evaluaciones))
+	for _, tp := range d.tps {
+		item := models.ItemNotaFinal{Tipo: models.ItemTp, ID: tp.ID, Fecha: tp.FechaHoraEntrega, Peso: pesoDe(models.ItemTp, tp.ID)}
+		entrega, entregado := d.entregasTp[notaKey{tp.ID, alumnoID}]
+		switch {
+		case entregado && entrega.Nota != nil:
+			item.Nota = entrega.Nota
+			item.Estado = models.ItemCalificado
+		case entregado || ahora.Before(tp.FechaHoraEntrega):
+			item.Estado = models.ItemPendiente
+		default:
+			item.Estado = models.ItemFaltante
+		}
+		items = append(items, item)
+	}
+	for _, evaluacion := range d.evaluaciones {
+		fecha, _ := time.Parse("2006-01-02", prefijoFecha(evaluacion.FechaEvaluacion))
+		item := models.ItemNotaFinal{Tipo: models.ItemEvaluacion, ID: evaluacion.ID, Fecha: fecha, Peso: pesoDe(models.ItemEvaluacion, evaluacion.ID)}
+		devolucion, err := time.ParseInLocation("2006-01-02", prefijoFecha(evaluacion.FechaDevolucion), ahora.Location())
+		if nota := d.notasEvaluacion[notaKey{evaluacion.ID, alumnoID}]; nota != nil {
+			item.Nota = nota
+			item.Estado = models.ItemCalificado
+		} else if err == nil && ahora.Before(devolucion.AddDate(0, 0, 1)) {
+			item.Estado = models.ItemPendiente
+		} else {
+			item.Estado = models.ItemFaltante
+		}
+		if recuperatorio, ok := d.recuperatorios[evaluacion.ID]; ok && d.inscriptos[notaKey{recuperatorio.ID, alumnoID}] {
+			aplicarRecuperatorio(&item, &recuperatorio, d.notasEvaluacion[notaKey{recuperatorio.ID, alumnoID}], ahora)
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// aplicarRecuperatorio reemplaza la nota de la evaluación por la mejor entre la
+// original y la del recuperatorio o por la del recuperatorio, según su criterio.
+// Sin nota del recuperatorio, el ítem queda pendiente hasta su fecha de
+// devolución y después conserva la nota original.
+func aplicarRecuperatorio(item *models.ItemNotaFinal, recuperatorio *models.EvaluacionModel, nota *float64, ahora time.Time) {
+	if nota == nil {
+		devolucion, err := time.ParseInLocation("2006-01-02", prefijoFecha(recuperatorio.FechaDevolucion), ahora.Location())
+		if err == nil && ahora.Before(devolucion.AddDate(0, 0, 1)) {
+			item.Estado = models.ItemPendiente
+		}
+		return
+	}
+	item.NotaOriginal = item.Nota
+	item.NotaRecuperatorio = nota
+	if recuperatorio.CriterioRecuperatorio == models.RecuperatorioUltimaNota || item.Nota == nil || *nota > *item.Nota {
+		item.Nota = nota
+	}
+	item.Estado = models.ItemCalificado
+}
+
+// CalcularNotaFinal aplica el esquema a los ítems de una cursada:
+//   - descarta los TpsDescartados TPs con menor nota (si quedan otros)
+//   - promedia TPs y evaluaciones según el peso de cada ítem; los faltantes
+//     cuentan 0 o no cuentan según esquema.Faltantes
+//   - pondera los promedios y la nota conceptual con los pesos del esquema,
+//     ignorando los grupos que todavía no tienen notas
+//   - la condición es libre si alguna evaluación quedó bajo NotaMinimaParcial
+//     (o faltó) y si no depende de NotaPromocion y NotaRegular
+func CalcularNotaFinal(esquema *models.EsquemaCalificacion, items []models.ItemNotaFinal, notaConceptual *float64) models.ResultadoNotaFinal {
+	resultado := models.ResultadoNotaFinal{EsquemaId: esquema.ID, NotaConceptual: notaConceptual, Items: items}
+	cero := 0.0
+
+	var tpsQueCuentan []int
+	for i := range items {
+		item := &items[i]
+		if item.Peso == 0 {
+			item.Estado = models.ItemSinPeso
+			continue
+		}
+		switch item.Estado {
+		case models.ItemPendiente:
+			resultado.Provisoria = true
+		case models.ItemFaltante:
+			if item.Tipo == models.ItemEvaluacion {
+				resultado.ParcialesBajoMinimo++
+			}
+			if esquema.Faltantes == models.FaltanteCero && item.Tipo == models.ItemTp {
+				tpsQueCuentan = append(tpsQueCuentan, i)
+			}
+		case models.ItemCalificado:
+			if item.Tipo == models.ItemEvaluacion && *item.Nota < esquema.NotaMinimaParcial {
+				resultado.ParcialesBajoMinimo++
+			}
+			if item.Tipo == models.ItemTp {
+				tpsQueCuentan = append(tpsQueCuentan, i)
+			}
+		}
+	}
+
+	// Descartar los TPs con peor nota
+	if esquema.TpsDescartados > 0 && len(tpsQueCuentan) > esquema.TpsDescartados {
+		sort.SliceStable(tpsQueCuentan, func(a, b int) bool {
+			return valorNota(items[tpsQueCuentan[a]].Nota) < valorNota(items[tpsQueCuentan[b]].Nota)
+		})
+		for _, i := range tpsQueCuentan[:esquema.TpsDescartados] {
+			items[i].Estado = models.ItemDescartado
+		}
+	}
+
+	promedio := func(tipo string) *float64 {
+		var suma, pesos float64
+		for _, item := range items {
+			if item.Tipo != tipo {
+				continue
+			}
+			nota := item.Nota
+			switch {
+			case item.Estado == models.ItemFaltante && esquema.Faltantes == models.FaltanteCero:
+				nota = &cero
+			case item.Estado != models.ItemCalificado:
+				continue
+			}
+			suma += *nota * item.Peso
+			pesos += item.Peso
+		}
+		if pesos == 0 {
+			return nil
+		}
+		return redondearNota(suma / pesos)
+	}
+	resultado.PromedioTps = promedio(models.ItemTp)
+	resultado.PromedioEvaluaciones = promedio(models.ItemEvaluacion)
+
+	var suma, pesos float64
+	for _, grupo := range []struct {
+		nota *float64
+		peso float64
+	}{
+		{resultado.PromedioTps, esquema.PesoTps},
+		{resultado.PromedioEvaluaciones, esquema.PesoEvaluaciones},
+		{notaConceptual, esquema.PesoConceptual},
+	} {
+		if grupo.peso == 0 {
+			continue
+		}
+		if grupo.nota == nil {
+			// Sin notas todavía (la conceptual se carga al final de la cursada)
+			resultado.Provisoria = true
+			continue
+		}
+		suma += *grupo.nota * grupo.peso
+		pesos += grupo.peso
+	}
+	if pesos == 0 {
+		return resultado
+	}
+	resultado.NotaFinal = redondearNota(suma / pesos)
+
+	switch nota := *resultado.NotaFinal; {
+	case resultado.ParcialesBajoMinimo > 0:
+		resultado.Condicion = models.CondicionLibre
+	case nota >= esquema.NotaPromocion:
+		resultado.Condicion = models.CondicionPromocionado
+	case nota >= esquema.NotaRegular:
+		resultado.Condicion = models.CondicionRegular
+	default:
+		resultado.Condicion = models.CondicionLibre
+	}
+	return resultado
+}
+
+func redondearNota(nota float64) *float64 {
+	nota = math.Round(nota*100) / 100
+	return &nota
+}
+
+func valorNota(nota *float64) float64 {
+	if nota == nil {
+		return 0
+	}
+	return *nota
+}
+
+// AjustarNotaFinal reemplaza la nota final y la condición calculadas por las que
+// indica el profesor; el cambio queda registrado con su motivo
+func (s *NotaFinalService) AjustarNotaFinal(cursadaID int, req *models.AjusteNotaFinalRequest, actorID int, role models.Role) (*models.Cursada, error) {
+	if !req.Condicion.IsValid() {
+		return nil, ErrCondicionInvalida
+	}
+
+	var cursada models.Cursada
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&cursada, cursadaID).Error; err != nil {
+			return err
+		}
+		ajuste := models.AjusteNotaFinal{
+			CursadaId:         cursada.ID,
+			NotaAnterior:      cursada.NotaFinal,
+			NotaNueva:         req.NotaFinal,
+			CondicionAnterior: cursada.Condicion,
+			CondicionNueva:    req.Condicion,
+			NotaCalculada:     cursada.NotaFinalCalculada,
+			Motivo:            req.Motivo,
+			ActorID:           actorID,
+			ActorRole:         role,
+		}
+		if err := tx.Create(&ajuste).Error; err != nil {
+			return err
+		}
+		if req.Condicion != cursada.Condicion {
+			if err := notificarCambioCondicion(tx, &cursada, req.Condicion, false); err != nil {
+				return err
+			}
+		}
+
+		cursada.NotaFinal = req.NotaFinal
+		cursada.Condicion = req.Condicion
+		cursada.NotaFinalAjustada = true
+		return tx.Model(&cursada).Updates(map[string]interface{}{
+			"nota_final":          cursada.NotaFinal,
+			"condicion":           cursada.Condicion,
+			"nota_final_ajustada": true,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &cursada, nil
+}
+
+// QuitarAjuste vuelve a la nota final y condición calculadas
+func (s *NotaFinalService) QuitarAjuste(cursadaID int, motivo string, actorID int, role models.Role) (*models.Cursada, error) {
+	if motivo == "" {
+		motivo = "Se restableció la nota calculada"
+	}
+
+	var cursada models.Cursada
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Preload("Comision").First(&cursada, cursadaID).Error; err != nil {
+			return err
+		}
+		if !cursada.NotaFinalAjustada {
+			return ErrNotaNoAjustada
+		}
+		resultados, err := calcularNotasFinales(tx, &cursada.Comision, []models.Cursada{cursada})
+		if err != nil {
+			return err
+		}
+		resultado := &resultados[0]
+
+		ajuste := models.AjusteNotaFinal{
+			CursadaId:         cursada.ID,
+			NotaAnterior:      cursada.NotaFinal,
+			NotaNueva:         valorNota(resultado.NotaFinal),
+			CondicionAnterior: cursada.Condicion,
+			CondicionNueva:    resultado.Condicion,
+			NotaCalculada:     resultado.NotaFinal,
+			Revertido:         true,
+			Motivo:            motivo,
+			ActorID:           actorID,
+			ActorRole:         role,
+		}
+		if err := tx.Create(&ajuste).Error; err != nil {
+			return err
+		}
+
+		cursada.NotaFinalAjustada = false
+		if err := tx.Model(&cursada).Update("nota_final_ajustada", false).Error; err != nil {
+			return err
+		}
+		return guardarResultado(tx, &cursada, resultado)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.First(&cursada, cursadaID).Error; err != nil {
+		return nil, err
+	}
+	return &cursada, nil
+}
+
+// GetAjustes devuelve el historial de ajustes manuales de la cursada, del más reciente al más antiguo
+func (s *NotaFinalService) GetAjustes(cursadaID int) ([]models.AjusteNotaFinal, error) {
+	var ajustes []models.AjusteNotaFinal
+	if err := s.db.Where("cursada_id = ?", cursadaID).Order("created_at DESC, id DESC").Find(&ajustes).Error; err != nil {
+		return nil, err
+	}
+	return ajustes, nil
+}
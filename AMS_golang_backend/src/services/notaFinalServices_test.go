@@ -0,0 +1,372 @@
+package services
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/LINSITrack/backend/src/models"
+)
+
+func nota(v float64) *float64 {
+	return &v
+}
+
+func itemTp(n *float64, estado string) models.ItemNotaFinal {
+	return models.ItemNotaFinal{Tipo: models.ItemTp, Nota: n, Peso: 1, Estado: estado}
+}
+
+func itemEvaluacion(n *float64, estado string) models.ItemNotaFinal {
+	return models.ItemNotaFinal{Tipo: models.ItemEvaluacion, Nota: n, Peso: 1, Estado: estado}
+}
+
+func mismaNota(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return math.Abs(*a-*b) < 1e-9
+}
+
+func TestCalcularNotaFinal(t *testing.T) {
+	base := models.EsquemaCalificacion{
+		PesoTps:           0.4,
+		PesoEvaluaciones:  0.6,
+		NotaMinimaParcial: 4,
+		NotaRegular:       4,
+		NotaPromocion:     7,
+		Faltantes:         models.FaltanteCero,
+	}
+	esquema := func(cambios func(*models.EsquemaCalificacion)) models.EsquemaCalificacion {
+		e := base
+		cambios(&e)
+		return e
+	}
+	conConceptual := esquema(func(e *models.EsquemaCalificacion) {
+		e.PesoTps, e.PesoEvaluaciones, e.PesoConceptual = 0.4, 0.4, 0.2
+	})
+	calificado, faltante, pendiente := models.ItemCalificado, models.ItemFaltante, models.ItemPendiente
+
+	tests := []struct {
+		name           string
+		esquema        models.EsquemaCalificacion
+		items          []models.ItemNotaFinal
+		conceptual     *float64
+		wantNota       *float64
+		wantCondicion  models.CondicionCursada
+		wantProvisoria bool
+		wantBajoMinimo int
+		// Estado final de cada ítem; vacío para no controlarlo
+		wantEstados []string
+	}{
+		{
+			name:          "todo calificado",
+			esquema:       base,
+			items:         []models.ItemNotaFinal{itemTp(nota(8), calificado), itemTp(nota(6), calificado), itemEvaluacion(nota(7), calificado), itemEvaluacion(nota(9), calificado)},
+			wantNota:      nota(7.6),
+			wantCondicion: models.CondicionPromocionado,
+		},
+		{
+			name:          "TP faltante cuenta 0",
+			esquema:       base,
+			items:         []models.ItemNotaFinal{itemTp(nota(8), calificado), itemTp(nil, faltante), itemEvaluacion(nota(6), calificado)},
+			wantNota:      nota(5.2),
+			wantCondicion: models.CondicionRegular,
+		},
+		{
+			name:          "TP faltante excluido",
+			esquema:       esquema(func(e *models.EsquemaCalificacion) { e.Faltantes = models.FaltanteExcluir }),
+			items:         []models.ItemNotaFinal{itemTp(nota(8), calificado), itemTp(nil, faltante), itemEvaluacion(nota(6), calificado)},
+			wantNota:      nota(6.8),
+			wantCondicion: models.CondicionRegular,
+		},
+		{
+			name:          "descarta el TP con peor nota",
+			esquema:       esquema(func(e *models.EsquemaCalificacion) { e.TpsDescartados = 1 }),
+			items:         []models.ItemNotaFinal{itemTp(nota(8), calificado), itemTp(nota(2), calificado), itemTp(nota(6), calificado), itemEvaluacion(nota(7), calificado)},
+			wantNota:      nota(7),
+			wantCondicion: models.CondicionPromocionado,
+			wantEstados:   []string{calificado, models.ItemDescartado, calificado, calificado},
+		},
+		{
+			name:          "el TP faltante que cuenta 0 es el primero en descartarse",
+			esquema:       esquema(func(e *models.EsquemaCalificacion) { e.TpsDescartados = 1 }),
+			items:         []models.ItemNotaFinal{itemTp(nota(8), calificado), itemTp(nil, faltante), itemTp(nota(6), calificado), itemEvaluacion(nota(7), calificado)},
+			wantNota:      nota(7),
+			wantCondicion: models.CondicionPromocionado,
+			wantEstados:   []string{calificado, models.ItemDescartado, calificado, calificado},
+		},
+		{
+			name:          "no descarta si no quedan otros TPs",
+			esquema:       esquema(func(e *models.EsquemaCalificacion) { e.TpsDescartados = 1 }),
+			items:         []models.ItemNotaFinal{itemTp(nota(5), calificado), itemEvaluacion(nota(5), calificado)},
+			wantNota:      nota(5),
+			wantCondicion: models.CondicionRegular,
+			wantEstados:   []string{calificado, calificado},
+		},
+		{
+			name:           "evaluación bajo el mínimo deja libre",
+			esquema:        base,
+			items:          []models.ItemNotaFinal{itemTp(nota(10), calificado), itemEvaluacion(nota(3), calificado), itemEvaluacion(nota(10), calificado)},
+			wantNota:       nota(7.9),
+			wantCondicion:  models.CondicionLibre,
+			wantBajoMinimo: 1,
+		},
+		{
+			name:           "evaluación faltante deja libre aunque se excluya del promedio",
+			esquema:        esquema(func(e *models.EsquemaCalificacion) { e.Faltantes = models.FaltanteExcluir }),
+			items:          []models.ItemNotaFinal{itemTp(nota(9), calificado), itemEvaluacion(nota(9), calificado), itemEvaluacion(nil, faltante)},
+			wantNota:       nota(9),
+			wantCondicion:  models.CondicionLibre,
+			wantBajoMinimo: 1,
+		},
+		{
+			name:           "pendiente no cuenta y deja el cálculo provisorio",
+			esquema:        base,
+			items:          []models.ItemNotaFinal{itemTp(nota(8), calificado), itemTp(nil, pendiente), itemEvaluacion(nota(6), calificado)},
+			wantNota:       nota(6.8),
+			wantCondicion:  models.CondicionRegular,
+			wantProvisoria: true,
+		},
+		{
+			name:           "TP pendiente no se descarta",
+			esquema:        esquema(func(e *models.EsquemaCalificacion) { e.TpsDescartados = 1 }),
+			items:          []models.ItemNotaFinal{itemTp(nota(8), calificado), itemTp(nil, pendiente), itemEvaluacion(nota(8), calificado)},
+			wantNota:       nota(8),
+			wantCondicion:  models.CondicionPromocionado,
+			wantProvisoria: true,
+			wantEstados:    []string{calificado, pendiente, calificado},
+		},
+		{
+			name:           "sin nota conceptual se ignora su peso",
+			esquema:        conConceptual,
+			items:          []models.ItemNotaFinal{itemTp(nota(6), calificado), itemEvaluacion(nota(8), calificado)},
+			wantNota:       nota(7),
+			wantCondicion:  models.CondicionPromocionado,
+			wantProvisoria: true,
+		},
+		{
+			name:          "con nota conceptual",
+			esquema:       conConceptual,
+			items:         []models.ItemNotaFinal{itemTp(nota(6), calificado), itemEvaluacion(nota(8), calificado)},
+			conceptual:    nota(10),
+			wantNota:      nota(7.6),
+			wantCondicion: models.CondicionPromocionado,
+		},
+		{
+			name:          "nota conceptual 0 cuenta",
+			esquema:       conConceptual,
+			items:         []models.ItemNotaFinal{itemTp(nota(6), calificado), itemEvaluacion(nota(8), calificado)},
+			conceptual:    nota(0),
+			wantNota:      nota(5.6),
+			wantCondicion: models.CondicionRegular,
+		},
+		{
+			name:           "sin notas todavía",
+			esquema:        base,
+			items:          []models.ItemNotaFinal{itemTp(nil, pendiente), itemEvaluacion(nil, pendiente)},
+			wantProvisoria: true,
+		},
+		{
+			name:          "ítem con peso 0 no cuenta",
+			esquema:       base,
+			items:         []models.ItemNotaFinal{{Tipo: models.ItemTp, Nota: nota(1), Estado: calificado}, itemTp(nota(8), calificado), itemEvaluacion(nota(8), calificado)},
+			wantNota:      nota(8),
+			wantCondicion: models.CondicionPromocionado,
+			wantEstados:   []string{models.ItemSinPeso, calificado, calificado},
+		},
+		{
+			name:    "promedia según el peso de cada ítem",
+			esquema: base,
+			items: []models.ItemNotaFinal{
+				{Tipo: models.ItemTp, Nota: nota(9), Peso: 2, Estado: calificado},
+				itemTp(nota(6), calificado),
+				itemEvaluacion(nota(8), calificado),
+			},
+			wantNota:      nota(8),
+			wantCondicion: models.CondicionPromocionado,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resultado := CalcularNotaFinal(&tt.esquema, tt.items, tt.conceptual)
+			if !mismaNota(resultado.NotaFinal, tt.wantNota) {
+				t.Errorf("NotaFinal = %v, want %v", valorNota(resultado.NotaFinal), valorNota(tt.wantNota))
+			}
+			if resultado.Condicion != tt.wantCondicion {
+				t.Errorf("Condicion = %q, want %q", resultado.Condicion, tt.wantCondicion)
+			}
+			if resultado.Provisoria != tt.wantProvisoria {
+				t.Errorf("Provisoria = %v, want %v", resultado.Provisoria, tt.wantProvisoria)
+			}
+			if resultado.ParcialesBajoMinimo != tt.wantBajoMinimo {
+				t.Errorf("ParcialesBajoMinimo = %d, want %d", resultado.ParcialesBajoMinimo, tt.wantBajoMinimo)
+			}
+			for i, estado := range tt.wantEstados {
+				if resultado.Items[i].Estado != estado {
+					t.Errorf("Items[%d].Estado = %q, want %q", i, resultado.Items[i].Estado, estado)
+				}
+			}
+		})
+	}
+}
+
+func TestItemsPendientesYFaltantes(t *testing.T) {
+	const alumnoID = 1
+	ahora := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	antes, despues := ahora.AddDate(0, 0, -1), ahora.AddDate(0, 0, 1)
+
+	tests := []struct {
+		name       string
+		tp         *models.TpModel
+		entrega    *models.EntregaTP
+		evaluacion *models.EvaluacionModel
+		nota       *float64
+		want       string
+	}{
+		{name: "TP calificado", tp: &models.TpModel{ID: 1, FechaHoraEntrega: antes}, entrega: &models.EntregaTP{Nota: nota(7)}, want: models.ItemCalificado},
+		{name: "TP entregado sin corregir", tp: &models.TpModel{ID: 1, FechaHoraEntrega: antes}, entrega: &models.EntregaTP{}, want: models.ItemPendiente},
+		{name: "TP sin entregar con el plazo abierto", tp: &models.TpModel{ID: 1, FechaHoraEntrega: despues}, want: models.ItemPendiente},
+		{name: "TP sin entregar con el plazo vencido", tp: &models.TpModel{ID: 1, FechaHoraEntrega: antes}, want: models.ItemFaltante},
+		{name: "evaluación calificada", evaluacion: &models.EvaluacionModel{ID: 1, FechaDevolucion: "2026-05-01"}, nota: nota(7), want: models.ItemCalificado},
+		{name: "evaluación sin nota el día de la devolución", evaluacion: &models.EvaluacionModel{ID: 1, FechaDevolucion: "2026-05-10"}, want: models.ItemPendiente},
+		{name: "evaluación sin nota después de la devolución", evaluacion: &models.EvaluacionModel{ID: 1, FechaDevolucion: "2026-05-09"}, want: models.ItemFaltante},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			datos := &datosNotaFinal{
+				recuperatorios:  map[int]models.EvaluacionModel{},
+				entregasTp:      map[notaKey]models.EntregaTP{},
+				notasEvaluacion: map[notaKey]*float64{},
+				inscriptos:      map[notaKey]bool{},
+			}
+			if tt.tp != nil {
+				datos.tps = []models.TpModel{*tt.tp}
+				if tt.entrega != nil {
+					datos.entregasTp[notaKey{tt.tp.ID, alumnoID}] = *tt.entrega
+				}
+			}
+			if tt.evaluacion != nil {
+				datos.evaluaciones = []models.EvaluacionModel{*tt.evaluacion}
+				datos.notasEvaluacion[notaKey{tt.evaluacion.ID, alumnoID}] = tt.nota
+			}
+
+			items := datos.items(nil, alumnoID, ahora)
+			if len(items) != 1 {
+				t.Fatalf("len(items) = %d, want 1", len(items))
+			}
+			if items[0].Estado != tt.want {
+				t.Errorf("Estado = %q, want %q", items[0].Estado, tt.want)
+			}
+		})
+	}
+}
+
+func TestItemsRecuperatorio(t *testing.T) {
+	const alumnoID = 1
+	despuesDeDevolver := time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC)
+	antesDeDevolver := time.Date(2026, 6, 5, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		criterio      models.CriterioRecuperatorio
+		original      *float64
+		recuperatorio *float64
+		noInscripto   bool
+		ahora         time.Time
+		wantNota      *float64
+		wantOriginal  *float64
+		wantEstado    string
+	}{
+		{name: "mejor nota: sube con el recuperatorio", criterio: models.RecuperatorioMejorNota, original: nota(3), recuperatorio: nota(6), ahora: despuesDeDevolver, wantNota: nota(6), wantOriginal: nota(3), wantEstado: models.ItemCalificado},
+		{name: "mejor nota: conserva la original si el recuperatorio es peor", criterio: models.RecuperatorioMejorNota, original: nota(7), recuperatorio: nota(5), ahora: despuesDeDevolver, wantNota: nota(7), wantOriginal: nota(7), wantEstado: models.ItemCalificado},
+		{name: "última nota: reemplaza aunque sea peor", criterio: models.RecuperatorioUltimaNota, original: nota(7), recuperatorio: nota(5), ahora: despuesDeDevolver, wantNota: nota(5), wantOriginal: nota(7), wantEstado: models.ItemCalificado},
+		{name: "ausente en la evaluación y aprobado en el recuperatorio", criterio: models.RecuperatorioMejorNota, recuperatorio: nota(6), ahora: despuesDeDevolver, wantNota: nota(6), wantEstado: models.ItemCalificado},
+		{name: "recuperatorio sin nota antes de la devolución", criterio: models.RecuperatorioMejorNota, original: nota(3), ahora: antesDeDevolver, wantNota: nota(3), wantEstado: models.ItemPendiente},
+		{name: "recuperatorio sin nota después de la devolución", criterio: models.RecuperatorioMejorNota, original: nota(3), ahora: despuesDeDevolver, wantNota: nota(3), wantEstado: models.ItemCalificado},
+		{name: "no inscripto en el recuperatorio", criterio: models.RecuperatorioUltimaNota, original: nota(3), recuperatorio: nota(8), noInscripto: true, ahora: despuesDeDevolver, wantNota: nota(3), wantEstado: models.ItemCalificado},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluacion := models.EvaluacionModel{ID: 1, FechaEvaluacion: "2026-05-01", FechaDevolucion: "2026-05-10"}
+			recuperatorio := models.EvaluacionModel{ID: 2, FechaEvaluacion: "2026-06-01", FechaDevolucion: "2026-06-10", RecuperatorioDeId: &evaluacion.ID, CriterioRecuperatorio: tt.criterio}
+			datos := &datosNotaFinal{
+				evaluaciones:   []models.EvaluacionModel{evaluacion},
+				recuperatorios: map[int]models.EvaluacionModel{evaluacion.ID: recuperatorio},
+				entregasTp:     map[notaKey]models.EntregaTP{},
+				notasEvaluacion: map[notaKey]*float64{
+					{evaluacion.ID, alumnoID}:    tt.original,
+					{recuperatorio.ID, alumnoID}: tt.recuperatorio,
+				},
+				inscriptos: map[notaKey]bool{{recuperatorio.ID, alumnoID}: !tt.noInscripto},
+			}
+
+			items := datos.items(nil, alumnoID, tt.ahora)
+			if len(items) != 1 {
+				t.Fatalf("len(items) = %d, want 1", len(items))
+			}
+			item := items[0]
+			if !mismaNota(item.Nota, tt.wantNota) {
+				t.Errorf("Nota = %v, want %v", valorNota(item.Nota), valorNota(tt.wantNota))
+			}
+			if tt.recuperatorio != nil && !tt.noInscripto && !mismaNota(item.NotaOriginal, tt.wantOriginal) {
+				t.Errorf("NotaOriginal = %v, want %v", valorNota(item.NotaOriginal), valorNota(tt.wantOriginal))
+			}
+			if item.Estado != tt.wantEstado {
+				t.Errorf("Estado = %q, want %q", item.Estado, tt.wantEstado)
+			}
+		})
+	}
+}
+
+func TestCambiosResultado(t *testing.T) {
+	tests := []struct {
+		name      string
+		ajustada  bool
+		resultado models.ResultadoNotaFinal
+		want      []string
+	}{
+		{
+			name:      "con esquema",
+			resultado: models.ResultadoNotaFinal{EsquemaId: 1, NotaFinal: nota(7.5), Condicion: models.CondicionRegular},
+			want:      []string{"nota_final_calculada", "condicion_calculada", "nota_final_provisoria", "nota_final", "condicion"},
+		},
+		{
+			name:      "con esquema y sin notas cargadas",
+			resultado: models.ResultadoNotaFinal{EsquemaId: 1, Provisoria: true},
+			want:      []string{"nota_final_calculada", "condicion_calculada", "nota_final_provisoria", "nota_final", "condicion"},
+		},
+		{
+			// la nota final cargada antes de las reglas no se pisa con 0
+			name:      "solo reglas de condición",
+			resultado: models.ResultadoNotaFinal{Condicion: models.CondicionLibre},
+			want:      []string{"condicion_calculada", "nota_final_provisoria", "condicion"},
+		},
+		{
+			name:      "ajustada por el profesor",
+			ajustada:  true,
+			resultado: models.ResultadoNotaFinal{EsquemaId: 1, NotaFinal: nota(4), Condicion: models.CondicionRegular},
+			want:      []string{"nota_final_calculada", "condicion_calculada", "nota_final_provisoria"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursada := &models.Cursada{NotaFinal: 8, NotaFinalAjustada: tt.ajustada}
+			cambios := cambiosResultado(cursada, &tt.resultado)
+			if len(cambios) != len(tt.want) {
+				t.Errorf("cambios = %v, want las columnas %v", cambios, tt.want)
+			}
+			for _, columna := range tt.want {
+				if _, ok := cambios[columna]; !ok {
+					t.Errorf("falta la columna %q en %v", columna, cambios)
+				}
+			}
+			if v, ok := cambios["nota_final"]; ok && v != valorNota(tt.resultado.NotaFinal) {
+				t.Errorf("nota_final = %v, want %v", v, valorNota(tt.resultado.NotaFinal))
+			}
+		})
+	}
+}
@@ -0,0 +1,438 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/LINSITrack/backend/src/models"
+	"github.com/LINSITrack/backend/utils/spreadsheet"
+	"gorm.io/gorm"
+)
+
+// ErrPlanillaInvalida indica que la planilla no se pudo leer o no tiene las columnas esperadas
+var ErrPlanillaInvalida = errors.New("planilla inválida")
+
+// NotasImportService carga notas en bloque desde una planilla (CSV o XLSX)
+// identificando a cada alumno por legajo. Primero valida todas las filas contra
+// los alumnos inscriptos en la comisión y arma el diff; solo si no hay errores
+// (y no es un dry-run) aplica todas las filas en una transacción.
+type NotasImportService struct {
+	db      *gorm.DB
+	estados *EntregaEstadoService
+	grupos  *GrupoService
+}
+
+func NewNotasImportService(db *gorm.DB, estados *EntregaEstadoService, grupos *GrupoService) *NotasImportService {
+	return &NotasImportService{db: db, estados: estados, grupos: grupos}
+}
+
+// filaNotas es una fila de la planilla; los campos nil son celdas vacías (no se modifican)
+type filaNotas struct {
+	numero        int
+	legajo        string
+	nota          *float64
+	devolucion    *string
+	observaciones *string
+	// Texto de la celda nota si no es un número
+	notaInvalida string
+}
+
+// ImportarNotasTp califica las entregas de un TP. La columna observaciones no aplica a los TPs.
+func (s *NotasImportService) ImportarNotasTp(tpID int, nombre string, r io.Reader, dryRun bool, actorID int, role models.Role) (*models.NotasImportResultado, error) {
+	var tp models.TpModel
+	if err := s.db.First(&tp, tpID).Error; err != nil {
+		return nil, err
+	}
+	filas, err := leerPlanillaNotas(nombre, r)
+	if err != nil {
+		return nil, err
+	}
+	inscriptos, err := s.inscriptosPorLegajo(tp.ComisionId)
+	if err != nil {
+		return nil, err
+	}
+
+	var entregas []models.EntregaTP
+	if err := s.db.Where("tp_id = ?", tpID).Find(&entregas).Error; err != nil {
+		return nil, err
+	}
+	entregaDe := map[int]*models.EntregaTP{}
+	for i := range entregas {
+		entregaDe[entregas[i].AlumnoId] = &entregas[i]
+	}
+
+	resultado := &models.NotasImportResultado{DryRun: dryRun}
+	var aplicar []*models.EntregaTP
+	aplicarFila := map[int]filaNotas{}
+	// En un TP grupal todos los integrantes comparten la corrección: sus filas deben
+	// coincidir y se aplica una sola (PropagarCorreccion la copia al resto del grupo)
+	porGrupo := map[int]filaNotas{}
+
+	for _, fila := range validarFilas(filas, inscriptos, resultado) {
+		resFila := &resultado.Filas[fila.idx]
+		entrega, ok := entregaDe[resFila.AlumnoId]
+		if !ok {
+			marcarError(resultado, resFila, "el alumno no tiene una entrega para este TP")
+			continue
+		}
+		if fila.nota == nil && fila.devolucion == nil {
+			marcarError(resultado, resFila, "la fila no tiene nota ni devolución")
+			continue
+		}
+		propagada := false
+		if entrega.GrupoId != nil {
+			previa, ok := porGrupo[*entrega.GrupoId]
+			if ok && !mismaCorreccion(previa, fila.filaNotas) {
+				marcarError(resultado, resFila, fmt.Sprintf("la corrección no coincide con la de la fila %d (mismo grupo)", previa.numero))
+				continue
+			}
+			propagada = ok
+			porGrupo[*entrega.GrupoId] = fila.filaNotas
+		}
+
+		campos := diffEntregaTP(entrega, fila.filaNotas)
+		if len(campos) == 0 {
+			marcarSinCambios(resultado, resFila)
+			continue
+		}
+		resFila.Estado = models.FilaImportCambio
+		resFila.Campos = campos
+		resultado.Cambios++
+		if !propagada {
+			aplicar = append(aplicar, entrega)
+			aplicarFila[entrega.ID] = fila.filaNotas
+		}
+	}
+
+	if dryRun || resultado.Errores > 0 || len(aplicar) == 0 {
+		return resultado, nil
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var alumnos []int
+		for _, entrega := range aplicar {
+			if err := s.calificarEntregaTP(tx, entrega.ID, aplicarFila[entrega.ID], actorID, role); err != nil {
+				return err
+			}
+			integrantes, err := s.grupos.IntegrantesDe(tpID, entrega.AlumnoId)
+			if err != nil {
+				return err
+			}
+			alumnos = append(alumnos, integrantes...)
+		}
+		return RecalcularCursadas(tx, tp.ComisionId, alumnos)
+	})
+	if err != nil {
+		return nil, err
+	}
+	resultado.Aplicado = true
+	return resultado, nil
+}
+
+// calificarEntregaTP aplica una fila igual que la corrección manual: penalización
+// por atraso, paso a "calificado" (con su notificación), intento vigente y grupo
+func (s *NotasImportService) calificarEntregaTP(tx *gorm.DB, entregaID int, fila filaNotas, actorID int, role models.Role) error {
+	var entrega models.EntregaTP
+	if err := tx.First(&entrega, entregaID).Error; err != nil {
+		return err
+	}
+	if fila.nota != nil {
+		AsignarNota(&entrega, *fila.nota)
+	}
+	if fila.devolucion != nil {
+		entrega.Devolucion = *fila.devolucion
+	}
+	if err := tx.Omit("Estado", "Intentos", "Tp", "Alumno", "Cursada").Save(&entrega).Error; err != nil {
+		return err
+	}
+
+	var nuevoEstado models.EstadoEntrega
+	if fila.nota != nil && (entrega.Estado == models.EstadoPendiente || entrega.Estado == models.EstadoEnCorreccion) {
+		nuevoEstado = models.EstadoCalificado
+		if err := s.estados.Transicionar(tx, &entrega, nuevoEstado, actorID, role, entrega.Devolucion); err != nil {
+			return err
+		}
+	}
+	if err := calificarIntento(tx, &entrega); err != nil {
+		return err
+	}
+	return s.grupos.PropagarCorreccion(tx, &entrega, nuevoEstado, actorID, role, entrega.Devolucion)
+}
+
+// ImportarNotasEvaluacion califica las entregas de una evaluación
+func (s *NotasImportService) ImportarNotasEvaluacion(evaluacionID int, nombre string, r io.Reader, dryRun bool) (*models.NotasImportResultado, error) {
+	var evaluacion models.EvaluacionModel
+	if err := s.db.Preload("Comision.Materia").First(&evaluacion, evaluacionID).Error; err != nil {
+		return nil, err
+	}
+	filas, err := leerPlanillaNotas(nombre, r)
+	if err != nil {
+		return nil, err
+	}
+	inscriptos, err := s.inscriptosPorLegajo(evaluacion.ComisionId)
+	if err != nil {
+		return nil, err
+	}
+
+	var entregas []models.EntregaEvaluacion
+	if err := s.db.Where("evaluacion_id = ?", evaluacionID).Find(&entregas).Error; err != nil {
+		return nil, err
+	}
+	entregaDe := map[int]*models.EntregaEvaluacion{}
+	for i := range entregas {
+		entregaDe[entregas[i].AlumnoId] = &entregas[i]
+	}
+
+	resultado := &models.NotasImportResultado{DryRun: dryRun}
+	var aplicar []*models.EntregaEvaluacion
+	var calificadas []*models.EntregaEvaluacion
+
+	for _, fila := range validarFilas(filas, inscriptos, resultado) {
+		resFila := &resultado.Filas[fila.idx]
+		if fila.nota == nil && fila.devolucion == nil && fila.observaciones == nil {
+			marcarError(resultado, resFila, "la fila no tiene nota, devolución ni observaciones")
+			continue
+		}
+
+		// Los inscriptos sin entrega (evaluación no sincronizada) se crean al aplicar;
+		// en un recuperatorio solo rinden los alumnos inscriptos
+		entrega, ok := entregaDe[resFila.AlumnoId]
+		if !ok && evaluacion.RecuperatorioDeId != nil {
+			marcarError(resultado, resFila, "el alumno no está inscripto en el recuperatorio")
+			continue
+		}
+		if !ok {
+			entrega = &models.EntregaEvaluacion{EvaluacionId: evaluacionID, AlumnoId: resFila.AlumnoId}
+		}
+		actualizada := *entrega
+		campos := diffEntregaEvaluacion(&actualizada, fila.filaNotas)
+		if len(campos) == 0 {
+			marcarSinCambios(resultado, resFila)
+			continue
+		}
+		resFila.Estado = models.FilaImportCambio
+		resFila.Campos = campos
+		resultado.Cambios++
+		aplicar = append(aplicar, &actualizada)
+		if entrega.Nota == nil && actualizada.Nota != nil {
+			calificadas = append(calificadas, &actualizada)
+		}
+	}
+
+	if dryRun || resultado.Errores > 0 || len(aplicar) == 0 {
+		return resultado, nil
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		alumnos := make([]int, 0, len(aplicar))
+		for _, entrega := range aplicar {
+			if err := tx.Omit("Evaluacion", "Alumno").Save(entrega).Error; err != nil {
+				return err
+			}
+			alumnos = append(alumnos, entrega.AlumnoId)
+		}
+		for _, entrega := range calificadas {
+			if err := notificarEvaluacionCalificada(tx, &evaluacion, entrega.AlumnoId, *entrega.Nota); err != nil {
+				return err
+			}
+		}
+		if err := inscribirRecuperatoriosDe(tx, evaluacionID); err != nil {
+			return err
+		}
+		return RecalcularCursadas(tx, evaluacion.ComisionId, alumnos)
+	})
+	if err != nil {
+		return nil, err
+	}
+	resultado.Aplicado = true
+	return resultado, nil
+}
+
+// filaValida es una fila que pasó las validaciones comunes; idx es su posición en resultado.Filas
+type filaValida struct {
+	filaNotas
+	idx int
+}
+
+// validarFilas agrega cada fila al resultado, marca los errores comunes (legajo
+// vacío, repetido o no inscripto, nota inválida) y devuelve las filas válidas
+func validarFilas(filas []filaNotas, inscriptos map[string]models.Alumno, resultado *models.NotasImportResultado) []filaValida {
+	var validas []filaValida
+	vistos := map[string]int{}
+
+	for _, fila := range filas {
+		resultado.Filas = append(resultado.Filas, models.NotasImportFila{Fila: fila.numero, Legajo: fila.legajo})
+		resFila := &resultado.Filas[len(resultado.Filas)-1]
+
+		alumno, inscripto := inscriptos[fila.legajo]
+		if inscripto {
+			resFila.AlumnoId = alumno.ID
+			resFila.Alumno = alumno.Apellido + ", " + alumno.Nombre
+		}
+		switch previa, repetido := vistos[fila.legajo]; {
+		case fila.legajo == "":
+			marcarError(resultado, resFila, "legajo vacío")
+			continue
+		case repetido:
+			marcarError(resultado, resFila, fmt.Sprintf("legajo repetido (fila %d)", previa))
+			continue
+		case !inscripto:
+			marcarError(resultado, resFila, "el legajo no corresponde a un alumno inscripto en la comisión")
+			continue
+		case fila.notaInvalida != "":
+			marcarError(resultado, resFila, fmt.Sprintf("nota inválida: %q", fila.notaInvalida))
+			continue
+		case fila.nota != nil && (*fila.nota < 0 || *fila.nota > 10):
+			marcarError(resultado, resFila, "la nota debe estar entre 0 y 10")
+			continue
+		}
+		vistos[fila.legajo] = fila.numero
+		validas = append(validas, filaValida{filaNotas: fila, idx: len(resultado.Filas) - 1})
+	}
+	return validas
+}
+
+func marcarError(resultado *models.NotasImportResultado, fila *models.NotasImportFila, mensaje string) {
+	fila.Estado = models.FilaImportError
+	fila.Error = mensaje
+	resultado.Errores++
+}
+
+func marcarSinCambios(resultado *models.NotasImportResultado, fila *models.NotasImportFila) {
+	fila.Estado = models.FilaImportSinCambios
+	resultado.SinCambios++
+}
+
+func mismaCorreccion(a, b filaNotas) bool {
+	return equalFloatPtr(a.nota, b.nota) && equalStringPtr(a.devolucion, b.devolucion)
+}
+
+// diffEntregaTP devuelve los campos que cambiaría la fila (la nota ya penalizada)
+func diffEntregaTP(entrega *models.EntregaTP, fila filaNotas) []models.CampoDiff {
+	var campos []models.CampoDiff
+	if fila.nota != nil {
+		actualizada := *entrega
+		AsignarNota(&actualizada, *fila.nota)
+		if !equalFloatPtr(entrega.Nota, actualizada.Nota) {
+			campos = append(campos, models.CampoDiff{Campo: "nota", Desde: entrega.Nota, Hasta: actualizada.Nota})
+		}
+		if entrega.Estado == models.EstadoPendiente || entrega.Estado == models.EstadoEnCorreccion {
+			campos = append(campos, models.CampoDiff{Campo: "estado", Desde: entrega.Estado, Hasta: models.EstadoCalificado})
+		}
+	}
+	if fila.devolucion != nil && *fila.devolucion != entrega.Devolucion {
+		campos = append(campos, models.CampoDiff{Campo: "devolucion", Desde: entrega.Devolucion, Hasta: *fila.devolucion})
+	}
+	return campos
+}
+
+// diffEntregaEvaluacion aplica la fila sobre entrega y devuelve los campos que cambiaron
+func diffEntregaEvaluacion(entrega *models.EntregaEvaluacion, fila filaNotas) []models.CampoDiff {
+	var campos []models.CampoDiff
+	if fila.nota != nil && !equalFloatPtr(entrega.Nota, fila.nota) {
+		campos = append(campos, models.CampoDiff{Campo: "nota", Desde: entrega.Nota, Hasta: *fila.nota})
+		entrega.Nota = fila.nota
+	}
+	if fila.devolucion != nil && !equalStringPtr(entrega.Devolucion, fila.devolucion) {
+		campos = append(campos, models.CampoDiff{Campo: "devolucion", Desde: entrega.Devolucion, Hasta: *fila.devolucion})
+		entrega.Devolucion = fila.devolucion
+	}
+	if fila.observaciones != nil && !equalStringPtr(entrega.Observaciones, fila.observaciones) {
+		campos = append(campos, models.CampoDiff{Campo: "observaciones", Desde: entrega.Observaciones, Hasta: *fila.observaciones})
+		entrega.Observaciones = fila.observaciones
+	}
+	return campos
+}
+
+// inscriptosPorLegajo devuelve los alumnos con cursada en la comisión
+func (s *NotasImportService) inscriptosPorLegajo(comisionID int) (map[string]models.Alumno, error) {
+	var cursadas []models.Cursada
+	if err := s.db.Preload("Alumno").Where("comision_id = ?", comisionID).Find(&cursadas).Error; err != nil {
+		return nil, err
+	}
+	inscriptos := make(map[string]models.Alumno, len(cursadas))
+	for _, cursada := range cursadas {
+		inscriptos[strings.TrimSpace(cursada.Alumno.Legajo)] = cursada.Alumno
+	}
+	return inscriptos, nil
+}
+
+// leerPlanillaNotas interpreta la planilla: la primera fila es el encabezado, con
+// una columna legajo y al menos una de nota, devolucion u observaciones
+func leerPlanillaNotas(nombre string, r io.Reader) ([]filaNotas, error) {
+	rows, err := spreadsheet.Read(nombre, r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPlanillaInvalida, err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: la planilla está vacía", ErrPlanillaInvalida)
+	}
+
+	columnas := map[string]int{}
+	for i, titulo := range rows[0] {
+		columnas[normalizarTitulo(titulo)] = i
+	}
+	colLegajo, ok := columnas["legajo"]
+	if !ok {
+		return nil, fmt.Errorf("%w: falta la columna legajo", ErrPlanillaInvalida)
+	}
+	colNota, hayNota := columnas["nota"]
+	colDevolucion, hayDevolucion := columnas["devolucion"]
+	colObservaciones, hayObservaciones := columnas["observaciones"]
+	if !hayNota && !hayDevolucion && !hayObservaciones {
+		return nil, fmt.Errorf("%w: se necesita al menos una columna nota, devolucion u observaciones", ErrPlanillaInvalida)
+	}
+
+	celda := func(row []string, col int, ok bool) string {
+		if !ok || col >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[col])
+	}
+
+	var filas []filaNotas
+	for i, row := range rows[1:] {
+		fila := filaNotas{numero: i + 2, legajo: celda(row, colLegajo, true)}
+		nota := celda(row, colNota, hayNota)
+		devolucion := celda(row, colDevolucion, hayDevolucion)
+		observaciones := celda(row, colObservaciones, hayObservaciones)
+		if fila.legajo == "" && nota == "" && devolucion == "" && observaciones == "" {
+			// Fila vacía (frecuente al final de las planillas)
+			continue
+		}
+
+		if nota != "" {
+			// Se acepta coma decimal ("7,5"); ParseFloat también acepta "NaN" e "Inf"
+			if value, err := strconv.ParseFloat(strings.Replace(nota, ",", ".", 1), 64); err == nil && !math.IsNaN(value) && !math.IsInf(value, 0) {
+				fila.nota = &value
+			} else {
+				fila.notaInvalida = nota
+			}
+		}
+		if devolucion != "" {
+			fila.devolucion = &devolucion
+		}
+		if observaciones != "" {
+			fila.observaciones = &observaciones
+		}
+		filas = append(filas, fila)
+	}
+	return filas, nil
+}
+
+// normalizarTitulo pasa el encabezado a minúscula y sin acentos ("Devolución" -> "devolucion")
+func normalizarTitulo(titulo string) string {
+	return strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u").
+		Replace(strings.ToLower(strings.TrimSpace(titulo)))
+}
+
+func equalStringPtr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
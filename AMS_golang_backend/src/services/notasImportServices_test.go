@@ -0,0 +1,132 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/LINSITrack/backend/src/models"
+)
+
+// resumenFila describe una filaNotas como texto para compararla en los tests
+func resumenFila(fila filaNotas) string {
+	texto := fmt.Sprintf("%d %s", fila.numero, fila.legajo)
+	if fila.nota != nil {
+		texto += fmt.Sprintf(" nota=%g", *fila.nota)
+	}
+	if fila.notaInvalida != "" {
+		texto += fmt.Sprintf(" invalida=%q", fila.notaInvalida)
+	}
+	if fila.devolucion != nil {
+		texto += fmt.Sprintf(" devolucion=%q", *fila.devolucion)
+	}
+	if fila.observaciones != nil {
+		texto += fmt.Sprintf(" observaciones=%q", *fila.observaciones)
+	}
+	return texto
+}
+
+func TestLeerPlanillaNotas(t *testing.T) {
+	tests := []struct {
+		name    string
+		nombre  string
+		data    string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "encabezados con acentos, coma decimal y filas vacías",
+			nombre: "notas.csv",
+			data:   "Legajo;Nota;Devolución\n 1234 ;7,5;Bien\n;;\n3456;;Falta la carátula\n",
+			want:   []string{`2 1234 nota=7.5 devolucion="Bien"`, `4 3456 devolucion="Falta la carátula"`},
+		},
+		{
+			name:   "notas que no son números finitos",
+			nombre: "notas.csv",
+			data:   "legajo,nota\n1,NaN\n2,inf\n3,-Infinity\n4,1e400\n5,siete\n6,0\n",
+			want: []string{
+				`2 1 invalida="NaN"`, `3 2 invalida="inf"`, `4 3 invalida="-Infinity"`,
+				`5 4 invalida="1e400"`, `6 5 invalida="siete"`, `7 6 nota=0`,
+			},
+		},
+		{
+			name:   "observaciones sin nota",
+			nombre: "notas.csv",
+			data:   "legajo,observaciones\n1234,Ausente con aviso\n",
+			want:   []string{`2 1234 observaciones="Ausente con aviso"`},
+		},
+		{name: "planilla vacía", nombre: "notas.csv", data: "", wantErr: true},
+		{name: "sin columna legajo", nombre: "notas.csv", data: "alumno,nota\n1234,7\n", wantErr: true},
+		{name: "solo legajo", nombre: "notas.csv", data: "legajo\n1234\n", wantErr: true},
+		{name: "formato no soportado", nombre: "notas.ods", data: "legajo,nota\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filas, err := leerPlanillaNotas(tt.nombre, strings.NewReader(tt.data))
+			if tt.wantErr {
+				if !errors.Is(err, ErrPlanillaInvalida) {
+					t.Fatalf("error = %v, want ErrPlanillaInvalida", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			got := make([]string, len(filas))
+			for i, fila := range filas {
+				got[i] = resumenFila(fila)
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("filas =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestValidarFilas(t *testing.T) {
+	inscriptos := map[string]models.Alumno{
+		"1234": {BaseUser: models.BaseUser{ID: 1, Nombre: "Ana", Apellido: "Pérez"}, Legajo: "1234"},
+		"5678": {BaseUser: models.BaseUser{ID: 2, Nombre: "Luis", Apellido: "Gómez"}, Legajo: "5678"},
+	}
+	ocho, once := 8.0, 11.0
+	filas := []filaNotas{
+		{numero: 2, legajo: "1234", nota: &ocho},
+		{numero: 3, legajo: "", nota: &ocho},
+		{numero: 4, legajo: "1234", nota: &ocho},
+		{numero: 5, legajo: "9999", nota: &ocho},
+		{numero: 6, legajo: "5678", notaInvalida: "NaN"},
+		// La fila 6 tenía error: esta no cuenta como repetida
+		{numero: 7, legajo: "5678", nota: &once},
+	}
+	wantErrores := map[int]string{
+		3: "legajo vacío",
+		4: "legajo repetido (fila 2)",
+		5: "el legajo no corresponde a un alumno inscripto en la comisión",
+		6: `nota inválida: "NaN"`,
+		7: "la nota debe estar entre 0 y 10",
+	}
+
+	var resultado models.NotasImportResultado
+	validas := validarFilas(filas, inscriptos, &resultado)
+
+	if len(validas) != 1 || validas[0].numero != 2 || validas[0].idx != 0 {
+		t.Fatalf("validas = %+v, want solo la fila 2 en el índice 0", validas)
+	}
+	if resultado.Errores != len(wantErrores) {
+		t.Errorf("Errores = %d, want %d", resultado.Errores, len(wantErrores))
+	}
+	if len(resultado.Filas) != len(filas) {
+		t.Fatalf("len(Filas) = %d, want %d", len(resultado.Filas), len(filas))
+	}
+	for _, fila := range resultado.Filas {
+		want, conError := wantErrores[fila.Fila]
+		if fila.Error != want || (fila.Estado == models.FilaImportError) != conError {
+			t.Errorf("fila %d: estado %q, error %q; want error %q", fila.Fila, fila.Estado, fila.Error, want)
+		}
+	}
+	if fila := resultado.Filas[0]; fila.AlumnoId != 1 || fila.Alumno != "Pérez, Ana" {
+		t.Errorf("fila 2: alumno %d %q, want 1 \"Pérez, Ana\"", fila.AlumnoId, fila.Alumno)
+	}
+}
@@ -0,0 +1,301 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/LINSITrack/backend/src/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPeriodoEnUso      = errors.New("el período tiene comisiones asignadas")
+	ErrRolloverDuplicado = errors.New("ya existe una comisión de la materia con ese nombre en el período destino")
+	ErrRolloverPeriodo   = errors.New("período destino inválido")
+)
+
+// PeriodoService administra los períodos académicos y la copia de comisiones
+// de un período al siguiente
+type PeriodoService struct {
+	db *gorm.DB
+}
+
+func NewPeriodoService(db *gorm.DB) *PeriodoService {
+	return &PeriodoService{db: db}
+}
+
+// filtroPeriodo limita una consulta a las comisiones del período; columna es la
+// que referencia a la comisión (por ejemplo "comision_id"). Sin período no filtra.
+func filtroPeriodo(periodoID *int, columna string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if periodoID == nil {
+			return db
+		}
+		return db.Where(columna+" IN (SELECT id FROM comisions WHERE periodo_id = ?)", *periodoID)
+	}
+}
+
+// GetPeriodos devuelve los períodos del más reciente al más antiguo
+func (s *PeriodoService) GetPeriodos() ([]models.PeriodoAcademico, error) {
+	periodos := []models.PeriodoAcademico{}
+	if err := s.db.Order("fecha_inicio DESC, id DESC").Find(&periodos).Error; err != nil {
+		return nil, err
+	}
+	return periodos, nil
+}
+
+func (s *PeriodoService) GetPeriodo(id int) (*models.PeriodoAcademico, error) {
+	var periodo models.PeriodoAcademico
+	if err := s.db.First(&periodo, id).Error; err != nil {
+		return nil, err
+	}
+	return &periodo, nil
+}
+
+func (s *PeriodoService) CrearPeriodo(req *models.PeriodoRequest) (*models.PeriodoAcademico, error) {
+	periodo, err := periodoDesdeRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validarPeriodoUnico(periodo); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(periodo).Error; err != nil {
+		return nil, err
+	}
+	return periodo, nil
+}
+
+// UpdatePeriodo reemplaza los datos del período
+func (s *PeriodoService) UpdatePeriodo(id int, req *models.PeriodoRequest) (*models.PeriodoAcademico, error) {
+	var existente models.PeriodoAcademico
+	if err := s.db.First(&existente, id).Error; err != nil {
+		return nil, err
+	}
+	periodo, err := periodoDesdeRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	periodo.ID = existente.ID
+	if err := s.validarPeriodoUnico(periodo); err != nil {
+		return nil, err
+	}
+	if err := s.db.Save(periodo).Error; err != nil {
+		return nil, err
+	}
+	return periodo, nil
+}
+
+func (s *PeriodoService) DeletePeriodo(id int) error {
+	var periodo models.PeriodoAcademico
+	if err := s.db.First(&periodo, id).Error; err != nil {
+		return err
+	}
+	var comisiones int64
+	if err := s.db.Model(&models.Comision{}).Where("periodo_id = ?", id).Count(&comisiones).Error; err != nil {
+		return err
+	}
+	if comisiones > 0 {
+		return ErrPeriodoEnUso
+	}
+	return s.db.Delete(&periodo).Error
+}
+
+func (s *PeriodoService) validarPeriodoUnico(periodo *models.PeriodoAcademico) error {
+	var count int64
+	if err := s.db.Model(&models.PeriodoAcademico{}).
+		Where("ano_lectivo = ? AND cuatrimestre = ? AND id <> ?", periodo.AnoLectivo, periodo.Cuatrimestre, periodo.ID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("ya existe un período para ese año lectivo y cuatrimestre")
+	}
+	return nil
+}
+
+// periodoDesdeRequest valida el pedido y completa el nombre si no viene
+func periodoDesdeRequest(req *models.PeriodoRequest) (*models.PeriodoAcademico, error) {
+	periodo := &models.PeriodoAcademico{Nombre: strings.TrimSpace(req.Nombre), AnoLectivo: req.AnoLectivo}
+	if req.Cuatrimestre != nil {
+		periodo.Cuatrimestre = *req.Cuatrimestre
+	}
+	if periodo.Cuatrimestre < 0 || periodo.Cuatrimestre > 2 {
+		return nil, errors.New("cuatrimestre inválido (0 = anual, 1 o 2)")
+	}
+	inicio, err := time.Parse("2006-01-02", req.FechaInicio)
+	if err != nil {
+		return nil, errors.New("fecha_inicio inválida (AAAA-MM-DD)")
+	}
+	fin, err := time.Parse("2006-01-02", req.FechaFin)
+	if err != nil {
+		return nil, errors.New("fecha_fin inválida (AAAA-MM-DD)")
+	}
+	if !fin.After(inicio) {
+		return nil, errors.New("fecha_fin debe ser posterior a fecha_inicio")
+	}
+	if inicio.Year() != periodo.AnoLectivo {
+		return nil, errors.New("el período debe empezar en su año lectivo")
+	}
+	periodo.FechaInicio = inicio
+	periodo.FechaFin = fin
+	if periodo.Nombre == "" {
+		if periodo.Cuatrimestre == 0 {
+			periodo.Nombre = fmt.Sprintf("Ciclo lectivo %d", periodo.AnoLectivo)
+		} else {
+			periodo.Nombre = fmt.Sprintf("%dº cuatrimestre %d", periodo.Cuatrimestre, periodo.AnoLectivo)
+		}
+	}
+	return periodo, nil
+}
+
+// Rollover crea en el período destino una comisión con la estructura de la
+// indicada: sus TPs (sin entregas ni notas), las competencias y los anexos de
+// cada TP y los profesores asignados. Sin PeriodoId se usa el período que sigue
+// al de la comisión. Los TPs se copian como no vigentes y con la fecha de
+// entrega al cierre del período: el profesor define las fechas al publicarlos.
+// Los horarios no se copian porque suelen cambiar de un período a otro.
+func (s *PeriodoService) Rollover(comisionID int, req *models.RolloverRequest) (*models.RolloverResultado, error) {
+	var origen models.Comision
+	if err := s.db.Preload("Periodo").First(&origen, comisionID).Error; err != nil {
+		return nil, err
+	}
+
+	var destino models.PeriodoAcademico
+	switch {
+	case req.PeriodoId != nil:
+		if err := s.db.First(&destino, *req.PeriodoId).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("%w: no existe", ErrRolloverPeriodo)
+			}
+			return nil, err
+		}
+	case origen.Periodo != nil:
+		err := s.db.Where("fecha_inicio > ?", origen.Periodo.FechaInicio).Order("fecha_inicio, id").First(&destino).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: no hay un período posterior a %s", ErrRolloverPeriodo, origen.Periodo.Nombre)
+		}
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: la comisión no tiene período, indicar periodo_id", ErrRolloverPeriodo)
+	}
+	if origen.Periodo != nil && !destino.FechaInicio.After(origen.Periodo.FechaInicio) {
+		return nil, fmt.Errorf("%w: debe empezar después de %s", ErrRolloverPeriodo, origen.Periodo.Nombre)
+	}
+
+	nombre := strings.TrimSpace(req.Nombre)
+	if nombre == "" {
+		nombre = origen.Nombre
+	}
+	resultado := &models.RolloverResultado{}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var duplicadas int64
+		if err := tx.Model(&models.Comision{}).
+			Where("materia_id = ? AND nombre = ? AND periodo_id = ?", origen.MateriaId, nombre, destino.ID).
+			Count(&duplicadas).Error; err != nil {
+			return err
+		}
+		if duplicadas > 0 {
+			return ErrRolloverDuplicado
+		}
+
+		comision := models.Comision{Nombre: nombre, MateriaId: origen.MateriaId, PeriodoId: &destino.ID}
+		if err := tx.Create(&comision).Error; err != nil {
+			return err
+		}
+
+		var asignaciones []models.ProfesorXComision
+		if err := tx.Where("comision_id = ?", origen.ID).Order("id").Find(&asignaciones).Error; err != nil {
+			return err
+		}
+		for _, asignacion := range asignaciones {
+			copia := models.ProfesorXComision{Cargo: asignacion.Cargo, ProfesorId: asignacion.ProfesorId, ComisionId: comision.ID}
+			if err := tx.Create(&copia).Error; err != nil {
+				return err
+			}
+			resultado.Profesores++
+		}
+
+		var tps []models.TpModel
+		if err := tx.Where("comision_id = ?", origen.ID).Order("fecha_entrega, id").Find(&tps).Error; err != nil {
+			return err
+		}
+		cierre := time.Date(destino.FechaFin.Year(), destino.FechaFin.Month(), destino.FechaFin.Day(), 23, 59, 0, 0, time.Local)
+		for _, tp := range tps {
+			copia := models.TpModel{
+				Consigna:              tp.Consigna,
+				FechaHoraEntrega:      cierre,
+				ComisionId:            comision.ID,
+				PoliticaTardia:        tp.PoliticaTardia,
+				PenalizacionPorDia:    tp.PenalizacionPorDia,
+				MaxIntentos:           tp.MaxIntentos,
+				TamanoMaxGrupo:        tp.TamanoMaxGrupo,
+				GruposPorAlumnos:      tp.GruposPorAlumnos,
+				MaxTamanoMB:           tp.MaxTamanoMB,
+				ExtensionesPermitidas: tp.ExtensionesPermitidas,
+			}
+			// Vigente tiene default:true, así que false se escribe aparte
+			if err := tx.Create(&copia).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&copia).Update("vigente", false).Error; err != nil {
+				return err
+			}
+			resultado.Tps++
+
+			if err := copiarEstructuraTp(tx, tp.ID, copia.ID, resultado); err != nil {
+				return err
+			}
+		}
+
+		resultado.Comision = comision
+		resultado.Comision.Periodo = &destino
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resultado, nil
+}
+
+// copiarEstructuraTp copia las competencias y los anexos (con sus archivos) de
+// un TP a otro. Los archivos comparten el contenido guardado en el Storage.
+func copiarEstructuraTp(tx *gorm.DB, origenID, destinoID int, resultado *models.RolloverResultado) error {
+	var competencias []models.Competencia
+	if err := tx.Where("tp_id = ?", origenID).Order("id").Find(&competencias).Error; err != nil {
+		return err
+	}
+	for _, competencia := range competencias {
+		copia := models.Competencia{Nombre: competencia.Nombre, Descripcion: competencia.Descripcion, TpId: destinoID}
+		if err := tx.Create(&copia).Error; err != nil {
+			return err
+		}
+		resultado.Competencias++
+	}
+
+	var anexos []models.Anexo
+	if err := tx.Preload("AnexoArchivo").Where("tp_id = ?", origenID).Order("id").Find(&anexos).Error; err != nil {
+		return err
+	}
+	for _, anexo := range anexos {
+		copia := models.Anexo{TpID: destinoID}
+		if err := tx.Omit("AnexoArchivo").Create(&copia).Error; err != nil {
+			return err
+		}
+		resultado.Anexos++
+		for _, archivo := range anexo.AnexoArchivo {
+			archivo.ID = 0
+			archivo.RecursoID = copia.ID
+			archivo.CreatedAt = time.Time{}
+			if err := tx.Create(&archivo).Error; err != nil {
+				return err
+			}
+			resultado.Archivos++
+		}
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/LINSITrack/backend/src/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTpNoVigente         = errors.New("el TP no está vigente")
+	ErrEntregaFueraDePlazo = errors.New("el plazo de entrega del TP venció")
+)
+
+// PlazoService calcula el plazo efectivo de cada alumno para un TP (fecha del
+// TP o prórroga) y aplica la política de entregas tardías del TP.
+type PlazoService struct {
+	db *gorm.DB
+}
+
+func NewPlazoService(db *gorm.DB) *PlazoService {
+	return &PlazoService{db: db}
+}
+
+// GetPlazo devuelve el plazo del alumno evaluado a la fecha dada
+func (s *PlazoService) GetPlazo(tp *models.TpModel, alumnoID int, fecha time.Time) (*models.PlazoEntrega, error) {
+	plazo := &models.PlazoEntrega{
+		TpId:        tp.ID,
+		AlumnoId:    alumnoID,
+		FechaLimite: tp.FechaHoraEntrega,
+		Politica:    tp.PoliticaTardia,
+	}
+	if plazo.Politica == "" {
+		plazo.Politica = models.PoliticaMarcarTardia
+	}
+
+	var prorroga models.ProrrogaTP
+	err := s.db.Where("tp_id = ? AND alumno_id = ?", tp.ID, alumnoID).First(&prorroga).Error
+	if err == nil {
+		plazo.FechaLimite = prorroga.NuevaFecha
+		plazo.ConProrroga = true
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if fecha.After(plazo.FechaLimite) {
+		plazo.Tardia = true
+		// Cada día (o fracción) de atraso cuenta como un día
+		plazo.DiasTardanza = int(math.Ceil(fecha.Sub(plazo.FechaLimite).Hours() / 24))
+		if plazo.Politica == models.PoliticaPenalizar {
+			plazo.Penalizacion = float64(plazo.DiasTardanza) * tp.PenalizacionPorDia
+		}
+	}
+	plazo.AceptaEntregas = tp.Vigente && !(plazo.Tardia && plazo.Politica == models.PoliticaRechazar)
+
+	return plazo, nil
+}
+
+// EvaluarEntrega valida que el alumno pueda entregar ahora y devuelve el plazo
+// aplicado. Devuelve ErrTpNoVigente o ErrEntregaFueraDePlazo si no puede.
+func (s *PlazoService) EvaluarEntrega(tp *models.TpModel, alumnoID int, fecha time.Time) (*models.PlazoEntrega, error) {
+	if !tp.Vigente {
+		return nil, ErrTpNoVigente
+	}
+
+	plazo, err := s.GetPlazo(tp, alumnoID, fecha)
+	if err != nil {
+		return nil, err
+	}
+	if !plazo.AceptaEntregas {
+		return nil, ErrEntregaFueraDePlazo
+	}
+	return plazo, nil
+}
+
+// AplicarPlazo copia el resultado del control de plazo en la entrega
+func AplicarPlazo(entrega *models.EntregaTP, plazo *models.PlazoEntrega) {
+	fechaLimite := plazo.FechaLimite
+	entrega.FechaLimite = &fechaLimite
+	entrega.Tardia = plazo.Tardia
+	entrega.DiasTardanza = plazo.DiasTardanza
+	entrega.Penalizacion = plazo.Penalizacion
+}
+
+// AplicarPenalizacion descuenta la penalización por atraso de la nota (mínimo 0)
+func AplicarPenalizacion(nota, penalizacion float64) float64 {
+	return math.Max(0, nota-penalizacion)
+}
+
+// AsignarNota pone la nota de la entrega descontando la penalización por
+// entrega tardía; la nota original queda en NotaSinPenalizacion
+func AsignarNota(entrega *models.EntregaTP, nota float64) {
+	entrega.NotaSinPenalizacion = nil
+	if entrega.Penalizacion > 0 {
+		original := nota
+		entrega.NotaSinPenalizacion = &original
+		nota = AplicarPenalizacion(nota, entrega.Penalizacion)
+	}
+	entrega.Nota = &nota
+}
+
+func (s *PlazoService) GetProrrogasByTp(tpID int) ([]models.ProrrogaTP, error) {
+	var prorrogas []models.ProrrogaTP
+	if err := s.db.Preload("Alumno").Where("tp_id = ?", tpID).Order("nueva_fecha").Find(&prorrogas).Error; err != nil {
+		return nil, err
+	}
+	return prorrogas, nil
+}
+
+// OtorgarProrroga crea o reemplaza la prórroga del alumno para el TP
+func (s *PlazoService) OtorgarProrroga(tpID int, req *models.ProrrogaCreateRequest, otorgadaPor int) (*models.ProrrogaTP, error) {
+	var tp models.TpModel
+	if err := s.db.First(&tp, tpID).Error; err != nil {
+		return nil, err
+	}
+	if !req.NuevaFecha.After(tp.FechaHoraEntrega) {
+		return nil, errors.New("la prórroga debe ser posterior a la fecha de entrega del TP")
+	}
+
+	// El alumno tiene que cursar en la comisión del TP
+	var count int64
+	if err := s.db.Model(&models.Cursada{}).
+		Where("alumno_id = ? AND comision_id = ?", req.AlumnoId, tp.ComisionId).
+		Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, errors.New("el alumno no cursa en la comisión del TP")
+	}
+
+	var prorroga models.ProrrogaTP
+	err := s.db.Where("tp_id = ? AND alumno_id = ?", tpID, req.AlumnoId).First(&prorroga).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	prorroga.TpId = tpID
+	prorroga.AlumnoId = req.AlumnoId
+	prorroga.NuevaFecha = req.NuevaFecha
+	prorroga.Motivo = req.Motivo
+	prorroga.OtorgadaPor = otorgadaPor
+	if err := s.db.Save(&prorroga).Error; err != nil {
+		return nil, err
+	}
+
+	notificacion := models.Notificacion{
+		Mensaje:   "Se te otorgó una prórroga para el TP \"" + truncateString(tp.Consigna, 50) + "\" hasta el " + req.NuevaFecha.Format("02/01/2006 15:04"),
+		FechaHora: time.Now(),
+		Leida:     false,
+		AlumnoID:  req.AlumnoId,
+	}
+	s.db.Create(&notificacion)
+
+	s.db.Preload("Alumno").First(&prorroga, prorroga.ID)
+	return &prorroga, nil
+}
+
+func (s *PlazoService) RevocarProrroga(tpID, alumnoID int) error {
+	result := s.db.Where("tp_id = ? AND alumno_id = ?", tpID, alumnoID).Delete(&models.ProrrogaTP{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
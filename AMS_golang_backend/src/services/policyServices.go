@@ -0,0 +1,238 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/LINSITrack/backend/src/models"
+	"gorm.io/gorm"
+)
+
+// PolicyService resuelve a qué comisión (o alumno) pertenece cada recurso y si
+// un profesor está asignado a esa comisión (vía ProfesorXComision).
+type PolicyService struct {
+	db *gorm.DB
+}
+
+func NewPolicyService(db *gorm.DB) *PolicyService {
+	return &PolicyService{db: db}
+}
+
+// ProfesorEnComision indica si el profesor está asignado a la comisión.
+// Si se pasan cargos, la asignación además debe tener alguno de ellos.
+func (s *PolicyService) ProfesorEnComision(profesorID, comisionID int, cargos ...models.ProfesorCargo) (bool, error) {
+	query := s.db.Model(&models.ProfesorXComision{}).Where("profesor_id = ? AND comision_id = ?", profesorID, comisionID)
+	if len(cargos) > 0 {
+		query = query.Where("cargo IN ?", cargos)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// AlumnoEnComision indica si el alumno tiene una cursada en la comisión
+func (s *PolicyService) AlumnoEnComision(alumnoID, comisionID int) (bool, error) {
+	var count int64
+	if err := s.db.Model(&models.Cursada{}).Where("alumno_id = ? AND comision_id = ?", alumnoID, comisionID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// ComisionIDForComision verifica que la comisión exista y devuelve su ID
+func (s *PolicyService) ComisionIDForComision(comisionID int) (int, error) {
+	var comision models.Comision
+	if err := s.db.Select("id").First(&comision, comisionID).Error; err != nil {
+		return 0, err
+	}
+	return comision.ID, nil
+}
+
+func (s *PolicyService) ComisionIDForTp(tpID int) (int, error) {
+	var tp models.TpModel
+	if err := s.db.Select("id", "comision_id").First(&tp, tpID).Error; err != nil {
+		return 0, err
+	}
+	return tp.ComisionId, nil
+}
+
+func (s *PolicyService) ComisionIDForEntregaTP(entregaID int) (int, error) {
+	var entrega models.EntregaTP
+	if err := s.db.Select("id", "tp_id").First(&entrega, entregaID).Error; err != nil {
+		return 0, err
+	}
+	return s.ComisionIDForTp(entrega.TpId)
+}
+
+func (s *PolicyService) ComisionIDForEntrega(entregaID int) (int, error) {
+	var entrega models.Entrega
+	if err := s.db.Select("id", "tp_id").First(&entrega, entregaID).Error; err != nil {
+		return 0, err
+	}
+	return s.ComisionIDForTp(entrega.TpID)
+}
+
+func (s *PolicyService) ComisionIDForEvaluacion(evaluacionID int) (int, error) {
+	var evaluacion models.EvaluacionModel
+	if err := s.db.Select("id", "comision_id").First(&evaluacion, evaluacionID).Error; err != nil {
+		return 0, err
+	}
+	return evaluacion.ComisionId, nil
+}
+
+func (s *PolicyService) ComisionIDForAnexo(anexoID int) (int, error) {
+	var anexo models.Anexo
+	if err := s.db.Select("id", "tp_id").First(&anexo, anexoID).Error; err != nil {
+		return 0, err
+	}
+	return s.ComisionIDForTp(anexo.TpID)
+}
+
+func (s *PolicyService) ComisionIDForCursada(cursadaID int) (int, error) {
+	var cursada models.Cursada
+	if err := s.db.Select("id", "comision_id").First(&cursada, cursadaID).Error; err != nil {
+		return 0, err
+	}
+	return cursada.ComisionID, nil
+}
+
+func (s *PolicyService) ComisionIDForCompetencia(competenciaID int) (int, error) {
+	var competencia models.Competencia
+	if err := s.db.Select("id", "tp_id").First(&competencia, competenciaID).Error; err != nil {
+		return 0, err
+	}
+	return s.ComisionIDForTp(competencia.TpId)
+}
+
+func (s *PolicyService) ComisionIDForClase(claseID int) (int, error) {
+	var clase models.Clase
+	if err := s.db.Select("id", "comision_id").First(&clase, claseID).Error; err != nil {
+		return 0, err
+	}
+	return clase.ComisionId, nil
+}
+
+func (s *PolicyService) AlumnoIDForCursada(cursadaID int) (int, error) {
+	var cursada models.Cursada
+	if err := s.db.Select("id", "alumno_id").First(&cursada, cursadaID).Error; err != nil {
+		return 0, err
+	}
+	return cursada.AlumnoID, nil
+}
+
+func (s *PolicyService) AlumnoIDForEntregaTP(entregaID int) (int, error) {
+	var entrega models.EntregaTP
+	if err := s.db.Select("id", "alumno_id").First(&entrega, entregaID).Error; err != nil {
+		return 0, err
+	}
+	return entrega.AlumnoId, nil
+}
+
+func (s *PolicyService) AlumnoIDForNotificacion(notificacionID int) (int, error) {
+	var notificacion models.Notificacion
+	if err := s.db.Select("id", "alumno_id").First(&notificacion, notificacionID).Error; err != nil {
+		return 0, err
+	}
+	return notificacion.AlumnoID, nil
+}
+
+// PuedeVerArchivo decide si un usuario puede descargar un archivo: quien lo
+// subió y los admins siempre; los profesores si el recurso es de una de sus
+// comisiones; los alumnos si el recurso es suyo (o de su grupo) o, en el caso
+// de los anexos, si cursan en la comisión del TP.
+func (s *PolicyService) PuedeVerArchivo(archivo *models.ArchivoMetadata, userID int, role models.Role) (bool, error) {
+	if role == models.RoleAdmin {
+		return true, nil
+	}
+	if archivo.SubidoPor == userID && archivo.SubidoPorRole == role {
+		return true, nil
+	}
+	if archivo.RecursoID == 0 {
+		// Todavía no está vinculado a ningún recurso: solo lo ve quien lo subió
+		return false, nil
+	}
+
+	var comisionID, alumnoID int
+	var grupoID *int
+	switch archivo.RecursoType {
+	case models.RecursoAnexo:
+		id, err := s.ComisionIDForAnexo(archivo.RecursoID)
+		if err != nil {
+			return false, ignoreNotFound(err)
+		}
+		comisionID = id
+		if role == models.RoleAlumno {
+			return s.AlumnoEnComision(userID, comisionID)
+		}
+	case models.RecursoEntrega:
+		var entrega models.Entrega
+		if err := s.db.Select("id", "alumno_id", "tp_id").First(&entrega, archivo.RecursoID).Error; err != nil {
+			return false, ignoreNotFound(err)
+		}
+		alumnoID = entrega.AlumnoID
+		if role == models.RoleProfesor {
+			id, err := s.ComisionIDForTp(entrega.TpID)
+			if err != nil {
+				return false, ignoreNotFound(err)
+			}
+			comisionID = id
+		}
+	case models.RecursoEntregaTP:
+		var entrega models.EntregaTP
+		if err := s.db.Select("id", "alumno_id", "tp_id", "grupo_id").First(&entrega, archivo.RecursoID).Error; err != nil {
+			return false, ignoreNotFound(err)
+		}
+		alumnoID = entrega.AlumnoId
+		grupoID = entrega.GrupoId
+		if role == models.RoleProfesor {
+			id, err := s.ComisionIDForTp(entrega.TpId)
+			if err != nil {
+				return false, ignoreNotFound(err)
+			}
+			comisionID = id
+		}
+	case models.RecursoEvaluacion:
+		var entrega models.EntregaEvaluacion
+		if err := s.db.Select("id", "alumno_id", "evaluacion_id").First(&entrega, archivo.RecursoID).Error; err != nil {
+			return false, ignoreNotFound(err)
+		}
+		alumnoID = entrega.AlumnoId
+		if role == models.RoleProfesor {
+			id, err := s.ComisionIDForEvaluacion(entrega.EvaluacionId)
+			if err != nil {
+				return false, ignoreNotFound(err)
+			}
+			comisionID = id
+		}
+	default:
+		return false, nil
+	}
+
+	switch role {
+	case models.RoleProfesor:
+		return s.ProfesorEnComision(userID, comisionID)
+	case models.RoleAlumno:
+		if alumnoID == userID {
+			return true, nil
+		}
+		if grupoID == nil {
+			return false, nil
+		}
+		var count int64
+		if err := s.db.Model(&models.IntegranteGrupoTP{}).Where("grupo_id = ? AND alumno_id = ?", *grupoID, userID).Count(&count).Error; err != nil {
+			return false, err
+		}
+		return count > 0, nil
+	}
+	return false, nil
+}
+
+// ignoreNotFound trata un recurso inexistente como "sin acceso"
+func ignoreNotFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	return err
+}
@@ -6,7 +6,6 @@ import (
 
 	"github.com/LINSITrack/backend/utils/validation"
 	"github.com/LINSITrack/backend/src/models"
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
@@ -32,29 +31,28 @@ func (s *ProfesorService) CreateProfesor(profesor *models.Profesor) (*models.Pro
 	if strings.TrimSpace(profesor.Email) == "" {
 		return nil, errors.New("el email es requerido")
 	}
-	if strings.TrimSpace(profesor.Password) == "" {
-		return nil, errors.New("la contraseña es requerida")
-	}
-	if len(profesor.Password) < 8 {
-		return nil, errors.New("la contraseña debe tener al menos 8 caracteres")
-	}
 
-	// Validar unicidad contra todas las tablas
-	if err := validation.ValidateEmailUniqueness(s.db, profesor.Email, "", ""); err != nil {
+	// El email es único por rol; entre roles identifica a la misma persona
+	if err := validation.ValidateEmailUniqueness(s.db, profesor.Email, "profesor", ""); err != nil {
 		return nil, err
 	}
 	if err := validation.ValidateLegajoUniqueness(s.db, profesor.Legajo, "", ""); err != nil {
 		return nil, err
 	}
 
-	// Hasheo de password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(profesor.Password), bcrypt.DefaultCost)
-	if err != nil {
-		return nil, err
-	}
-	profesor.Password = string(hashedPassword)
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Vincular con la identidad existente o crear una nueva
+		usuario, err := resolveUsuario(tx, profesor.Email, profesor.Password)
+		if err != nil {
+			return err
+		}
+		profesor.UsuarioID = &usuario.ID
+		profesor.Email = usuario.Email
+		profesor.Password = ""
 
-	if err := s.db.Create(profesor).Error; err != nil {
+		return tx.Create(profesor).Error
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -100,11 +98,17 @@ func (s *ProfesorService) UpdateProfesor(id string, updatedData *models.Profesor
 		if strings.TrimSpace(*updatedData.Email) == "" {
 			return nil, errors.New("el email no puede estar vacío")
 		}
-		// Validar unicidad de email contra todas las tablas
+		// Validar unicidad de email en el rol y contra otras identidades
 		if err := validation.ValidateEmailUniqueness(s.db, *updatedData.Email, "profesor", id); err != nil {
 			return nil, err
 		}
-		profesor.Email = *updatedData.Email
+		usuarioID := 0
+		if profesor.UsuarioID != nil {
+			usuarioID = *profesor.UsuarioID
+		}
+		if err := validation.ValidateUsuarioEmailUniqueness(s.db, *updatedData.Email, usuarioID); err != nil {
+			return nil, err
+		}
 	}
 
 	if updatedData.Password != nil {
@@ -114,14 +118,19 @@ func (s *ProfesorService) UpdateProfesor(id string, updatedData *models.Profesor
 		if len(*updatedData.Password) < 8 {
 			return nil, errors.New("la contraseña debe tener al menos 8 caracteres")
 		}
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(*updatedData.Password), bcrypt.DefaultCost)
-		if err != nil {
-			return nil, err
-		}
-		profesor.Password = string(hashedPassword)
 	}
 
-	if err := s.db.Save(&profesor).Error; err != nil {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Email y contraseña pertenecen al usuario (compartido entre roles)
+		if err := updateUsuarioCredentials(tx, profesor.UsuarioID, updatedData.Email, updatedData.Password); err != nil {
+			return err
+		}
+		if updatedData.Email != nil {
+			profesor.Email = normalizeEmail(*updatedData.Email)
+		}
+		return tx.Omit("password").Save(&profesor).Error
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -167,6 +176,18 @@ func (s *ProfesorService) GetProfesorByID(id int) (*models.ProfesorResponse, err
 }
 
 func (s *ProfesorService) DeleteProfesor(id string) error {
-	result := s.db.Delete(&models.Profesor{}, "id = ?", id)
-	return result.Error
+	var profesor models.Profesor
+	if err := s.db.Select("id", "usuario_id").First(&profesor, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.Profesor{}, "id = ?", id).Error; err != nil {
+			return err
+		}
+		return deleteUsuarioIfOrphan(tx, profesor.UsuarioID)
+	})
 }
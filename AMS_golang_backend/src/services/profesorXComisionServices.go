@@ -80,9 +80,10 @@ func (s *ProfesorXComisionService) CreateProfesorXComision(req *models.ProfesorX
 	return response, nil
 }
 
-func (s *ProfesorXComisionService) GetAllProfesorXComision() ([]models.ProfesorXComisionResponse, error) {
+// GetAllProfesorXComision devuelve las asignaciones, opcionalmente solo las de las comisiones de un período
+func (s *ProfesorXComisionService) GetAllProfesorXComision(periodoID *int) ([]models.ProfesorXComisionResponse, error) {
 	var relaciones []models.ProfesorXComision
-	result := s.db.Preload("Profesor").Preload("Comision.Materia").Find(&relaciones)
+	result := s.db.Preload("Profesor").Preload("Comision.Materia").Scopes(filtroPeriodo(periodoID, "comision_id")).Find(&relaciones)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -134,9 +135,9 @@ func (s *ProfesorXComisionService) GetProfesorXComisionByID(id int) (*models.Pro
 	return response, nil
 }
 
-func (s *ProfesorXComisionService) GetComisionesByProfesorID(profesorID int) ([]models.ProfesorXComisionResponse, error) {
+func (s *ProfesorXComisionService) GetComisionesByProfesorID(profesorID int, periodoID *int) ([]models.ProfesorXComisionResponse, error) {
 	var relaciones []models.ProfesorXComision
-	result := s.db.Preload("Profesor").Preload("Comision.Materia").Where("profesor_id = ?", profesorID).Find(&relaciones)
+	result := s.db.Preload("Profesor").Preload("Comision.Materia").Scopes(filtroPeriodo(periodoID, "comision_id")).Where("profesor_id = ?", profesorID).Find(&relaciones)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -1,10 +1,13 @@
 package services
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/LINSITrack/backend/src/models"
+	"github.com/LINSITrack/backend/utils/upload"
 	"gorm.io/gorm"
 )
 
@@ -16,9 +19,10 @@ func NewTpService(db *gorm.DB) *TpService {
 	return &TpService{db: db}
 }
 
-func (s *TpService) GetAllTps() ([]models.TpModel, error) {
+// GetAllTps devuelve los TPs, opcionalmente solo los de las comisiones de un período
+func (s *TpService) GetAllTps(periodoID *int) ([]models.TpModel, error) {
 	var tps []models.TpModel
-	result := s.db.Preload("Comision").Preload("Comision.Materia").Find(&tps)
+	result := s.db.Preload("Comision").Preload("Comision.Materia").Scopes(filtroPeriodo(periodoID, "comision_id")).Find(&tps)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -26,7 +30,7 @@ func (s *TpService) GetAllTps() ([]models.TpModel, error) {
 }
 
 // GetTpsByProfesorID returns only TPs for comisiones assigned to the given profesor
-func (s *TpService) GetTpsByProfesorID(profesorID int) ([]models.TpModel, error) {
+func (s *TpService) GetTpsByProfesorID(profesorID int, periodoID *int) ([]models.TpModel, error) {
 	// First get all comision IDs for this profesor
 	var profesorComisiones []models.ProfesorXComision
 	if err := s.db.Where("profesor_id = ?", profesorID).Find(&profesorComisiones).Error; err != nil {
@@ -46,7 +50,7 @@ func (s *TpService) GetTpsByProfesorID(profesorID int) ([]models.TpModel, error)
 
 	// Get TPs for these comisiones
 	var tps []models.TpModel
-	result := s.db.Preload("Comision").Preload("Comision.Materia").Where("comision_id IN ?", comisionIDs).Find(&tps)
+	result := s.db.Preload("Comision").Preload("Comision.Materia").Scopes(filtroPeriodo(periodoID, "comision_id")).Where("comision_id IN ?", comisionIDs).Find(&tps)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -54,7 +58,7 @@ func (s *TpService) GetTpsByProfesorID(profesorID int) ([]models.TpModel, error)
 }
 
 // GetTpsByAlumnoID returns TPs for all comisiones that the student is enrolled in
-func (s *TpService) GetTpsByAlumnoID(alumnoID int) ([]models.TpModel, error) {
+func (s *TpService) GetTpsByAlumnoID(alumnoID int, periodoID *int) ([]models.TpModel, error) {
 	// First get all comision IDs for this alumno
 	var cursadas []models.Cursada
 	if err := s.db.Where("alumno_id = ?", alumnoID).Find(&cursadas).Error; err != nil {
@@ -74,7 +78,7 @@ func (s *TpService) GetTpsByAlumnoID(alumnoID int) ([]models.TpModel, error) {
 
 	// Get TPs for these comisiones
 	var tps []models.TpModel
-	result := s.db.Preload("Comision").Preload("Comision.Materia").Where("comision_id IN ?", comisionIDs).Find(&tps)
+	result := s.db.Preload("Comision").Preload("Comision.Materia").Scopes(filtroPeriodo(periodoID, "comision_id")).Where("comision_id IN ?", comisionIDs).Find(&tps)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -91,6 +95,23 @@ func (s *TpService) GetTpByID(id int) (*models.TpModel, error) {
 }
 
 func (s *TpService) CreateTp(tp *models.TpModel) error {
+	if tp.PoliticaTardia != "" && !tp.PoliticaTardia.IsValid() {
+		return errors.New("política de entrega tardía inválida")
+	}
+	if tp.PenalizacionPorDia < 0 {
+		return errors.New("la penalización por día no puede ser negativa")
+	}
+	if tp.MaxIntentos < 0 {
+		return errors.New("la cantidad máxima de intentos no puede ser negativa")
+	}
+	if tp.TamanoMaxGrupo < 0 || tp.TamanoMaxGrupo == 1 {
+		return errors.New("el tamaño máximo de grupo debe ser 0 (TP individual) o mayor a 1")
+	}
+	if tp.MaxTamanoMB < 0 {
+		return errors.New("el tamaño máximo de archivo no puede ser negativo")
+	}
+	tp.ExtensionesPermitidas = strings.Join(upload.ParseExtensiones(tp.ExtensionesPermitidas), ",")
+
 	result := s.db.Create(tp)
 	if result.Error != nil {
 		return result.Error
@@ -154,6 +175,42 @@ func (s *TpService) UpdateTp(id int, updateRequest *models.TpUpdateRequest) (*mo
 	if updateRequest.ComisionId != nil {
 		tp.ComisionId = *updateRequest.ComisionId
 	}
+	if updateRequest.PoliticaTardia != nil {
+		if !updateRequest.PoliticaTardia.IsValid() {
+			return nil, errors.New("política de entrega tardía inválida")
+		}
+		tp.PoliticaTardia = *updateRequest.PoliticaTardia
+	}
+	if updateRequest.PenalizacionPorDia != nil {
+		if *updateRequest.PenalizacionPorDia < 0 {
+			return nil, errors.New("la penalización por día no puede ser negativa")
+		}
+		tp.PenalizacionPorDia = *updateRequest.PenalizacionPorDia
+	}
+	if updateRequest.MaxIntentos != nil {
+		if *updateRequest.MaxIntentos < 0 {
+			return nil, errors.New("la cantidad máxima de intentos no puede ser negativa")
+		}
+		tp.MaxIntentos = *updateRequest.MaxIntentos
+	}
+	if updateRequest.TamanoMaxGrupo != nil {
+		if *updateRequest.TamanoMaxGrupo < 0 || *updateRequest.TamanoMaxGrupo == 1 {
+			return nil, errors.New("el tamaño máximo de grupo debe ser 0 (TP individual) o mayor a 1")
+		}
+		tp.TamanoMaxGrupo = *updateRequest.TamanoMaxGrupo
+	}
+	if updateRequest.GruposPorAlumnos != nil {
+		tp.GruposPorAlumnos = *updateRequest.GruposPorAlumnos
+	}
+	if updateRequest.MaxTamanoMB != nil {
+		if *updateRequest.MaxTamanoMB < 0 {
+			return nil, errors.New("el tamaño máximo de archivo no puede ser negativo")
+		}
+		tp.MaxTamanoMB = *updateRequest.MaxTamanoMB
+	}
+	if updateRequest.ExtensionesPermitidas != nil {
+		tp.ExtensionesPermitidas = strings.Join(upload.ParseExtensiones(*updateRequest.ExtensionesPermitidas), ",")
+	}
 
 	saveResult := s.db.Save(&tp)
 	if saveResult.Error != nil {
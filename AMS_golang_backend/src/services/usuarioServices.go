@@ -0,0 +1,192 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/LINSITrack/backend/src/models"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// Helpers compartidos por los services de perfiles (Admin, Profesor, Alumno)
+// para mantener la identidad única en models.Usuario.
+
+func normalizeEmail(email string) string {
+	return strings.TrimSpace(strings.ToLower(email))
+}
+
+// resolveUsuario devuelve la identidad asociada al email. Si no existe, la crea
+// con la contraseña dada, o pendiente de activación si no se dio ninguna. Si ya
+// existe (la persona tiene otro rol) se conserva su contraseña actual.
+func resolveUsuario(tx *gorm.DB, email, password string) (*models.Usuario, error) {
+	var usuario models.Usuario
+	err := tx.Where("email = ?", normalizeEmail(email)).First(&usuario).Error
+	if err == nil {
+		return &usuario, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	// Sin contraseña la cuenta queda pendiente hasta que se active con la invitación
+	if strings.TrimSpace(password) == "" {
+		usuario = models.Usuario{Email: normalizeEmail(email), Pendiente: true}
+		if err := tx.Create(&usuario).Error; err != nil {
+			return nil, err
+		}
+		return &usuario, nil
+	}
+	if len(password) < 8 {
+		return nil, errors.New("la contraseña debe tener al menos 8 caracteres")
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	usuario = models.Usuario{
+		Email:    normalizeEmail(email),
+		Password: string(hashedPassword),
+	}
+	if err := tx.Create(&usuario).Error; err != nil {
+		return nil, err
+	}
+	return &usuario, nil
+}
+
+// updateUsuarioCredentials cambia el email y/o la contraseña de la identidad.
+// El email se replica en todos los perfiles de la persona.
+func updateUsuarioCredentials(tx *gorm.DB, usuarioID *int, email, password *string) error {
+	if email == nil && password == nil {
+		return nil
+	}
+	if usuarioID == nil {
+		return errors.New("el perfil no está vinculado a un usuario")
+	}
+
+	var usuario models.Usuario
+	if err := tx.First(&usuario, *usuarioID).Error; err != nil {
+		return err
+	}
+
+	if password != nil {
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(*password), bcrypt.DefaultCost)
+		if err != nil {
+			return err
+		}
+		usuario.Password = string(hashedPassword)
+	}
+
+	if email != nil {
+		usuario.Email = normalizeEmail(*email)
+		for _, model := range []interface{}{&models.Admin{}, &models.Profesor{}, &models.Alumno{}} {
+			if err := tx.Model(model).Where("usuario_id = ?", usuario.ID).Update("email", usuario.Email).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Save(&usuario).Error
+}
+
+// deleteUsuarioIfOrphan elimina la identidad cuando ya no le quedan perfiles
+func deleteUsuarioIfOrphan(tx *gorm.DB, usuarioID *int) error {
+	if usuarioID == nil {
+		return nil
+	}
+	roles, err := rolesForUsuario(tx, *usuarioID)
+	if err != nil {
+		return err
+	}
+	if len(roles) > 0 {
+		return nil
+	}
+	return tx.Delete(&models.Usuario{}, *usuarioID).Error
+}
+
+// rolesForUsuario lista los roles (perfiles) que tiene la identidad
+func rolesForUsuario(db *gorm.DB, usuarioID int) ([]models.Role, error) {
+	profiles := []struct {
+		model interface{}
+		role  models.Role
+	}{
+		{&models.Admin{}, models.RoleAdmin},
+		{&models.Profesor{}, models.RoleProfesor},
+		{&models.Alumno{}, models.RoleAlumno},
+	}
+
+	roles := []models.Role{}
+	for _, p := range profiles {
+		var count int64
+		if err := db.Model(p.model).Where("usuario_id = ?", usuarioID).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			roles = append(roles, p.role)
+		}
+	}
+	return roles, nil
+}
+
+// MigrateUsuarios vincula los perfiles existentes (creados antes de models.Usuario
+// o por los seeds) con una identidad única por email, moviendo el hash de la
+// contraseña del perfil al usuario. Es idempotente: solo toca perfiles sin usuario_id.
+func MigrateUsuarios(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var admins []models.Admin
+		if err := tx.Where("usuario_id IS NULL").Find(&admins).Error; err != nil {
+			return err
+		}
+		for i := range admins {
+			if err := linkLegacyProfile(tx, &admins[i], &admins[i].BaseUser); err != nil {
+				return err
+			}
+		}
+
+		var profesores []models.Profesor
+		if err := tx.Where("usuario_id IS NULL").Find(&profesores).Error; err != nil {
+			return err
+		}
+		for i := range profesores {
+			if err := linkLegacyProfile(tx, &profesores[i], &profesores[i].BaseUser); err != nil {
+				return err
+			}
+		}
+
+		var alumnos []models.Alumno
+		if err := tx.Where("usuario_id IS NULL").Find(&alumnos).Error; err != nil {
+			return err
+		}
+		for i := range alumnos {
+			if err := linkLegacyProfile(tx, &alumnos[i], &alumnos[i].BaseUser); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func linkLegacyProfile(tx *gorm.DB, profile interface{}, base *models.BaseUser) error {
+	email := normalizeEmail(base.Email)
+
+	var usuario models.Usuario
+	err := tx.Where("email = ?", email).First(&usuario).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		usuario = models.Usuario{Email: email, Password: base.Password}
+		if err := tx.Create(&usuario).Error; err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	} else if base.Password != "" && base.Password != usuario.Password {
+		log.Printf("Usuario '%s' tiene contraseñas distintas por rol; se conserva la primera", email)
+	}
+
+	return tx.Model(profile).Updates(map[string]interface{}{
+		"usuario_id": usuario.ID,
+		"email":      email,
+		"password":   "",
+	}).Error
+}
@@ -0,0 +1,114 @@
+package horario
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Franja es un bloque semanal de clase; Inicio y Fin se miden desde las 0:00
+type Franja struct {
+	Dia    time.Weekday
+	Inicio time.Duration
+	Fin    time.Duration
+}
+
+var dias = map[string]time.Weekday{
+	"lunes":     time.Monday,
+	"martes":    time.Tuesday,
+	"miercoles": time.Wednesday,
+	"jueves":    time.Thursday,
+	"viernes":   time.Friday,
+	"sabado":    time.Saturday,
+	"domingo":   time.Sunday,
+}
+
+var (
+	sinAcentos = strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u")
+	reDia      = regexp.MustCompile(`\b(lunes|martes|miercoles|jueves|viernes|sabado|domingo)\b`)
+	reRango    = regexp.MustCompile(`(\d{1,2})(?:[:.](\d{2}))?\s*(?:hs?)?\s*(?:-|a)\s*(\d{1,2})(?:[:.](\d{2}))?`)
+)
+
+// Parse interpreta el texto libre de Comision.Horarios. Cada parte separada por
+// coma o punto y coma tiene uno o más días y un rango horario, por ejemplo
+// "Lunes 8:00-12:00, Miércoles y Viernes 14-18hs".
+func Parse(texto string) ([]Franja, error) {
+	var franjas []Franja
+	for _, parte := range strings.FieldsFunc(sinAcentos.Replace(strings.ToLower(texto)), func(r rune) bool {
+		return r == ',' || r == ';'
+	}) {
+		parte = strings.TrimSpace(parte)
+		if parte == "" {
+			continue
+		}
+		nombres := reDia.FindAllString(parte, -1)
+		if len(nombres) == 0 {
+			return nil, fmt.Errorf("no se reconoce el día en %q", parte)
+		}
+		rango := reRango.FindStringSubmatch(parte)
+		if rango == nil {
+			return nil, fmt.Errorf("no se reconoce el horario en %q", parte)
+		}
+		inicio, err := hora(rango[1], rango[2])
+		if err != nil {
+			return nil, err
+		}
+		fin, err := hora(rango[3], rango[4])
+		if err != nil {
+			return nil, err
+		}
+		if fin <= inicio {
+			return nil, fmt.Errorf("el horario de %q termina antes de empezar", parte)
+		}
+		for _, nombre := range nombres {
+			franjas = append(franjas, Franja{Dia: dias[nombre], Inicio: inicio, Fin: fin})
+		}
+	}
+	if len(franjas) == 0 {
+		return nil, fmt.Errorf("el horario está vacío")
+	}
+	return franjas, nil
+}
+
+func hora(horas, minutos string) (time.Duration, error) {
+	h, _ := strconv.Atoi(horas)
+	m := 0
+	if minutos != "" {
+		m, _ = strconv.Atoi(minutos)
+	}
+	if h > 24 || m > 59 || (h == 24 && m > 0) {
+		return 0, fmt.Errorf("hora inválida: %s:%02d", horas, m)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
+
+var reHora = regexp.MustCompile(`^(\d{1,2}):(\d{2})$`)
+
+// ParseHora interpreta una hora "HH:MM" como la duración desde las 0:00
+func ParseHora(texto string) (time.Duration, error) {
+	partes := reHora.FindStringSubmatch(strings.TrimSpace(texto))
+	if partes == nil {
+		return 0, fmt.Errorf("hora inválida: %q (HH:MM)", texto)
+	}
+	return hora(partes[1], partes[2])
+}
+
+// FormatHora devuelve la hora como "HH:MM"
+func FormatHora(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d/time.Hour), int(d%time.Hour/time.Minute))
+}
+
+var nombresDia = [...]string{"Domingo", "Lunes", "Martes", "Miércoles", "Jueves", "Viernes", "Sábado"}
+
+// NombreDia devuelve el nombre del día en castellano
+func NombreDia(dia time.Weekday) string {
+	return nombresDia[dia]
+}
+
+// En devuelve el inicio y el fin de la franja en la fecha dada (que debe caer en su día)
+func (f Franja) En(fecha time.Time) (time.Time, time.Time) {
+	dia := time.Date(fecha.Year(), fecha.Month(), fecha.Day(), 0, 0, 0, 0, fecha.Location())
+	return dia.Add(f.Inicio), dia.Add(f.Fin)
+}
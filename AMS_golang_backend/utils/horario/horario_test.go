@@ -0,0 +1,106 @@
+package horario
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	h := func(horas, minutos int) time.Duration {
+		return time.Duration(horas)*time.Hour + time.Duration(minutos)*time.Minute
+	}
+
+	tests := []struct {
+		name    string
+		texto   string
+		want    []Franja
+		wantErr bool
+	}{
+		{
+			name:  "un día",
+			texto: "Lunes 8:00-12:00",
+			want:  []Franja{{Dia: time.Monday, Inicio: h(8, 0), Fin: h(12, 0)}},
+		},
+		{
+			name:  "varios días con el mismo rango y acentos",
+			texto: "Miércoles y Viernes 14-18hs",
+			want: []Franja{
+				{Dia: time.Wednesday, Inicio: h(14, 0), Fin: h(18, 0)},
+				{Dia: time.Friday, Inicio: h(14, 0), Fin: h(18, 0)},
+			},
+		},
+		{
+			name:  "partes separadas por punto y coma, con \"a\" y punto en los minutos",
+			texto: "lunes 8.30 a 10.15; JUEVES 18:00 - 22:00",
+			want: []Franja{
+				{Dia: time.Monday, Inicio: h(8, 30), Fin: h(10, 15)},
+				{Dia: time.Thursday, Inicio: h(18, 0), Fin: h(22, 0)},
+			},
+		},
+		{
+			name:  "sufijo hs en ambas horas",
+			texto: "Sábado 9hs-13hs",
+			want:  []Franja{{Dia: time.Saturday, Inicio: h(9, 0), Fin: h(13, 0)}},
+		},
+		{
+			name:  "hasta la medianoche",
+			texto: "Domingo 20-24",
+			want:  []Franja{{Dia: time.Sunday, Inicio: h(20, 0), Fin: h(24, 0)}},
+		},
+		{
+			name:  "ignora partes vacías",
+			texto: "Martes 10-12, , ",
+			want:  []Franja{{Dia: time.Tuesday, Inicio: h(10, 0), Fin: h(12, 0)}},
+		},
+		{name: "vacío", texto: "", wantErr: true},
+		{name: "solo separadores", texto: " , ; ", wantErr: true},
+		{name: "sin día", texto: "8-12", wantErr: true},
+		{name: "sin horario", texto: "Lunes por la mañana", wantErr: true},
+		{name: "termina antes de empezar", texto: "Lunes 12-8", wantErr: true},
+		{name: "mismo inicio y fin", texto: "Lunes 10-10", wantErr: true},
+		{name: "hora inválida", texto: "Lunes 8-25", wantErr: true},
+		{name: "después de la medianoche", texto: "Lunes 20:00-24:30", wantErr: true},
+		{name: "una parte inválida invalida todo", texto: "Lunes 8-12, Martes", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.texto)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.texto, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) = %v, want %v", tt.texto, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseHora(t *testing.T) {
+	tests := []struct {
+		texto   string
+		want    string
+		wantErr bool
+	}{
+		{texto: "08:30", want: "08:30"},
+		{texto: "8:05", want: "08:05"},
+		{texto: " 24:00 ", want: "24:00"},
+		{texto: "24:01", wantErr: true},
+		{texto: "12:60", wantErr: true},
+		{texto: "8", wantErr: true},
+		{texto: "8.30", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.texto, func(t *testing.T) {
+			got, err := ParseHora(tt.texto)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseHora(%q) error = %v, wantErr %v", tt.texto, err, tt.wantErr)
+			}
+			if err == nil && FormatHora(got) != tt.want {
+				t.Errorf("FormatHora(ParseHora(%q)) = %q, want %q", tt.texto, FormatHora(got), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+// Package ical genera calendarios iCalendar (RFC 5545) a los que se puede
+// suscribir cualquier aplicación de calendario.
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Evento es un VEVENT del calendario
+type Evento struct {
+	// Identificador estable: el cliente reemplaza el evento con el mismo UID
+	UID         string
+	Resumen     string
+	Descripcion string
+	Lugar       string
+	Inicio      time.Time
+	// Sin Fin el evento es un instante (por ejemplo, una fecha límite)
+	Fin time.Time
+	// Evento de día completo: solo se usan las fechas de Inicio y Fin (inclusive)
+	DiaCompleto bool
+	// Repetición semanal desde Inicio hasta la fecha Hasta (nil = sin fin)
+	Semanal bool
+	Hasta   *time.Time
+}
+
+// Calendario es un VCALENDAR con sus eventos
+type Calendario struct {
+	Nombre string
+	// Cada cuánto el cliente debería volver a descargar el calendario
+	Refresco time.Duration
+	Eventos  []Evento
+}
+
+const (
+	formatoFecha     = "20060102"
+	formatoFechaHora = "20060102T150405Z"
+)
+
+// Write escribe el calendario; ahora se usa como DTSTAMP de los eventos
+func Write(w io.Writer, c Calendario, ahora time.Time) error {
+	out := bufio.NewWriter(w)
+	linea := func(nombre, valor string) {
+		out.WriteString(plegar(nombre + ":" + valor))
+	}
+
+	linea("BEGIN", "VCALENDAR")
+	linea("VERSION", "2.0")
+	linea("PRODID", "-//LINSITrack//Calendario//ES")
+	linea("CALSCALE", "GREGORIAN")
+	linea("METHOD", "PUBLISH")
+	if c.Nombre != "" {
+		linea("X-WR-CALNAME", escapar(c.Nombre))
+	}
+	if c.Refresco > 0 {
+		linea("REFRESH-INTERVAL;VALUE=DURATION", duracion(c.Refresco))
+		linea("X-PUBLISHED-TTL", duracion(c.Refresco))
+	}
+
+	stamp := ahora.UTC().Format(formatoFechaHora)
+	for _, e := range c.Eventos {
+		linea("BEGIN", "VEVENT")
+		linea("UID", e.UID)
+		linea("DTSTAMP", stamp)
+		if e.DiaCompleto {
+			fin := e.Fin
+			if fin.IsZero() {
+				fin = e.Inicio
+			}
+			linea("DTSTART;VALUE=DATE", e.Inicio.Format(formatoFecha))
+			// DTEND de un evento de día completo es exclusivo
+			linea("DTEND;VALUE=DATE", fin.AddDate(0, 0, 1).Format(formatoFecha))
+		} else {
+			linea("DTSTART", e.Inicio.UTC().Format(formatoFechaHora))
+			if !e.Fin.IsZero() {
+				linea("DTEND", e.Fin.UTC().Format(formatoFechaHora))
+			}
+		}
+		if e.Semanal {
+			regla := "FREQ=WEEKLY"
+			if e.Hasta != nil {
+				hasta := time.Date(e.Hasta.Year(), e.Hasta.Month(), e.Hasta.Day(), 23, 59, 59, 0, e.Inicio.Location())
+				regla += ";UNTIL=" + hasta.UTC().Format(formatoFechaHora)
+			}
+			linea("RRULE", regla)
+		}
+		linea("SUMMARY", escapar(e.Resumen))
+		if e.Descripcion != "" {
+			linea("DESCRIPTION", escapar(e.Descripcion))
+		}
+		if e.Lugar != "" {
+			linea("LOCATION", escapar(e.Lugar))
+		}
+		linea("END", "VEVENT")
+	}
+	linea("END", "VCALENDAR")
+	return out.Flush()
+}
+
+// duracion devuelve d en horas y minutos, por ejemplo "PT1H30M"
+func duracion(d time.Duration) string {
+	texto := "PT"
+	if h := int(d / time.Hour); h > 0 {
+		texto += strconv.Itoa(h) + "H"
+	}
+	if m := int(d % time.Hour / time.Minute); m > 0 || d < time.Hour {
+		texto += strconv.Itoa(m) + "M"
+	}
+	return texto
+}
+
+var escape = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+func escapar(texto string) string {
+	return escape.Replace(texto)
+}
+
+// plegar corta la línea en tramos de hasta 75 octetos sin partir caracteres
+// UTF-8; las continuaciones empiezan con un espacio
+func plegar(linea string) string {
+	var b strings.Builder
+	largo := 0
+	for _, r := range linea {
+		n := len(string(r))
+		if largo+n > 75 {
+			b.WriteString("\r\n ")
+			largo = 1
+		}
+		b.WriteRune(r)
+		largo += n
+	}
+	b.WriteString("\r\n")
+	return b.String()
+}
@@ -0,0 +1,183 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWrite(t *testing.T) {
+	ahora := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	art := time.FixedZone("ART", -3*60*60)
+	hasta := time.Date(2026, 7, 3, 0, 0, 0, 0, art)
+
+	tests := []struct {
+		name       string
+		calendario Calendario
+		want       []string
+		notWant    []string
+	}{
+		{
+			name:       "encabezado del calendario",
+			calendario: Calendario{Nombre: "Agenda, 2026", Refresco: 90 * time.Minute},
+			want: []string{
+				"BEGIN:VCALENDAR", "VERSION:2.0", "METHOD:PUBLISH",
+				`X-WR-CALNAME:Agenda\, 2026`,
+				"REFRESH-INTERVAL;VALUE=DURATION:PT1H30M",
+				"X-PUBLISHED-TTL:PT1H30M",
+				"END:VCALENDAR",
+			},
+			notWant: []string{"BEGIN:VEVENT"},
+		},
+		{
+			name:       "refresco de menos de una hora",
+			calendario: Calendario{Refresco: 30 * time.Minute},
+			want:       []string{"REFRESH-INTERVAL;VALUE=DURATION:PT30M"},
+			notWant:    []string{"X-WR-CALNAME"},
+		},
+		{
+			name:       "refresco en horas justas",
+			calendario: Calendario{Refresco: 2 * time.Hour},
+			want:       []string{"REFRESH-INTERVAL;VALUE=DURATION:PT2H"},
+		},
+		{
+			name: "evento con horario en UTC",
+			calendario: Calendario{Eventos: []Evento{{
+				UID:     "clase-1@linsitrack",
+				Resumen: "Clase",
+				Lugar:   "Aula 3",
+				Inicio:  time.Date(2026, 3, 9, 8, 0, 0, 0, art),
+				Fin:     time.Date(2026, 3, 9, 12, 0, 0, 0, art),
+			}}},
+			want: []string{
+				"BEGIN:VEVENT", "UID:clase-1@linsitrack", "DTSTAMP:20260302T120000Z",
+				"DTSTART:20260309T110000Z", "DTEND:20260309T150000Z",
+				"SUMMARY:Clase", "LOCATION:Aula 3", "END:VEVENT",
+			},
+			notWant: []string{"RRULE", "DESCRIPTION"},
+		},
+		{
+			name: "fecha límite sin fin",
+			calendario: Calendario{Eventos: []Evento{{
+				UID:     "tp-1@linsitrack",
+				Resumen: "Entrega TP 1",
+				Inicio:  time.Date(2026, 4, 10, 23, 59, 0, 0, art),
+			}}},
+			want:    []string{"DTSTART:20260411T025900Z"},
+			notWant: []string{"DTEND"},
+		},
+		{
+			name: "día completo de un solo día",
+			calendario: Calendario{Eventos: []Evento{{
+				UID:         "feriado@linsitrack",
+				Resumen:     "Feriado",
+				Inicio:      time.Date(2026, 4, 10, 0, 0, 0, 0, art),
+				DiaCompleto: true,
+			}}},
+			// DTEND es exclusivo
+			want: []string{"DTSTART;VALUE=DATE:20260410", "DTEND;VALUE=DATE:20260411"},
+		},
+		{
+			name: "día completo de varios días",
+			calendario: Calendario{Eventos: []Evento{{
+				UID:         "mesas@linsitrack",
+				Resumen:     "Mesas de examen",
+				Inicio:      time.Date(2026, 7, 27, 0, 0, 0, 0, art),
+				Fin:         time.Date(2026, 7, 31, 0, 0, 0, 0, art),
+				DiaCompleto: true,
+			}}},
+			want: []string{"DTSTART;VALUE=DATE:20260727", "DTEND;VALUE=DATE:20260801"},
+		},
+		{
+			name: "semanal hasta una fecha (inclusive, en la zona del inicio)",
+			calendario: Calendario{Eventos: []Evento{{
+				UID:     "horario-1@linsitrack",
+				Resumen: "Clase",
+				Inicio:  time.Date(2026, 3, 9, 8, 0, 0, 0, art),
+				Fin:     time.Date(2026, 3, 9, 12, 0, 0, 0, art),
+				Semanal: true,
+				Hasta:   &hasta,
+			}}},
+			want: []string{"RRULE:FREQ=WEEKLY;UNTIL=20260704T025959Z"},
+		},
+		{
+			name: "semanal sin fin",
+			calendario: Calendario{Eventos: []Evento{{
+				UID:     "horario-2@linsitrack",
+				Resumen: "Clase",
+				Inicio:  time.Date(2026, 3, 9, 8, 0, 0, 0, art),
+				Semanal: true,
+			}}},
+			want: []string{"RRULE:FREQ=WEEKLY"},
+		},
+		{
+			name: "escapa el texto",
+			calendario: Calendario{Eventos: []Evento{{
+				UID:         "tp-2@linsitrack",
+				Resumen:     "TP 2: listas, pilas; colas",
+				Descripcion: "Consigna\nen dos líneas \\ fin",
+				Inicio:      time.Date(2026, 4, 10, 23, 59, 0, 0, art),
+			}}},
+			want: []string{
+				`SUMMARY:TP 2: listas\, pilas\; colas`,
+				`DESCRIPTION:Consigna\nen dos líneas \\ fin`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := Write(&b, tt.calendario, ahora); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			salida := b.String()
+			if !strings.HasSuffix(salida, "\r\n") {
+				t.Errorf("la salida no termina en CRLF")
+			}
+			lineas := strings.Split(strings.TrimSuffix(salida, "\r\n"), "\r\n")
+			for _, want := range tt.want {
+				if !contieneLinea(lineas, want) {
+					t.Errorf("falta la línea %q en:\n%s", want, salida)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				for _, linea := range lineas {
+					if strings.HasPrefix(linea, notWant) {
+						t.Errorf("no se esperaba la línea %q", linea)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestWritePliegaLineasLargas(t *testing.T) {
+	// 60 "ñ" ocupan 120 octetos
+	resumen := strings.Repeat("ñ", 60)
+	var b strings.Builder
+	err := Write(&b, Calendario{Eventos: []Evento{{UID: "largo@linsitrack", Resumen: resumen, Inicio: time.Date(2026, 3, 9, 8, 0, 0, 0, time.UTC)}}}, time.Now())
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	salida := b.String()
+	for _, linea := range strings.Split(strings.TrimSuffix(salida, "\r\n"), "\r\n") {
+		if len(linea) > 75 {
+			t.Errorf("línea de %d octetos: %q", len(linea), linea)
+		}
+	}
+	desplegada := strings.ReplaceAll(salida, "\r\n ", "")
+	if !strings.Contains(desplegada, "\r\nSUMMARY:"+resumen+"\r\n") {
+		t.Errorf("la línea SUMMARY no se recupera al desplegar:\n%s", salida)
+	}
+}
+
+func contieneLinea(lineas []string, want string) bool {
+	for _, linea := range lineas {
+		if linea == want {
+			return true
+		}
+	}
+	return false
+}
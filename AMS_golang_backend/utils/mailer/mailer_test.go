@@ -0,0 +1,36 @@
+package mailer
+
+import (
+	"mime"
+	"strings"
+	"testing"
+)
+
+func TestBuildMessageSubject(t *testing.T) {
+	tests := []struct {
+		subject string
+		want    string
+	}{
+		{subject: "Bienvenido", want: "Subject: Bienvenido\r\n"},
+		{subject: "Restablecer contraseña", want: "Subject: =?utf-8?q?Restablecer_contrase=C3=B1a?=\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.subject, func(t *testing.T) {
+			mensaje := string(buildMessage("no-reply@linsi.com", "alumno@linsi.com", tt.subject, "Hola"))
+			encabezados, _, _ := strings.Cut(mensaje, "\r\n\r\n")
+			for _, r := range encabezados {
+				if r > 127 {
+					t.Fatalf("encabezado con caracteres no ASCII:\n%s", encabezados)
+				}
+			}
+			if !strings.Contains(mensaje, tt.want) {
+				t.Errorf("falta %q en:\n%s", tt.want, encabezados)
+			}
+			decodificado, err := new(mime.WordDecoder).DecodeHeader(strings.TrimSuffix(strings.TrimPrefix(tt.want, "Subject: "), "\r\n"))
+			if err != nil || decodificado != tt.subject {
+				t.Errorf("DecodeHeader = %q, %v; want %q", decodificado, err, tt.subject)
+			}
+		})
+	}
+}
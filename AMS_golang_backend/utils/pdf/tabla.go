@@ -0,0 +1,323 @@
+// Package pdf genera documentos PDF simples (una tabla paginada) sin
+// dependencias externas, usando las fuentes estándar Helvetica.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Página A4 apaisada, en puntos
+const (
+	pageWidth  = 842.0
+	pageHeight = 595.0
+	margin     = 36.0
+	cellPad    = 3.0
+	maxFont    = 9.0
+	minFont    = 6.0
+)
+
+// Tabla describe un documento con un título, líneas de encabezado y una tabla
+// que se reparte en tantas páginas como haga falta repitiendo la fila de títulos.
+type Tabla struct {
+	Titulo      string
+	Subtitulos  []string
+	Encabezados []string
+	Filas       [][]string
+	// Columnas alineadas a la derecha (números); puede ser más corto que Encabezados
+	Derecha []bool
+	// Etiquetas de las líneas de firma que se agregan al final del documento
+	Firmas []string
+}
+
+// WriteTabla escribe la tabla como PDF
+func WriteTabla(w io.Writer, t Tabla) error {
+	if len(t.Encabezados) == 0 {
+		return fmt.Errorf("la tabla no tiene columnas")
+	}
+	l := newLayout(t)
+
+	var pages []*bytes.Buffer
+	row := 0
+	for {
+		page := &bytes.Buffer{}
+		y := pageHeight - margin
+		if len(pages) == 0 {
+			y = l.drawTitulo(page, y)
+		}
+		y = l.drawRow(page, y, t.Encabezados, true)
+		for row < len(t.Filas) && y-l.rowHeight >= margin+l.footerHeight {
+			y = l.drawRow(page, y, t.Filas[row], false)
+			row++
+		}
+		pages = append(pages, page)
+		if row < len(t.Filas) {
+			continue
+		}
+		if len(t.Firmas) > 0 {
+			const firmasHeight = 70.0
+			if y-firmasHeight < margin+l.footerHeight {
+				page = &bytes.Buffer{}
+				pages = append(pages, page)
+				y = pageHeight - margin
+			}
+			l.drawFirmas(page, y-firmasHeight+10)
+		}
+		break
+	}
+
+	for i, page := range pages {
+		label := fmt.Sprintf("Página %d de %d", i+1, len(pages))
+		text(page, "F1", 7, pageWidth-margin-textWidth(label, 7, false), margin-12, label)
+	}
+	return writeDocument(w, t.Titulo, pages)
+}
+
+type layout struct {
+	tabla        Tabla
+	fontSize     float64
+	rowHeight    float64
+	footerHeight float64
+	widths       []float64
+}
+
+// newLayout calcula el tamaño de letra y el ancho de cada columna para que la
+// tabla ocupe el ancho de la página. Si no entra ni con la letra mínima, los
+// textos se recortan.
+func newLayout(t Tabla) *layout {
+	available := pageWidth - 2*margin
+	natural := func(size float64) ([]float64, float64) {
+		widths := make([]float64, len(t.Encabezados))
+		for i, h := range t.Encabezados {
+			widths[i] = textWidth(h, size, true) + 2*cellPad
+		}
+		for _, fila := range t.Filas {
+			for i := 0; i < len(fila) && i < len(widths); i++ {
+				if w := textWidth(fila[i], size, false) + 2*cellPad; w > widths[i] {
+					widths[i] = w
+				}
+			}
+		}
+		total := 0.0
+		for _, w := range widths {
+			total += w
+		}
+		return widths, total
+	}
+
+	size := maxFont
+	widths, total := natural(size)
+	if total > available {
+		size = maxFont * available / total
+		if size < minFont {
+			size = minFont
+		}
+		widths, total = natural(size)
+	}
+	for i := range widths {
+		widths[i] *= available / total
+	}
+	return &layout{tabla: t, fontSize: size, rowHeight: size * 1.9, footerHeight: 10, widths: widths}
+}
+
+func (l *layout) drawTitulo(page *bytes.Buffer, y float64) float64 {
+	y -= 14
+	text(page, "F2", 14, margin, y, fit(l.tabla.Titulo, pageWidth-2*margin, 14, true))
+	y -= 6
+	for _, s := range l.tabla.Subtitulos {
+		y -= 12
+		text(page, "F1", 9, margin, y, fit(s, pageWidth-2*margin, 9, false))
+	}
+	return y - 12
+}
+
+func (l *layout) drawRow(page *bytes.Buffer, y float64, cells []string, header bool) float64 {
+	y -= l.rowHeight
+	font := "F1"
+	if header {
+		font = "F2"
+		fmt.Fprintf(page, "0.9 g %.2f %.2f %.2f %.2f re f 0 g\n", margin, y, pageWidth-2*margin, l.rowHeight)
+	}
+	x := margin
+	for i, width := range l.widths {
+		fmt.Fprintf(page, "0.5 w 0.6 G %.2f %.2f %.2f %.2f re S\n", x, y, width, l.rowHeight)
+		if i < len(cells) {
+			value := fit(cells[i], width-2*cellPad, l.fontSize, header)
+			tx := x + cellPad
+			if !header && i < len(l.tabla.Derecha) && l.tabla.Derecha[i] {
+				tx = x + width - cellPad - textWidth(value, l.fontSize, false)
+			}
+			text(page, font, l.fontSize, tx, y+(l.rowHeight-l.fontSize)/2+1, value)
+		}
+		x += width
+	}
+	return y
+}
+
+// drawFirmas dibuja las líneas de firma repartidas en el ancho de la página
+func (l *layout) drawFirmas(page *bytes.Buffer, y float64) {
+	n := float64(len(l.tabla.Firmas))
+	slot := (pageWidth - 2*margin) / n
+	for i, label := range l.tabla.Firmas {
+		x := margin + slot*float64(i) + slot*0.1
+		lineWidth := slot * 0.8
+		fmt.Fprintf(page, "0.5 w 0 G %.2f %.2f m %.2f %.2f l S\n", x, y+12, x+lineWidth, y+12)
+		text(page, "F1", 8, x+(lineWidth-textWidth(label, 8, false))/2, y, label)
+	}
+}
+
+// fit recorta el texto con "..." para que entre en el ancho dado
+func fit(s string, width, size float64, bold bool) string {
+	if textWidth(s, size, bold) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		candidate := strings.TrimRight(string(runes), " ") + "..."
+		if textWidth(candidate, size, bold) <= width {
+			return candidate
+		}
+	}
+	return ""
+}
+
+func text(page *bytes.Buffer, font string, size, x, y float64, s string) {
+	fmt.Fprintf(page, "BT /%s %.2f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, escapeString(s))
+}
+
+// writeDocument arma el archivo: catálogo, árbol de páginas, las dos fuentes
+// estándar y, por cada página, su objeto y su contenido comprimido
+func writeDocument(w io.Writer, titulo string, pages []*bytes.Buffer) error {
+	var out bytes.Buffer
+	var offsets []int
+	startObj := func() int {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n", len(offsets))
+		return len(offsets)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	startObj() // 1: catálogo
+	out.WriteString("<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+
+	// Páginas: objetos 6, 8, 10, ... con su contenido en el siguiente
+	startObj() // 2: árbol de páginas
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 6+2*i)
+	}
+	fmt.Fprintf(&out, "<< /Type /Pages /Kids [%s] /Count %d >>\nendobj\n", strings.Join(kids, " "), len(pages))
+
+	startObj() // 3
+	out.WriteString("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>\nendobj\n")
+	startObj() // 4
+	out.WriteString("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>\nendobj\n")
+	startObj() // 5
+	fmt.Fprintf(&out, "<< /Title (%s) >>\nendobj\n", escapeString(titulo))
+
+	for _, page := range pages {
+		var content bytes.Buffer
+		zw := zlib.NewWriter(&content)
+		if _, err := zw.Write(page.Bytes()); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+
+		pageObj := startObj()
+		fmt.Fprintf(&out, "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>\nendobj\n",
+			pageWidth, pageHeight, pageObj+1)
+		startObj()
+		fmt.Fprintf(&out, "<< /Length %d /Filter /FlateDecode >>\nstream\n", content.Len())
+		out.Write(content.Bytes())
+		out.WriteString("\nendstream\nendobj\n")
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// escapeString convierte el texto a WinAnsiEncoding y escapa los caracteres
+// especiales de un string literal de PDF. Lo que no se puede representar queda como "?".
+func escapeString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		c, ok := winAnsi(r)
+		if !ok {
+			c = '?'
+		}
+		switch c {
+		case '(', ')', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\n', '\r', '\t':
+			b.WriteByte(' ')
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+var winAnsiExtra = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '‘': 0x91, '’': 0x92,
+	'“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+}
+
+func winAnsi(r rune) (byte, bool) {
+	if r < 0x80 || (r >= 0xa0 && r <= 0xff) {
+		return byte(r), true
+	}
+	c, ok := winAnsiExtra[r]
+	return c, ok
+}
+
+// Anchos de Helvetica (en milésimas del tamaño de letra) para los caracteres ASCII imprimibles
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// Letras acentuadas: se miden como la letra sin acento
+var baseLetter = map[rune]rune{
+	'á': 'a', 'é': 'e', 'í': 'i', 'ó': 'o', 'ú': 'u', 'ü': 'u', 'ñ': 'n',
+	'Á': 'A', 'É': 'E', 'Í': 'I', 'Ó': 'O', 'Ú': 'U', 'Ü': 'U', 'Ñ': 'N',
+}
+
+// textWidth estima el ancho del texto en puntos. La negrita se aproxima
+// con un 8% más que la regular.
+func textWidth(s string, size float64, bold bool) float64 {
+	total := 0
+	for _, r := range s {
+		if base, ok := baseLetter[r]; ok {
+			r = base
+		}
+		if r >= 32 && r < 127 {
+			total += helveticaWidths[r-32]
+		} else {
+			total += 556
+		}
+	}
+	width := float64(total) * size / 1000
+	if bold {
+		width *= 1.08
+	}
+	return width
+}
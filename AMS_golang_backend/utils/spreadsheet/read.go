@@ -0,0 +1,277 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+var ErrFormatoNoSoportado = errors.New("formato de planilla no soportado (se acepta .csv o .xlsx)")
+
+// Límites de lo que se lee de una planilla. Un .xlsx chico puede declarar filas
+// o celdas muy lejanas, o descomprimirse en un XML enorme.
+const (
+	MaxFilas    = 10000
+	MaxColumnas = 256
+	// Tamaño máximo de cada XML del .xlsx ya descomprimido
+	maxXMLSize = 32 << 20
+)
+
+var (
+	ErrDemasiadasFilas    = fmt.Errorf("la planilla supera las %d filas", MaxFilas)
+	ErrDemasiadasColumnas = fmt.Errorf("la planilla supera las %d columnas", MaxColumnas)
+)
+
+// Read lee la primera hoja de una planilla según la extensión del nombre.
+// Devuelve las filas como texto; las celdas vacías quedan como "".
+func Read(nombre string, r io.Reader) ([][]string, error) {
+	switch strings.ToLower(path.Ext(nombre)) {
+	case ".csv":
+		return ReadCSV(r)
+	case ".xlsx":
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		return ReadXLSX(data)
+	default:
+		return nil, ErrFormatoNoSoportado
+	}
+}
+
+// ReadCSV lee un CSV separado por coma o por punto y coma (el que exporta Excel
+// en español), con o sin BOM
+func ReadCSV(r io.Reader) ([][]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	var rows [][]string
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) == MaxFilas {
+			return nil, ErrDemasiadasFilas
+		}
+		if len(row) > MaxColumnas {
+			return nil, ErrDemasiadasColumnas
+		}
+		rows = append(rows, row)
+	}
+}
+
+// Estructuras mínimas de SpreadsheetML para leer valores de celdas
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxRichText `xml:"si"`
+}
+
+// xlsxRichText es un texto simple (<t>) o con formato (<r><t>)
+type xlsxRichText struct {
+	T string `xml:"t"`
+	R []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxRichText) String() string {
+	if len(t.R) == 0 {
+		return t.T
+	}
+	var b strings.Builder
+	for _, r := range t.R {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+type xlsxWorksheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			Ref       string       `xml:"r,attr"`
+			Type      string       `xml:"t,attr"`
+			Value     string       `xml:"v"`
+			InlineStr xlsxRichText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// ReadXLSX lee los valores de la primera hoja de un archivo .xlsx
+func ReadXLSX(data []byte) ([][]string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("el archivo no es un .xlsx válido: %v", err)
+	}
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared xlsxSharedStrings
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodeXML(f, &shared); err != nil {
+			return nil, err
+		}
+	}
+
+	sheetFile, ok := files[sheetPath]
+	if !ok {
+		return nil, fmt.Errorf("el .xlsx no contiene la hoja %s", sheetPath)
+	}
+	var sheet xlsxWorksheet
+	if err := decodeXML(sheetFile, &sheet); err != nil {
+		return nil, err
+	}
+
+	var rows [][]string
+	for i, row := range sheet.Rows {
+		// Las filas vacías no aparecen en el XML: se completan para conservar la numeración
+		rowNum := row.R
+		if rowNum == 0 {
+			rowNum = len(rows) + 1
+		}
+		if rowNum > MaxFilas {
+			return nil, ErrDemasiadasFilas
+		}
+		for len(rows) < rowNum-1 {
+			rows = append(rows, nil)
+		}
+
+		var values []string
+		for j, cell := range row.Cells {
+			col := j
+			if cell.Ref != "" {
+				if c, ok := columnIndex(cell.Ref); ok {
+					col = c
+				}
+			}
+			if col >= MaxColumnas {
+				return nil, ErrDemasiadasColumnas
+			}
+			for len(values) <= col {
+				values = append(values, "")
+			}
+			switch cell.Type {
+			case "s":
+				idx, err := strconv.Atoi(cell.Value)
+				if err != nil || idx < 0 || idx >= len(shared.Items) {
+					return nil, fmt.Errorf("fila %d: referencia a texto inválida", i+1)
+				}
+				values[col] = shared.Items[idx].String()
+			case "inlineStr":
+				values[col] = cell.InlineStr.String()
+			default:
+				values[col] = cell.Value
+			}
+		}
+		rows = append(rows, values)
+	}
+	return rows, nil
+}
+
+// firstSheetPath resuelve el archivo de la primera hoja a partir del workbook y sus relaciones
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	const fallback = "xl/worksheets/sheet1.xml"
+	wbFile, ok := files["xl/workbook.xml"]
+	if !ok {
+		return "", errors.New("el .xlsx no contiene xl/workbook.xml")
+	}
+	var wb xlsxWorkbook
+	if err := decodeXML(wbFile, &wb); err != nil {
+		return "", err
+	}
+	relsFile, ok := files["xl/_rels/workbook.xml.rels"]
+	if len(wb.Sheets) == 0 || !ok {
+		return fallback, nil
+	}
+	var rels xlsxRelationships
+	if err := decodeXML(relsFile, &rels); err != nil {
+		return "", err
+	}
+	for _, rel := range rels.Relationships {
+		if rel.ID == wb.Sheets[0].RID {
+			if strings.HasPrefix(rel.Target, "/") {
+				return strings.TrimPrefix(rel.Target, "/"), nil
+			}
+			return path.Join("xl", rel.Target), nil
+		}
+	}
+	return fallback, nil
+}
+
+func decodeXML(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	// Se lee un byte más que el límite para distinguir un XML truncado de uno demasiado grande
+	limitado := &io.LimitedReader{R: rc, N: maxXMLSize + 1}
+	if err := xml.NewDecoder(limitado).Decode(v); err != nil {
+		if limitado.N == 0 {
+			return fmt.Errorf("%s supera el tamaño máximo", f.Name)
+		}
+		return fmt.Errorf("error leyendo %s: %v", f.Name, err)
+	}
+	return nil
+}
+
+// columnIndex convierte la referencia de una celda ("C12") en el índice de su
+// columna (2). Pasado MaxColumnas deja de acumular para no desbordar.
+func columnIndex(ref string) (int, bool) {
+	col := 0
+	n := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		if col <= MaxColumnas {
+			col = col*26 + int(r-'A'+1)
+		}
+		n++
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return col - 1, true
+}
@@ -0,0 +1,119 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// xlsx arma un .xlsx mínimo con la hoja dada (sin relaciones: se usa sheet1.xml)
+func xlsx(t *testing.T, sheetData string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for nombre, contenido := range map[string]string{
+		"xl/workbook.xml":          `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"/>`,
+		"xl/sharedStrings.xml":     `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><si><t>legajo</t></si><si><r><t>no</t></r><r><t>ta</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` + sheetData + `</sheetData></worksheet>`,
+	} {
+		w, err := zw.Create(nombre)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(contenido))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestReadXLSX(t *testing.T) {
+	tests := []struct {
+		name      string
+		sheetData string
+		want      [][]string
+		wantErr   error
+	}{
+		{
+			name:      "textos compartidos, inline y números",
+			sheetData: `<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row><row r="2"><c r="A2" t="inlineStr"><is><t>1234</t></is></c><c r="B2"><v>7.5</v></c></row>`,
+			want:      [][]string{{"legajo", "nota"}, {"1234", "7.5"}},
+		},
+		{
+			name:      "filas y celdas salteadas",
+			sheetData: `<row r="1"><c r="A1"><v>1</v></c></row><row r="3"><c r="C3"><v>3</v></c></row>`,
+			want:      [][]string{{"1"}, nil, {"", "", "3"}},
+		},
+		{
+			name:      "última fila permitida",
+			sheetData: `<row r="10000"><c r="IV10000"><v>x</v></c></row>`,
+		},
+		{
+			name:      "fila fuera del límite",
+			sheetData: `<row r="100000000"><c r="A100000000"><v>1</v></c></row>`,
+			wantErr:   ErrDemasiadasFilas,
+		},
+		{
+			name:      "columna fuera del límite",
+			sheetData: `<row r="1"><c r="IW1"><v>1</v></c></row>`,
+			wantErr:   ErrDemasiadasColumnas,
+		},
+		{
+			name:      "referencia de columna enorme",
+			sheetData: `<row r="1"><c r="ZZZZZZZZZZZZZZZZZZZZ1"><v>1</v></c></row>`,
+			wantErr:   ErrDemasiadasColumnas,
+		},
+		{
+			name:      "texto compartido inexistente",
+			sheetData: `<row r="1"><c r="A1" t="s"><v>9</v></c></row>`,
+			wantErr:   errors.New("referencia a texto inválida"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := ReadXLSX(xlsx(t, tt.sheetData))
+			switch {
+			case tt.wantErr == nil && err != nil:
+				t.Fatalf("ReadXLSX() error = %v", err)
+			case tt.wantErr != nil && err == nil:
+				t.Fatalf("ReadXLSX() error = nil, want %v", tt.wantErr)
+			case tt.wantErr != nil && !errors.Is(err, tt.wantErr) && !strings.Contains(err.Error(), tt.wantErr.Error()):
+				t.Fatalf("ReadXLSX() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.want != nil && !reflect.DeepEqual(rows, tt.want) {
+				t.Errorf("ReadXLSX() = %q, want %q", rows, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    [][]string
+		wantErr error
+	}{
+		{name: "separado por coma", data: "legajo,nota\n1234,7\n", want: [][]string{{"legajo", "nota"}, {"1234", "7"}}},
+		{name: "punto y coma con BOM", data: "\xef\xbb\xbflegajo;nota\n1234;7,5\n", want: [][]string{{"legajo", "nota"}, {"1234", "7,5"}}},
+		{name: "demasiadas filas", data: strings.Repeat("1\n", MaxFilas+1), wantErr: ErrDemasiadasFilas},
+		{name: "demasiadas columnas", data: strings.Repeat("x,", MaxColumnas) + "x\n", wantErr: ErrDemasiadasColumnas},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := ReadCSV(strings.NewReader(tt.data))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ReadCSV() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.want != nil && !reflect.DeepEqual(rows, tt.want) {
+				t.Errorf("ReadCSV() = %q, want %q", rows, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxLines es el máximo de líneas por lado que se comparan (el algoritmo es O(n*m))
+const MaxLines = 2000
+
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+type op struct {
+	kind opKind
+	line string
+}
+
+// Unified devuelve el diff en formato unificado entre a y b (con `context`
+// líneas de contexto alrededor de cada cambio) y la cantidad de líneas
+// agregadas y quitadas. Devuelve error si algún lado supera MaxLines.
+func Unified(nameA, nameB, a, b string, context int) (string, int, int, error) {
+	linesA := splitLines(a)
+	linesB := splitLines(b)
+	if len(linesA) > MaxLines || len(linesB) > MaxLines {
+		return "", 0, 0, fmt.Errorf("los archivos superan las %d líneas", MaxLines)
+	}
+
+	ops := diffLines(linesA, linesB)
+
+	added, removed := 0, 0
+	for _, o := range ops {
+		switch o.kind {
+		case opInsert:
+			added++
+		case opDelete:
+			removed++
+		}
+	}
+	if added == 0 && removed == 0 {
+		return "", 0, 0, nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("--- " + nameA + "\n")
+	sb.WriteString("+++ " + nameB + "\n")
+	writeHunks(&sb, ops, context)
+	return sb.String(), added, removed, nil
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines calcula la secuencia de operaciones a partir de la subsecuencia común más larga
+func diffLines(a, b []string) []op {
+	n, m := len(a), len(b)
+	lcs := make([][]int32, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	ops := make([]op, 0, n+m)
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, op{opEqual, a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, op{opDelete, a[i]})
+			i++
+		default:
+			ops = append(ops, op{opInsert, b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, op{opDelete, a[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, op{opInsert, b[j]})
+	}
+	return ops
+}
+
+func writeHunks(sb *strings.Builder, ops []op, context int) {
+	// Índices de las operaciones que son cambios
+	changes := []int{}
+	for idx, o := range ops {
+		if o.kind != opEqual {
+			changes = append(changes, idx)
+		}
+	}
+
+	// Posición (1-based) en a y en b antes de cada operación
+	posA := make([]int, len(ops)+1)
+	posB := make([]int, len(ops)+1)
+	posA[0], posB[0] = 1, 1
+	for idx, o := range ops {
+		posA[idx+1], posB[idx+1] = posA[idx], posB[idx]
+		if o.kind != opInsert {
+			posA[idx+1]++
+		}
+		if o.kind != opDelete {
+			posB[idx+1]++
+		}
+	}
+
+	for c := 0; c < len(changes); {
+		start := max(changes[c]-context, 0)
+		end := changes[c]
+		// Unir los cambios cuyo contexto se superpone
+		for c < len(changes) && changes[c]-end <= 2*context+1 {
+			end = changes[c]
+			c++
+		}
+		end = min(end+context, len(ops)-1)
+
+		countA, countB := 0, 0
+		for idx := start; idx <= end; idx++ {
+			if ops[idx].kind != opInsert {
+				countA++
+			}
+			if ops[idx].kind != opDelete {
+				countB++
+			}
+		}
+		fmt.Fprintf(sb, "@@ -%d,%d +%d,%d @@\n", posA[start], countA, posB[start], countB)
+		for idx := start; idx <= end; idx++ {
+			switch ops[idx].kind {
+			case opEqual:
+				sb.WriteString(" ")
+			case opDelete:
+				sb.WriteString("-")
+			case opInsert:
+				sb.WriteString("+")
+			}
+			sb.WriteString(ops[idx].line + "\n")
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package textdiff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	const ocho = "1\n2\n3\n4\n5\n6\n7\n8\n"
+	const ochoCambiada = "1\nX\n3\n4\n5\n6\nY\n8\n"
+
+	tests := []struct {
+		name        string
+		a, b        string
+		context     int
+		want        string
+		wantAdded   int
+		wantRemoved int
+	}{
+		{
+			name: "iguales salvo el salto de línea final",
+			a:    "uno\ndos\n",
+			b:    "uno\ndos",
+		},
+		{
+			name: "iguales salvo CRLF",
+			a:    "uno\r\ndos\r\n",
+			b:    "uno\ndos\n",
+		},
+		{
+			name:        "una línea cambiada",
+			a:           "uno\ndos\ntres\n",
+			b:           "uno\nDOS\ntres\n",
+			context:     1,
+			want:        "--- a\n+++ b\n@@ -1,3 +1,3 @@\n uno\n-dos\n+DOS\n tres\n",
+			wantAdded:   1,
+			wantRemoved: 1,
+		},
+		{
+			name:        "líneas agregadas al final",
+			a:           "uno\n",
+			b:           "uno\ndos\ntres\n",
+			context:     3,
+			want:        "--- a\n+++ b\n@@ -1,1 +1,3 @@\n uno\n+dos\n+tres\n",
+			wantAdded:   2,
+			wantRemoved: 0,
+		},
+		{
+			name:        "cambios lejanos en hunks separados",
+			a:           ocho,
+			b:           ochoCambiada,
+			context:     1,
+			want:        "--- a\n+++ b\n@@ -1,3 +1,3 @@\n 1\n-2\n+X\n 3\n@@ -6,3 +6,3 @@\n 6\n-7\n+Y\n 8\n",
+			wantAdded:   2,
+			wantRemoved: 2,
+		},
+		{
+			name:        "contextos superpuestos en un solo hunk",
+			a:           ocho,
+			b:           ochoCambiada,
+			context:     3,
+			want:        "--- a\n+++ b\n@@ -1,8 +1,8 @@\n 1\n-2\n+X\n 3\n 4\n 5\n 6\n-7\n+Y\n 8\n",
+			wantAdded:   2,
+			wantRemoved: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, added, removed, err := Unified("a", "b", tt.a, tt.b, tt.context)
+			if err != nil {
+				t.Fatalf("Unified() error = %v", err)
+			}
+			if diff != tt.want {
+				t.Errorf("diff =\n%s\nwant\n%s", diff, tt.want)
+			}
+			if added != tt.wantAdded || removed != tt.wantRemoved {
+				t.Errorf("added, removed = %d, %d; want %d, %d", added, removed, tt.wantAdded, tt.wantRemoved)
+			}
+		})
+	}
+}
+
+func TestUnifiedMaxLines(t *testing.T) {
+	largo := strings.Repeat("x\n", MaxLines+1)
+	if _, _, _, err := Unified("a", "b", largo, "x\n", 3); err == nil {
+		t.Errorf("Unified() con %d líneas no devolvió error", MaxLines+1)
+	}
+	if _, _, _, err := Unified("a", "b", strings.Repeat("x\n", MaxLines), "x\n", 3); err != nil {
+		t.Errorf("Unified() con %d líneas error = %v", MaxLines, err)
+	}
+}
@@ -0,0 +1,190 @@
+package upload
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+// SniffLen es la cantidad de bytes del principio del archivo que se usan para detectar su tipo
+const SniffLen = 3072
+
+// Tamaño máximo global por defecto si no se define UPLOAD_MAX_MB
+const defaultMaxMB = 100
+
+// Códigos de rechazo que se devuelven al cliente
+const (
+	CodeArchivoVacio         = "archivo_vacio"
+	CodeArchivoMuyGrande     = "archivo_muy_grande"
+	CodeExtensionNoPermitida = "extension_no_permitida"
+	CodeTipoNoCoincide       = "tipo_no_coincide"
+	CodeTipoNoPermitido      = "tipo_no_permitido"
+	CodeArchivoNoDisponible  = "archivo_no_disponible"
+)
+
+// Error es un rechazo de validación de un archivo subido
+type Error struct {
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Reglas son las restricciones que debe cumplir un archivo
+type Reglas struct {
+	// MaxSize en bytes (0 = sin límite)
+	MaxSize int64
+	// Extensiones permitidas en minúscula y con punto (vacío = cualquiera)
+	Extensiones []string
+}
+
+// Tipos ejecutables que se rechazan salvo que la extensión esté permitida explícitamente
+var tiposBloqueados = []string{
+	"application/vnd.microsoft.portable-executable",
+	"application/x-elf",
+	"application/x-executable",
+	"application/x-sharedlib",
+	"application/x-mach-binary",
+	"application/x-msi",
+}
+
+// Tipos aceptables para cada extensión; si el contenido no es de ninguno el
+// archivo se rechaza (ej: un .pdf que en realidad es un ejecutable)
+var tiposPorExtension = map[string][]string{
+	".pdf":   {"application/pdf"},
+	".zip":   {"application/zip"},
+	".rar":   {"application/x-rar-compressed"},
+	".7z":    {"application/x-7z-compressed"},
+	".gz":    {"application/gzip"},
+	".tar":   {"application/x-tar"},
+	".png":   {"image/png"},
+	".jpg":   {"image/jpeg"},
+	".jpeg":  {"image/jpeg"},
+	".gif":   {"image/gif"},
+	".docx":  {"application/vnd.openxmlformats-officedocument.wordprocessingml.document", "application/zip"},
+	".xlsx":  {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "application/zip"},
+	".pptx":  {"application/vnd.openxmlformats-officedocument.presentationml.presentation", "application/zip"},
+	".ipynb": {"application/json", "text/plain"},
+	".json":  {"application/json", "text/plain"},
+	".txt":   {"text/plain"},
+	".md":    {"text/plain"},
+	".csv":   {"text/csv", "text/plain"},
+	".py":    {"text/plain"},
+	".js":    {"text/plain"},
+	".ts":    {"text/plain"},
+	".java":  {"text/plain"},
+	".c":     {"text/plain"},
+	".h":     {"text/plain"},
+	".cpp":   {"text/plain"},
+	".go":    {"text/plain"},
+	".sql":   {"text/plain"},
+	".html":  {"text/html", "text/plain"},
+	".xml":   {"text/xml", "application/xml", "text/plain"},
+}
+
+// Detectar devuelve el tipo real del contenido a partir de sus primeros bytes
+func Detectar(head []byte) *mimetype.MIME {
+	return mimetype.Detect(head)
+}
+
+// Validar controla tamaño, extensión y tipo real de un archivo subido y
+// devuelve el tipo detectado. Los rechazos son *Error.
+func Validar(nombre string, size int64, head []byte, reglas Reglas) (*mimetype.MIME, error) {
+	if size == 0 {
+		return nil, &Error{CodeArchivoVacio, "El archivo está vacío"}
+	}
+	if err := ValidarTamanoYExtension(nombre, size, reglas); err != nil {
+		return nil, err
+	}
+
+	ext := Extension(nombre)
+	tipo := Detectar(head)
+	if esTipo(tipo, tiposBloqueados) && !contiene(reglas.Extensiones, ext) {
+		return nil, &Error{CodeTipoNoPermitido, fmt.Sprintf("No se permiten archivos ejecutables (%s)", tipo.String())}
+	}
+	if esperados, ok := tiposPorExtension[ext]; ok && !esTipo(tipo, esperados) {
+		return nil, &Error{CodeTipoNoCoincide, fmt.Sprintf("El contenido del archivo (%s) no corresponde a la extensión %s", tipo.String(), ext)}
+	}
+	return tipo, nil
+}
+
+// ValidarTamanoYExtension aplica las reglas que no necesitan el contenido
+// (sirve también para validar un archivo ya guardado contra las reglas de un TP)
+func ValidarTamanoYExtension(nombre string, size int64, reglas Reglas) error {
+	if reglas.MaxSize > 0 && size > reglas.MaxSize {
+		return &Error{CodeArchivoMuyGrande, fmt.Sprintf("El archivo excede el tamaño máximo permitido (%s)", FormatSize(reglas.MaxSize))}
+	}
+	if len(reglas.Extensiones) > 0 && !contiene(reglas.Extensiones, Extension(nombre)) {
+		return &Error{CodeExtensionNoPermitida, "Extensión no permitida. Se aceptan: " + strings.Join(reglas.Extensiones, ", ")}
+	}
+	return nil
+}
+
+// Extension devuelve la extensión del nombre en minúscula (".tar.gz" cuenta como ".gz")
+func Extension(nombre string) string {
+	return strings.ToLower(path.Ext(path.Base(filepath.ToSlash(nombre))))
+}
+
+// ParseExtensiones normaliza una lista separada por comas (".PY, zip" -> [".py", ".zip"])
+func ParseExtensiones(lista string) []string {
+	var extensiones []string
+	for _, ext := range strings.Split(lista, ",") {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		if !contiene(extensiones, ext) {
+			extensiones = append(extensiones, ext)
+		}
+	}
+	return extensiones
+}
+
+// MaxSizeFromEnv devuelve el límite global en bytes (UPLOAD_MAX_MB, por defecto 100MB)
+func MaxSizeFromEnv() int64 {
+	mb := defaultMaxMB
+	if value := os.Getenv("UPLOAD_MAX_MB"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 {
+			mb = parsed
+		}
+	}
+	return int64(mb) << 20
+}
+
+func FormatSize(size int64) string {
+	if size%(1<<20) == 0 {
+		return fmt.Sprintf("%dMB", size>>20)
+	}
+	return fmt.Sprintf("%.1fMB", float64(size)/(1<<20))
+}
+
+// esTipo indica si el tipo detectado (o alguno de sus padres, ej: text/x-python -> text/plain) está en la lista
+func esTipo(tipo *mimetype.MIME, tipos []string) bool {
+	for m := tipo; m != nil; m = m.Parent() {
+		for _, t := range tipos {
+			if m.Is(t) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func contiene(lista []string, valor string) bool {
+	for _, v := range lista {
+		if v == valor {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package upload
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestValidar(t *testing.T) {
+	pdf := []byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\n")
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x02\x00\x00\x00")
+	elf := append([]byte("\x7fELF\x02\x01\x01\x00"), make([]byte, 56)...)
+	elf[16] = 2 // ET_EXEC
+	exe := append([]byte("MZ\x90\x00\x03\x00\x00\x00"), make([]byte, 56)...)
+	python := []byte("def main():\n    print('hola')\n")
+
+	tests := []struct {
+		name     string
+		nombre   string
+		size     int64
+		head     []byte
+		reglas   Reglas
+		wantCode string
+	}{
+		{name: "pdf válido", nombre: "tp1.pdf", size: int64(len(pdf)), head: pdf},
+		{name: "extensión en mayúsculas", nombre: "TP1.PDF", size: int64(len(pdf)), head: pdf, reglas: Reglas{Extensiones: []string{".pdf"}}},
+		{name: "código fuente como texto", nombre: "main.py", size: int64(len(python)), head: python, reglas: Reglas{Extensiones: []string{".py", ".zip"}}},
+		{name: "extensión sin tipos esperados", nombre: "datos.dat", size: 3, head: []byte{0x01, 0x02, 0x03}},
+		{name: "vacío", nombre: "tp1.pdf", size: 0, wantCode: CodeArchivoVacio},
+		{name: "supera el tamaño máximo", nombre: "tp1.pdf", size: 2 << 20, head: pdf, reglas: Reglas{MaxSize: 1 << 20}, wantCode: CodeArchivoMuyGrande},
+		{name: "justo en el tamaño máximo", nombre: "tp1.pdf", size: 1 << 20, head: pdf, reglas: Reglas{MaxSize: 1 << 20}},
+		{name: "extensión no permitida por el TP", nombre: "tp1.docx", size: int64(len(pdf)), head: pdf, reglas: Reglas{Extensiones: []string{".pdf"}}, wantCode: CodeExtensionNoPermitida},
+		{name: "contenido que no corresponde a la extensión", nombre: "foto.jpg", size: int64(len(png)), head: png, wantCode: CodeTipoNoCoincide},
+		{name: "ejecutable ELF disfrazado", nombre: "tp1.pdf", size: int64(len(elf)), head: elf, wantCode: CodeTipoNoPermitido},
+		{name: "ejecutable de Windows disfrazado", nombre: "main.py", size: int64(len(exe)), head: exe, wantCode: CodeTipoNoPermitido},
+		{name: "ejecutable con la extensión permitida por el TP", nombre: "programa.bin", size: int64(len(elf)), head: elf, reglas: Reglas{Extensiones: []string{".bin"}}},
+		{name: "ejecutable permitido pero con otra extensión esperada", nombre: "tp1.pdf", size: int64(len(elf)), head: elf, reglas: Reglas{Extensiones: []string{".pdf"}}, wantCode: CodeTipoNoCoincide},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tipo, err := Validar(tt.nombre, tt.size, tt.head, tt.reglas)
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Fatalf("Validar() error = %v", err)
+				}
+				if tipo == nil {
+					t.Errorf("Validar() no devolvió el tipo detectado")
+				}
+				return
+			}
+			var rechazo *Error
+			if !errors.As(err, &rechazo) {
+				t.Fatalf("Validar() error = %v, want *Error %s", err, tt.wantCode)
+			}
+			if rechazo.Code != tt.wantCode {
+				t.Errorf("Code = %s, want %s (%s)", rechazo.Code, tt.wantCode, rechazo.Message)
+			}
+		})
+	}
+}
+
+func TestParseExtensiones(t *testing.T) {
+	tests := []struct {
+		lista string
+		want  []string
+	}{
+		{lista: "", want: nil},
+		{lista: ".py", want: []string{".py"}},
+		{lista: ".PY, zip", want: []string{".py", ".zip"}},
+		{lista: "pdf, .pdf,,  ", want: []string{".pdf"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.lista, func(t *testing.T) {
+			if got := ParseExtensiones(tt.lista); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseExtensiones(%q) = %q, want %q", tt.lista, got, tt.want)
+			}
+		})
+	}
+}
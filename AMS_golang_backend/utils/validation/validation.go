@@ -2,6 +2,7 @@ package validation
 
 import (
 	"errors"
+	"strconv"
 	"strings"
 
 	"github.com/LINSITrack/backend/src/models"
@@ -34,41 +35,52 @@ func validateUniqueField(db *gorm.DB, model interface{}, field string, value str
 	return nil
 }
 
-// ValidateEmailUniqueness verifica que el email no exista en Admin, Profesor o Alumno.
-// También permite excluir una tabla y un ID específico (caso update).
-func ValidateEmailUniqueness(db *gorm.DB, email string, excludeTable string, excludeID string) error {
-	// Validación mínima del email
-	if email == "" {
-		return errors.New("el email no puede estar vacío")
-	}
-	if !strings.Contains(email, "@") {
-		return errors.New("el email no es válido")
+// ValidateEmailUniqueness verifica que el email no exista en la tabla del rol indicado
+// ("admin", "profesor" o "alumno"), excluyendo opcionalmente un ID (caso update).
+// El mismo email sí puede repetirse entre roles: es la misma persona (ver models.Usuario).
+func ValidateEmailUniqueness(db *gorm.DB, email string, table string, excludeID string) error {
+	if err := validateEmailFormat(email); err != nil {
+		return err
 	}
 
 	// Normalizar para evitar falsos duplicados
 	email = strings.TrimSpace(strings.ToLower(email))
 
-	// Verificar en Admin
-	if excludeTable != "admin" {
-		if err := validateUniqueField(db, &models.Admin{}, "email", email, excludeID); err != nil {
-			return err
-		}
+	switch table {
+	case "admin":
+		return validateUniqueField(db, &models.Admin{}, "email", email, excludeID)
+	case "profesor":
+		return validateUniqueField(db, &models.Profesor{}, "email", email, excludeID)
+	case "alumno":
+		return validateUniqueField(db, &models.Alumno{}, "email", email, excludeID)
 	}
+	return errors.New("tabla inválida")
+}
 
-	// Verificar en Profesor
-	if excludeTable != "profesor" {
-		if err := validateUniqueField(db, &models.Profesor{}, "email", email, excludeID); err != nil {
-			return err
-		}
+// ValidateUsuarioEmailUniqueness verifica que ninguna otra identidad use el email.
+// Se usa al cambiar el email de un perfil, ya que el cambio alcanza al usuario.
+func ValidateUsuarioEmailUniqueness(db *gorm.DB, email string, excludeUsuarioID int) error {
+	if err := validateEmailFormat(email); err != nil {
+		return err
 	}
 
-	// Verificar en Alumno
-	if excludeTable != "alumno" {
-		if err := validateUniqueField(db, &models.Alumno{}, "email", email, excludeID); err != nil {
-			return err
-		}
+	email = strings.TrimSpace(strings.ToLower(email))
+
+	excludeID := ""
+	if excludeUsuarioID != 0 {
+		excludeID = strconv.Itoa(excludeUsuarioID)
 	}
+	return validateUniqueField(db, &models.Usuario{}, "email", email, excludeID)
+}
 
+func validateEmailFormat(email string) error {
+	// Validación mínima del email
+	if email == "" {
+		return errors.New("el email no puede estar vacío")
+	}
+	if !strings.Contains(email, "@") {
+		return errors.New("el email no es válido")
+	}
 	return nil
 }
 
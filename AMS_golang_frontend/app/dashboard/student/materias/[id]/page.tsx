@@ -24,6 +24,7 @@ import {
   evaluacionAPI,
   competenciaAPI,
   materiaCompetenciaAPI,
+  fetchWithRefresh,
 } from "@/lib/api";
 import {
   BookOpen,
@@ -292,8 +293,6 @@ export default function MateriaDetailPage() {
     setProfesorFeedback(feedbackParsed.profesor);
   }, [cursada]);
 
-  const API_URL = process.env.NEXT_PUBLIC_API_URL || "http://localhost:8080";
-
   async function handleFileSelect(
     tpId: number,
     e: ChangeEvent<HTMLInputElement>,
@@ -319,9 +318,8 @@ export default function MateriaDetailPage() {
       const form = new FormData();
       form.append("file", file);
 
-      const uploadRes = await fetch(`${API_URL}/entregas/upload`, {
+      const uploadRes = await fetchWithRefresh(`/entregas/upload`, {
         method: "POST",
-        credentials: "include",
         body: form,
       });
 
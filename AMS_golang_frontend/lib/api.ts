@@ -1,9 +1,49 @@
 const API_URL = process.env.NEXT_PUBLIC_API_URL || "http://localhost:8080";
 
+// The access token is short-lived: on a 401 the session is renewed with the
+// refresh token cookie and the request is retried once. Concurrent 401s share
+// a single /auth/refresh call.
+let refreshing: Promise<boolean> | null = null;
+
+function refreshSession(): Promise<boolean> {
+  if (!refreshing) {
+    refreshing = fetch(`${API_URL}/auth/refresh`, {
+      method: "POST",
+      credentials: "include",
+    })
+      .then((res) => res.ok)
+      .catch(() => false)
+      .finally(() => {
+        refreshing = null;
+      });
+  }
+  return refreshing;
+}
+
+const noRefresh = ["/auth/login", "/auth/refresh", "/auth/logout"];
+
+export async function fetchWithRefresh(
+  endpoint: string,
+  options: RequestInit = {},
+) {
+  const request = () =>
+    fetch(`${API_URL}${endpoint}`, { ...options, credentials: "include" });
+  const response = await request();
+  if (
+    response.status !== 401 ||
+    noRefresh.some((path) => endpoint.startsWith(path))
+  ) {
+    return response;
+  }
+  if (!(await refreshSession())) {
+    return response;
+  }
+  return request();
+}
+
 async function fetchAPI(endpoint: string, options: RequestInit = {}) {
-  const response = await fetch(`${API_URL}${endpoint}`, {
+  const response = await fetchWithRefresh(endpoint, {
     ...options,
-    credentials: "include",
     headers: {
       "Content-Type": "application/json",
       ...options.headers,
@@ -115,9 +155,8 @@ export const entregaAPI = {
       body: JSON.stringify(data),
     }),
   uploadFile: (id: string, formData: FormData) =>
-    fetch(`${API_URL}/entregas/${id}/upload`, {
+    fetchWithRefresh(`/entregas/${id}/upload`, {
       method: "POST",
-      credentials: "include",
       body: formData,
     }).then((res) => {
       if (!res.ok) throw new Error("Upload failed");
@@ -211,7 +250,7 @@ export const cursadaAPI = {
     }),
   update: (
     id: string,
-    data: { nota_final?: number; nota_conceptual?: number; feedback?: string },
+    data: { nota_conceptual?: number; feedback?: string },
   ) =>
     fetchAPI(`/cursadas/${id}`, {
       method: "PATCH",
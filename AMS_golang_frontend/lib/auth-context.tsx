@@ -1,6 +1,7 @@
 "use client"
 
 import { createContext, useContext, useEffect, useState, type ReactNode } from "react"
+import { fetchWithRefresh } from "@/lib/api"
 
 type User = {
   id: number
@@ -32,9 +33,7 @@ export function AuthProvider({ children }: { children: ReactNode }) {
   useEffect(() => {
     const checkSession = async () => {
       try {
-        const res = await fetch(`${API_URL}/auth/whoami`, {
-          credentials: "include",
-        })
+        const res = await fetchWithRefresh("/auth/whoami")
 
         if (res.ok) {
           const data = await res.json()